@@ -19,11 +19,13 @@ import (
 	"yourapp/internal/config"
 	db "yourapp/internal/db/gen"
     "yourapp/internal/handlers"
+    "yourapp/internal/handlers/files"
     "yourapp/internal/logging"
     "yourapp/internal/middleware"
     "yourapp/internal/models"
     "yourapp/internal/repo"
     "yourapp/internal/session"
+    "yourapp/internal/storage"
 )
 
 func main() {
@@ -64,6 +66,29 @@ func main() {
 	q := db.New(pool)
 	r := repo.New(q)
 
+	// --- Background work order trash purge ---
+	retention := time.Duration(cfg.WorkOrders.Trash.RetentionDays) * 24 * time.Hour
+	repo.StartTrashPurger(ctx, r, retention, cfg.WorkOrders.Trash.PurgeInterval)
+
+	// --- Import jobs cut off by the previous shutdown ---
+	if n, err := r.FailInterruptedImportJobs(ctx); err != nil {
+		slog.Error("failing interrupted import jobs", "err", err)
+	} else if n > 0 {
+		slog.Info("marked interrupted import jobs as failed", "count", n)
+	}
+
+	// --- File storage ---
+	store, err := storage.New(cfg.Storage.Backend, cfg.Storage.LocalPath)
+	if err != nil {
+		slog.Error("storage init error", "err", err)
+		os.Exit(1)
+	}
+	uploader := files.NewUploader(r, store, files.Limits{
+		MaxUploadBytes:       cfg.Storage.MaxUploadBytes,
+		DefaultOrgQuotaBytes: cfg.Storage.DefaultOrgQuotaBytes,
+		MaxOrgQuotaBytes:     cfg.Storage.MaxOrgQuotaBytes,
+	})
+
 	// --- Setup OAuth/OIDC providers ---
 	providers := auth.SetupProviders(cfg)
 
@@ -86,9 +111,9 @@ func main() {
 	// --- CORS middleware ---
 	mux.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5500", "http://localhost:3000", "http://127.0.0.1:5500", "http://127.0.0.1:3000"}, // adjust as needed
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"Link", "ETag", "Content-Disposition"},
 		AllowCredentials: true,
 		MaxAge:           300, // Maximum value not ignored by browsers
 	}))
@@ -134,7 +159,7 @@ func main() {
 	})
 
 	// Work orders and tasks routes
-	handlers.RegisterRoutes(mux, r)
+	handlers.RegisterRoutes(mux, r, uploader, cfg.BaseURL)
 
 	// Serve static files from ./static at /static/*
 	mux.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("./static/"))))
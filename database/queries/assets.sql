@@ -1,4 +1,4 @@
--- Search assets used within an organisation with filter + paging
+-- Search assets owned by or used within an organisation with filter + paging
 -- name: SearchOrgAssets :many
 WITH input AS (
   SELECT
@@ -14,10 +14,13 @@ params AS (
   FROM input
 ),
 base AS (
-  SELECT DISTINCT a.id, a.name, a.created_at
+  SELECT a.id, a.name, a.created_at
   FROM assets a
-  JOIN work_order w ON w.asset_id = a.id
-  WHERE w.organisation_id = (SELECT org_id FROM params)
+  WHERE a.organisation_id = (SELECT org_id FROM params)
+     OR EXISTS (
+       SELECT 1 FROM work_order w
+       WHERE w.asset_id = a.id AND w.organisation_id = (SELECT org_id FROM params)
+     )
 ),
 filtered AS (
   SELECT
@@ -0,0 +1,188 @@
+-- name: ListWorkingHours :many
+SELECT
+  h.user_id,
+  COALESCE(u.name, u.email)::text AS user_name,
+  h.weekday,
+  h.start_time,
+  h.end_time,
+  h.time_zone
+FROM user_working_hours h
+JOIN users u ON u.id = h.user_id
+WHERE h.organisation_id = @organisation_id
+  AND (sqlc.narg(user_id)::uuid IS NULL OR h.user_id = sqlc.narg(user_id)::uuid)
+ORDER BY user_name, h.user_id, h.weekday;
+
+-- name: DeleteWorkingHours :exec
+DELETE FROM user_working_hours
+WHERE organisation_id = @organisation_id AND user_id = @user_id;
+
+-- name: InsertWorkingHours :exec
+INSERT INTO user_working_hours (organisation_id, user_id, weekday, start_time, end_time, time_zone)
+VALUES (@organisation_id, @user_id, @weekday, @start_time, @end_time, @time_zone);
+
+-- name: IsTimeZone :one
+SELECT EXISTS (SELECT 1 FROM pg_timezone_names WHERE name = @name)::boolean AS ok;
+
+-- name: ListUserLeave :many
+-- Leave overlapping [range_start, range_end); either bound may be omitted.
+SELECT
+  l.id,
+  l.user_id,
+  COALESCE(u.name, u.email)::text AS user_name,
+  l.starts_at,
+  l.ends_at,
+  l.reason,
+  l.created_by_id,
+  l.created_at
+FROM user_leave l
+JOIN users u ON u.id = l.user_id
+WHERE l.organisation_id = @organisation_id
+  AND (sqlc.narg(user_id)::uuid IS NULL OR l.user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(range_end)::timestamptz IS NULL OR l.starts_at < sqlc.narg(range_end)::timestamptz)
+  AND (sqlc.narg(range_start)::timestamptz IS NULL OR l.ends_at > sqlc.narg(range_start)::timestamptz)
+ORDER BY l.starts_at, l.id;
+
+-- name: GetUserLeave :one
+SELECT
+  l.id,
+  l.user_id,
+  COALESCE(u.name, u.email)::text AS user_name,
+  l.starts_at,
+  l.ends_at,
+  l.reason,
+  l.created_by_id,
+  l.created_at
+FROM user_leave l
+JOIN users u ON u.id = l.user_id
+WHERE l.id = @id AND l.organisation_id = @organisation_id;
+
+-- name: CreateUserLeave :one
+INSERT INTO user_leave (organisation_id, user_id, starts_at, ends_at, reason, created_by_id)
+VALUES (@organisation_id, @user_id, @starts_at, @ends_at, sqlc.narg(reason), @created_by_id)
+RETURNING id;
+
+-- name: DeleteUserLeave :execrows
+DELETE FROM user_leave
+WHERE id = @id AND organisation_id = @organisation_id;
+
+-- name: GetWorkOrderSchedule :one
+-- Locks the work order for a reschedule.
+SELECT
+  w.status,
+  w.archived,
+  w.estimated_start_date,
+  w.estimated_duration,
+  w.primary_user_id,
+  ARRAY(
+    SELECT a.user_id FROM work_order_assigned_to a
+    WHERE a.work_order_id = w.id
+    ORDER BY a.user_id
+  )::uuid[] AS assignee_ids,
+  w.version
+FROM work_order w
+WHERE w.id = @id AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+FOR UPDATE OF w;
+
+-- name: LockScheduleUsers :exec
+-- Serialises reschedules touching the same people until the transaction
+-- ends. Locks are taken in a fixed order to avoid deadlocks.
+SELECT pg_advisory_xact_lock(hashtextextended('schedule:' || u::text, 0))
+FROM (SELECT DISTINCT unnest(@user_ids::uuid[]) AS u ORDER BY 1) s;
+
+-- name: CheckScheduleCapacity :many
+-- Availability of each member in user_ids for [starts_at, ends_at), leaving
+-- work_order_id itself out: overlapping leave and bookings (scheduled work
+-- orders that are not archived, complete or cancelled) and, for users with
+-- working hours, the shift on that local day and the hours of it already
+-- booked.
+WITH people AS (
+  SELECT
+    u.id AS user_id,
+    COALESCE(u.name, u.email)::text AS user_name,
+    (SELECT h.time_zone FROM user_working_hours h
+      WHERE h.organisation_id = @organisation_id AND h.user_id = u.id
+      LIMIT 1) AS time_zone
+  FROM users u
+  JOIN org_memberships m ON m.user_id = u.id AND m.org_id = @organisation_id
+  WHERE u.id = ANY (@user_ids::uuid[])
+),
+shift AS (
+  SELECT
+    p.user_id,
+    p.user_name,
+    p.time_zone,
+    ((@starts_at::timestamptz AT TIME ZONE p.time_zone)::date + h.start_time) AT TIME ZONE p.time_zone AS shift_start,
+    ((@starts_at::timestamptz AT TIME ZONE p.time_zone)::date + h.end_time) AT TIME ZONE p.time_zone AS shift_end
+  FROM people p
+  LEFT JOIN user_working_hours h
+    ON h.organisation_id = @organisation_id
+   AND h.user_id = p.user_id
+   AND h.weekday = EXTRACT(DOW FROM @starts_at::timestamptz AT TIME ZONE p.time_zone)
+),
+booked AS (
+  SELECT
+    x.user_id,
+    w.id AS work_order_id,
+    w.custom_id,
+    w.title,
+    w.estimated_start_date AS starts_at,
+    w.estimated_start_date
+      + make_interval(secs => COALESCE(NULLIF(w.estimated_duration, 0), 1) * 3600) AS ends_at
+  FROM work_order w
+  JOIN LATERAL (
+    SELECT w.primary_user_id AS user_id
+    UNION
+    SELECT a.user_id FROM work_order_assigned_to a WHERE a.work_order_id = w.id
+  ) x ON x.user_id = ANY (@user_ids::uuid[])
+  WHERE w.organisation_id = @organisation_id
+    AND w.id <> @work_order_id
+    AND w.deleted_at IS NULL
+    AND NOT w.archived
+    AND w.status NOT IN ('COMPLETE', 'CANCELLED')
+    AND w.estimated_start_date IS NOT NULL
+    AND w.estimated_start_date < @ends_at::timestamptz + interval '1 day'
+    AND w.estimated_start_date
+      + make_interval(secs => COALESCE(NULLIF(w.estimated_duration, 0), 1) * 3600) > @starts_at::timestamptz - interval '1 day'
+)
+SELECT
+  s.user_id,
+  s.user_name,
+  s.time_zone,
+  s.shift_start::timestamptz AS shift_start,
+  s.shift_end::timestamptz AS shift_end,
+  COALESCE((
+    SELECT SUM(EXTRACT(EPOCH FROM LEAST(b.ends_at, s.shift_end) - GREATEST(b.starts_at, s.shift_start))) / 3600
+    FROM booked b
+    WHERE b.user_id = s.user_id
+      AND b.starts_at < s.shift_end
+      AND b.ends_at > s.shift_start
+  ), 0)::float8 AS shift_booked_hours,
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+             'work_order_id', b.work_order_id,
+             'custom_id', b.custom_id,
+             'title', b.title,
+             'starts_at', b.starts_at,
+             'ends_at', b.ends_at
+           ) ORDER BY b.starts_at, b.work_order_id)
+    FROM booked b
+    WHERE b.user_id = s.user_id
+      AND b.starts_at < @ends_at::timestamptz
+      AND b.ends_at > @starts_at::timestamptz
+  ), '[]'::jsonb)::jsonb AS bookings,
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+             'leave_id', l.id,
+             'starts_at', l.starts_at,
+             'ends_at', l.ends_at,
+             'reason', l.reason
+           ) ORDER BY l.starts_at, l.id)
+    FROM user_leave l
+    WHERE l.organisation_id = @organisation_id
+      AND l.user_id = s.user_id
+      AND l.starts_at < @ends_at::timestamptz
+      AND l.ends_at > @starts_at::timestamptz
+  ), '[]'::jsonb)::jsonb AS leave
+FROM shift s
+ORDER BY s.user_name, s.user_id;
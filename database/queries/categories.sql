@@ -0,0 +1,83 @@
+-- name: ListWorkOrderCategories :many
+SELECT id, name, created_at, organisation_id, description, default_priority,
+       default_estimated_duration, default_team_id, created_by_id, updated_at
+FROM work_order_categories
+WHERE organisation_id = sqlc.arg(organisation_id)
+ORDER BY lower(name);
+
+-- name: GetWorkOrderCategory :one
+SELECT id, name, created_at, organisation_id, description, default_priority,
+       default_estimated_duration, default_team_id, created_by_id, updated_at
+FROM work_order_categories
+WHERE id = sqlc.arg(id)
+  AND organisation_id = sqlc.arg(organisation_id);
+
+-- name: CreateWorkOrderCategory :one
+INSERT INTO work_order_categories (
+  organisation_id, created_by_id, name, description, default_priority,
+  default_estimated_duration, default_team_id
+)
+VALUES (
+  sqlc.arg(organisation_id), sqlc.narg(created_by_id), sqlc.arg(name), sqlc.narg(description),
+  sqlc.narg(default_priority), sqlc.narg(default_estimated_duration), sqlc.narg(default_team_id)
+)
+RETURNING id, name, created_at, organisation_id, description, default_priority,
+          default_estimated_duration, default_team_id, created_by_id, updated_at;
+
+-- name: UpdateWorkOrderCategory :one
+UPDATE work_order_categories
+SET name                       = sqlc.arg(name),
+    description                = sqlc.narg(description),
+    default_priority           = sqlc.narg(default_priority),
+    default_estimated_duration = sqlc.narg(default_estimated_duration),
+    default_team_id            = sqlc.narg(default_team_id),
+    updated_at                 = now()
+WHERE id = sqlc.arg(id)
+  AND organisation_id = sqlc.arg(organisation_id)
+RETURNING id, name, created_at, organisation_id, description, default_priority,
+          default_estimated_duration, default_team_id, created_by_id, updated_at;
+
+-- name: DeleteWorkOrderCategory :execrows
+-- Work orders keep existing and lose their category (ON DELETE SET NULL).
+DELETE FROM work_order_categories
+WHERE id = sqlc.arg(id)
+  AND organisation_id = sqlc.arg(organisation_id);
+
+-- name: CountOrgTaskBases :one
+-- Task bases usable in an org's templates: its own plus global ones.
+SELECT COUNT(*)::bigint AS task_bases
+FROM task_bases
+WHERE id = ANY (sqlc.arg(task_base_ids)::uuid[])
+  AND (organisation_id = sqlc.arg(organisation_id) OR organisation_id IS NULL);
+
+-- name: TeamExists :one
+-- Teams owned by or used within the organisation (see 033).
+SELECT EXISTS (
+  SELECT 1 FROM teams t
+  WHERE t.id = sqlc.arg(id)
+    AND (
+      t.organisation_id = sqlc.arg(organisation_id)
+      OR EXISTS (
+        SELECT 1 FROM work_order w
+        WHERE w.team_id = t.id AND w.organisation_id = sqlc.arg(organisation_id)
+      )
+    )
+)::bool AS exists;
+
+-- name: ClearWorkOrderCategoryTasks :exec
+DELETE FROM work_order_category_tasks
+WHERE category_id = sqlc.arg(category_id);
+
+-- name: AddWorkOrderCategoryTasks :exec
+-- Positions follow the order of the array.
+INSERT INTO work_order_category_tasks (category_id, task_base_id, position)
+SELECT sqlc.arg(category_id), t.task_base_id, t.ord::int
+FROM unnest(sqlc.arg(task_base_ids)::uuid[]) WITH ORDINALITY AS t(task_base_id, ord)
+ON CONFLICT (category_id, task_base_id) DO NOTHING;
+
+-- name: ListWorkOrderCategoryTasks :many
+SELECT ct.category_id, ct.task_base_id, ct.position, tb.label, tb.task_type
+FROM work_order_category_tasks ct
+JOIN task_bases tb ON tb.id = ct.task_base_id
+WHERE ct.category_id = ANY (sqlc.arg(category_ids)::uuid[])
+ORDER BY ct.category_id, ct.position, ct.task_base_id;
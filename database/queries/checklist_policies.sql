@@ -0,0 +1,63 @@
+-- name: ListChecklistPolicies :many
+-- Category policies first, then the org default.
+SELECT
+  cp.id,
+  cp.category_id,
+  c.name AS category_name,
+  cp.require_tasks_complete,
+  cp.require_failure_notes,
+  cp.created_by_id,
+  cp.created_at,
+  cp.updated_at
+FROM checklist_policies cp
+LEFT JOIN work_order_categories c ON c.id = cp.category_id
+WHERE cp.organisation_id = @organisation_id
+ORDER BY (cp.category_id IS NOT NULL) DESC, lower(c.name), cp.created_at;
+
+-- name: GetChecklistPolicy :one
+SELECT
+  cp.id,
+  cp.category_id,
+  c.name AS category_name,
+  cp.require_tasks_complete,
+  cp.require_failure_notes,
+  cp.created_by_id,
+  cp.created_at,
+  cp.updated_at
+FROM checklist_policies cp
+LEFT JOIN work_order_categories c ON c.id = cp.category_id
+WHERE cp.id = @id
+  AND cp.organisation_id = @organisation_id;
+
+-- name: CreateChecklistPolicy :one
+INSERT INTO checklist_policies (
+  organisation_id, category_id, require_tasks_complete, require_failure_notes, created_by_id
+)
+VALUES (
+  @organisation_id, sqlc.narg(category_id), @require_tasks_complete, @require_failure_notes, @created_by_id
+)
+RETURNING id;
+
+-- name: UpdateChecklistPolicy :one
+UPDATE checklist_policies
+SET
+  category_id = sqlc.narg(category_id),
+  require_tasks_complete = @require_tasks_complete,
+  require_failure_notes = @require_failure_notes,
+  updated_at = now()
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING id;
+
+-- name: DeleteChecklistPolicy :execrows
+DELETE FROM checklist_policies
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: ListChecklistBlockers :many
+-- The tasks keeping a work order from COMPLETE under its checklist policy.
+SELECT b.task_id, b.label, b.task_type, b.reason
+FROM work_order wo
+CROSS JOIN LATERAL work_order_checklist_blockers(wo.id) b
+WHERE wo.id = @work_order_id
+  AND wo.organisation_id = @organisation_id;
@@ -0,0 +1,147 @@
+-- name: CreateWorkOrderComment :one
+-- Org-scoped insert: returns no rows if the work order (or the parent
+-- comment) does not exist in this organisation.
+INSERT INTO work_order_comments (
+  organisation_id, work_order_id, parent_id, author_id, body
+)
+SELECT w.organisation_id, w.id, sqlc.narg(parent_id)::uuid, @author_id::uuid, @body::text
+FROM work_order w
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+  AND (
+    sqlc.narg(parent_id)::uuid IS NULL
+    OR EXISTS (
+      SELECT 1 FROM work_order_comments p
+      WHERE p.id = sqlc.narg(parent_id)::uuid
+        AND p.work_order_id = w.id
+    )
+  )
+RETURNING *;
+
+-- name: GetWorkOrderComment :one
+SELECT *
+FROM work_order_comments
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL;
+
+-- name: UpdateWorkOrderCommentBody :one
+UPDATE work_order_comments
+SET
+  body = @body,
+  edited_at = now(),
+  updated_at = now()
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SoftDeleteWorkOrderComment :execrows
+UPDATE work_order_comments
+SET
+  deleted_at = now(),
+  updated_at = now()
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL;
+
+-- name: CountOrgMembers :one
+SELECT COUNT(DISTINCT user_id)::bigint AS members
+FROM org_memberships
+WHERE org_id = @org_id
+  AND user_id = ANY (@user_ids::uuid[]);
+
+-- name: ReplaceWorkOrderCommentMentions :exec
+-- Removes mentions not in user_ids and adds the new ones (members only).
+-- Existing rows are kept rather than deleted and re-inserted: both halves of
+-- the statement see the same snapshot.
+WITH cleared AS (
+  DELETE FROM work_order_comment_mentions
+  WHERE comment_id = @comment_id
+    AND NOT (user_id = ANY (@user_ids::uuid[]))
+)
+INSERT INTO work_order_comment_mentions (comment_id, user_id)
+SELECT @comment_id, m.user_id
+FROM org_memberships m
+WHERE m.org_id = @org_id
+  AND m.user_id = ANY (@user_ids::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: ListWorkOrderComments :many
+SELECT
+  c.id,
+  c.parent_id,
+  c.author_id,
+  u.name AS author_name,
+  CASE WHEN c.deleted_at IS NULL THEN c.body ELSE '' END::text AS body,
+  c.created_at,
+  c.edited_at,
+  (c.deleted_at IS NOT NULL)::boolean AS deleted,
+  COALESCE(
+    (
+      SELECT jsonb_agg(jsonb_build_object('user_id', mu.id, 'name', mu.name) ORDER BY mu.name)
+      FROM work_order_comment_mentions cm
+      JOIN users mu ON mu.id = cm.user_id
+      WHERE cm.comment_id = c.id
+    ),
+    '[]'::jsonb
+  )::jsonb AS mentions
+FROM work_order_comments c
+LEFT JOIN users u ON u.id = c.author_id
+WHERE c.work_order_id = @work_order_id
+  AND c.organisation_id = @organisation_id
+ORDER BY c.created_at, c.id;
+
+-- name: ListWorkOrderTimeline :many
+-- Comments, status moves and field edits merged into one chronological feed.
+SELECT
+  t.kind,
+  t.id,
+  t.occurred_at,
+  t.actor_id,
+  u.name AS actor_name,
+  t.data
+FROM (
+  SELECT
+    'comment'::text AS kind,
+    c.id,
+    c.created_at AS occurred_at,
+    c.author_id AS actor_id,
+    jsonb_build_object(
+      'parent_id', c.parent_id,
+      'body',      CASE WHEN c.deleted_at IS NULL THEN c.body ELSE '' END,
+      'edited_at', c.edited_at,
+      'deleted',   c.deleted_at IS NOT NULL
+    ) AS data
+  FROM work_order_comments c
+  WHERE c.work_order_id = @work_order_id
+
+  UNION ALL
+
+  SELECT
+    'status'::text,
+    h.id,
+    h.changed_at,
+    h.changed_by_id,
+    jsonb_build_object('from', h.from_status, 'to', h.to_status, 'reason', h.reason)
+  FROM work_order_status_history h
+  WHERE h.work_order_id = @work_order_id
+
+  UNION ALL
+
+  SELECT
+    'field'::text,
+    fc.id,
+    fc.changed_at,
+    fc.changed_by_id,
+    jsonb_build_object('field', fc.field, 'old', fc.old_value, 'new', fc.new_value)
+  FROM work_order_field_changes fc
+  WHERE fc.work_order_id = @work_order_id
+) t
+JOIN work_order wo ON wo.id = @work_order_id AND wo.organisation_id = @organisation_id AND wo.deleted_at IS NULL
+LEFT JOIN users u ON u.id = t.actor_id
+ORDER BY t.occurred_at, t.kind, t.id;
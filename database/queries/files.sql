@@ -0,0 +1,115 @@
+-- name: LockOrgStorage :exec
+-- Serialises uploads per org so concurrent requests cannot overshoot the quota.
+SELECT pg_advisory_xact_lock(hashtextextended(@organisation_id::uuid::text, 0));
+
+-- name: CreateFile :one
+-- Quota-guarded insert: returns no rows when the upload would take the org
+-- over its quota (org_storage_quotas override, else @default_quota).
+INSERT INTO files (
+  organisation_id, uploaded_by_id, path, filename, storage_backend, content_type, size_bytes, sha256
+)
+SELECT
+  @organisation_id::uuid, @uploaded_by_id::uuid, @path::text, @filename::text,
+  @storage_backend::text, @content_type::text, @size_bytes::bigint, @sha256::text
+WHERE (
+  SELECT COALESCE(SUM(f.size_bytes), 0) FROM files f WHERE f.organisation_id = @organisation_id::uuid
+) + @size_bytes::bigint <= COALESCE(
+  (SELECT q.quota_bytes FROM org_storage_quotas q WHERE q.organisation_id = @organisation_id::uuid),
+  @default_quota::bigint
+)
+RETURNING *;
+
+-- name: GetFile :one
+SELECT *
+FROM files
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: DeleteFile :one
+DELETE FROM files
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING *;
+
+-- name: GetOrgStorageUsage :one
+SELECT
+  COALESCE(SUM(size_bytes), 0)::bigint AS used_bytes,
+  COUNT(*)::bigint AS file_count
+FROM files
+WHERE organisation_id = @organisation_id;
+
+-- name: GetOrgStorageQuota :one
+SELECT quota_bytes
+FROM org_storage_quotas
+WHERE organisation_id = @organisation_id;
+
+-- name: SetOrgStorageQuota :exec
+INSERT INTO org_storage_quotas (organisation_id, quota_bytes)
+VALUES (@organisation_id, @quota_bytes)
+ON CONFLICT (organisation_id) DO UPDATE
+SET quota_bytes = EXCLUDED.quota_bytes,
+    updated_at  = now();
+
+-- name: AttachFileToWorkOrder :execrows
+INSERT INTO work_order_files (work_order_id, file_id)
+SELECT w.id, f.id
+FROM work_order w
+JOIN files f ON f.organisation_id = w.organisation_id
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+  AND f.id = @file_id
+ON CONFLICT DO NOTHING;
+
+-- name: DetachFileFromWorkOrder :execrows
+DELETE FROM work_order_files wf
+USING work_order w
+WHERE wf.work_order_id = w.id
+  AND w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+  AND wf.file_id = @file_id;
+
+-- name: ListWorkOrderFiles :many
+SELECT f.*
+FROM files f
+JOIN work_order_files wf ON wf.file_id = f.id
+JOIN work_order w ON w.id = wf.work_order_id
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+ORDER BY f.created_at, f.id;
+
+-- name: AttachFileToTask :execrows
+INSERT INTO task_files (task_id, file_id)
+SELECT t.id, f.id
+FROM tasks t
+JOIN files f ON f.organisation_id = t.organisation_id
+WHERE t.id = @task_id
+  AND t.organisation_id = @organisation_id
+  AND f.id = @file_id
+ON CONFLICT DO NOTHING;
+
+-- name: DetachFileFromTask :execrows
+DELETE FROM task_files tf
+USING tasks t
+WHERE tf.task_id = t.id
+  AND t.id = @task_id
+  AND t.organisation_id = @organisation_id
+  AND tf.file_id = @file_id;
+
+-- name: ListTaskFiles :many
+SELECT f.*
+FROM files f
+JOIN task_files tf ON tf.file_id = f.id
+JOIN tasks t ON t.id = tf.task_id
+WHERE t.id = @task_id
+  AND t.organisation_id = @organisation_id
+ORDER BY f.created_at, f.id;
+
+-- name: TaskExists :one
+SELECT EXISTS (
+  SELECT 1 FROM tasks
+  WHERE id = @task_id
+    AND organisation_id = @organisation_id
+) AS exists;
@@ -0,0 +1,132 @@
+-- name: CreateImportJob :one
+INSERT INTO import_jobs (
+  organisation_id, kind, dry_run, filename, total_rows, created_by_id
+)
+VALUES (
+  @organisation_id, @kind, @dry_run, @filename, @total_rows, @created_by_id
+)
+RETURNING id;
+
+-- name: GetImportJob :one
+SELECT
+  id, kind, dry_run, status, filename, total_rows, processed_rows, created_rows,
+  error_count, errors, message, created_by_id, created_at, started_at, finished_at
+FROM import_jobs
+WHERE id = @id AND organisation_id = @organisation_id;
+
+-- name: ListImportJobs :many
+-- Most recent first; errors are left out, fetch a job for them.
+SELECT
+  id, kind, dry_run, status, filename, total_rows, processed_rows, created_rows,
+  error_count, message, created_by_id, created_at, started_at, finished_at
+FROM import_jobs
+WHERE organisation_id = @organisation_id
+ORDER BY created_at DESC
+LIMIT 50;
+
+-- name: StartImportJob :exec
+UPDATE import_jobs
+SET status = 'RUNNING', started_at = now()
+WHERE id = @id;
+
+-- name: UpdateImportJobProgress :exec
+UPDATE import_jobs
+SET processed_rows = @processed_rows, error_count = @error_count
+WHERE id = @id;
+
+-- name: FailInterruptedImportJobs :execrows
+-- Jobs still QUEUED or RUNNING when the server starts lost their goroutine
+-- with the previous process; their transaction was rolled back.
+UPDATE import_jobs
+SET
+  status      = 'FAILED',
+  message     = @message,
+  finished_at = now()
+WHERE status IN ('QUEUED', 'RUNNING');
+
+-- name: FinishImportJob :exec
+UPDATE import_jobs
+SET
+  status         = @status,
+  processed_rows = @processed_rows,
+  created_rows   = @created_rows,
+  error_count    = @error_count,
+  errors         = @errors,
+  message        = @message,
+  finished_at    = now()
+WHERE id = @id;
+
+-- name: FindLocationsByRef :many
+-- Locations of the organisation (owned, or used by its work orders) whose
+-- custom ID or name is ref, custom ID matches first. Two rows are enough to
+-- tell a unique match from an ambiguous one.
+SELECT
+  l.id,
+  COALESCE(lower(l.custom_id) = lower(@ref::text), false) AS by_custom_id
+FROM locations l
+WHERE (lower(l.custom_id) = lower(@ref::text) OR lower(l.name) = lower(@ref::text))
+  AND (
+    l.organisation_id = @organisation_id
+    OR EXISTS (
+      SELECT 1 FROM work_order w
+      WHERE w.location_id = l.id AND w.organisation_id = @organisation_id
+    )
+  )
+ORDER BY by_custom_id DESC, l.created_at
+LIMIT 2;
+
+-- name: FindAssetsByRef :many
+-- Same lookup as FindLocationsByRef, for assets.
+SELECT
+  a.id,
+  COALESCE(lower(a.custom_id) = lower(@ref::text), false) AS by_custom_id
+FROM assets a
+WHERE (lower(a.custom_id) = lower(@ref::text) OR lower(a.name) = lower(@ref::text))
+  AND (
+    a.organisation_id = @organisation_id
+    OR EXISTS (
+      SELECT 1 FROM work_order w
+      WHERE w.asset_id = a.id AND w.organisation_id = @organisation_id
+    )
+  )
+ORDER BY by_custom_id DESC, a.created_at
+LIMIT 2;
+
+-- name: FindTeamsByName :many
+-- Teams of the organisation (owned, or used by its work orders), by name.
+SELECT t.id
+FROM teams t
+WHERE lower(t.name) = lower(@name::text)
+  AND (
+    t.organisation_id = @organisation_id
+    OR EXISTS (
+      SELECT 1 FROM work_order w
+      WHERE w.team_id = t.id AND w.organisation_id = @organisation_id
+    )
+  )
+ORDER BY t.created_at
+LIMIT 2;
+
+-- name: FindCategoriesByName :many
+SELECT id
+FROM work_order_categories
+WHERE organisation_id = @organisation_id
+  AND lower(name) = lower(@name::text)
+LIMIT 2;
+
+-- name: FindMemberByEmail :one
+SELECT u.id
+FROM users u
+JOIN org_memberships m ON m.user_id = u.id
+WHERE m.org_id = @organisation_id
+  AND lower(u.email) = lower(@email::text);
+
+-- name: CreateImportedLocation :one
+INSERT INTO locations (organisation_id, name, custom_id)
+VALUES (@organisation_id, @name, @custom_id)
+RETURNING id;
+
+-- name: CreateImportedAsset :one
+INSERT INTO assets (organisation_id, name, custom_id, location_id)
+VALUES (@organisation_id, @name, @custom_id, @location_id)
+RETURNING id;
@@ -0,0 +1,180 @@
+-- name: ListParts :many
+-- Catalogue search on part number and description. Archived parts are only
+-- returned when include_archived is set.
+SELECT
+  id,
+  part_number,
+  revision,
+  description,
+  category,
+  uom,
+  unit_cost::float8 AS unit_cost,
+  tracking,
+  archived,
+  created_by_id,
+  created_at,
+  updated_at
+FROM parts
+WHERE organisation_id = @organisation_id
+  AND (@include_archived::boolean OR NOT archived)
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR part_number ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR description ILIKE '%' || sqlc.narg(search)::text || '%'
+  )
+ORDER BY lower(part_number), lower(revision);
+
+-- name: GetPart :one
+SELECT
+  id,
+  part_number,
+  revision,
+  description,
+  category,
+  uom,
+  unit_cost::float8 AS unit_cost,
+  tracking,
+  archived,
+  created_by_id,
+  created_at,
+  updated_at
+FROM parts
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: CreatePart :one
+INSERT INTO parts (
+  organisation_id, part_number, revision, description, category, uom, unit_cost, tracking, archived, created_by_id
+)
+VALUES (
+  @organisation_id, @part_number, @revision, sqlc.narg(description), @category, @uom,
+  @unit_cost::float8, @tracking, @archived, @created_by_id
+)
+RETURNING id;
+
+-- name: UpdatePart :one
+UPDATE parts
+SET
+  part_number = @part_number,
+  revision = @revision,
+  description = sqlc.narg(description),
+  category = @category,
+  uom = @uom,
+  unit_cost = @unit_cost::float8,
+  tracking = @tracking,
+  archived = @archived,
+  updated_at = now()
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING id;
+
+-- name: DeletePart :execrows
+DELETE FROM parts
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: ListWorkOrderParts :many
+SELECT
+  wp.id,
+  wp.work_order_id,
+  wp.part_id,
+  p.part_number,
+  p.revision,
+  p.description AS part_description,
+  p.uom,
+  wp.quantity::float8 AS quantity,
+  wp.serial_or_batch,
+  wp.unit_cost::float8 AS unit_cost,
+  wp.notes,
+  wp.used_at,
+  wp.created_by_id,
+  wp.created_at
+FROM work_order_parts wp
+JOIN parts p ON p.id = wp.part_id
+WHERE wp.work_order_id = @work_order_id
+  AND wp.organisation_id = @organisation_id
+ORDER BY wp.used_at, wp.id;
+
+-- name: GetWorkOrderPart :one
+SELECT
+  wp.id,
+  wp.work_order_id,
+  wp.part_id,
+  p.part_number,
+  p.revision,
+  p.description AS part_description,
+  p.uom,
+  wp.quantity::float8 AS quantity,
+  wp.serial_or_batch,
+  wp.unit_cost::float8 AS unit_cost,
+  wp.notes,
+  wp.used_at,
+  wp.created_by_id,
+  wp.created_at
+FROM work_order_parts wp
+JOIN parts p ON p.id = wp.part_id
+WHERE wp.id = @id
+  AND wp.work_order_id = @work_order_id
+  AND wp.organisation_id = @organisation_id;
+
+-- name: AddWorkOrderPart :one
+-- Org-scoped insert: returns no rows if the work order does not exist in
+-- this organisation.
+INSERT INTO work_order_parts (
+  organisation_id, work_order_id, part_id, quantity, serial_or_batch, unit_cost, notes, used_at, created_by_id
+)
+SELECT w.organisation_id, w.id, @part_id::uuid, @quantity::float8, sqlc.narg(serial_or_batch)::text,
+       @unit_cost::float8, sqlc.narg(notes)::text, @used_at::timestamptz, @created_by_id::uuid
+FROM work_order w
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+RETURNING id;
+
+-- name: UpdateWorkOrderPart :one
+UPDATE work_order_parts
+SET
+  part_id = @part_id::uuid,
+  quantity = @quantity::float8,
+  serial_or_batch = sqlc.narg(serial_or_batch)::text,
+  unit_cost = @unit_cost::float8,
+  notes = sqlc.narg(notes)::text,
+  used_at = @used_at::timestamptz,
+  updated_at = now()
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+RETURNING id;
+
+-- name: DeleteWorkOrderPart :execrows
+DELETE FROM work_order_parts
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id;
+
+-- name: ListPartConsumption :many
+-- Parts consumed per month (UTC, "YYYY-MM"), asset and part within
+-- [period_start, period_end). Work orders without an asset are grouped under
+-- a NULL asset.
+SELECT
+  to_char(date_trunc('month', wp.used_at AT TIME ZONE 'UTC'), 'YYYY-MM')::text AS month,
+  w.asset_id,
+  a.name AS asset_name,
+  p.id AS part_id,
+  p.part_number,
+  p.revision,
+  p.category,
+  p.uom,
+  SUM(wp.quantity)::float8 AS quantity,
+  SUM(wp.quantity * wp.unit_cost)::float8 AS cost,
+  COUNT(DISTINCT w.id)::bigint AS work_orders
+FROM work_order_parts wp
+JOIN work_order w ON w.id = wp.work_order_id AND w.deleted_at IS NULL
+JOIN parts p ON p.id = wp.part_id
+LEFT JOIN assets a ON a.id = w.asset_id
+WHERE wp.organisation_id = @organisation_id
+  AND wp.used_at >= @period_start::timestamptz
+  AND wp.used_at < @period_end::timestamptz
+  AND (sqlc.narg(asset_id)::uuid IS NULL OR w.asset_id = sqlc.narg(asset_id)::uuid)
+GROUP BY 1, w.asset_id, a.name, p.id
+ORDER BY 1, a.name NULLS LAST, lower(p.part_number), lower(p.revision);
@@ -0,0 +1,106 @@
+-- name: ListScheduleEvents :many
+-- Work orders on the calendar between range_start and range_end: scheduled
+-- ones overlapping the range, plus unscheduled ones due in it. Archived and
+-- cancelled work orders are left out. people is the primary worker plus the
+-- assignees; conflicts lists other scheduled work orders that overlap in
+-- time and share someone, with the shared user IDs.
+WITH wo AS (
+  SELECT
+    w.id,
+    w.custom_id,
+    w.title,
+    w.status,
+    w.priority,
+    w.team_id,
+    w.location_id,
+    w.estimated_start_date AS starts_at,
+    w.estimated_start_date
+      + make_interval(secs => COALESCE(NULLIF(w.estimated_duration, 0), 1) * 3600) AS ends_at,
+    w.due_date,
+    ARRAY(
+      SELECT p.u FROM (
+        SELECT w.primary_user_id AS u
+        UNION
+        SELECT x.user_id FROM work_order_assigned_to x WHERE x.work_order_id = w.id
+      ) p
+      WHERE p.u IS NOT NULL
+      ORDER BY p.u
+    )::uuid[] AS people
+  FROM work_order w
+  WHERE w.organisation_id = @organisation_id
+    AND w.deleted_at IS NULL
+    AND NOT w.archived
+    AND w.status <> 'CANCELLED'
+    AND (
+      (w.estimated_start_date IS NOT NULL
+        AND w.estimated_start_date < @range_end::timestamptz
+        AND w.estimated_start_date
+          + make_interval(secs => COALESCE(NULLIF(w.estimated_duration, 0), 1) * 3600) > @range_start::timestamptz)
+      OR (w.estimated_start_date IS NULL
+        AND w.due_date >= @range_start::timestamptz
+        AND w.due_date <  @range_end::timestamptz)
+    )
+)
+SELECT
+  wo.id,
+  wo.custom_id,
+  wo.title,
+  wo.status,
+  wo.priority,
+  wo.team_id,
+  t.name AS team_name,
+  wo.location_id,
+  l.name AS location_name,
+  wo.starts_at,
+  wo.ends_at,
+  wo.due_date,
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object('id', u.id, 'name', COALESCE(u.name, u.email)) ORDER BY u.name, u.email)
+    FROM users u
+    WHERE u.id = ANY (wo.people)
+  ), '[]'::jsonb)::jsonb AS people,
+  COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+             'work_order_id', o.id,
+             'user_ids', to_jsonb(ARRAY(SELECT unnest(o.people) INTERSECT SELECT unnest(wo.people)))
+           ) ORDER BY o.starts_at, o.id)
+    FROM wo o
+    WHERE o.id <> wo.id
+      AND o.starts_at IS NOT NULL
+      AND wo.starts_at IS NOT NULL
+      AND o.starts_at < wo.ends_at
+      AND wo.starts_at < o.ends_at
+      AND o.people && wo.people
+  ), '[]'::jsonb)::jsonb AS conflicts
+FROM wo
+LEFT JOIN teams t     ON t.id = wo.team_id
+LEFT JOIN locations l ON l.id = wo.location_id
+WHERE (sqlc.narg(user_id)::uuid IS NULL OR sqlc.narg(user_id)::uuid = ANY (wo.people))
+  AND (sqlc.narg(team_id)::uuid IS NULL OR wo.team_id = sqlc.narg(team_id)::uuid)
+ORDER BY COALESCE(wo.starts_at, wo.due_date), wo.id;
+
+-- name: GetCalendarFeed :one
+SELECT created_at, last_used_at
+FROM calendar_feed_tokens
+WHERE organisation_id = @organisation_id AND user_id = @user_id;
+
+-- name: UpsertCalendarFeed :exec
+-- Issues a feed token, replacing any earlier one for the user.
+INSERT INTO calendar_feed_tokens (token_hash, organisation_id, user_id)
+VALUES (@token_hash, @organisation_id, @user_id)
+ON CONFLICT (organisation_id, user_id)
+DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = now(), last_used_at = NULL;
+
+-- name: DeleteCalendarFeed :execrows
+DELETE FROM calendar_feed_tokens
+WHERE organisation_id = @organisation_id AND user_id = @user_id;
+
+-- name: UseCalendarFeed :one
+-- Resolves a feed token for a user who is still a member, and stamps it.
+UPDATE calendar_feed_tokens f
+SET last_used_at = now()
+FROM org_memberships m
+WHERE f.token_hash = @token_hash
+  AND m.org_id = f.organisation_id
+  AND m.user_id = f.user_id
+RETURNING f.organisation_id, f.user_id;
@@ -0,0 +1,64 @@
+-- name: ListSLAPolicies :many
+-- Most specific first, in the order they are matched.
+SELECT
+  sp.id,
+  sp.priority,
+  sp.category_id,
+  c.name AS category_name,
+  sp.react_within_minutes,
+  sp.complete_within_minutes,
+  sp.at_risk_percent,
+  sp.created_by_id,
+  sp.created_at,
+  sp.updated_at
+FROM sla_policies sp
+LEFT JOIN work_order_categories c ON c.id = sp.category_id
+WHERE sp.organisation_id = @organisation_id
+ORDER BY (sp.category_id IS NOT NULL) DESC, (sp.priority IS NOT NULL) DESC,
+         lower(c.name), sp.priority, sp.created_at;
+
+-- name: GetSLAPolicy :one
+SELECT
+  sp.id,
+  sp.priority,
+  sp.category_id,
+  c.name AS category_name,
+  sp.react_within_minutes,
+  sp.complete_within_minutes,
+  sp.at_risk_percent,
+  sp.created_by_id,
+  sp.created_at,
+  sp.updated_at
+FROM sla_policies sp
+LEFT JOIN work_order_categories c ON c.id = sp.category_id
+WHERE sp.id = @id
+  AND sp.organisation_id = @organisation_id;
+
+-- name: CreateSLAPolicy :one
+INSERT INTO sla_policies (
+  organisation_id, priority, category_id, react_within_minutes, complete_within_minutes,
+  at_risk_percent, created_by_id
+)
+VALUES (
+  @organisation_id, sqlc.narg(priority), sqlc.narg(category_id), sqlc.narg(react_within_minutes),
+  sqlc.narg(complete_within_minutes), @at_risk_percent, @created_by_id
+)
+RETURNING id;
+
+-- name: UpdateSLAPolicy :one
+UPDATE sla_policies
+SET
+  priority = sqlc.narg(priority),
+  category_id = sqlc.narg(category_id),
+  react_within_minutes = sqlc.narg(react_within_minutes),
+  complete_within_minutes = sqlc.narg(complete_within_minutes),
+  at_risk_percent = @at_risk_percent,
+  updated_at = now()
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING id;
+
+-- name: DeleteSLAPolicy :execrows
+DELETE FROM sla_policies
+WHERE id = @id
+  AND organisation_id = @organisation_id;
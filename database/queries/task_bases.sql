@@ -0,0 +1,141 @@
+-- name: ListTaskBases :many
+-- The organisation's library plus the global bases. Archived bases are only
+-- returned when include_archived is set.
+SELECT
+  tb.id,
+  tb.organisation_id,
+  tb.label,
+  tb.task_type,
+  tb.user_id,
+  u.name AS user_name,
+  tb.asset_id,
+  a.name AS asset_name,
+  tb.unit,
+  tb.min_value::float8 AS min_value,
+  tb.max_value::float8 AS max_value,
+  tb.meter_id,
+  m.name AS meter_name,
+  tb.optional,
+  tb.archived,
+  (SELECT COUNT(*) FROM tasks t WHERE t.task_base_id = tb.id)::bigint AS usage_count,
+  tb.created_by_id,
+  tb.created_at,
+  tb.updated_at
+FROM task_bases tb
+LEFT JOIN users u ON u.id = tb.user_id
+LEFT JOIN assets a ON a.id = tb.asset_id
+LEFT JOIN meters m ON m.id = tb.meter_id
+WHERE (tb.organisation_id = @organisation_id OR tb.organisation_id IS NULL)
+  AND (@include_archived::boolean OR NOT tb.archived)
+  AND (sqlc.narg(task_type)::text IS NULL OR tb.task_type = sqlc.narg(task_type)::text)
+  AND (sqlc.narg(search)::text IS NULL OR tb.label ILIKE '%' || sqlc.narg(search)::text || '%')
+ORDER BY lower(tb.label), tb.id;
+
+-- name: GetTaskBase :one
+SELECT
+  tb.id,
+  tb.organisation_id,
+  tb.label,
+  tb.task_type,
+  tb.user_id,
+  u.name AS user_name,
+  tb.asset_id,
+  a.name AS asset_name,
+  tb.unit,
+  tb.min_value::float8 AS min_value,
+  tb.max_value::float8 AS max_value,
+  tb.meter_id,
+  m.name AS meter_name,
+  tb.optional,
+  tb.archived,
+  (SELECT COUNT(*) FROM tasks t WHERE t.task_base_id = tb.id)::bigint AS usage_count,
+  tb.created_by_id,
+  tb.created_at,
+  tb.updated_at
+FROM task_bases tb
+LEFT JOIN users u ON u.id = tb.user_id
+LEFT JOIN assets a ON a.id = tb.asset_id
+LEFT JOIN meters m ON m.id = tb.meter_id
+WHERE tb.id = @id
+  AND (tb.organisation_id = @organisation_id OR tb.organisation_id IS NULL);
+
+-- name: ListTaskOptions :many
+SELECT id, task_base_id, label, position
+FROM task_options
+WHERE task_base_id = ANY (@task_base_ids::uuid[])
+ORDER BY task_base_id, position, created_at, id;
+
+-- name: CreateTaskBase :one
+INSERT INTO task_bases (
+  organisation_id, created_by_id, label, task_type, user_id, asset_id,
+  unit, min_value, max_value, meter_id, optional, archived
+)
+VALUES (
+  @organisation_id, @created_by_id, @label, @task_type, sqlc.narg(user_id), sqlc.narg(asset_id),
+  sqlc.narg(unit), sqlc.narg(min_value)::float8, sqlc.narg(max_value)::float8, sqlc.narg(meter_id), @optional, @archived
+)
+RETURNING id;
+
+-- name: UpdateTaskBase :one
+-- Global bases are not matched: only the organisation's own can change.
+UPDATE task_bases
+SET
+  label = @label,
+  task_type = @task_type,
+  user_id = sqlc.narg(user_id),
+  asset_id = sqlc.narg(asset_id),
+  unit = sqlc.narg(unit),
+  min_value = sqlc.narg(min_value)::float8,
+  max_value = sqlc.narg(max_value)::float8,
+  meter_id = sqlc.narg(meter_id),
+  optional = @optional,
+  archived = @archived,
+  updated_at = now()
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING id;
+
+-- name: DeleteTaskBase :execrows
+-- Fails with a foreign key violation while tasks use the base; category and
+-- template task lists drop it (ON DELETE CASCADE).
+DELETE FROM task_bases
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: DeleteTaskOptionsExcept :exec
+DELETE FROM task_options
+WHERE task_base_id = @task_base_id
+  AND NOT (id = ANY (@keep_ids::uuid[]));
+
+-- name: CreateTaskOption :exec
+INSERT INTO task_options (organisation_id, created_by_id, task_base_id, label, position)
+VALUES (@organisation_id, @created_by_id, @task_base_id, @label, @position);
+
+-- name: UpdateTaskOption :execrows
+UPDATE task_options
+SET label = @label,
+    position = @position,
+    updated_at = now()
+WHERE id = @id
+  AND task_base_id = @task_base_id;
+
+-- name: AssetExists :one
+-- Assets owned by or used within the organisation, as in SearchOrgAssets.
+SELECT EXISTS (
+  SELECT 1 FROM assets a
+  WHERE a.id = @id
+    AND (
+      a.organisation_id = @organisation_id
+      OR EXISTS (
+        SELECT 1 FROM work_order w
+        WHERE w.asset_id = a.id AND w.organisation_id = @organisation_id
+      )
+    )
+)::bool AS exists;
+
+-- name: MeterExists :one
+-- Only the organisation's own meters; see 035.
+SELECT EXISTS (
+  SELECT 1 FROM meters
+  WHERE id = @id AND organisation_id = @organisation_id
+)::bool AS exists;
@@ -0,0 +1,76 @@
+-- name: ListTaskFindingRules :many
+SELECT
+  r.task_base_id,
+  tb.label AS task_base_label,
+  tb.task_type,
+  r.on_fail,
+  r.on_flag,
+  r.on_out_of_tolerance,
+  r.priority,
+  r.category_id,
+  c.name AS category_name,
+  r.team_id,
+  tm.name AS team_name,
+  r.due_in_days,
+  r.created_by_id,
+  r.created_at,
+  r.updated_at
+FROM task_finding_rules r
+JOIN task_bases tb ON tb.id = r.task_base_id
+LEFT JOIN work_order_categories c ON c.id = r.category_id
+LEFT JOIN teams tm ON tm.id = r.team_id
+WHERE r.organisation_id = @organisation_id
+ORDER BY lower(tb.label), r.task_base_id;
+
+-- name: GetTaskFindingRule :one
+SELECT
+  r.task_base_id,
+  tb.label AS task_base_label,
+  tb.task_type,
+  r.on_fail,
+  r.on_flag,
+  r.on_out_of_tolerance,
+  r.priority,
+  r.category_id,
+  c.name AS category_name,
+  r.team_id,
+  tm.name AS team_name,
+  r.due_in_days,
+  r.created_by_id,
+  r.created_at,
+  r.updated_at
+FROM task_finding_rules r
+JOIN task_bases tb ON tb.id = r.task_base_id
+LEFT JOIN work_order_categories c ON c.id = r.category_id
+LEFT JOIN teams tm ON tm.id = r.team_id
+WHERE r.organisation_id = @organisation_id
+  AND r.task_base_id = @task_base_id;
+
+-- name: UpsertTaskFindingRule :exec
+INSERT INTO task_finding_rules (
+  organisation_id, task_base_id, on_fail, on_flag, on_out_of_tolerance,
+  priority, category_id, team_id, due_in_days, created_by_id
+)
+VALUES (
+  @organisation_id, @task_base_id, @on_fail, @on_flag, @on_out_of_tolerance,
+  sqlc.narg(priority), sqlc.narg(category_id), sqlc.narg(team_id), sqlc.narg(due_in_days), @created_by_id
+)
+ON CONFLICT (organisation_id, task_base_id) DO UPDATE
+SET
+  on_fail = EXCLUDED.on_fail,
+  on_flag = EXCLUDED.on_flag,
+  on_out_of_tolerance = EXCLUDED.on_out_of_tolerance,
+  priority = EXCLUDED.priority,
+  category_id = EXCLUDED.category_id,
+  team_id = EXCLUDED.team_id,
+  due_in_days = EXCLUDED.due_in_days,
+  updated_at = now();
+
+-- name: DeleteTaskFindingRule :execrows
+DELETE FROM task_finding_rules
+WHERE organisation_id = @organisation_id
+  AND task_base_id = @task_base_id;
+
+-- name: CreateCorrectiveWorkOrder :one
+-- NULL unless a finding rule matches the task's current value.
+SELECT public.create_corrective_work_order(@organisation_id::uuid, @created_by_id::uuid, @task_id::uuid)::uuid AS work_order_id;
@@ -7,13 +7,28 @@ SELECT
   t.created_by_id             AS task_created_by_id,
   t.notes                     AS task_notes,
   t.value                     AS task_value,
+  t.numeric_value::float8     AS task_numeric_value,
+  t.task_option_id            AS task_option_id,
+  t.out_of_tolerance          AS task_out_of_tolerance,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS task_completed,
+  wf.work_order_id            AS task_corrective_work_order_id,
+  t.assignee_id               AS task_assignee_id,
+  au.name                     AS task_assignee_name,
+  t.due_at                    AS task_due_at,
+  t.completed_by_id           AS task_completed_by_id,
+  cu.name                     AS task_completed_by_name,
+  t.completed_at              AS task_completed_at,
   t.work_order_id             AS task_work_order_id,
   t.preventive_maintenance_id AS task_preventive_maintenance_id,
+  t.position                  AS task_position,
 
   -- TaskBase
   tb.id                       AS task_base_id,
   tb.label                    AS task_base_label,
   tb.task_type                AS task_base_type,
+  tb.unit                     AS task_base_unit,
+  tb.min_value::float8        AS task_base_min_value,
+  tb.max_value::float8        AS task_base_max_value,
 
   -- Linked User
   u.id                        AS task_user_id,
@@ -27,6 +42,8 @@ SELECT
   -- Meter
   m.id                        AS task_meter_id,
   m.name                      AS task_meter_name,
+  m.last_value::float8        AS task_meter_last_value,
+  m.last_read_at              AS task_meter_last_read_at,
 
   -- PreventiveMaintenance
   pm.id                       AS pm_id,
@@ -42,7 +59,8 @@ SELECT
     json_agg(
       DISTINCT jsonb_build_object(
         'id', topt.id,
-        'label', topt.label
+        'label', topt.label,
+        'position', topt.position
       )
     ) FILTER (WHERE topt.id IS NOT NULL),
     '[]'
@@ -58,91 +76,262 @@ SELECT
       )
     ) FILTER (WHERE f.id IS NOT NULL),
     '[]'
-  )                           AS task_files
+  )                           AS task_files,
+
+  -- History (oldest first)
+  COALESCE(
+    (SELECT json_agg(
+              jsonb_build_object(
+                'field', h.field,
+                'old_value', h.old_value,
+                'new_value', h.new_value,
+                'changed_by_id', h.changed_by_id,
+                'changed_by_name', hu.name,
+                'changed_at', h.changed_at
+              ) ORDER BY h.changed_at, h.id
+            )
+     FROM task_history h
+     LEFT JOIN users hu ON hu.id = h.changed_by_id
+     WHERE h.task_id = t.id),
+    '[]'
+  )                           AS task_history
 
 FROM tasks t
 JOIN task_bases tb ON tb.id = t.task_base_id
 LEFT JOIN users u ON u.id = tb.user_id
+LEFT JOIN users au ON au.id = t.assignee_id
+LEFT JOIN users cu ON cu.id = t.completed_by_id
 LEFT JOIN assets a ON a.id = tb.asset_id
 LEFT JOIN meters m ON m.id = tb.meter_id
 LEFT JOIN preventive_maintenances pm ON pm.id = t.preventive_maintenance_id
-JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1
+JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1 AND wo.deleted_at IS NULL
 LEFT JOIN task_options topt ON topt.task_base_id = tb.id
 LEFT JOIN task_files tf ON tf.task_id = t.id
 LEFT JOIN files f ON f.id = tf.file_id
+LEFT JOIN work_order_findings wf ON wf.source_task_id = t.id
 
 WHERE t.work_order_id = $2
   AND t.organisation_id = $1
 
 GROUP BY
-  t.id, tb.id, u.id, a.id, m.id, pm.id, wo.id;
+  t.id, tb.id, u.id, au.id, cu.id, a.id, m.id, pm.id, wo.id, wf.work_order_id
+ORDER BY t.position, t.created_at, t.id;
 
 
 -- name: ListSimpleTasksByWorkOrder :many
 SELECT
   t.id AS id,                                                -- UUID
   tb.label AS title,                                         -- task "title"
-  (UPPER(COALESCE(t.value, '')) IN ('COMPLETE','PASS')) AS completed,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS completed,
+  t.out_of_tolerance AS out_of_tolerance,
+  t.assignee_id AS assignee_id,
   u.name AS assignee_name,
+  t.due_at AS due_at,
+  t.completed_at AS completed_at,
+  t.position AS position,
   jsonb_build_object(                                        -- taskBase payload
     'id', tb.id,
     'label', tb.label,
     'taskType', tb.task_type,
     'assetId', tb.asset_id,
-    'meterId', tb.meter_id
+    'meterId', tb.meter_id,
+    'unit', tb.unit,
+    'minValue', tb.min_value,
+    'maxValue', tb.max_value,
+    'optional', tb.optional
   ) AS task_base
 FROM tasks t
 JOIN task_bases tb ON tb.id = t.task_base_id
-LEFT JOIN users u ON u.id = tb.user_id
-JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1
+LEFT JOIN users u ON u.id = t.assignee_id
+JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1 AND wo.deleted_at IS NULL
 WHERE t.organisation_id = $1
   AND t.work_order_id   = $2
-ORDER BY t.created_at ASC;
+ORDER BY t.position, t.created_at ASC;
 
 
 -- name: MarkTaskComplete :one
+-- No row for a task of a trashed work order.
 UPDATE tasks
 SET
   value = 'COMPLETE',
+  updated_by_id = @updated_by_id,
   updated_at = now()
-WHERE organisation_id = $1
-  AND id = $2
+FROM work_order w
+WHERE tasks.organisation_id = @organisation_id
+  AND tasks.id = @id
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM task_bases tb WHERE tb.id = tasks.task_base_id AND tb.task_type = 'SUBTASK')
 RETURNING
-  id,
-  organisation_id,
-  value,
-  updated_at;
+  tasks.id,
+  tasks.organisation_id,
+  tasks.value,
+  tasks.completed_by_id,
+  tasks.completed_at,
+  tasks.updated_at;
 
 
--- name: DeleteTaskByID :exec
+-- name: DeleteTaskByID :execrows
+-- Tasks of a trashed work order are kept until it is restored or purged.
 DELETE FROM tasks
-WHERE organisation_id = $1
-  AND id = $2;
+USING work_order w
+WHERE tasks.organisation_id = $1
+  AND tasks.id = $2
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL;
 
 -- name: ToggleTaskCompletion :one
+-- Only SUBTASK tasks toggle; typed tasks take a value through UpdateTask.
+-- No row for a task of a trashed work order.
 UPDATE tasks
 SET
   value = CASE
     WHEN @complete::boolean = true
       THEN 'COMPLETE'
-    WHEN @complete::boolean = false AND previous_value IS NOT NULL
-      THEN previous_value
+    WHEN @complete::boolean = false AND tasks.previous_value IS NOT NULL
+      THEN tasks.previous_value
     WHEN @complete::boolean = false
       THEN 'OPEN'
-    ELSE value
+    ELSE tasks.value
   END,
   previous_value = CASE
     WHEN @complete::boolean = true
-      THEN value  -- stash current before marking complete
-    ELSE previous_value
+      THEN tasks.value  -- stash current before marking complete
+    ELSE tasks.previous_value
   END,
+  updated_by_id = @updated_by_id,
   updated_at = now()
-WHERE organisation_id = @organisation_id
-  AND id = @id
+FROM work_order w
+WHERE tasks.organisation_id = @organisation_id
+  AND tasks.id = @id
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM task_bases tb WHERE tb.id = tasks.task_base_id AND tb.task_type = 'SUBTASK')
 RETURNING
-  id,
-  organisation_id,
-  value,
-  previous_value,
-  updated_at;
+  tasks.id,
+  tasks.organisation_id,
+  tasks.value,
+  tasks.previous_value,
+  tasks.completed_by_id,
+  tasks.completed_at,
+  tasks.updated_at;
+
+
+-- name: CreateTask :one
+-- Appends a task (see trg_tasks_assign_position) to a live work order of the
+-- organisation; no row when the work order is unknown or trashed. A NULL
+-- assignee defaults to the base's user (trg_tasks_stamp_completion).
+INSERT INTO tasks (
+  organisation_id, created_by_id, task_base_id, work_order_id, notes,
+  value, numeric_value, task_option_id, out_of_tolerance, assignee_id, due_at
+)
+SELECT w.organisation_id, @created_by_id::uuid, @task_base_id::uuid, w.id, sqlc.narg(notes)::text,
+       sqlc.narg(value)::text, sqlc.narg(numeric_value)::float8, sqlc.narg(task_option_id)::uuid, @out_of_tolerance::boolean,
+       sqlc.narg(assignee_id)::uuid, sqlc.narg(due_at)::timestamptz
+FROM work_order w
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+RETURNING id;
+
+-- name: GetTask :one
+SELECT
+  t.id,
+  t.work_order_id,
+  t.task_base_id,
+  tb.label,
+  tb.task_type,
+  tb.unit,
+  tb.min_value::float8 AS min_value,
+  tb.max_value::float8 AS max_value,
+  tb.meter_id,
+  t.notes,
+  t.value,
+  t.numeric_value::float8 AS numeric_value,
+  t.task_option_id,
+  t.out_of_tolerance,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS completed,
+  f.work_order_id AS corrective_work_order_id,
+  t.assignee_id,
+  au.name AS assignee_name,
+  t.due_at,
+  t.completed_by_id,
+  cu.name AS completed_by_name,
+  t.completed_at,
+  t.position,
+  t.created_by_id,
+  t.created_at,
+  t.updated_at
+FROM tasks t
+JOIN task_bases tb ON tb.id = t.task_base_id
+JOIN work_order w ON w.id = t.work_order_id AND w.deleted_at IS NULL
+LEFT JOIN work_order_findings f ON f.source_task_id = t.id
+LEFT JOIN users au ON au.id = t.assignee_id
+LEFT JOIN users cu ON cu.id = t.completed_by_id
+WHERE t.id = @id
+  AND t.organisation_id = @organisation_id;
 
+-- name: UpdateTask :one
+-- Sets notes and/or value; a new value (already validated against the task
+-- type) forgets the one stashed by ToggleTaskCompletion.
+UPDATE tasks t
+SET
+  notes = CASE WHEN @set_notes::boolean THEN sqlc.narg(notes)::text ELSE t.notes END,
+  value = CASE WHEN @set_value::boolean THEN sqlc.narg(value)::text ELSE t.value END,
+  numeric_value = CASE WHEN @set_value::boolean THEN sqlc.narg(numeric_value)::float8 ELSE t.numeric_value END,
+  task_option_id = CASE WHEN @set_value::boolean THEN sqlc.narg(task_option_id)::uuid ELSE t.task_option_id END,
+  out_of_tolerance = CASE WHEN @set_value::boolean THEN @out_of_tolerance::boolean ELSE t.out_of_tolerance END,
+  previous_value = CASE WHEN @set_value::boolean THEN NULL ELSE t.previous_value END,
+  updated_by_id = @updated_by_id,
+  updated_at = now()
+FROM work_order w
+WHERE t.id = @id
+  AND t.organisation_id = @organisation_id
+  AND w.id = t.work_order_id
+  AND w.deleted_at IS NULL
+RETURNING t.id;
+
+-- name: AssignTask :one
+-- Replaces the assignee and due time of a task on a live work order.
+UPDATE tasks t
+SET
+  assignee_id = sqlc.narg(assignee_id)::uuid,
+  due_at = sqlc.narg(due_at)::timestamptz,
+  updated_by_id = @updated_by_id,
+  updated_at = now()
+FROM work_order w
+WHERE t.id = @id
+  AND t.organisation_id = @organisation_id
+  AND w.id = t.work_order_id
+  AND w.deleted_at IS NULL
+RETURNING t.id;
+
+-- name: ListWorkOrderTaskIDs :many
+-- The checklist order of a work order, locking its tasks for a reorder.
+SELECT t.id
+FROM tasks t
+WHERE t.work_order_id = @work_order_id
+  AND t.organisation_id = @organisation_id
+ORDER BY t.position, t.created_at, t.id
+FOR UPDATE;
+
+-- name: ReorderWorkOrderTasks :execrows
+UPDATE tasks t
+SET position = x.ord::int
+FROM unnest(@task_ids::uuid[]) WITH ORDINALITY AS x(id, ord)
+WHERE t.id = x.id
+  AND t.work_order_id = @work_order_id
+  AND t.organisation_id = @organisation_id;
+
+-- name: RecordMeterReading :execrows
+-- Stores a METER task's reading as the meter's last one. No row for a
+-- meter of another organisation.
+UPDATE meters
+SET
+  last_value = @value::float8,
+  unit = COALESCE(sqlc.narg(unit)::text, unit),
+  last_read_at = now(),
+  last_read_by_id = @read_by_id::uuid,
+  last_task_id = @task_id::uuid
+WHERE id = @id
+  AND organisation_id = @organisation_id;
@@ -0,0 +1,61 @@
+-- name: ListWorkOrderTemplates :many
+SELECT *
+FROM work_order_templates
+WHERE organisation_id = @organisation_id
+ORDER BY lower(name);
+
+-- name: GetWorkOrderTemplate :one
+SELECT *
+FROM work_order_templates
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: CreateWorkOrderTemplate :one
+INSERT INTO work_order_templates (
+  organisation_id, created_by_id, name, description, payload
+)
+VALUES (
+  @organisation_id, sqlc.narg(created_by_id), @name, sqlc.narg(description), @payload
+)
+RETURNING *;
+
+-- name: UpdateWorkOrderTemplate :one
+UPDATE work_order_templates
+SET name        = @name,
+    description = sqlc.narg(description),
+    payload     = @payload,
+    updated_at  = now()
+WHERE id = @id
+  AND organisation_id = @organisation_id
+RETURNING *;
+
+-- name: DeleteWorkOrderTemplate :execrows
+DELETE FROM work_order_templates
+WHERE id = @id
+  AND organisation_id = @organisation_id;
+
+-- name: ClearWorkOrderTemplateTasks :exec
+DELETE FROM work_order_template_tasks
+WHERE template_id = @template_id;
+
+-- name: AddWorkOrderTemplateTasks :exec
+-- Positions follow the order of the array.
+INSERT INTO work_order_template_tasks (template_id, task_base_id, position)
+SELECT @template_id, t.task_base_id, t.ord::int
+FROM unnest(@task_base_ids::uuid[]) WITH ORDINALITY AS t(task_base_id, ord)
+ON CONFLICT (template_id, task_base_id) DO NOTHING;
+
+-- name: ListWorkOrderTemplateTasks :many
+SELECT tt.template_id, tt.task_base_id, tt.position, tb.label, tb.task_type
+FROM work_order_template_tasks tt
+JOIN task_bases tb ON tb.id = tt.task_base_id
+WHERE tt.template_id = ANY (@template_ids::uuid[])
+ORDER BY tt.template_id, tt.position, tt.task_base_id;
+
+-- name: InstantiateWorkOrderTemplate :one
+SELECT instantiate_work_order_template(
+  @organisation_id::uuid,
+  @template_id::uuid,
+  @created_by_id::uuid,
+  @overrides::jsonb
+)::uuid AS id;
@@ -0,0 +1,108 @@
+-- name: ListWorkOrderTimeEntries :many
+SELECT
+  e.id,
+  e.work_order_id,
+  e.user_id,
+  u.name AS user_name,
+  e.started_at,
+  e.ended_at,
+  e.hourly_rate::float8 AS hourly_rate,
+  e.notes,
+  e.source,
+  e.created_by_id,
+  e.created_at,
+  EXTRACT(EPOCH FROM (COALESCE(e.ended_at, now()) - e.started_at))::bigint AS duration_seconds
+FROM work_order_time_entries e
+LEFT JOIN users u ON u.id = e.user_id
+WHERE e.work_order_id = @work_order_id
+  AND e.organisation_id = @organisation_id
+ORDER BY e.started_at, e.id;
+
+-- name: GetWorkOrderTimeEntry :one
+SELECT
+  e.id,
+  e.work_order_id,
+  e.user_id,
+  u.name AS user_name,
+  e.started_at,
+  e.ended_at,
+  e.hourly_rate::float8 AS hourly_rate,
+  e.notes,
+  e.source,
+  e.created_by_id,
+  e.created_at,
+  EXTRACT(EPOCH FROM (COALESCE(e.ended_at, now()) - e.started_at))::bigint AS duration_seconds
+FROM work_order_time_entries e
+LEFT JOIN users u ON u.id = e.user_id
+WHERE e.id = @id
+  AND e.work_order_id = @work_order_id
+  AND e.organisation_id = @organisation_id;
+
+-- name: GetRunningTimeEntry :one
+-- The user's running timer in this organisation, on any work order.
+SELECT id, work_order_id, started_at
+FROM work_order_time_entries
+WHERE user_id = @user_id
+  AND organisation_id = @organisation_id
+  AND ended_at IS NULL
+LIMIT 1;
+
+-- name: StartWorkOrderTimer :one
+-- Org-scoped insert: returns no rows if the work order does not exist in
+-- this organisation.
+INSERT INTO work_order_time_entries (
+  organisation_id, work_order_id, user_id, started_at, hourly_rate, notes, source, created_by_id
+)
+SELECT w.organisation_id, w.id, @user_id::uuid, now(), @hourly_rate::float8, sqlc.narg(notes)::text, 'timer', @user_id::uuid
+FROM work_order w
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+RETURNING id;
+
+-- name: StopWorkOrderTimer :one
+-- Notes are replaced only when given.
+UPDATE work_order_time_entries
+SET
+  ended_at = GREATEST(now(), started_at),
+  notes = COALESCE(sqlc.narg(notes)::text, notes),
+  updated_at = now()
+WHERE work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND user_id = @user_id
+  AND ended_at IS NULL
+RETURNING id;
+
+-- name: CreateWorkOrderTimeEntry :one
+INSERT INTO work_order_time_entries (
+  organisation_id, work_order_id, user_id, started_at, ended_at, hourly_rate, notes, source, created_by_id
+)
+SELECT w.organisation_id, w.id, @user_id::uuid, @started_at::timestamptz, @ended_at::timestamptz,
+       @hourly_rate::float8, sqlc.narg(notes)::text, 'manual', @created_by_id::uuid
+FROM work_order w
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+RETURNING id;
+
+-- name: UpdateWorkOrderTimeEntry :one
+-- Running timers cannot be edited; stop them first.
+UPDATE work_order_time_entries
+SET
+  user_id = @user_id::uuid,
+  started_at = @started_at::timestamptz,
+  ended_at = @ended_at::timestamptz,
+  hourly_rate = @hourly_rate::float8,
+  notes = sqlc.narg(notes)::text,
+  updated_at = now()
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND ended_at IS NOT NULL
+RETURNING id;
+
+-- name: DeleteWorkOrderTimeEntry :execrows
+DELETE FROM work_order_time_entries
+WHERE id = @id
+  AND work_order_id = @work_order_id
+  AND organisation_id = @organisation_id;
@@ -0,0 +1,58 @@
+-- name: SetWorkOrderParent :one
+-- Moves a work order under parent_id (NULL makes it top-level). Raises
+-- no_data_found, CM412, foreign_key_violation for a missing or trashed
+-- parent and, for a loop, CM409; see set_work_order_parent().
+SELECT public.set_work_order_parent(
+  @organisation_id::uuid,
+  @work_order_id::uuid,
+  sqlc.narg(parent_id)::uuid,
+  @updated_by_id::uuid,
+  sqlc.narg(expected_version)::bigint
+)::bigint AS version;
+
+-- name: AddWorkOrderDependency :execrows
+-- Records that work_order_id waits for blocked_by_id. Both must be live work
+-- orders of the organisation; an existing link inserts nothing. A loop
+-- raises CM409.
+INSERT INTO work_order_dependencies (organisation_id, work_order_id, blocked_by_id, created_by_id)
+SELECT w.organisation_id, w.id, b.id, @created_by_id::uuid
+FROM work_order w
+JOIN work_order b ON b.organisation_id = w.organisation_id
+WHERE w.id = @work_order_id
+  AND w.organisation_id = @organisation_id
+  AND w.deleted_at IS NULL
+  AND b.id = @blocked_by_id
+  AND b.deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteWorkOrderDependency :execrows
+DELETE FROM work_order_dependencies
+WHERE work_order_id = @work_order_id
+  AND blocked_by_id = @blocked_by_id
+  AND organisation_id = @organisation_id;
+
+-- name: ListWorkOrderDependencies :many
+-- Both directions for one work order: what it is blocked by and what it
+-- blocks, leaving trashed work orders out. A link is resolved once the
+-- blocker is complete or cancelled.
+SELECT
+  x.direction::text AS direction,
+  o.id,
+  o.custom_id,
+  o.title,
+  o.status,
+  (o.status IN ('COMPLETE', 'CANCELLED'))::boolean AS resolved,
+  x.created_at
+FROM (
+  SELECT 'BLOCKED_BY' AS direction, d.blocked_by_id AS other_id, d.created_at
+  FROM work_order_dependencies d
+  WHERE d.work_order_id = @work_order_id
+    AND d.organisation_id = @organisation_id
+  UNION ALL
+  SELECT 'BLOCKING', d.work_order_id, d.created_at
+  FROM work_order_dependencies d
+  WHERE d.blocked_by_id = @work_order_id
+    AND d.organisation_id = @organisation_id
+) x
+JOIN work_order o ON o.id = x.other_id AND o.deleted_at IS NULL
+ORDER BY x.direction, x.created_at, o.id;
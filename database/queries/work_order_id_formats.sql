@@ -0,0 +1,45 @@
+-- name: GetWorkOrderIDFormat :one
+-- The org's custom ID format (defaults when it has none) with the number
+-- and ID the next generated work order would get today.
+SELECT
+  COALESCE(f.prefix, 'WO-')::text              AS prefix,
+  COALESCE(f.date_part, 'YYYY')::text          AS date_part,
+  COALESCE(f.separator, '-')::text             AS separator,
+  COALESCE(f.padding, 4)::int                  AS padding,
+  COALESCE(f.reset_period, 'YEARLY')::text     AS reset_period,
+  (f.organisation_id IS NOT NULL)::boolean     AS customised,
+  f.updated_at,
+  COALESCE(c.next_seq, 1)::bigint              AS next_number,
+  public.format_work_order_custom_id(p.stem, COALESCE(c.next_seq, 1), p.padding)::text AS next_id
+FROM public.work_order_id_period(@organisation_id::uuid, current_date) p
+LEFT JOIN work_order_id_formats f
+  ON f.organisation_id = @organisation_id::uuid
+LEFT JOIN work_order_counters c
+  ON c.organisation_id = @organisation_id::uuid
+ AND c.year = p.year
+ AND c.month = p.month;
+
+-- name: UpsertWorkOrderIDFormat :exec
+INSERT INTO work_order_id_formats (
+  organisation_id, prefix, date_part, separator, padding, reset_period, updated_by_id
+)
+VALUES (
+  @organisation_id, @prefix, @date_part, @separator, @padding, @reset_period, @updated_by_id
+)
+ON CONFLICT (organisation_id) DO UPDATE SET
+  prefix        = EXCLUDED.prefix,
+  date_part     = EXCLUDED.date_part,
+  separator     = EXCLUDED.separator,
+  padding       = EXCLUDED.padding,
+  reset_period  = EXCLUDED.reset_period,
+  updated_by_id = EXCLUDED.updated_by_id,
+  updated_at    = now();
+
+-- name: SetWorkOrderCounter :exec
+-- Sets the next number for the current period of the org's format.
+-- Numbers already used are skipped when IDs are generated.
+INSERT INTO work_order_counters (organisation_id, year, month, next_seq)
+SELECT @organisation_id::uuid, p.year, p.month, @next_seq::int
+FROM public.work_order_id_period(@organisation_id::uuid, current_date) p
+ON CONFLICT (organisation_id, year, month)
+DO UPDATE SET next_seq = EXCLUDED.next_seq;
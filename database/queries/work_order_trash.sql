@@ -0,0 +1,61 @@
+-- name: SoftDeleteWorkOrder :one
+-- Moves a live work order to the trash (see soft_delete_work_order() in
+-- 024); raises no_data_found when there is none.
+SELECT public.soft_delete_work_order(
+  @organisation_id::uuid,
+  @work_order_id::uuid,
+  @deleted_by_id::uuid
+)::bigint AS version;
+
+-- name: RestoreWorkOrder :one
+-- Takes a work order back out of the trash; raises no_data_found when it
+-- is not there.
+SELECT public.restore_work_order(
+  @organisation_id::uuid,
+  @work_order_id::uuid,
+  @restored_by_id::uuid
+)::bigint AS version;
+
+-- name: ListTrashedWorkOrders :many
+SELECT
+  w.id,
+  w.custom_id,
+  w.title,
+  w.status,
+  w.priority,
+  w.deleted_at::timestamptz AS deleted_at,
+  w.deleted_by_id,
+  u.name AS deleted_by_name
+FROM work_order w
+LEFT JOIN users u ON u.id = w.deleted_by_id
+WHERE w.organisation_id = @organisation_id
+  AND w.deleted_at IS NOT NULL
+  AND (
+    sqlc.narg(search)::text IS NULL
+    OR w.title ILIKE '%' || sqlc.narg(search)::text || '%'
+    OR w.custom_id ILIKE '%' || sqlc.narg(search)::text || '%'
+  )
+ORDER BY w.deleted_at DESC, w.id;
+
+-- name: PurgeTrashedWorkOrder :execrows
+-- Permanently deletes one trashed work order with everything that
+-- cascades from it.
+DELETE FROM work_order
+WHERE id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NOT NULL;
+
+-- name: PurgeTrashedWorkOrders :execrows
+-- Permanently deletes up to batch_size work orders trashed before
+-- deleted_before, for one organisation or, without one, for all of them.
+-- Rows locked by a concurrent restore are left for the next batch.
+DELETE FROM work_order
+WHERE id IN (
+  SELECT w.id
+  FROM work_order w
+  WHERE w.deleted_at < @deleted_before::timestamptz
+    AND (sqlc.narg(organisation_id)::uuid IS NULL OR w.organisation_id = sqlc.narg(organisation_id)::uuid)
+  ORDER BY w.deleted_at
+  LIMIT @batch_size::int
+  FOR UPDATE SKIP LOCKED
+);
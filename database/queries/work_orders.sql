@@ -1,4 +1,8 @@
 -- name: ListWorkOrdersPaged :many
+-- payload: {"pageNum", "pageSize", "sortField", "direction", "filter",
+-- "after"}; the filter tree is evaluated by work_order_matches_filter() (see
+-- 019). "after" ({"key", "createdAt", "id"} of the last row already seen)
+-- switches from pageNum offsets to a keyset seek past that row.
 WITH
 params AS (
   SELECT
@@ -12,32 +16,6 @@ page AS (
     COALESCE((p->>'pageSize')::int,50) AS page_size
   FROM params
 ),
-ff AS (
-  SELECT jsonb_array_elements(p->'filterFields') AS f
-  FROM params
-  WHERE (p ? 'filterFields') AND jsonb_typeof(p->'filterFields') = 'array'
-),
-status_vals AS (
-  SELECT COALESCE(array_agg(v), ARRAY[]::text[]) AS vals
-  FROM (
-    SELECT jsonb_array_elements_text(f->'values') AS v
-    FROM ff
-    WHERE f->>'field' = 'status' AND COALESCE(f->>'operation','') = 'in'
-  ) s
-),
-archived_eq AS (
-  SELECT (f->>'value')::boolean AS archived
-  FROM ff
-  WHERE f->>'field' = 'archived' AND COALESCE(f->>'operation','') IN ('eq','equals')
-  LIMIT 1
-),
-/* generic text search (title + description) */
-text_cn AS (
-  SELECT NULLIF(btrim(f->>'value'), '') AS term
-  FROM ff
-  WHERE f->>'field' = 'text' AND COALESCE(f->>'operation','') IN ('cn','contains','like')
-  LIMIT 1
-),
 /* NEW: sort options (whitelisted later) */
 sort AS (
   SELECT
@@ -46,29 +24,16 @@ sort AS (
   FROM params
 ),
 filtered AS (
-  SELECT w.*
+  SELECT
+    w.*,
+    COALESCE(sla.breached, false) AS sla_breached,
+    COALESCE(sla.at_risk, false)  AS sla_at_risk
   FROM work_order w
-  LEFT JOIN status_vals sv ON TRUE
-  LEFT JOIN archived_eq  a  ON TRUE
-  LEFT JOIN text_cn      t  ON TRUE
-  WHERE
-    (w.organisation_id = (SELECT org_id FROM params))
-    AND (sv.vals = '{}'::text[] OR w.status = ANY (sv.vals))
-    AND (a.archived IS NULL OR w.archived = a.archived)
-    AND (
-      t.term IS NULL
-      OR (
-        w.title ILIKE (
-          '%' ||
-          replace(replace(replace(t.term, E'\\', E'\\\\'), '%', E'\\%'), '_', E'\\_')
-          || '%'
-        ) ESCAPE E'\\'
-        OR w.description ILIKE (
-          '%' ||
-          replace(replace(replace(t.term, E'\\', E'\\\\'), '%', E'\\%'), '_', E'\\_')
-          || '%'
-        ) ESCAPE E'\\'
-      )
+  JOIN params pr ON pr.org_id = w.organisation_id
+  LEFT JOIN work_order_sla sla ON sla.work_order_id = w.id
+  WHERE w.deleted_at IS NULL
+    AND public.work_order_matches_filter(
+      w, COALESCE(sla.breached, false), COALESCE(sla.at_risk, false), pr.p->'filter'
     )
 ),
 ordered AS (
@@ -106,13 +71,140 @@ page_bounds AS (
     (page_num * page_size)             AS off,
     (page_num * page_size + page_size) AS lim
   FROM page
+),
+/* keyset cursor: the sort key, created_at and id of the last row seen */
+seek AS (
+  SELECT
+    s.field IN ('custom_id','priority','status','title')
+      AND s.dir IN ('ASC','DESC')                   AS txt_sort,
+    s.field IN ('due_date','created_at','updated_at')
+      AND s.dir IN ('ASC','DESC')                   AS ts_sort,
+    p->'after'->>'key'                              AS key,
+    (p->'after'->>'createdAt')::timestamptz         AS created_at,
+    (p->'after'->>'id')::uuid                       AS id
+  FROM params
+  CROSS JOIN sort s
 )
 SELECT
   o.*
 FROM ordered o
 JOIN page_bounds b ON TRUE
-WHERE o.rn > b.off AND o.rn <= b.lim
-ORDER BY o.rn;
+CROSS JOIN sort s
+CROSS JOIN seek a
+CROSS JOIN LATERAL (
+  SELECT
+    CASE WHEN a.txt_sort THEN CASE s.field
+      WHEN 'custom_id' THEN o.custom_id
+      WHEN 'priority'  THEN o.priority
+      WHEN 'status'    THEN o.status
+      WHEN 'title'     THEN o.title
+    END END AS row_txt,
+    CASE WHEN a.ts_sort THEN CASE s.field
+      WHEN 'due_date'   THEN o.due_date
+      WHEN 'created_at' THEN o.created_at
+      WHEN 'updated_at' THEN o.updated_at
+    END END AS row_ts,
+    CASE WHEN a.txt_sort THEN a.key END              AS after_txt,
+    CASE WHEN a.ts_sort  THEN a.key::timestamptz END AS after_ts
+) k
+WHERE CASE
+  WHEN a.id IS NULL THEN o.rn > b.off AND o.rn <= b.lim
+  ELSE
+    /* rows whose sort key comes after the cursor's (NULLS LAST) ... */
+    (
+      (k.after_txt IS NOT NULL OR k.after_ts IS NOT NULL)
+      AND (
+        (k.row_txt IS NULL AND k.row_ts IS NULL)
+        OR (s.dir = 'ASC'  AND (k.row_txt > k.after_txt OR k.row_ts > k.after_ts))
+        OR (s.dir = 'DESC' AND (k.row_txt < k.after_txt OR k.row_ts < k.after_ts))
+      )
+    )
+    /* ... or ties it and follow on the created_at DESC, id DESC fallback */
+    OR (
+      k.row_txt IS NOT DISTINCT FROM k.after_txt
+      AND k.row_ts IS NOT DISTINCT FROM k.after_ts
+      AND (o.created_at, o.id) < (a.created_at, a.id)
+    )
+END
+ORDER BY o.rn
+LIMIT (SELECT page_size FROM page);
+
+-- name: ExportWorkOrders :many
+-- Same filter and sort as ListWorkOrdersPaged, unpaged and flattened for
+-- CSV/XLSX export. repo streams it through ExportWorkOrdersSQL, by column name.
+WITH
+params AS (
+  SELECT
+    sqlc.arg(org_id)::uuid   AS org_id,
+    sqlc.arg(payload)::jsonb AS p
+),
+sort AS (
+  SELECT
+    lower(NULLIF(p->>'sortField',''))     AS field,
+    upper(COALESCE(NULLIF(p->>'direction',''),'DESC')) AS dir
+  FROM params
+)
+SELECT
+  w.id,
+  w.custom_id,
+  w.title,
+  w.status,
+  w.priority,
+  w.due_date,
+  w.created_at,
+  w.completed_on,
+  pu.name                               AS primary_worker,
+  COALESCE(asg.names, '')::text         AS assignees,
+  tm.name                               AS team,
+  l.name                                AS location,
+  a.name                                AS asset,
+  c.name                                AS category,
+  COALESCE(tk.total, 0)::bigint         AS tasks_total,
+  COALESCE(tk.completed, 0)::bigint     AS tasks_completed,
+  COALESCE(sla.breached, false)         AS sla_breached
+FROM work_order w
+JOIN params pr ON pr.org_id = w.organisation_id
+CROSS JOIN sort s
+LEFT JOIN work_order_sla sla        ON sla.work_order_id = w.id
+LEFT JOIN users pu                  ON pu.id = w.primary_user_id
+LEFT JOIN teams tm                  ON tm.id = w.team_id
+LEFT JOIN locations l               ON l.id = w.location_id
+LEFT JOIN assets a                  ON a.id = w.asset_id
+LEFT JOIN work_order_categories c   ON c.id = w.category_id
+LEFT JOIN LATERAL (
+  SELECT string_agg(COALESCE(u.name, u.email), '; ' ORDER BY COALESCE(u.name, u.email)) AS names
+  FROM work_order_assigned_to x
+  JOIN users u ON u.id = x.user_id
+  WHERE x.work_order_id = w.id
+) asg ON TRUE
+LEFT JOIN LATERAL (
+  SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE public.task_is_complete(tb.task_type, t.value)) AS completed
+  FROM tasks t
+  JOIN task_bases tb ON tb.id = t.task_base_id
+  WHERE t.work_order_id = w.id
+) tk ON TRUE
+WHERE w.deleted_at IS NULL
+  AND public.work_order_matches_filter(
+    w, COALESCE(sla.breached, false), COALESCE(sla.at_risk, false), pr.p->'filter'
+  )
+ORDER BY
+  CASE WHEN s.field='custom_id'  AND s.dir='ASC'  THEN w.custom_id  END ASC  NULLS LAST,
+  CASE WHEN s.field='due_date'   AND s.dir='ASC'  THEN w.due_date   END ASC  NULLS LAST,
+  CASE WHEN s.field='created_at' AND s.dir='ASC'  THEN w.created_at END ASC  NULLS LAST,
+  CASE WHEN s.field='updated_at' AND s.dir='ASC'  THEN w.updated_at END ASC  NULLS LAST,
+  CASE WHEN s.field='priority'   AND s.dir='ASC'  THEN w.priority   END ASC  NULLS LAST,
+  CASE WHEN s.field='status'     AND s.dir='ASC'  THEN w.status     END ASC  NULLS LAST,
+  CASE WHEN s.field='title'      AND s.dir='ASC'  THEN w.title      END ASC  NULLS LAST,
+  CASE WHEN s.field='custom_id'  AND s.dir='DESC' THEN w.custom_id  END DESC NULLS LAST,
+  CASE WHEN s.field='due_date'   AND s.dir='DESC' THEN w.due_date   END DESC NULLS LAST,
+  CASE WHEN s.field='created_at' AND s.dir='DESC' THEN w.created_at END DESC NULLS LAST,
+  CASE WHEN s.field='updated_at' AND s.dir='DESC' THEN w.updated_at END DESC NULLS LAST,
+  CASE WHEN s.field='priority'   AND s.dir='DESC' THEN w.priority   END DESC NULLS LAST,
+  CASE WHEN s.field='status'     AND s.dir='DESC' THEN w.status     END DESC NULLS LAST,
+  CASE WHEN s.field='title'      AND s.dir='DESC' THEN w.title      END DESC NULLS LAST,
+  w.created_at DESC, w.id DESC;
 
 
 
@@ -139,6 +231,7 @@ SELECT
       'archived',                 wo.archived,
       'feedback',                 wo.feedback,
       'first_time_to_react',      wo.first_time_to_react,
+      'version',                  wo.version,
 
       -- NEW: primary worker (object with id + name)
       'primary_worker', (
@@ -198,25 +291,227 @@ SELECT
                                       JOIN files f ON f.id = wf.file_id
                                       WHERE wf.work_order_id = wo.id),
                                      '[]'::jsonb
+                                   ),
+
+      -- Labour: logged time counts finished entries only; running timers
+      -- are reported separately.
+      'time_tracking',            (SELECT jsonb_build_object(
+                                     'entries',       COUNT(*),
+                                     'running',       COUNT(*) FILTER (WHERE te.ended_at IS NULL),
+                                     'total_seconds', COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at))), 0)::bigint,
+                                     'total_hours',   ROUND(COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at))), 0) / 3600, 2),
+                                     'labour_cost',   ROUND(COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at)) / 3600 * te.hourly_rate), 0), 2)
+                                   )
+                                   FROM work_order_time_entries te
+                                   WHERE te.work_order_id = wo.id),
+
+      'parts_usage',              (SELECT jsonb_build_object(
+                                     'lines',      COUNT(*),
+                                     'parts_cost', ROUND(COALESCE(SUM(wp.quantity * wp.unit_cost), 0), 2)
+                                   )
+                                   FROM work_order_parts wp
+                                   WHERE wp.work_order_id = wo.id),
+
+      -- Only present when an SLA policy applies (see work_order_sla)
+      'sla',                      (SELECT jsonb_build_object(
+                                     'policy_id',         s.policy_id,
+                                     'react_due_at',      s.react_due_at,
+                                     'complete_due_at',   s.complete_due_at,
+                                     'react_breached',    s.react_breached,
+                                     'complete_breached', s.complete_breached,
+                                     'breached',          s.breached,
+                                     'at_risk',           s.at_risk
+                                   )
+                                   FROM work_order_sla s
+                                   WHERE s.work_order_id = wo.id
+                                     AND s.policy_id IS NOT NULL),
+      -- Hierarchy: the live parent and ancestors (nearest first), the child
+      -- tree and the completion roll-up over every descendant
+      'parent',                   (SELECT jsonb_build_object('id', p.id, 'custom_id', p.custom_id, 'title', p.title, 'status', p.status)
+                                   FROM work_order p
+                                   WHERE p.id = wo.parent_work_order_id
+                                     AND p.deleted_at IS NULL),
+      'ancestors',                (WITH RECURSIVE up AS (
+                                     SELECT p.id, p.custom_id, p.title, p.status, p.parent_work_order_id, p.deleted_at, 1 AS depth
+                                     FROM work_order p
+                                     WHERE p.id = wo.parent_work_order_id
+                                     UNION ALL
+                                     SELECT p.id, p.custom_id, p.title, p.status, p.parent_work_order_id, p.deleted_at, up.depth + 1
+                                     FROM work_order p
+                                     JOIN up ON p.id = up.parent_work_order_id
+                                   )
+                                   SELECT jsonb_agg(jsonb_build_object('id', up.id, 'custom_id', up.custom_id, 'title', up.title, 'status', up.status)
+                                                    ORDER BY up.depth)
+                                   FROM up
+                                   WHERE up.deleted_at IS NULL),
+      'children',                 public.work_order_children_json(wo.id),
+      'rollup',                   public.work_order_rollup_json(wo.id),
+
+      -- Dependencies: a blocker is resolved once it is complete or cancelled;
+      -- blocked is true while any is not
+      'blocked_by',               COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object(
+                                               'id',        b.id,
+                                               'custom_id', b.custom_id,
+                                               'title',     b.title,
+                                               'status',    b.status,
+                                               'resolved',  b.status IN ('COMPLETE', 'CANCELLED')
+                                             ) ORDER BY d.created_at, b.id)
+                                      FROM work_order_dependencies d
+                                      JOIN work_order b ON b.id = d.blocked_by_id
+                                      WHERE d.work_order_id = wo.id
+                                        AND b.deleted_at IS NULL),
+                                     '[]'::jsonb
+                                   ),
+      'blocking',                 COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object('id', x.id, 'custom_id', x.custom_id, 'title', x.title, 'status', x.status)
+                                                       ORDER BY d.created_at, x.id)
+                                      FROM work_order_dependencies d
+                                      JOIN work_order x ON x.id = d.work_order_id
+                                      WHERE d.blocked_by_id = wo.id
+                                        AND x.deleted_at IS NULL),
+                                     '[]'::jsonb
+                                   ),
+      'blocked',                  EXISTS (
+                                     SELECT 1
+                                     FROM work_order_dependencies d
+                                     JOIN work_order b ON b.id = d.blocked_by_id
+                                     WHERE d.work_order_id = wo.id
+                                       AND b.deleted_at IS NULL
+                                       AND b.status NOT IN ('COMPLETE', 'CANCELLED')
+                                   ),
+
+      -- Inspection findings: the task this corrective work order came from,
+      -- and the corrective work orders spawned by this one's tasks
+      'finding',                  (SELECT jsonb_build_object(
+                                            'trigger',              f.trigger,
+                                            'finding',              f.finding,
+                                            'source_task_id',       f.source_task_id,
+                                            'source_work_order_id', f.source_work_order_id,
+                                            'source_custom_id',     s.custom_id,
+                                            'source_title',         s.title,
+                                            'created_at',           f.created_at
+                                          )
+                                   FROM work_order_findings f
+                                   LEFT JOIN work_order s ON s.id = f.source_work_order_id AND s.deleted_at IS NULL
+                                   WHERE f.work_order_id = wo.id),
+      'corrective_work_orders',   COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object(
+                                               'id',             c.id,
+                                               'custom_id',      c.custom_id,
+                                               'title',          c.title,
+                                               'status',         c.status,
+                                               'trigger',        f.trigger,
+                                               'source_task_id', f.source_task_id
+                                             ) ORDER BY f.created_at, c.id)
+                                      FROM work_order_findings f
+                                      JOIN work_order c ON c.id = f.work_order_id
+                                      WHERE f.source_work_order_id = wo.id
+                                        AND c.deleted_at IS NULL),
+                                     '[]'::jsonb
                                    )
     )
   ) AS work_order
 FROM work_order wo
-WHERE wo.id = $1::uuid
+WHERE wo.id = @work_order_id
+  AND wo.organisation_id = @organisation_id
+  AND wo.deleted_at IS NULL
 LIMIT 1;
 -- ---------------------------------------------------------------------------
 
--- name: ChangeWorkOrderStatus :exec
-UPDATE work_order
-SET
-  status = @status,
-  completed_on = CASE WHEN upper(@status) = 'COMPLETE'
-                      THEN COALESCE(completed_on, now())
-                      ELSE NULL
-                 END,
-  updated_at = now()
+-- name: GetWorkOrderStatus :one
+SELECT status
+FROM work_order
+WHERE id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL;
+
+-- name: GetWorkOrderVersion :one
+SELECT version
+FROM work_order
 WHERE id = @work_order_id
-  AND organisation_id = @organisation_id;
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL;
+
+-- name: ChangeWorkOrderStatus :one
+-- Guarded by from_status (and expected_version when the client sent If-Match)
+-- so a concurrent change makes this return no rows.
+WITH upd AS (
+  UPDATE work_order
+  SET
+    status = @to_status,
+    completed_on = CASE WHEN @to_status = 'COMPLETE'
+                        THEN COALESCE(completed_on, now())
+                        ELSE NULL
+                   END,
+    completed_by_id = CASE WHEN @to_status = 'COMPLETE'
+                           THEN COALESCE(completed_by_id, @changed_by_id::uuid)
+                           ELSE NULL
+                      END,
+    updated_at = now()
+  WHERE id = @work_order_id
+    AND organisation_id = @organisation_id
+    AND deleted_at IS NULL
+    AND status = @from_status
+    AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version)::bigint)
+  RETURNING id, organisation_id
+)
+INSERT INTO work_order_status_history (
+  organisation_id, work_order_id, from_status, to_status, changed_by_id, reason
+)
+SELECT upd.organisation_id, upd.id, @from_status, @to_status, @changed_by_id::uuid, sqlc.narg(reason)::text
+FROM upd
+RETURNING id;
+
+-- name: CompleteWorkOrder :one
+-- ChangeWorkOrderStatus to COMPLETE that also stores the captured signature
+-- and always stamps the completing user. Same guards, same no-rows contract.
+WITH upd AS (
+  UPDATE work_order
+  SET
+    status = 'COMPLETE',
+    signature_id = COALESCE(sqlc.narg(signature_id)::uuid, signature_id),
+    completed_on = now(),
+    completed_by_id = @completed_by_id::uuid,
+    updated_at = now()
+  WHERE id = @work_order_id
+    AND organisation_id = @organisation_id
+    AND deleted_at IS NULL
+    AND status = @from_status
+    AND (sqlc.narg(expected_version)::bigint IS NULL OR version = sqlc.narg(expected_version)::bigint)
+  RETURNING id, organisation_id
+)
+INSERT INTO work_order_status_history (
+  organisation_id, work_order_id, from_status, to_status, changed_by_id, reason
+)
+SELECT upd.organisation_id, upd.id, @from_status, 'COMPLETE', @completed_by_id::uuid, sqlc.narg(reason)::text
+FROM upd
+RETURNING id;
+
+-- name: GetWorkOrderCompletionState :one
+SELECT status, required_signature, signature_id, version
+FROM work_order
+WHERE id = @work_order_id
+  AND organisation_id = @organisation_id
+  AND deleted_at IS NULL;
+
+-- name: ListWorkOrderStatusHistory :many
+SELECT
+  h.id,
+  h.from_status,
+  h.to_status,
+  h.reason,
+  h.changed_at,
+  h.changed_by_id,
+  u.name AS changed_by_name,
+  EXTRACT(EPOCH FROM (
+    COALESCE(LEAD(h.changed_at) OVER (ORDER BY h.changed_at, h.id), now()) - h.changed_at
+  ))::double precision AS seconds_in_status
+FROM work_order_status_history h
+JOIN work_order wo ON wo.id = h.work_order_id AND wo.organisation_id = @organisation_id AND wo.deleted_at IS NULL
+LEFT JOIN users u ON u.id = h.changed_by_id
+WHERE h.work_order_id = @work_order_id
+ORDER BY h.changed_at, h.id;
 
 
 -- name: CreateWorkOrderFromJSON :one
@@ -226,15 +521,19 @@ SELECT create_work_order_from_json(
   @payload::jsonb
 )::uuid AS id;
 
+-- name: DuplicateWorkOrder :one
+SELECT duplicate_work_order(
+  @organisation_id::uuid,
+  @work_order_id::uuid,
+  @created_by_id::uuid,
+  @overrides::jsonb
+)::uuid AS id;
+
 -- name: UpdateWorkOrderFromJSON :one
-SELECT public.update_work_order_from_json(
+SELECT public.update_work_order_from_json_if_match(
   @organisation_id::uuid,
   @work_order_id::uuid,
   @payload::jsonb,
-  @updated_by_id::uuid
+  @updated_by_id::uuid,
+  sqlc.narg(expected_version)::bigint
 )::uuid AS id;
-
--- name: DeleteWorkOrderByID :exec
-DELETE FROM work_order
-WHERE organisation_id = @organisation_id
-  AND id = @id;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_work_order_status_history_insert ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_status_history_on_insert();
+DROP INDEX IF EXISTS idx_wo_status_history_org;
+DROP INDEX IF EXISTS idx_wo_status_history_work_order;
+DROP TABLE IF EXISTS work_order_status_history;
+ALTER TABLE work_order DROP CONSTRAINT IF EXISTS chk_work_order_status;
+
+COMMIT;
@@ -0,0 +1,76 @@
+-- Work order status lifecycle + transition history
+-- Lifecycle:
+--   OPEN -> IN_PROGRESS -> ON_HOLD -> COMPLETE, plus CANCELLED
+-- Notes:
+--   - Allowed transitions (and the role needed for each) live in the app
+--     (internal/models/work_order_status.go); the DB only guards the value set.
+--   - Every status change is appended to work_order_status_history so time
+--     spent in each state can be derived from consecutive rows.
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Normalise legacy free-text statuses before the constraint goes on
+-- ---------------------------------------------------------------------------
+UPDATE work_order SET status = upper(btrim(status));
+UPDATE work_order SET status = 'COMPLETE'    WHERE status IN ('COMPLETED', 'DONE', 'CLOSED');
+UPDATE work_order SET status = 'IN_PROGRESS' WHERE status IN ('IN PROGRESS', 'IN-PROGRESS', 'INPROGRESS', 'STARTED');
+UPDATE work_order SET status = 'ON_HOLD'     WHERE status IN ('ON HOLD', 'ON-HOLD', 'HOLD', 'PAUSED');
+UPDATE work_order SET status = 'CANCELLED'   WHERE status IN ('CANCELED');
+UPDATE work_order SET status = 'OPEN'
+ WHERE status NOT IN ('OPEN', 'IN_PROGRESS', 'ON_HOLD', 'COMPLETE', 'CANCELLED');
+
+ALTER TABLE work_order
+  ADD CONSTRAINT chk_work_order_status
+  CHECK (status IN ('OPEN', 'IN_PROGRESS', 'ON_HOLD', 'COMPLETE', 'CANCELLED'));
+
+-- ---------------------------------------------------------------------------
+-- Transition history
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS work_order_status_history (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  from_status      TEXT,                    -- NULL for the initial state
+  to_status        TEXT NOT NULL,
+  changed_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  reason           TEXT,
+  changed_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wo_status_history_work_order ON work_order_status_history (work_order_id, changed_at);
+CREATE INDEX IF NOT EXISTS idx_wo_status_history_org        ON work_order_status_history (organisation_id);
+
+-- Record the initial state of every new work order, whatever inserted it
+CREATE OR REPLACE FUNCTION public.work_order_status_history_on_insert()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  INSERT INTO work_order_status_history (
+    organisation_id, work_order_id, from_status, to_status, changed_by_id, changed_at
+  )
+  VALUES (
+    NEW.organisation_id, NEW.id, NULL, NEW.status, NEW.created_by_id, NEW.created_at
+  );
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_status_history_insert ON work_order;
+CREATE TRIGGER trg_work_order_status_history_insert
+  AFTER INSERT ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_status_history_on_insert();
+
+-- Backfill: one "initial" row per existing work order
+INSERT INTO work_order_status_history (
+  organisation_id, work_order_id, from_status, to_status, changed_by_id, changed_at
+)
+SELECT w.organisation_id, w.id, NULL, w.status, w.created_by_id, w.created_at
+FROM work_order w
+WHERE NOT EXISTS (
+  SELECT 1 FROM work_order_status_history h WHERE h.work_order_id = w.id
+);
+
+COMMIT;
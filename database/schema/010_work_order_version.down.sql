@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS public.update_work_order_from_json_if_match(uuid, uuid, jsonb, uuid, bigint);
+DROP TRIGGER IF EXISTS trg_work_order_bump_version ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_bump_version();
+ALTER TABLE work_order DROP COLUMN IF EXISTS version;
+
+COMMIT;
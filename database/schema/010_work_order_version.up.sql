@@ -0,0 +1,61 @@
+-- Optimistic concurrency for work orders
+-- Notes:
+--   - work_order.version is bumped on every UPDATE (trigger) and exposed as the ETag.
+--   - update_work_order_from_json_if_match() locks the row, checks the expected
+--     version and then delegates to update_work_order_from_json(), so the
+--     assigned_to / customers replacement happens under the same lock.
+--   - A stale version raises SQLSTATE 'CM412' (mapped to HTTP 412 by the app).
+
+BEGIN;
+
+ALTER TABLE work_order
+  ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION public.work_order_bump_version()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_bump_version ON work_order;
+CREATE TRIGGER trg_work_order_bump_version
+  BEFORE UPDATE ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_bump_version();
+
+CREATE OR REPLACE FUNCTION public.update_work_order_from_json_if_match(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_payload          JSONB,
+  p_updated_by       UUID DEFAULT NULL,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+BEGIN
+  SELECT version INTO v_version
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+     USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+     USING ERRCODE = 'CM412';
+  END IF;
+
+  RETURN public.update_work_order_from_json(p_org_id, p_work_order_id, p_payload, p_updated_by);
+END;
+$$;
+
+COMMIT;
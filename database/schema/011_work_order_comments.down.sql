@@ -0,0 +1,46 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_work_order_log_field_changes ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_log_field_changes();
+DROP INDEX IF EXISTS idx_wo_field_changes_work_order;
+DROP TABLE IF EXISTS work_order_field_changes;
+DROP INDEX IF EXISTS idx_wo_comment_mentions_user;
+DROP TABLE IF EXISTS work_order_comment_mentions;
+DROP INDEX IF EXISTS idx_wo_comments_author;
+DROP INDEX IF EXISTS idx_wo_comments_parent;
+DROP INDEX IF EXISTS idx_wo_comments_work_order;
+DROP TABLE IF EXISTS work_order_comments;
+
+-- Restore the 010 version of the wrapper (without set_config)
+CREATE OR REPLACE FUNCTION public.update_work_order_from_json_if_match(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_payload          JSONB,
+  p_updated_by       UUID DEFAULT NULL,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+BEGIN
+  SELECT version INTO v_version
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+     USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+     USING ERRCODE = 'CM412';
+  END IF;
+
+  RETURN public.update_work_order_from_json(p_org_id, p_work_order_id, p_payload, p_updated_by);
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,120 @@
+-- Work order comments, @mentions and field-change audit (activity timeline)
+-- Notes:
+--   - Comments are threaded via parent_id and soft-deleted (deleted_at) so
+--     replies keep their context.
+--   - work_order_field_changes is filled by a trigger on work_order UPDATE.
+--     The acting user is read from the transaction-local setting
+--     'cmms.actor_id', set by update_work_order_from_json_if_match().
+--   - status/updated_at/version are not logged here: status moves already
+--     live in work_order_status_history.
+
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- Comments
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS work_order_comments (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  parent_id        UUID REFERENCES work_order_comments(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  author_id        UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  body             TEXT NOT NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  edited_at        TIMESTAMPTZ,
+  deleted_at       TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_wo_comments_work_order ON work_order_comments (work_order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_wo_comments_parent     ON work_order_comments (parent_id);
+CREATE INDEX IF NOT EXISTS idx_wo_comments_author     ON work_order_comments (author_id);
+
+CREATE TABLE IF NOT EXISTS work_order_comment_mentions (
+  comment_id  UUID NOT NULL REFERENCES work_order_comments(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id     UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  PRIMARY KEY (comment_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wo_comment_mentions_user ON work_order_comment_mentions (user_id);
+
+-- ---------------------------------------------------------------------------
+-- Field change audit
+-- ---------------------------------------------------------------------------
+CREATE TABLE IF NOT EXISTS work_order_field_changes (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  field            TEXT NOT NULL,
+  old_value        JSONB,
+  new_value        JSONB,
+  changed_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  changed_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wo_field_changes_work_order ON work_order_field_changes (work_order_id, changed_at);
+
+CREATE OR REPLACE FUNCTION public.work_order_log_field_changes()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_old   JSONB := to_jsonb(OLD) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id'];
+  v_new   JSONB := to_jsonb(NEW) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id'];
+  v_actor UUID  := NULLIF(current_setting('cmms.actor_id', true), '')::uuid;
+  v_key   TEXT;
+BEGIN
+  FOR v_key IN SELECT jsonb_object_keys(v_new)
+  LOOP
+    IF (v_old -> v_key) IS DISTINCT FROM (v_new -> v_key) THEN
+      INSERT INTO work_order_field_changes (
+        organisation_id, work_order_id, field, old_value, new_value, changed_by_id
+      )
+      VALUES (NEW.organisation_id, NEW.id, v_key, v_old -> v_key, v_new -> v_key, v_actor);
+    END IF;
+  END LOOP;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_log_field_changes ON work_order;
+CREATE TRIGGER trg_work_order_log_field_changes
+  AFTER UPDATE ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_log_field_changes();
+
+-- Same as 010, plus publishing the acting user for the audit trigger
+CREATE OR REPLACE FUNCTION public.update_work_order_from_json_if_match(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_payload          JSONB,
+  p_updated_by       UUID DEFAULT NULL,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+BEGIN
+  SELECT version INTO v_version
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+     USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+     USING ERRCODE = 'CM412';
+  END IF;
+
+  PERFORM set_config('cmms.actor_id', COALESCE(p_updated_by::text, ''), true);
+
+  RETURN public.update_work_order_from_json(p_org_id, p_work_order_id, p_payload, p_updated_by);
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_work_order_files_file;
+DROP TABLE IF EXISTS org_storage_quotas;
+DROP INDEX IF EXISTS idx_files_org;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS chk_files_size_non_negative;
+ALTER TABLE files
+  DROP COLUMN IF EXISTS sha256,
+  DROP COLUMN IF EXISTS size_bytes,
+  DROP COLUMN IF EXISTS content_type,
+  DROP COLUMN IF EXISTS storage_backend,
+  DROP COLUMN IF EXISTS uploaded_by_id,
+  DROP COLUMN IF EXISTS organisation_id;
+
+COMMIT;
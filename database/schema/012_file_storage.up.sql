@@ -0,0 +1,30 @@
+BEGIN;
+
+-- ---------------------------------------------------------------------------
+-- files: the 004 stub only had path/filename. Uploaded blobs now carry their
+-- owning org, storage location and the metadata we sniffed on the way in.
+-- Legacy/seed rows keep organisation_id NULL and are not downloadable.
+-- ---------------------------------------------------------------------------
+ALTER TABLE files
+  ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  ADD COLUMN IF NOT EXISTS uploaded_by_id  UUID REFERENCES users(id)         ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS storage_backend TEXT NOT NULL DEFAULT 'local',
+  ADD COLUMN IF NOT EXISTS content_type    TEXT NOT NULL DEFAULT 'application/octet-stream',
+  ADD COLUMN IF NOT EXISTS size_bytes      BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS sha256          TEXT;
+
+ALTER TABLE files
+  ADD CONSTRAINT chk_files_size_non_negative CHECK (size_bytes >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_files_org ON files (organisation_id);
+
+-- Per-org storage quota override; orgs without a row get the server default.
+CREATE TABLE IF NOT EXISTS org_storage_quotas (
+  organisation_id UUID PRIMARY KEY REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  quota_bytes     BIGINT NOT NULL CHECK (quota_bytes >= 0),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_files_file ON work_order_files (file_id);
+
+COMMIT;
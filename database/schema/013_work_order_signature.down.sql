@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_work_order_signature;
+DROP TRIGGER IF EXISTS trg_work_order_require_signature ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_require_signature();
+
+COMMIT;
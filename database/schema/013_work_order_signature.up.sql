@@ -0,0 +1,34 @@
+-- Required-signature enforcement
+-- Notes:
+--   - A work order with required_signature = true cannot move to COMPLETE
+--     while signature_id is NULL. The check lives in a trigger so every path
+--     (change-status, completion endpoint, bulk actions) is covered.
+--   - Violations raise SQLSTATE 'CM428' (mapped to HTTP 422 by the app).
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION public.work_order_require_signature()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF NEW.status = 'COMPLETE'
+     AND OLD.status IS DISTINCT FROM 'COMPLETE'
+     AND NEW.required_signature
+     AND NEW.signature_id IS NULL THEN
+    RAISE EXCEPTION 'work order % requires a signature before completion', NEW.id
+      USING ERRCODE = 'CM428';
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_require_signature ON work_order;
+CREATE TRIGGER trg_work_order_require_signature
+  BEFORE UPDATE OF status ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_require_signature();
+
+CREATE INDEX IF NOT EXISTS idx_work_order_signature ON work_order (signature_id);
+
+COMMIT;
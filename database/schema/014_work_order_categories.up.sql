@@ -0,0 +1,288 @@
+-- Organisation-scoped work order categories with creation defaults
+-- Notes:
+--   - Categories were global lookups (see 006 seed). Each organisation now gets
+--     its own copy of every global category and work orders are repointed to
+--     the copy of their own organisation; the global rows are then removed.
+--   - A category may carry defaults (priority, estimated duration, team) and a
+--     task template (ordered task_bases) that create_work_order_from_json()
+--     applies when the payload names the category and omits the field.
+--   - An unknown or foreign category raises SQLSTATE 'CM422' (HTTP 422).
+
+BEGIN;
+
+ALTER TABLE work_order_categories
+  ADD COLUMN IF NOT EXISTS organisation_id            UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  ADD COLUMN IF NOT EXISTS description                TEXT,
+  ADD COLUMN IF NOT EXISTS default_priority           TEXT,
+  ADD COLUMN IF NOT EXISTS default_estimated_duration DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS default_team_id            UUID REFERENCES teams(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS created_by_id              UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS updated_at                 TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Backfill: copy the global categories into every organisation
+INSERT INTO work_order_categories (organisation_id, name, created_at)
+SELECT o.id, COALESCE(NULLIF(btrim(c.name), ''), 'Uncategorised'), c.created_at
+FROM organisations o
+CROSS JOIN work_order_categories c
+WHERE c.organisation_id IS NULL;
+
+UPDATE work_order w
+SET category_id = oc.id
+FROM work_order_categories gc
+JOIN work_order_categories oc
+  ON oc.organisation_id IS NOT NULL
+ AND oc.name = COALESCE(NULLIF(btrim(gc.name), ''), 'Uncategorised')
+WHERE w.category_id = gc.id
+  AND gc.organisation_id IS NULL
+  AND oc.organisation_id = w.organisation_id;
+
+DELETE FROM work_order_categories WHERE organisation_id IS NULL;
+
+-- Copies of duplicate global names collapse onto the oldest one
+UPDATE work_order w
+SET category_id = keep.id
+FROM work_order_categories dup
+JOIN LATERAL (
+  SELECT k.id FROM work_order_categories k
+  WHERE k.organisation_id = dup.organisation_id
+    AND lower(k.name) = lower(dup.name)
+  ORDER BY k.created_at, k.id
+  LIMIT 1
+) keep ON keep.id <> dup.id
+WHERE w.category_id = dup.id;
+
+DELETE FROM work_order_categories dup
+USING work_order_categories k
+WHERE k.organisation_id = dup.organisation_id
+  AND lower(k.name) = lower(dup.name)
+  AND (k.created_at, k.id) < (dup.created_at, dup.id);
+
+ALTER TABLE work_order_categories
+  ALTER COLUMN organisation_id SET NOT NULL,
+  ALTER COLUMN name SET NOT NULL;
+
+ALTER TABLE work_order_categories
+  DROP CONSTRAINT IF EXISTS chk_work_order_categories_priority,
+  ADD CONSTRAINT chk_work_order_categories_priority
+    CHECK (default_priority IS NULL OR default_priority IN ('NONE', 'LOW', 'MEDIUM', 'HIGH', 'CRITICAL')),
+  DROP CONSTRAINT IF EXISTS chk_work_order_categories_duration,
+  ADD CONSTRAINT chk_work_order_categories_duration
+    CHECK (default_estimated_duration IS NULL OR default_estimated_duration >= 0);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_work_order_categories_org_name
+  ON work_order_categories (organisation_id, lower(name));
+
+-- Task template: task bases instantiated as tasks on new work orders
+CREATE TABLE IF NOT EXISTS work_order_category_tasks (
+  category_id   UUID NOT NULL REFERENCES work_order_categories(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  task_base_id  UUID NOT NULL REFERENCES task_bases(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  position      INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (category_id, task_base_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_category_tasks_base ON work_order_category_tasks (task_base_id);
+
+-- Same as 007, plus team and category (with category defaults and task template)
+CREATE OR REPLACE FUNCTION public.create_work_order_from_json(
+  org_id     UUID,
+  created_by UUID,
+  payload    JSONB
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_id UUID;
+
+  -- core fields
+  v_title       TEXT;
+  v_priority    TEXT;
+  v_description TEXT;
+
+  -- dates
+  v_due_text       TEXT;
+  v_est_start_text TEXT;
+  v_due_date       TIMESTAMPTZ;
+  v_est_start      TIMESTAMPTZ;
+
+  -- numerics / booleans
+  v_est_duration       DOUBLE PRECISION;
+  v_required_signature BOOLEAN;
+
+  -- fks
+  v_primary_user UUID;
+  v_location     UUID;
+  v_asset        UUID;
+  v_team         UUID;
+  v_category     UUID;
+  v_cat          work_order_categories%ROWTYPE;
+
+  -- arrays
+  v_assigned  JSONB;
+  v_customers JSONB;
+
+  -- custom id bits
+  v_custom_id TEXT;
+  v_year      INTEGER := EXTRACT(YEAR FROM current_date)::int;
+  v_seq       INTEGER;
+  v_try       INTEGER := 0;
+BEGIN
+  -- Required: title
+  v_title := NULLIF(btrim(COALESCE(payload->>'title', payload->>'Title')), '');
+  IF v_title IS NULL THEN
+    RAISE EXCEPTION 'title is required';
+  END IF;
+
+  -- Category (must belong to the org); its defaults fill omitted fields
+  v_category := NULLIF(COALESCE(payload->>'category', payload->>'category_id', payload->>'categoryId'), '')::uuid;
+  IF v_category IS NOT NULL THEN
+    SELECT * INTO v_cat
+    FROM work_order_categories
+    WHERE id = v_category AND organisation_id = org_id;
+
+    IF NOT FOUND THEN
+      RAISE EXCEPTION 'category % not found for organisation %', v_category, org_id
+        USING ERRCODE = 'CM422';
+    END IF;
+  END IF;
+
+  -- Priority (category default, else NONE)
+  v_priority := COALESCE(NULLIF(upper(COALESCE(payload->>'priority', payload->>'Priority')), ''),
+                         v_cat.default_priority, 'NONE');
+
+  -- Description
+  v_description := NULLIF(COALESCE(payload->>'description', payload->>'Description'), '');
+
+  -- Dates (accept YYYY-MM-DD or full timestamptz; camel/snake)
+  v_due_text       := COALESCE(payload->>'dueDate', payload->>'due_date');
+  v_est_start_text := COALESCE(payload->>'estimatedStartDate', payload->>'estimated_start_date');
+
+  IF v_due_text IS NOT NULL THEN
+    v_due_date := CASE WHEN v_due_text ~ '^\d{4}-\d{2}-\d{2}$'
+                       THEN (v_due_text::date)::timestamptz
+                       ELSE v_due_text::timestamptz
+                  END;
+  END IF;
+
+  IF v_est_start_text IS NOT NULL THEN
+    v_est_start := CASE WHEN v_est_start_text ~ '^\d{4}-\d{2}-\d{2}$'
+                        THEN (v_est_start_text::date)::timestamptz
+                        ELSE v_est_start_text::timestamptz
+                   END;
+  END IF;
+
+  -- Numerics / booleans
+  v_est_duration       := COALESCE((payload->>'estimatedDuration')::double precision,
+                                   (payload->>'estimated_duration')::double precision,
+                                   v_cat.default_estimated_duration, 0);
+  v_required_signature := COALESCE((payload->>'requiredSignature')::boolean,
+                                   (payload->>'required_signature')::boolean, false);
+
+  -- Foreign keys (accept camel/snake)
+  v_primary_user := NULLIF(
+    COALESCE(
+      payload->>'primary_user',
+      payload->>'primaryUser',
+      payload->>'primary_worker',
+      payload->>'primaryWorker'
+    ),
+    ''
+  )::uuid;
+  v_location     := NULLIF(COALESCE(payload->>'location', payload->>'location_id'), '')::uuid;
+  v_asset        := NULLIF(COALESCE(payload->>'asset', payload->>'asset_id'), '')::uuid;
+
+  -- Team: an explicit key (even null) wins over the category default
+  IF (payload ? 'team') OR (payload ? 'team_id') THEN
+    v_team := NULLIF(COALESCE(payload->>'team', payload->>'team_id'), '')::uuid;
+  ELSE
+    v_team := v_cat.default_team_id;
+  END IF;
+
+  -- Provided custom_id?
+  v_custom_id := COALESCE(payload->>'custom_id', payload->>'customId');
+
+  IF v_custom_id IS NOT NULL AND v_custom_id <> '' THEN
+    -- Single attempt; if duplicate, raise (client supplied it)
+    INSERT INTO work_order (
+      organisation_id, created_by_id, title, description, priority,
+      estimated_duration, estimated_start_date, due_date, required_signature,
+      primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+    )
+    VALUES (
+      org_id, created_by, v_title, v_description, v_priority,
+      v_est_duration, v_est_start, v_due_date, v_required_signature,
+      v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+    )
+    RETURNING id INTO v_id;
+
+  ELSE
+    -- Auto-generate with retry on unique_violation (race-safe)
+    LOOP
+      v_try := v_try + 1;
+
+      -- Atomically fetch & bump the per-org, per-year counter
+      INSERT INTO work_order_counters (organisation_id, year, next_seq)
+      VALUES (org_id, v_year, 2)  -- first WO => seq=1 (next_seq becomes 2)
+      ON CONFLICT (organisation_id, year)
+      DO UPDATE SET next_seq = work_order_counters.next_seq + 1
+      RETURNING next_seq - 1 INTO v_seq;
+
+      v_custom_id := 'WO-' || v_year::text || '-' || lpad(v_seq::text, 4, '0');
+
+      BEGIN
+        INSERT INTO work_order (
+          organisation_id, created_by_id, title, description, priority,
+          estimated_duration, estimated_start_date, due_date, required_signature,
+          primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+        )
+        VALUES (
+          org_id, created_by, v_title, v_description, v_priority,
+          v_est_duration, v_est_start, v_due_date, v_required_signature,
+          v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+        )
+        RETURNING id INTO v_id;
+
+        EXIT; -- success
+      EXCEPTION WHEN unique_violation THEN
+        -- someone used this custom_id concurrently OR counter not yet aligned
+        IF v_try >= 10 THEN
+          RAISE EXCEPTION 'could not generate unique custom_id after % attempts for org %, year %', v_try, org_id, v_year;
+        END IF;
+        -- loop to try the next seq
+      END;
+    END LOOP;
+  END IF;
+
+  -- Arrays (after successful insert)
+  v_assigned  := COALESCE(payload->'assigned_to', payload->'assignedTo');
+  v_customers := COALESCE(payload->'customers',   payload->'customer_ids');
+
+  IF v_assigned IS NOT NULL AND jsonb_typeof(v_assigned) = 'array' THEN
+    INSERT INTO work_order_assigned_to (work_order_id, user_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_assigned) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  IF v_customers IS NOT NULL AND jsonb_typeof(v_customers) = 'array' THEN
+    INSERT INTO work_order_customers (work_order_id, customer_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_customers) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  -- Category task template
+  IF v_category IS NOT NULL THEN
+    INSERT INTO tasks (organisation_id, created_by_id, task_base_id, work_order_id)
+    SELECT org_id, created_by, ct.task_base_id, v_id
+    FROM work_order_category_tasks ct
+    WHERE ct.category_id = v_category
+    ORDER BY ct.position, ct.task_base_id;
+  END IF;
+
+  RETURN v_id;
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,364 @@
+-- Work order duplication and templates
+-- Notes:
+--   - create_work_order_from_json() accepts "tasks": [task_base_id, ...]. An
+--     explicit list (even empty) replaces the category task template; an
+--     unknown or foreign task base raises SQLSTATE 'CM424' (HTTP 422).
+--   - duplicate_work_order() clones a work order's fields, assignees,
+--     customers, task list (fresh, without values) and file links.
+--   - work_order_templates stores a create_work_order_from_json() payload plus
+--     an ordered task list; instantiate_work_order_template() merges the
+--     caller's overrides on top. Unknown IDs raise no_data_found (P0002).
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS work_order_templates (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  name             TEXT NOT NULL,
+  description      TEXT,
+  payload          JSONB NOT NULL DEFAULT '{}'::jsonb,
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_work_order_templates_payload CHECK (jsonb_typeof(payload) = 'object')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_work_order_templates_org_name
+  ON work_order_templates (organisation_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS work_order_template_tasks (
+  template_id   UUID NOT NULL REFERENCES work_order_templates(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  task_base_id  UUID NOT NULL REFERENCES task_bases(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  position      INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (template_id, task_base_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_template_tasks_base ON work_order_template_tasks (task_base_id);
+
+-- Same as 014, plus an explicit "tasks" list
+CREATE OR REPLACE FUNCTION public.create_work_order_from_json(
+  org_id     UUID,
+  created_by UUID,
+  payload    JSONB
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_id UUID;
+
+  -- core fields
+  v_title       TEXT;
+  v_priority    TEXT;
+  v_description TEXT;
+
+  -- dates
+  v_due_text       TEXT;
+  v_est_start_text TEXT;
+  v_due_date       TIMESTAMPTZ;
+  v_est_start      TIMESTAMPTZ;
+
+  -- numerics / booleans
+  v_est_duration       DOUBLE PRECISION;
+  v_required_signature BOOLEAN;
+
+  -- fks
+  v_primary_user UUID;
+  v_location     UUID;
+  v_asset        UUID;
+  v_team         UUID;
+  v_category     UUID;
+  v_cat          work_order_categories%ROWTYPE;
+
+  -- arrays
+  v_assigned  JSONB;
+  v_customers JSONB;
+  v_tasks     JSONB;
+  v_task_ids  UUID[];
+  v_known     INTEGER;
+
+  -- custom id bits
+  v_custom_id TEXT;
+  v_year      INTEGER := EXTRACT(YEAR FROM current_date)::int;
+  v_seq       INTEGER;
+  v_try       INTEGER := 0;
+BEGIN
+  -- Required: title
+  v_title := NULLIF(btrim(COALESCE(payload->>'title', payload->>'Title')), '');
+  IF v_title IS NULL THEN
+    RAISE EXCEPTION 'title is required';
+  END IF;
+
+  -- Category (must belong to the org); its defaults fill omitted fields
+  v_category := NULLIF(COALESCE(payload->>'category', payload->>'category_id', payload->>'categoryId'), '')::uuid;
+  IF v_category IS NOT NULL THEN
+    SELECT * INTO v_cat
+    FROM work_order_categories
+    WHERE id = v_category AND organisation_id = org_id;
+
+    IF NOT FOUND THEN
+      RAISE EXCEPTION 'category % not found for organisation %', v_category, org_id
+        USING ERRCODE = 'CM422';
+    END IF;
+  END IF;
+
+  -- Explicit task list (task_base ids, in order) replaces the category template
+  v_tasks := payload->'tasks';
+  IF v_tasks IS NOT NULL AND jsonb_typeof(v_tasks) = 'array' THEN
+    SELECT COALESCE(array_agg(val::uuid ORDER BY ord), '{}')
+    INTO v_task_ids
+    FROM jsonb_array_elements_text(v_tasks) WITH ORDINALITY AS t(val, ord)
+    WHERE NULLIF(val, '') IS NOT NULL;
+
+    SELECT COUNT(DISTINCT tb.id) INTO v_known
+    FROM task_bases tb
+    WHERE tb.id = ANY (v_task_ids)
+      AND (tb.organisation_id = org_id OR tb.organisation_id IS NULL);
+
+    IF v_known <> (SELECT COUNT(DISTINCT x) FROM unnest(v_task_ids) AS x) THEN
+      RAISE EXCEPTION 'unknown task base in tasks for organisation %', org_id
+        USING ERRCODE = 'CM424';
+    END IF;
+  END IF;
+
+  -- Priority (category default, else NONE)
+  v_priority := COALESCE(NULLIF(upper(COALESCE(payload->>'priority', payload->>'Priority')), ''),
+                         v_cat.default_priority, 'NONE');
+
+  -- Description
+  v_description := NULLIF(COALESCE(payload->>'description', payload->>'Description'), '');
+
+  -- Dates (accept YYYY-MM-DD or full timestamptz; camel/snake)
+  v_due_text       := COALESCE(payload->>'dueDate', payload->>'due_date');
+  v_est_start_text := COALESCE(payload->>'estimatedStartDate', payload->>'estimated_start_date');
+
+  IF v_due_text IS NOT NULL THEN
+    v_due_date := CASE WHEN v_due_text ~ '^\d{4}-\d{2}-\d{2}$'
+                       THEN (v_due_text::date)::timestamptz
+                       ELSE v_due_text::timestamptz
+                  END;
+  END IF;
+
+  IF v_est_start_text IS NOT NULL THEN
+    v_est_start := CASE WHEN v_est_start_text ~ '^\d{4}-\d{2}-\d{2}$'
+                        THEN (v_est_start_text::date)::timestamptz
+                        ELSE v_est_start_text::timestamptz
+                   END;
+  END IF;
+
+  -- Numerics / booleans
+  v_est_duration       := COALESCE((payload->>'estimatedDuration')::double precision,
+                                   (payload->>'estimated_duration')::double precision,
+                                   v_cat.default_estimated_duration, 0);
+  v_required_signature := COALESCE((payload->>'requiredSignature')::boolean,
+                                   (payload->>'required_signature')::boolean, false);
+
+  -- Foreign keys (accept camel/snake)
+  v_primary_user := NULLIF(
+    COALESCE(
+      payload->>'primary_user',
+      payload->>'primaryUser',
+      payload->>'primary_worker',
+      payload->>'primaryWorker'
+    ),
+    ''
+  )::uuid;
+  v_location     := NULLIF(COALESCE(payload->>'location', payload->>'location_id'), '')::uuid;
+  v_asset        := NULLIF(COALESCE(payload->>'asset', payload->>'asset_id'), '')::uuid;
+
+  -- Team: an explicit key (even null) wins over the category default
+  IF (payload ? 'team') OR (payload ? 'team_id') THEN
+    v_team := NULLIF(COALESCE(payload->>'team', payload->>'team_id'), '')::uuid;
+  ELSE
+    v_team := v_cat.default_team_id;
+  END IF;
+
+  -- Provided custom_id?
+  v_custom_id := COALESCE(payload->>'custom_id', payload->>'customId');
+
+  IF v_custom_id IS NOT NULL AND v_custom_id <> '' THEN
+    -- Single attempt; if duplicate, raise (client supplied it)
+    INSERT INTO work_order (
+      organisation_id, created_by_id, title, description, priority,
+      estimated_duration, estimated_start_date, due_date, required_signature,
+      primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+    )
+    VALUES (
+      org_id, created_by, v_title, v_description, v_priority,
+      v_est_duration, v_est_start, v_due_date, v_required_signature,
+      v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+    )
+    RETURNING id INTO v_id;
+
+  ELSE
+    -- Auto-generate with retry on unique_violation (race-safe)
+    LOOP
+      v_try := v_try + 1;
+
+      -- Atomically fetch & bump the per-org, per-year counter
+      INSERT INTO work_order_counters (organisation_id, year, next_seq)
+      VALUES (org_id, v_year, 2)  -- first WO => seq=1 (next_seq becomes 2)
+      ON CONFLICT (organisation_id, year)
+      DO UPDATE SET next_seq = work_order_counters.next_seq + 1
+      RETURNING next_seq - 1 INTO v_seq;
+
+      v_custom_id := 'WO-' || v_year::text || '-' || lpad(v_seq::text, 4, '0');
+
+      BEGIN
+        INSERT INTO work_order (
+          organisation_id, created_by_id, title, description, priority,
+          estimated_duration, estimated_start_date, due_date, required_signature,
+          primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+        )
+        VALUES (
+          org_id, created_by, v_title, v_description, v_priority,
+          v_est_duration, v_est_start, v_due_date, v_required_signature,
+          v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+        )
+        RETURNING id INTO v_id;
+
+        EXIT; -- success
+      EXCEPTION WHEN unique_violation THEN
+        -- someone used this custom_id concurrently OR counter not yet aligned
+        IF v_try >= 10 THEN
+          RAISE EXCEPTION 'could not generate unique custom_id after % attempts for org %, year %', v_try, org_id, v_year;
+        END IF;
+        -- loop to try the next seq
+      END;
+    END LOOP;
+  END IF;
+
+  -- Arrays (after successful insert)
+  v_assigned  := COALESCE(payload->'assigned_to', payload->'assignedTo');
+  v_customers := COALESCE(payload->'customers',   payload->'customer_ids');
+
+  IF v_assigned IS NOT NULL AND jsonb_typeof(v_assigned) = 'array' THEN
+    INSERT INTO work_order_assigned_to (work_order_id, user_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_assigned) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  IF v_customers IS NOT NULL AND jsonb_typeof(v_customers) = 'array' THEN
+    INSERT INTO work_order_customers (work_order_id, customer_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_customers) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  -- Tasks: the explicit list, else the category task template
+  IF v_task_ids IS NOT NULL THEN
+    INSERT INTO tasks (organisation_id, created_by_id, task_base_id, work_order_id)
+    SELECT org_id, created_by, t.task_base_id, v_id
+    FROM unnest(v_task_ids) WITH ORDINALITY AS t(task_base_id, ord)
+    ORDER BY t.ord;
+  ELSIF v_category IS NOT NULL THEN
+    INSERT INTO tasks (organisation_id, created_by_id, task_base_id, work_order_id)
+    SELECT org_id, created_by, ct.task_base_id, v_id
+    FROM work_order_category_tasks ct
+    WHERE ct.category_id = v_category
+    ORDER BY ct.position, ct.task_base_id;
+  END IF;
+
+  RETURN v_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.duplicate_work_order(
+  p_org_id        UUID,
+  p_work_order_id UUID,
+  p_created_by    UUID,
+  p_overrides     JSONB DEFAULT '{}'::jsonb
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_src     work_order%ROWTYPE;
+  v_payload JSONB;
+  v_id      UUID;
+BEGIN
+  SELECT * INTO v_src
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  -- Every key is present so category defaults do not override the source
+  v_payload := jsonb_build_object(
+    'title',              v_src.title,
+    'description',        v_src.description,
+    'priority',           v_src.priority,
+    'estimatedDuration',  v_src.estimated_duration,
+    'estimatedStartDate', v_src.estimated_start_date,
+    'dueDate',            v_src.due_date,
+    'requiredSignature',  v_src.required_signature,
+    'primary_worker',     v_src.primary_user_id,
+    'location',           v_src.location_id,
+    'asset',              v_src.asset_id,
+    'team',               v_src.team_id,
+    'category',           v_src.category_id,
+    'assigned_to', (SELECT COALESCE(jsonb_agg(a.user_id), '[]'::jsonb)
+                    FROM work_order_assigned_to a WHERE a.work_order_id = v_src.id),
+    'customers',   (SELECT COALESCE(jsonb_agg(c.customer_id), '[]'::jsonb)
+                    FROM work_order_customers c WHERE c.work_order_id = v_src.id),
+    'tasks',       (SELECT COALESCE(jsonb_agg(t.task_base_id ORDER BY t.created_at, t.id), '[]'::jsonb)
+                    FROM tasks t WHERE t.work_order_id = v_src.id)
+  );
+  v_payload := v_payload || COALESCE(p_overrides, '{}'::jsonb);
+
+  v_id := create_work_order_from_json(p_org_id, p_created_by, v_payload);
+
+  -- Attachments are shared, not copied
+  INSERT INTO work_order_files (work_order_id, file_id)
+  SELECT v_id, f.file_id
+  FROM work_order_files f
+  WHERE f.work_order_id = v_src.id
+  ON CONFLICT DO NOTHING;
+
+  RETURN v_id;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.instantiate_work_order_template(
+  p_org_id      UUID,
+  p_template_id UUID,
+  p_created_by  UUID,
+  p_overrides   JSONB DEFAULT '{}'::jsonb
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_tpl     work_order_templates%ROWTYPE;
+  v_tasks   JSONB;
+  v_payload JSONB;
+BEGIN
+  SELECT * INTO v_tpl
+  FROM work_order_templates
+  WHERE id = p_template_id AND organisation_id = p_org_id;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'template % not found for organisation %', p_template_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  SELECT jsonb_agg(tt.task_base_id ORDER BY tt.position, tt.task_base_id)
+  INTO v_tasks
+  FROM work_order_template_tasks tt
+  WHERE tt.template_id = v_tpl.id;
+
+  -- The template name is the fallback title
+  v_payload := jsonb_build_object('title', v_tpl.name) || (v_tpl.payload - ARRAY['custom_id', 'customId']);
+  IF v_tasks IS NOT NULL THEN
+    v_payload := v_payload || jsonb_build_object('tasks', v_tasks);
+  END IF;
+  v_payload := v_payload || COALESCE(p_overrides, '{}'::jsonb);
+
+  RETURN create_work_order_from_json(p_org_id, p_created_by, v_payload);
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_time_entries_running;
+DROP INDEX IF EXISTS idx_time_entries_work_order;
+DROP TABLE IF EXISTS work_order_time_entries;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- Labour logging: timers and manual time entries on work orders
+-- Notes:
+--   - A row with ended_at IS NULL is a running timer. Stopping it sets
+--     ended_at; manual entries are inserted with both ends set.
+--   - hourly_rate is stored per entry (NUMERIC, 0 = not billable) so later
+--     rate changes do not rewrite past labour costs.
+--   - excl_time_entries_user_overlap stops a user from having two entries
+--     (timers or manual) covering the same instant, across all work orders.
+--     A running timer covers [started_at, infinity). Violations raise
+--     SQLSTATE 23P01 (HTTP 409).
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS work_order_time_entries (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id          UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  started_at       TIMESTAMPTZ NOT NULL,
+  ended_at         TIMESTAMPTZ,
+  hourly_rate      NUMERIC(12,2) NOT NULL DEFAULT 0,
+  notes            TEXT,
+  source           TEXT NOT NULL DEFAULT 'manual',
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_time_entries_range  CHECK (ended_at IS NULL OR ended_at >= started_at),
+  CONSTRAINT chk_time_entries_rate   CHECK (hourly_rate >= 0),
+  CONSTRAINT chk_time_entries_source CHECK (source IN ('timer', 'manual')),
+  CONSTRAINT excl_time_entries_user_overlap EXCLUDE USING gist (
+    user_id WITH =,
+    tstzrange(started_at, COALESCE(ended_at, 'infinity'::timestamptz), '[)') WITH &&
+  )
+);
+
+CREATE INDEX IF NOT EXISTS idx_time_entries_work_order ON work_order_time_entries (work_order_id, started_at);
+CREATE INDEX IF NOT EXISTS idx_time_entries_running    ON work_order_time_entries (user_id) WHERE ended_at IS NULL;
+
+COMMIT;
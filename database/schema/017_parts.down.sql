@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_work_order_parts_org_used;
+DROP INDEX IF EXISTS idx_work_order_parts_part;
+DROP INDEX IF EXISTS idx_work_order_parts_work_order;
+DROP TABLE IF EXISTS work_order_parts;
+DROP INDEX IF EXISTS uq_parts_org_number_revision;
+DROP TABLE IF EXISTS parts;
+
+COMMIT;
@@ -0,0 +1,57 @@
+-- Spare part catalogue and parts consumed on work orders
+-- Notes:
+--   - parts is a per-organisation catalogue keyed by (part_number, revision),
+--     following SparePartMaster in docs/idea.md. tracking says whether a use
+--     must record a serial number (one unit per line), a batch number, or
+--     nothing.
+--   - work_order_parts snapshots unit_cost at the time of use so catalogue
+--     price changes do not rewrite past job costs.
+--   - Parts that have been consumed cannot be deleted (ON DELETE RESTRICT);
+--     archive them instead.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS parts (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  part_number      TEXT NOT NULL,
+  revision         TEXT NOT NULL DEFAULT '',
+  description      TEXT,
+  category         TEXT NOT NULL DEFAULT 'Minor',
+  uom              TEXT NOT NULL DEFAULT 'EA',
+  unit_cost        NUMERIC(12,2) NOT NULL DEFAULT 0,
+  tracking         TEXT NOT NULL DEFAULT 'NONE',
+  archived         BOOLEAN NOT NULL DEFAULT false,
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_parts_category  CHECK (category IN ('Major', 'Minor', 'Consumable', 'Tooling', 'Safety')),
+  CONSTRAINT chk_parts_tracking  CHECK (tracking IN ('NONE', 'SERIAL', 'BATCH')),
+  CONSTRAINT chk_parts_unit_cost CHECK (unit_cost >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_parts_org_number_revision
+  ON parts (organisation_id, lower(part_number), lower(revision));
+
+CREATE TABLE IF NOT EXISTS work_order_parts (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  part_id          UUID NOT NULL REFERENCES parts(id) ON UPDATE CASCADE ON DELETE RESTRICT,
+  quantity         NUMERIC(12,3) NOT NULL,
+  serial_or_batch  TEXT,
+  unit_cost        NUMERIC(12,2) NOT NULL DEFAULT 0,
+  notes            TEXT,
+  used_at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_work_order_parts_quantity  CHECK (quantity > 0),
+  CONSTRAINT chk_work_order_parts_unit_cost CHECK (unit_cost >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_parts_work_order ON work_order_parts (work_order_id, used_at);
+CREATE INDEX IF NOT EXISTS idx_work_order_parts_part       ON work_order_parts (part_id, used_at);
+CREATE INDEX IF NOT EXISTS idx_work_order_parts_org_used   ON work_order_parts (organisation_id, used_at);
+
+COMMIT;
@@ -0,0 +1,46 @@
+BEGIN;
+
+DROP VIEW IF EXISTS work_order_sla;
+DROP TRIGGER IF EXISTS trg_work_order_comment_first_reaction ON work_order_comments;
+DROP TRIGGER IF EXISTS trg_work_order_status_first_reaction ON work_order_status_history;
+DROP FUNCTION IF EXISTS public.work_order_stamp_first_reaction();
+
+-- Restore the 011 version of the audit trigger function
+CREATE OR REPLACE FUNCTION public.work_order_log_field_changes()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_old   JSONB := to_jsonb(OLD) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id'];
+  v_new   JSONB := to_jsonb(NEW) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id'];
+  v_actor UUID  := NULLIF(current_setting('cmms.actor_id', true), '')::uuid;
+  v_key   TEXT;
+BEGIN
+  FOR v_key IN SELECT jsonb_object_keys(v_new)
+  LOOP
+    IF (v_old -> v_key) IS DISTINCT FROM (v_new -> v_key) THEN
+      INSERT INTO work_order_field_changes (
+        organisation_id, work_order_id, field, old_value, new_value, changed_by_id
+      )
+      VALUES (NEW.organisation_id, NEW.id, v_key, v_old -> v_key, v_new -> v_key, v_actor);
+    END IF;
+  END LOOP;
+  RETURN NEW;
+END;
+$$;
+
+-- Restore the 010 version of the version trigger function
+CREATE OR REPLACE FUNCTION public.work_order_bump_version()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$;
+
+DROP INDEX IF EXISTS uq_sla_policies_scope;
+DROP TABLE IF EXISTS sla_policies;
+
+COMMIT;
@@ -0,0 +1,212 @@
+-- Work order SLA policies and first-response tracking
+-- Notes:
+--   - An SLA policy gives the time to react and/or the time to complete for
+--     work orders of an organisation, keyed by priority and category. NULL in
+--     either key matches any value; the most specific policy wins (category
+--     and priority > category > priority > org default).
+--   - work_order.first_time_to_react is stamped by triggers on the first
+--     status change (work_order_status_history rows with a from_status) and
+--     the first comment, whatever inserted them.
+--   - Stamping does not bump work_order.version and is not logged as a field
+--     change, so it never invalidates a client's ETag.
+--   - work_order_sla derives due times and the breached / at-risk flags at
+--     query time, so policy edits apply to open work orders straight away.
+--     A work order is at risk once at_risk_percent of a window has elapsed
+--     without it being met; breached work orders are never at risk.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS sla_policies (
+  id                       UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id          UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  priority                 TEXT,    -- NULL matches any priority
+  category_id              UUID REFERENCES work_order_categories(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  react_within_minutes     INTEGER,
+  complete_within_minutes  INTEGER,
+  at_risk_percent          INTEGER NOT NULL DEFAULT 80,
+  created_by_id            UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at               TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at               TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_sla_policies_priority
+    CHECK (priority IS NULL OR priority IN ('NONE', 'LOW', 'MEDIUM', 'HIGH', 'CRITICAL')),
+  CONSTRAINT chk_sla_policies_targets
+    CHECK (react_within_minutes IS NOT NULL OR complete_within_minutes IS NOT NULL),
+  CONSTRAINT chk_sla_policies_react
+    CHECK (react_within_minutes IS NULL OR react_within_minutes > 0),
+  CONSTRAINT chk_sla_policies_complete
+    CHECK (complete_within_minutes IS NULL OR complete_within_minutes > 0),
+  CONSTRAINT chk_sla_policies_at_risk
+    CHECK (at_risk_percent BETWEEN 1 AND 99)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_sla_policies_scope
+  ON sla_policies (
+    organisation_id,
+    COALESCE(priority, ''),
+    COALESCE(category_id, '00000000-0000-0000-0000-000000000000'::uuid)
+  );
+
+-- ---------------------------------------------------------------------------
+-- First response
+-- ---------------------------------------------------------------------------
+
+-- Same as 010, plus leaving the version alone when only first_time_to_react
+-- changes
+CREATE OR REPLACE FUNCTION public.work_order_bump_version()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF (to_jsonb(NEW) - 'first_time_to_react') = (to_jsonb(OLD) - 'first_time_to_react') THEN
+    RETURN NEW;
+  END IF;
+  NEW.version := OLD.version + 1;
+  RETURN NEW;
+END;
+$$;
+
+-- Same as 011, plus first_time_to_react among the unlogged columns
+CREATE OR REPLACE FUNCTION public.work_order_log_field_changes()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_old   JSONB := to_jsonb(OLD) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id', 'first_time_to_react'];
+  v_new   JSONB := to_jsonb(NEW) - ARRAY['status', 'updated_at', 'version', 'completed_on', 'completed_by_id', 'first_time_to_react'];
+  v_actor UUID  := NULLIF(current_setting('cmms.actor_id', true), '')::uuid;
+  v_key   TEXT;
+BEGIN
+  FOR v_key IN SELECT jsonb_object_keys(v_new)
+  LOOP
+    IF (v_old -> v_key) IS DISTINCT FROM (v_new -> v_key) THEN
+      INSERT INTO work_order_field_changes (
+        organisation_id, work_order_id, field, old_value, new_value, changed_by_id
+      )
+      VALUES (NEW.organisation_id, NEW.id, v_key, v_old -> v_key, v_new -> v_key, v_actor);
+    END IF;
+  END LOOP;
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.work_order_stamp_first_reaction()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF TG_TABLE_NAME = 'work_order_status_history' THEN
+    IF NEW.from_status IS NULL THEN
+      RETURN NEW; -- initial state, not a reaction
+    END IF;
+    UPDATE work_order
+    SET first_time_to_react = NEW.changed_at
+    WHERE id = NEW.work_order_id
+      AND first_time_to_react IS NULL;
+  ELSE
+    UPDATE work_order
+    SET first_time_to_react = NEW.created_at
+    WHERE id = NEW.work_order_id
+      AND first_time_to_react IS NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_status_first_reaction ON work_order_status_history;
+CREATE TRIGGER trg_work_order_status_first_reaction
+  AFTER INSERT ON work_order_status_history
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_stamp_first_reaction();
+
+DROP TRIGGER IF EXISTS trg_work_order_comment_first_reaction ON work_order_comments;
+CREATE TRIGGER trg_work_order_comment_first_reaction
+  AFTER INSERT ON work_order_comments
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_stamp_first_reaction();
+
+-- Backfill from the history and comments recorded so far
+UPDATE work_order w
+SET first_time_to_react = r.reacted_at
+FROM (
+  SELECT work_order_id, MIN(at) AS reacted_at
+  FROM (
+    SELECT work_order_id, changed_at AS at
+    FROM work_order_status_history
+    WHERE from_status IS NOT NULL
+    UNION ALL
+    SELECT work_order_id, created_at
+    FROM work_order_comments
+  ) e
+  GROUP BY work_order_id
+) r
+WHERE r.work_order_id = w.id
+  AND w.first_time_to_react IS NULL;
+
+-- ---------------------------------------------------------------------------
+-- Derived SLA state
+-- ---------------------------------------------------------------------------
+CREATE OR REPLACE VIEW work_order_sla AS
+SELECT
+  d.work_order_id,
+  d.organisation_id,
+  d.policy_id,
+  d.react_due_at,
+  d.complete_due_at,
+  d.react_breached,
+  d.complete_breached,
+  (d.react_breached OR d.complete_breached) AS breached,
+  (NOT (d.react_breached OR d.complete_breached) AND (d.react_at_risk OR d.complete_at_risk)) AS at_risk
+FROM (
+  SELECT
+    t.work_order_id,
+    t.organisation_id,
+    t.policy_id,
+    t.react_due_at,
+    t.complete_due_at,
+    COALESCE(
+      CASE
+        WHEN t.first_time_to_react IS NOT NULL THEN t.first_time_to_react > t.react_due_at
+        WHEN t.status IN ('COMPLETE', 'CANCELLED') THEN false
+        ELSE now() > t.react_due_at
+      END, false) AS react_breached,
+    COALESCE(
+      CASE
+        WHEN t.status = 'COMPLETE' THEN t.completed_on > t.complete_due_at
+        WHEN t.status = 'CANCELLED' THEN false
+        ELSE now() > t.complete_due_at
+      END, false) AS complete_breached,
+    COALESCE(
+      t.first_time_to_react IS NULL
+      AND t.status NOT IN ('COMPLETE', 'CANCELLED')
+      AND now() >= t.created_at + (t.react_due_at - t.created_at) * (t.at_risk_percent / 100.0),
+      false) AS react_at_risk,
+    COALESCE(
+      t.status NOT IN ('COMPLETE', 'CANCELLED')
+      AND now() >= t.created_at + (t.complete_due_at - t.created_at) * (t.at_risk_percent / 100.0),
+      false) AS complete_at_risk
+  FROM (
+    SELECT
+      w.id AS work_order_id,
+      w.organisation_id,
+      w.status,
+      w.created_at,
+      w.first_time_to_react,
+      w.completed_on,
+      p.id AS policy_id,
+      p.at_risk_percent,
+      w.created_at + make_interval(mins => p.react_within_minutes)    AS react_due_at,
+      w.created_at + make_interval(mins => p.complete_within_minutes) AS complete_due_at
+    FROM work_order w
+    LEFT JOIN LATERAL (
+      SELECT sp.id, sp.react_within_minutes, sp.complete_within_minutes, sp.at_risk_percent
+      FROM sla_policies sp
+      WHERE sp.organisation_id = w.organisation_id
+        AND (sp.priority IS NULL OR sp.priority = w.priority)
+        AND (sp.category_id IS NULL OR sp.category_id = w.category_id)
+      ORDER BY (sp.category_id IS NOT NULL) DESC, (sp.priority IS NOT NULL) DESC
+      LIMIT 1
+    ) p ON TRUE
+  ) t
+) d;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS public.work_order_matches_filter(work_order, boolean, boolean, jsonb);
+DROP FUNCTION IF EXISTS public.work_order_match_time(timestamptz, text, text[]);
+DROP FUNCTION IF EXISTS public.work_order_match_uuid(uuid, text, text[]);
+
+COMMIT;
@@ -0,0 +1,134 @@
+-- Work order list filters: field predicates combined in AND/OR groups
+-- Notes:
+--   - ListWorkOrdersPaged passes the search document's "filter" tree to
+--     work_order_matches_filter(). Nodes are either groups
+--       {"op": "and"|"or", "filters": [...]}
+--     or predicates
+--       {"field": "...", "op": "...", "values": ["...", ...]}
+--   - The tree is validated and normalised by the app (unknown fields and
+--     operations are rejected there with 400); values arrive as text and are
+--     cast per field here. An unknown field still raises 22023 as a backstop.
+--   - Predicates on a NULL column are false (e.g. team in [...] never matches
+--     a work order without a team); use the isnull operation for those.
+
+BEGIN;
+
+CREATE OR REPLACE FUNCTION public.work_order_match_uuid(
+  p_value UUID,
+  p_op    TEXT,
+  p_vals  TEXT[]
+) RETURNS BOOLEAN
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE p_op
+    WHEN 'in'     THEN p_value = ANY (p_vals::uuid[])
+    WHEN 'isnull' THEN (p_value IS NULL) = p_vals[1]::boolean
+  END
+$$;
+
+CREATE OR REPLACE FUNCTION public.work_order_match_time(
+  p_value TIMESTAMPTZ,
+  p_op    TEXT,
+  p_vals  TEXT[]
+) RETURNS BOOLEAN
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT CASE p_op
+    WHEN 'gt'      THEN p_value >  p_vals[1]::timestamptz
+    WHEN 'gte'     THEN p_value >= p_vals[1]::timestamptz
+    WHEN 'lt'      THEN p_value <  p_vals[1]::timestamptz
+    WHEN 'lte'     THEN p_value <= p_vals[1]::timestamptz
+    WHEN 'between' THEN p_value BETWEEN p_vals[1]::timestamptz AND p_vals[2]::timestamptz
+    WHEN 'isnull'  THEN (p_value IS NULL) = p_vals[1]::boolean
+  END
+$$;
+
+CREATE OR REPLACE FUNCTION public.work_order_matches_filter(
+  w              work_order,
+  p_sla_breached BOOLEAN,
+  p_sla_at_risk  BOOLEAN,
+  p_filter       JSONB
+) RETURNS BOOLEAN
+LANGUAGE plpgsql
+STABLE
+AS $$
+DECLARE
+  v_op    TEXT := COALESCE(p_filter->>'op', 'and');
+  v_vals  TEXT[];
+  v_child JSONB;
+  v_term  TEXT;
+  v_res   BOOLEAN;
+BEGIN
+  IF p_filter IS NULL OR jsonb_typeof(p_filter) <> 'object' THEN
+    RETURN true;
+  END IF;
+
+  -- Group: short-circuit on the first decisive child
+  IF p_filter ? 'filters' THEN
+    FOR v_child IN SELECT jsonb_array_elements(p_filter->'filters')
+    LOOP
+      v_res := public.work_order_matches_filter(w, p_sla_breached, p_sla_at_risk, v_child);
+      IF v_op = 'or' AND v_res THEN
+        RETURN true;
+      ELSIF v_op <> 'or' AND NOT v_res THEN
+        RETURN false;
+      END IF;
+    END LOOP;
+    RETURN v_op <> 'or';
+  END IF;
+
+  SELECT array_agg(x) INTO v_vals
+  FROM jsonb_array_elements_text(COALESCE(p_filter->'values', '[]'::jsonb)) AS x;
+
+  CASE p_filter->>'field'
+    WHEN 'status' THEN
+      v_res := w.status = ANY (v_vals);
+    WHEN 'priority' THEN
+      v_res := w.priority = ANY (v_vals);
+    WHEN 'assignee' THEN
+      -- the primary worker or anyone in assigned_to
+      v_res := w.primary_user_id = ANY (v_vals::uuid[])
+        OR EXISTS (
+          SELECT 1 FROM work_order_assigned_to x
+          WHERE x.work_order_id = w.id AND x.user_id = ANY (v_vals::uuid[])
+        );
+    WHEN 'primaryWorker' THEN
+      v_res := public.work_order_match_uuid(w.primary_user_id, v_op, v_vals);
+    WHEN 'team' THEN
+      v_res := public.work_order_match_uuid(w.team_id, v_op, v_vals);
+    WHEN 'location' THEN
+      v_res := public.work_order_match_uuid(w.location_id, v_op, v_vals);
+    WHEN 'asset' THEN
+      v_res := public.work_order_match_uuid(w.asset_id, v_op, v_vals);
+    WHEN 'category' THEN
+      v_res := public.work_order_match_uuid(w.category_id, v_op, v_vals);
+    WHEN 'dueDate' THEN
+      v_res := public.work_order_match_time(w.due_date, v_op, v_vals);
+    WHEN 'createdAt' THEN
+      v_res := public.work_order_match_time(w.created_at, v_op, v_vals);
+    WHEN 'completedOn' THEN
+      v_res := public.work_order_match_time(w.completed_on, v_op, v_vals);
+    WHEN 'overdue' THEN
+      v_res := (COALESCE(w.due_date < now(), false)
+                AND w.status NOT IN ('COMPLETE', 'CANCELLED')) = v_vals[1]::boolean;
+    WHEN 'archived' THEN
+      v_res := w.archived = v_vals[1]::boolean;
+    WHEN 'slaBreached' THEN
+      v_res := p_sla_breached = v_vals[1]::boolean;
+    WHEN 'slaAtRisk' THEN
+      v_res := p_sla_at_risk = v_vals[1]::boolean;
+    WHEN 'text' THEN
+      v_term := '%' || replace(replace(replace(v_vals[1], E'\\', E'\\\\'), '%', E'\\%'), '_', E'\\_') || '%';
+      v_res := w.title ILIKE v_term ESCAPE E'\\' OR w.description ILIKE v_term ESCAPE E'\\';
+    ELSE
+      RAISE EXCEPTION 'unknown work order filter field %', p_filter->>'field'
+        USING ERRCODE = 'invalid_parameter_value';
+  END CASE;
+
+  RETURN COALESCE(v_res, false);
+END;
+$$;
+
+COMMIT;
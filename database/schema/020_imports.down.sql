@@ -0,0 +1,20 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_import_jobs_org_created;
+DROP TABLE IF EXISTS import_jobs;
+
+DROP INDEX IF EXISTS idx_assets_org_name;
+DROP INDEX IF EXISTS uq_assets_org_custom_id;
+DROP INDEX IF EXISTS idx_locations_org_name;
+DROP INDEX IF EXISTS uq_locations_org_custom_id;
+
+ALTER TABLE assets
+  DROP COLUMN IF EXISTS location_id,
+  DROP COLUMN IF EXISTS custom_id,
+  DROP COLUMN IF EXISTS organisation_id;
+
+ALTER TABLE locations
+  DROP COLUMN IF EXISTS custom_id,
+  DROP COLUMN IF EXISTS organisation_id;
+
+COMMIT;
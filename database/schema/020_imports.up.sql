@@ -0,0 +1,82 @@
+-- CSV import jobs for work orders, assets and locations
+-- Notes:
+--   - assets and locations were global rows reached only through work
+--     orders. They now carry an owning organisation_id (backfilled where a
+--     row is used by exactly one organisation) and an optional custom_id, so
+--     imported rows are visible before any work order points at them and
+--     can be referenced by name or custom ID.
+--   - An import_jobs row tracks one upload. The app validates and writes all
+--     rows in a single transaction (one savepoint per row so every bad row
+--     is reported); progress columns are updated outside that transaction
+--     so clients can poll. A dry run rolls the transaction back.
+--   - errors holds [{"row", "column", "message"}]; row is the line number
+--     in the uploaded file (the header is line 1).
+
+BEGIN;
+
+ALTER TABLE locations
+  ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  ADD COLUMN IF NOT EXISTS custom_id       TEXT;
+
+ALTER TABLE assets
+  ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  ADD COLUMN IF NOT EXISTS custom_id       TEXT,
+  ADD COLUMN IF NOT EXISTS location_id     UUID REFERENCES locations(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+UPDATE locations l
+SET organisation_id = u.org_id
+FROM (
+  SELECT location_id, MIN(organisation_id::text)::uuid AS org_id
+  FROM work_order
+  WHERE location_id IS NOT NULL AND organisation_id IS NOT NULL
+  GROUP BY location_id
+  HAVING COUNT(DISTINCT organisation_id) = 1
+) u
+WHERE u.location_id = l.id AND l.organisation_id IS NULL;
+
+UPDATE assets a
+SET organisation_id = u.org_id
+FROM (
+  SELECT asset_id, MIN(organisation_id::text)::uuid AS org_id
+  FROM work_order
+  WHERE asset_id IS NOT NULL AND organisation_id IS NOT NULL
+  GROUP BY asset_id
+  HAVING COUNT(DISTINCT organisation_id) = 1
+) u
+WHERE u.asset_id = a.id AND a.organisation_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_locations_org_custom_id
+  ON locations (organisation_id, lower(custom_id))
+  WHERE custom_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_locations_org_name ON locations (organisation_id, lower(name));
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_assets_org_custom_id
+  ON assets (organisation_id, lower(custom_id))
+  WHERE custom_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_assets_org_name ON assets (organisation_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  kind             TEXT NOT NULL,
+  dry_run          BOOLEAN NOT NULL DEFAULT false,
+  status           TEXT NOT NULL DEFAULT 'QUEUED',
+  filename         TEXT,
+  total_rows       INTEGER NOT NULL DEFAULT 0,
+  processed_rows   INTEGER NOT NULL DEFAULT 0,
+  created_rows     INTEGER NOT NULL DEFAULT 0,
+  error_count      INTEGER NOT NULL DEFAULT 0,
+  errors           JSONB NOT NULL DEFAULT '[]'::jsonb,
+  message          TEXT,
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  started_at       TIMESTAMPTZ,
+  finished_at      TIMESTAMPTZ,
+  CONSTRAINT chk_import_jobs_kind   CHECK (kind IN ('work_orders', 'assets', 'locations')),
+  CONSTRAINT chk_import_jobs_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED')),
+  CONSTRAINT chk_import_jobs_rows   CHECK (total_rows >= 0 AND processed_rows >= 0 AND created_rows >= 0 AND error_count >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_org_created ON import_jobs (organisation_id, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_work_order_org_due_date;
+DROP INDEX IF EXISTS idx_work_order_org_est_start;
+DROP TABLE IF EXISTS calendar_feed_tokens;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- Schedule view and per-user iCalendar feeds
+-- Notes:
+--   - A scheduled work order runs from estimated_start_date for
+--     estimated_duration hours (1 hour when the duration is 0). Work orders
+--     with only a due_date show as all-day events on that date.
+--   - calendar_feed_tokens stores the SHA-256 of each feed token, never the
+--     token. One feed per user and organisation; issuing a new token
+--     replaces the old one. The feed stops working once the user leaves the
+--     organisation.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS calendar_feed_tokens (
+  token_hash       TEXT PRIMARY KEY,
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id          UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at     TIMESTAMPTZ,
+  CONSTRAINT uq_calendar_feed_tokens_org_user UNIQUE (organisation_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_org_est_start
+  ON work_order (organisation_id, estimated_start_date)
+  WHERE estimated_start_date IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_work_order_org_due_date
+  ON work_order (organisation_id, due_date)
+  WHERE due_date IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS user_leave;
+DROP TABLE IF EXISTS user_working_hours;
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- Technician capacity: working hours and leave
+-- Notes:
+--   - user_working_hours holds one shift per weekday (0 = Sunday, as
+--     EXTRACT(DOW)) in the user's time_zone, an IANA name. All rows of a
+--     user share the same zone; the API replaces the whole week at once.
+--     Users without rows have no working-hours limits.
+--   - user_leave blocks a user for [starts_at, ends_at); rescheduling a work
+--     order onto someone on leave is refused unless forced.
+--   - Shifts end on the day they start (end_time > start_time).
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_working_hours (
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id          UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  weekday          SMALLINT NOT NULL,
+  start_time       TIME NOT NULL,
+  end_time         TIME NOT NULL,
+  time_zone        TEXT NOT NULL DEFAULT 'UTC',
+  PRIMARY KEY (organisation_id, user_id, weekday),
+  CONSTRAINT chk_user_working_hours_weekday CHECK (weekday BETWEEN 0 AND 6),
+  CONSTRAINT chk_user_working_hours_range   CHECK (end_time > start_time)
+);
+
+CREATE TABLE IF NOT EXISTS user_leave (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  user_id          UUID NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  starts_at        TIMESTAMPTZ NOT NULL,
+  ends_at          TIMESTAMPTZ NOT NULL,
+  reason           TEXT,
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_user_leave_range CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_leave_org_user_start
+  ON user_leave (organisation_id, user_id, starts_at);
+
+COMMIT;
@@ -0,0 +1,363 @@
+-- Configurable work order custom ID format
+-- Notes:
+--   - work_order_id_formats holds one format per organisation; organisations
+--     without a row keep the old WO-YYYY-NNNN format. A generated ID is
+--     prefix || date part || separator || sequence, where the separator only
+--     follows a date part and the sequence is zero-padded to `padding` digits
+--     but never truncated (WO-2026-10000 follows WO-2026-9999).
+--   - work_order_counters gains a month column; the counter key is
+--     (organisation_id, year, month) with year = 0 for reset_period NEVER
+--     and month = 0 unless MONTHLY. Existing rows are the yearly counters.
+--   - The checks tie the reset period to the date part (MONTHLY needs a
+--     month, YEARLY a year), so one format never repeats an ID. When the
+--     format changes and a generated ID is already taken,
+--     realign_work_order_counter() moves the counter past the highest
+--     existing ID with the same stem; uq_work_order_org_custom_id still
+--     guards uniqueness.
+--   - create_work_order_from_json() is the 015 version with the generator
+--     swapped for next_work_order_custom_id().
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS work_order_id_formats (
+  organisation_id  UUID PRIMARY KEY REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  prefix           TEXT NOT NULL DEFAULT 'WO-',
+  date_part        TEXT NOT NULL DEFAULT 'YYYY',
+  separator        TEXT NOT NULL DEFAULT '-',
+  padding          INTEGER NOT NULL DEFAULT 4,
+  reset_period     TEXT NOT NULL DEFAULT 'YEARLY',
+  updated_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_work_order_id_formats_date_part CHECK (date_part IN ('NONE', 'YYYY', 'YY', 'YYYYMM', 'YYMM')),
+  CONSTRAINT chk_work_order_id_formats_reset     CHECK (reset_period IN ('YEARLY', 'MONTHLY', 'NEVER')),
+  CONSTRAINT chk_work_order_id_formats_padding   CHECK (padding BETWEEN 1 AND 12),
+  CONSTRAINT chk_work_order_id_formats_prefix    CHECK (length(prefix) <= 16),
+  CONSTRAINT chk_work_order_id_formats_separator CHECK (length(separator) <= 3),
+  CONSTRAINT chk_work_order_id_formats_monthly   CHECK (reset_period <> 'MONTHLY' OR date_part IN ('YYYYMM', 'YYMM')),
+  CONSTRAINT chk_work_order_id_formats_yearly    CHECK (reset_period <> 'YEARLY' OR date_part <> 'NONE')
+);
+
+ALTER TABLE work_order_counters ADD COLUMN IF NOT EXISTS month SMALLINT NOT NULL DEFAULT 0;
+ALTER TABLE work_order_counters DROP CONSTRAINT IF EXISTS work_order_counters_pkey;
+ALTER TABLE work_order_counters ADD CONSTRAINT work_order_counters_pkey PRIMARY KEY (organisation_id, year, month);
+
+-- The org's format applied to p_at: the ID stem (everything before the
+-- sequence), the counter key and the padding.
+CREATE OR REPLACE FUNCTION public.work_order_id_period(
+  p_org_id  UUID,
+  p_at      DATE,
+  OUT stem    TEXT,
+  OUT year    INTEGER,
+  OUT month   SMALLINT,
+  OUT padding INTEGER
+)
+LANGUAGE plpgsql STABLE
+AS $$
+DECLARE
+  v_fmt work_order_id_formats%ROWTYPE;
+BEGIN
+  SELECT * INTO v_fmt FROM work_order_id_formats WHERE organisation_id = p_org_id;
+  IF NOT FOUND THEN
+    v_fmt.prefix       := 'WO-';
+    v_fmt.date_part    := 'YYYY';
+    v_fmt.separator    := '-';
+    v_fmt.padding      := 4;
+    v_fmt.reset_period := 'YEARLY';
+  END IF;
+
+  stem := v_fmt.prefix || CASE v_fmt.date_part
+            WHEN 'YYYY'   THEN to_char(p_at, 'YYYY')   || v_fmt.separator
+            WHEN 'YY'     THEN to_char(p_at, 'YY')     || v_fmt.separator
+            WHEN 'YYYYMM' THEN to_char(p_at, 'YYYYMM') || v_fmt.separator
+            WHEN 'YYMM'   THEN to_char(p_at, 'YYMM')   || v_fmt.separator
+            ELSE ''
+          END;
+  year    := CASE WHEN v_fmt.reset_period = 'NEVER' THEN 0 ELSE EXTRACT(YEAR FROM p_at)::int END;
+  month   := CASE WHEN v_fmt.reset_period = 'MONTHLY' THEN EXTRACT(MONTH FROM p_at)::smallint ELSE 0 END;
+  padding := v_fmt.padding;
+END;
+$$;
+
+-- Zero-pads p_seq to p_padding digits without ever truncating it.
+CREATE OR REPLACE FUNCTION public.format_work_order_custom_id(p_stem TEXT, p_seq BIGINT, p_padding INTEGER)
+RETURNS TEXT
+LANGUAGE sql IMMUTABLE
+AS $$
+  SELECT p_stem || CASE WHEN length(p_seq::text) >= p_padding
+                        THEN p_seq::text
+                        ELSE lpad(p_seq::text, p_padding, '0')
+                   END
+$$;
+
+-- Bumps the org's counter for today and returns the ID it yields.
+CREATE OR REPLACE FUNCTION public.next_work_order_custom_id(p_org_id UUID)
+RETURNS TEXT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_p   RECORD;
+  v_seq INTEGER;
+BEGIN
+  SELECT * INTO v_p FROM public.work_order_id_period(p_org_id, current_date);
+
+  INSERT INTO work_order_counters (organisation_id, year, month, next_seq)
+  VALUES (p_org_id, v_p.year, v_p.month, 2)  -- first WO => seq=1 (next_seq becomes 2)
+  ON CONFLICT (organisation_id, year, month)
+  DO UPDATE SET next_seq = work_order_counters.next_seq + 1
+  RETURNING next_seq - 1 INTO v_seq;
+
+  RETURN public.format_work_order_custom_id(v_p.stem, v_seq, v_p.padding);
+END;
+$$;
+
+-- Moves today's counter past the highest existing ID sharing today's stem.
+CREATE OR REPLACE FUNCTION public.realign_work_order_counter(p_org_id UUID)
+RETURNS VOID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_p   RECORD;
+  v_max BIGINT;
+BEGIN
+  SELECT * INTO v_p FROM public.work_order_id_period(p_org_id, current_date);
+
+  SELECT MAX(substr(w.custom_id, length(v_p.stem) + 1)::bigint) INTO v_max
+  FROM work_order w
+  WHERE w.organisation_id = p_org_id
+    AND left(w.custom_id, length(v_p.stem)) = v_p.stem
+    AND substr(w.custom_id, length(v_p.stem) + 1) ~ '^[0-9]{1,9}$';
+
+  IF v_max IS NOT NULL THEN
+    INSERT INTO work_order_counters (organisation_id, year, month, next_seq)
+    VALUES (p_org_id, v_p.year, v_p.month, v_max + 1)
+    ON CONFLICT (organisation_id, year, month)
+    DO UPDATE SET next_seq = GREATEST(work_order_counters.next_seq, EXCLUDED.next_seq);
+  END IF;
+END;
+$$;
+
+-- Same as 015, with next_work_order_custom_id() generating the ID
+CREATE OR REPLACE FUNCTION public.create_work_order_from_json(
+  org_id     UUID,
+  created_by UUID,
+  payload    JSONB
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_id UUID;
+
+  -- core fields
+  v_title       TEXT;
+  v_priority    TEXT;
+  v_description TEXT;
+
+  -- dates
+  v_due_text       TEXT;
+  v_est_start_text TEXT;
+  v_due_date       TIMESTAMPTZ;
+  v_est_start      TIMESTAMPTZ;
+
+  -- numerics / booleans
+  v_est_duration       DOUBLE PRECISION;
+  v_required_signature BOOLEAN;
+
+  -- fks
+  v_primary_user UUID;
+  v_location     UUID;
+  v_asset        UUID;
+  v_team         UUID;
+  v_category     UUID;
+  v_cat          work_order_categories%ROWTYPE;
+
+  -- arrays
+  v_assigned  JSONB;
+  v_customers JSONB;
+  v_tasks     JSONB;
+  v_task_ids  UUID[];
+  v_known     INTEGER;
+
+  -- custom id bits
+  v_custom_id TEXT;
+  v_try       INTEGER := 0;
+BEGIN
+  -- Required: title
+  v_title := NULLIF(btrim(COALESCE(payload->>'title', payload->>'Title')), '');
+  IF v_title IS NULL THEN
+    RAISE EXCEPTION 'title is required';
+  END IF;
+
+  -- Category (must belong to the org); its defaults fill omitted fields
+  v_category := NULLIF(COALESCE(payload->>'category', payload->>'category_id', payload->>'categoryId'), '')::uuid;
+  IF v_category IS NOT NULL THEN
+    SELECT * INTO v_cat
+    FROM work_order_categories
+    WHERE id = v_category AND organisation_id = org_id;
+
+    IF NOT FOUND THEN
+      RAISE EXCEPTION 'category % not found for organisation %', v_category, org_id
+        USING ERRCODE = 'CM422';
+    END IF;
+  END IF;
+
+  -- Explicit task list (task_base ids, in order) replaces the category template
+  v_tasks := payload->'tasks';
+  IF v_tasks IS NOT NULL AND jsonb_typeof(v_tasks) = 'array' THEN
+    SELECT COALESCE(array_agg(val::uuid ORDER BY ord), '{}')
+    INTO v_task_ids
+    FROM jsonb_array_elements_text(v_tasks) WITH ORDINALITY AS t(val, ord)
+    WHERE NULLIF(val, '') IS NOT NULL;
+
+    SELECT COUNT(DISTINCT tb.id) INTO v_known
+    FROM task_bases tb
+    WHERE tb.id = ANY (v_task_ids)
+      AND (tb.organisation_id = org_id OR tb.organisation_id IS NULL);
+
+    IF v_known <> (SELECT COUNT(DISTINCT x) FROM unnest(v_task_ids) AS x) THEN
+      RAISE EXCEPTION 'unknown task base in tasks for organisation %', org_id
+        USING ERRCODE = 'CM424';
+    END IF;
+  END IF;
+
+  -- Priority (category default, else NONE)
+  v_priority := COALESCE(NULLIF(upper(COALESCE(payload->>'priority', payload->>'Priority')), ''),
+                         v_cat.default_priority, 'NONE');
+
+  -- Description
+  v_description := NULLIF(COALESCE(payload->>'description', payload->>'Description'), '');
+
+  -- Dates (accept YYYY-MM-DD or full timestamptz; camel/snake)
+  v_due_text       := COALESCE(payload->>'dueDate', payload->>'due_date');
+  v_est_start_text := COALESCE(payload->>'estimatedStartDate', payload->>'estimated_start_date');
+
+  IF v_due_text IS NOT NULL THEN
+    v_due_date := CASE WHEN v_due_text ~ '^\d{4}-\d{2}-\d{2}$'
+                       THEN (v_due_text::date)::timestamptz
+                       ELSE v_due_text::timestamptz
+                  END;
+  END IF;
+
+  IF v_est_start_text IS NOT NULL THEN
+    v_est_start := CASE WHEN v_est_start_text ~ '^\d{4}-\d{2}-\d{2}$'
+                        THEN (v_est_start_text::date)::timestamptz
+                        ELSE v_est_start_text::timestamptz
+                   END;
+  END IF;
+
+  -- Numerics / booleans
+  v_est_duration       := COALESCE((payload->>'estimatedDuration')::double precision,
+                                   (payload->>'estimated_duration')::double precision,
+                                   v_cat.default_estimated_duration, 0);
+  v_required_signature := COALESCE((payload->>'requiredSignature')::boolean,
+                                   (payload->>'required_signature')::boolean, false);
+
+  -- Foreign keys (accept camel/snake)
+  v_primary_user := NULLIF(
+    COALESCE(
+      payload->>'primary_user',
+      payload->>'primaryUser',
+      payload->>'primary_worker',
+      payload->>'primaryWorker'
+    ),
+    ''
+  )::uuid;
+  v_location     := NULLIF(COALESCE(payload->>'location', payload->>'location_id'), '')::uuid;
+  v_asset        := NULLIF(COALESCE(payload->>'asset', payload->>'asset_id'), '')::uuid;
+
+  -- Team: an explicit key (even null) wins over the category default
+  IF (payload ? 'team') OR (payload ? 'team_id') THEN
+    v_team := NULLIF(COALESCE(payload->>'team', payload->>'team_id'), '')::uuid;
+  ELSE
+    v_team := v_cat.default_team_id;
+  END IF;
+
+  -- Provided custom_id?
+  v_custom_id := COALESCE(payload->>'custom_id', payload->>'customId');
+
+  IF v_custom_id IS NOT NULL AND v_custom_id <> '' THEN
+    -- Single attempt; if duplicate, raise (client supplied it)
+    INSERT INTO work_order (
+      organisation_id, created_by_id, title, description, priority,
+      estimated_duration, estimated_start_date, due_date, required_signature,
+      primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+    )
+    VALUES (
+      org_id, created_by, v_title, v_description, v_priority,
+      v_est_duration, v_est_start, v_due_date, v_required_signature,
+      v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+    )
+    RETURNING id INTO v_id;
+
+  ELSE
+    -- Auto-generate with retry on unique_violation (race-safe)
+    LOOP
+      v_try := v_try + 1;
+
+      -- Atomically fetch & bump the counter in the org's ID format
+      v_custom_id := public.next_work_order_custom_id(org_id);
+
+      BEGIN
+        INSERT INTO work_order (
+          organisation_id, created_by_id, title, description, priority,
+          estimated_duration, estimated_start_date, due_date, required_signature,
+          primary_user_id, location_id, asset_id, team_id, category_id, status, custom_id
+        )
+        VALUES (
+          org_id, created_by, v_title, v_description, v_priority,
+          v_est_duration, v_est_start, v_due_date, v_required_signature,
+          v_primary_user, v_location, v_asset, v_team, v_category, 'OPEN', v_custom_id
+        )
+        RETURNING id INTO v_id;
+
+        EXIT; -- success
+      EXCEPTION WHEN unique_violation THEN
+        -- someone used this custom_id concurrently OR counter not yet aligned
+        IF v_try >= 10 THEN
+          RAISE EXCEPTION 'could not generate unique custom_id after % attempts for org %', v_try, org_id;
+        END IF;
+        -- move the counter past existing IDs with this stem, then retry
+        PERFORM public.realign_work_order_counter(org_id);
+      END;
+    END LOOP;
+  END IF;
+
+  -- Arrays (after successful insert)
+  v_assigned  := COALESCE(payload->'assigned_to', payload->'assignedTo');
+  v_customers := COALESCE(payload->'customers',   payload->'customer_ids');
+
+  IF v_assigned IS NOT NULL AND jsonb_typeof(v_assigned) = 'array' THEN
+    INSERT INTO work_order_assigned_to (work_order_id, user_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_assigned) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  IF v_customers IS NOT NULL AND jsonb_typeof(v_customers) = 'array' THEN
+    INSERT INTO work_order_customers (work_order_id, customer_id)
+    SELECT v_id, val::uuid
+    FROM jsonb_array_elements_text(v_customers) AS t(val)
+    WHERE NULLIF(val, '') IS NOT NULL
+    ON CONFLICT DO NOTHING;
+  END IF;
+
+  -- Tasks: the explicit list, else the category task template
+  IF v_task_ids IS NOT NULL THEN
+    INSERT INTO tasks (organisation_id, created_by_id, task_base_id, work_order_id)
+    SELECT org_id, created_by, t.task_base_id, v_id
+    FROM unnest(v_task_ids) WITH ORDINALITY AS t(task_base_id, ord)
+    ORDER BY t.ord;
+  ELSIF v_category IS NOT NULL THEN
+    INSERT INTO tasks (organisation_id, created_by_id, task_base_id, work_order_id)
+    SELECT org_id, created_by, ct.task_base_id, v_id
+    FROM work_order_category_tasks ct
+    WHERE ct.category_id = v_category
+    ORDER BY ct.position, ct.task_base_id;
+  END IF;
+
+  RETURN v_id;
+END;
+$$;
+
+
+COMMIT;
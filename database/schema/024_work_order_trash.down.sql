@@ -0,0 +1,106 @@
+BEGIN;
+
+-- Restore the 011 wrapper and the 015 duplicate_work_order()
+CREATE OR REPLACE FUNCTION public.update_work_order_from_json_if_match(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_payload          JSONB,
+  p_updated_by       UUID DEFAULT NULL,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+BEGIN
+  SELECT version INTO v_version
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+     USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+     USING ERRCODE = 'CM412';
+  END IF;
+
+  PERFORM set_config('cmms.actor_id', COALESCE(p_updated_by::text, ''), true);
+
+  RETURN public.update_work_order_from_json(p_org_id, p_work_order_id, p_payload, p_updated_by);
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.duplicate_work_order(
+  p_org_id        UUID,
+  p_work_order_id UUID,
+  p_created_by    UUID,
+  p_overrides     JSONB DEFAULT '{}'::jsonb
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_src     work_order%ROWTYPE;
+  v_payload JSONB;
+  v_id      UUID;
+BEGIN
+  SELECT * INTO v_src
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  -- Every key is present so category defaults do not override the source
+  v_payload := jsonb_build_object(
+    'title',              v_src.title,
+    'description',        v_src.description,
+    'priority',           v_src.priority,
+    'estimatedDuration',  v_src.estimated_duration,
+    'estimatedStartDate', v_src.estimated_start_date,
+    'dueDate',            v_src.due_date,
+    'requiredSignature',  v_src.required_signature,
+    'primary_worker',     v_src.primary_user_id,
+    'location',           v_src.location_id,
+    'asset',              v_src.asset_id,
+    'team',               v_src.team_id,
+    'category',           v_src.category_id,
+    'assigned_to', (SELECT COALESCE(jsonb_agg(a.user_id), '[]'::jsonb)
+                    FROM work_order_assigned_to a WHERE a.work_order_id = v_src.id),
+    'customers',   (SELECT COALESCE(jsonb_agg(c.customer_id), '[]'::jsonb)
+                    FROM work_order_customers c WHERE c.work_order_id = v_src.id),
+    'tasks',       (SELECT COALESCE(jsonb_agg(t.task_base_id ORDER BY t.created_at, t.id), '[]'::jsonb)
+                    FROM tasks t WHERE t.work_order_id = v_src.id)
+  );
+  v_payload := v_payload || COALESCE(p_overrides, '{}'::jsonb);
+
+  v_id := create_work_order_from_json(p_org_id, p_created_by, v_payload);
+
+  -- Attachments are shared, not copied
+  INSERT INTO work_order_files (work_order_id, file_id)
+  SELECT v_id, f.file_id
+  FROM work_order_files f
+  WHERE f.work_order_id = v_src.id
+  ON CONFLICT DO NOTHING;
+
+  RETURN v_id;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS public.restore_work_order(uuid, uuid, uuid);
+DROP FUNCTION IF EXISTS public.soft_delete_work_order(uuid, uuid, uuid);
+
+-- Trashed rows would reappear as live work orders
+DELETE FROM work_order WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_work_order_org_deleted_at;
+ALTER TABLE work_order
+  DROP COLUMN IF EXISTS deleted_by_id,
+  DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
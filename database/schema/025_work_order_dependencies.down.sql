@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS public.work_order_rollup_json(uuid);
+DROP FUNCTION IF EXISTS public.work_order_children_json(uuid);
+DROP FUNCTION IF EXISTS public.set_work_order_parent(uuid, uuid, uuid, uuid, bigint);
+
+DROP TRIGGER IF EXISTS trg_work_order_enforce_dependencies ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_enforce_dependencies();
+DROP TRIGGER IF EXISTS trg_work_order_parent_check_cycle ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_parent_check_cycle();
+DROP TRIGGER IF EXISTS trg_work_order_dependencies_check_cycle ON work_order_dependencies;
+DROP FUNCTION IF EXISTS public.work_order_dependencies_check_cycle();
+
+DROP TABLE IF EXISTS work_order_dependencies;
+
+DROP INDEX IF EXISTS idx_work_order_parent;
+ALTER TABLE work_order DROP COLUMN IF EXISTS parent_work_order_id;
+
+COMMIT;
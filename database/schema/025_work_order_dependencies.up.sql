@@ -0,0 +1,278 @@
+-- Work order hierarchy and dependencies
+-- Notes:
+--   - work_order.parent_work_order_id splits a big job into child work
+--     orders (which can have children of their own). Setting it goes through
+--     set_work_order_parent(), so the change is logged with its actor.
+--   - work_order_dependencies(work_order_id, blocked_by_id): the work order
+--     cannot start (move to IN_PROGRESS, or to COMPLETE without having been
+--     in progress) while any blocker that is not trashed is neither COMPLETE
+--     nor CANCELLED. The check is a trigger so every path is covered;
+--     violations raise SQLSTATE 'CM423' (HTTP 409).
+--   - Both link kinds must stay acyclic. The triggers walk the existing
+--     links under a per-organisation advisory lock, so two concurrent
+--     inserts cannot close a loop together; cycles raise SQLSTATE 'CM409'.
+--   - work_order_children_json() and work_order_rollup_json() feed the tree
+--     and the completion roll-up in GetWorkOrderDetail. Trashed work orders
+--     (and anything below them) are left out.
+
+BEGIN;
+
+ALTER TABLE work_order
+  ADD COLUMN IF NOT EXISTS parent_work_order_id UUID REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_work_order_parent
+  ON work_order (parent_work_order_id)
+  WHERE parent_work_order_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS work_order_dependencies (
+  organisation_id  UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  work_order_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  blocked_by_id    UUID NOT NULL REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  created_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (work_order_id, blocked_by_id),
+  CONSTRAINT chk_work_order_dependencies_self CHECK (work_order_id <> blocked_by_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_dependencies_blocked_by
+  ON work_order_dependencies (blocked_by_id);
+
+-- ---------------------------------------------------------------------------
+-- Cycle detection
+-- ---------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION public.work_order_dependencies_check_cycle()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  PERFORM pg_advisory_xact_lock(hashtextextended('work_order_links:' || NEW.organisation_id::text, 0));
+
+  -- Would the blocker (transitively) wait for the work order it blocks?
+  IF EXISTS (
+    WITH RECURSIVE up AS (
+      SELECT d.blocked_by_id AS id
+      FROM work_order_dependencies d
+      WHERE d.work_order_id = NEW.blocked_by_id
+      UNION
+      SELECT d.blocked_by_id
+      FROM work_order_dependencies d
+      JOIN up ON d.work_order_id = up.id
+    )
+    SELECT 1 FROM up WHERE up.id = NEW.work_order_id
+  ) THEN
+    RAISE EXCEPTION 'work order % already waits for % through its dependencies', NEW.blocked_by_id, NEW.work_order_id
+      USING ERRCODE = 'CM409';
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_dependencies_check_cycle ON work_order_dependencies;
+CREATE TRIGGER trg_work_order_dependencies_check_cycle
+  BEFORE INSERT OR UPDATE ON work_order_dependencies
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_dependencies_check_cycle();
+
+CREATE OR REPLACE FUNCTION public.work_order_parent_check_cycle()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF NEW.parent_work_order_id IS NULL
+     OR NEW.parent_work_order_id IS NOT DISTINCT FROM OLD.parent_work_order_id THEN
+    RETURN NEW;
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended('work_order_links:' || NEW.organisation_id::text, 0));
+
+  IF NOT EXISTS (
+    SELECT 1 FROM work_order p
+    WHERE p.id = NEW.parent_work_order_id
+      AND p.organisation_id = NEW.organisation_id
+  ) THEN
+    RAISE EXCEPTION 'parent work order % not found for organisation %', NEW.parent_work_order_id, NEW.organisation_id
+      USING ERRCODE = 'foreign_key_violation';
+  END IF;
+
+  -- Is the work order the new parent itself or one of its ancestors?
+  IF EXISTS (
+    WITH RECURSIVE up AS (
+      SELECT NEW.parent_work_order_id AS id
+      UNION
+      SELECT w.parent_work_order_id
+      FROM work_order w
+      JOIN up ON w.id = up.id
+      WHERE w.parent_work_order_id IS NOT NULL
+    )
+    SELECT 1 FROM up WHERE up.id = NEW.id
+  ) THEN
+    RAISE EXCEPTION 'work order % cannot be placed under its own descendant %', NEW.id, NEW.parent_work_order_id
+      USING ERRCODE = 'CM409';
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_parent_check_cycle ON work_order;
+CREATE TRIGGER trg_work_order_parent_check_cycle
+  BEFORE UPDATE OF parent_work_order_id ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_parent_check_cycle();
+
+-- ---------------------------------------------------------------------------
+-- Blocked work orders cannot start
+-- ---------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION public.work_order_enforce_dependencies()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_blockers TEXT;
+BEGIN
+  IF NEW.status IN ('IN_PROGRESS', 'COMPLETE')
+     AND OLD.status NOT IN ('IN_PROGRESS', 'COMPLETE') THEN
+    SELECT string_agg(COALESCE(b.custom_id, b.id::text), ', ' ORDER BY b.custom_id, b.id)
+    INTO v_blockers
+    FROM work_order_dependencies d
+    JOIN work_order b ON b.id = d.blocked_by_id
+    WHERE d.work_order_id = NEW.id
+      AND b.deleted_at IS NULL
+      AND b.status NOT IN ('COMPLETE', 'CANCELLED');
+
+    IF v_blockers IS NOT NULL THEN
+      RAISE EXCEPTION 'work order % is blocked by %', NEW.id, v_blockers
+        USING ERRCODE = 'CM423';
+    END IF;
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_enforce_dependencies ON work_order;
+CREATE TRIGGER trg_work_order_enforce_dependencies
+  BEFORE UPDATE OF status ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_enforce_dependencies();
+
+-- ---------------------------------------------------------------------------
+-- Parent changes
+-- ---------------------------------------------------------------------------
+
+-- Raises no_data_found for unknown, foreign or trashed work orders and
+-- CM412 for a stale expected version, like
+-- update_work_order_from_json_if_match().
+CREATE OR REPLACE FUNCTION public.set_work_order_parent(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_parent_id        UUID,
+  p_updated_by       UUID,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS BIGINT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+  v_parent  UUID;
+BEGIN
+  SELECT version, parent_work_order_id INTO v_version, v_parent
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+    AND deleted_at IS NULL
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+      USING ERRCODE = 'CM412';
+  END IF;
+
+  IF p_parent_id IS NOT DISTINCT FROM v_parent THEN
+    RETURN v_version;
+  END IF;
+
+  PERFORM set_config('cmms.actor_id', COALESCE(p_updated_by::text, ''), true);
+
+  UPDATE work_order
+  SET parent_work_order_id = p_parent_id,
+      updated_at = now()
+  WHERE id = p_work_order_id
+  RETURNING version INTO v_version;
+
+  RETURN v_version;
+END;
+$$;
+
+-- ---------------------------------------------------------------------------
+-- Tree and roll-up for the detail view
+-- ---------------------------------------------------------------------------
+
+-- Children of a work order, each with its own children (plpgsql so the
+-- function can call itself)
+CREATE OR REPLACE FUNCTION public.work_order_children_json(p_work_order_id UUID)
+RETURNS JSONB
+LANGUAGE plpgsql
+STABLE
+AS $$
+BEGIN
+  RETURN (
+    SELECT COALESCE(
+      jsonb_agg(
+        jsonb_build_object(
+          'id',        c.id,
+          'custom_id', c.custom_id,
+          'title',     c.title,
+          'status',    c.status,
+          'due_date',  c.due_date,
+          'children',  public.work_order_children_json(c.id)
+        )
+        ORDER BY c.created_at, c.id
+      ),
+      '[]'::jsonb
+    )
+    FROM work_order c
+    WHERE c.parent_work_order_id = p_work_order_id
+      AND c.deleted_at IS NULL
+  );
+END;
+$$;
+
+-- Completion across all descendants; NULL without children. progress is the
+-- share of the non-cancelled descendants that are complete.
+CREATE OR REPLACE FUNCTION public.work_order_rollup_json(p_work_order_id UUID)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+  WITH RECURSIVE tree AS (
+    SELECT c.id, c.status, 1 AS depth
+    FROM work_order c
+    WHERE c.parent_work_order_id = p_work_order_id
+      AND c.deleted_at IS NULL
+    UNION ALL
+    SELECT c.id, c.status, t.depth + 1
+    FROM work_order c
+    JOIN tree t ON c.parent_work_order_id = t.id
+    WHERE c.deleted_at IS NULL
+  )
+  SELECT CASE WHEN COUNT(*) = 0 THEN NULL ELSE
+    jsonb_build_object(
+      'children',     COUNT(*) FILTER (WHERE depth = 1),
+      'descendants',  COUNT(*),
+      'complete',     COUNT(*) FILTER (WHERE status = 'COMPLETE'),
+      'cancelled',    COUNT(*) FILTER (WHERE status = 'CANCELLED'),
+      'remaining',    COUNT(*) FILTER (WHERE status NOT IN ('COMPLETE', 'CANCELLED')),
+      'progress',     COALESCE(ROUND(100.0 * COUNT(*) FILTER (WHERE status = 'COMPLETE')
+                                 / NULLIF(COUNT(*) FILTER (WHERE status <> 'CANCELLED'), 0), 1), 100),
+      'all_complete', COUNT(*) FILTER (WHERE status NOT IN ('COMPLETE', 'CANCELLED')) = 0
+    )
+  END
+  FROM tree;
+$$;
+
+COMMIT;
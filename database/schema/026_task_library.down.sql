@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_work_order_position;
+DROP TRIGGER IF EXISTS trg_tasks_assign_position ON tasks;
+DROP FUNCTION IF EXISTS public.tasks_assign_position();
+ALTER TABLE tasks DROP COLUMN IF EXISTS position;
+
+DROP INDEX IF EXISTS idx_task_options_base_position;
+ALTER TABLE task_options DROP COLUMN IF EXISTS position;
+
+ALTER TABLE task_bases DROP COLUMN IF EXISTS archived;
+
+COMMIT;
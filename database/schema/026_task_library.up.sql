@@ -0,0 +1,76 @@
+-- Task library and task editing
+-- Notes:
+--   - task_bases become an organisation library: archived hides a base from
+--     pickers without breaking the tasks, categories and templates that use
+--     it (tasks keep blocking hard deletes, see 005).
+--   - task_options.position orders the choices of a base.
+--   - tasks.position orders the checklist of a work order. Every insert
+--     path (create_work_order_from_json, duplicate_work_order, templates,
+--     the tasks API) goes through trg_tasks_assign_position, which appends
+--     the task when no position is given. duplicate_work_order() still
+--     listed the source's tasks in creation order; 036 makes it follow
+--     position.
+
+BEGIN;
+
+ALTER TABLE task_bases
+  ADD COLUMN IF NOT EXISTS archived BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE task_options
+  ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+UPDATE task_options o
+SET position = x.rn
+FROM (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY task_base_id ORDER BY created_at, id) AS rn
+  FROM task_options
+) x
+WHERE x.id = o.id;
+
+CREATE INDEX IF NOT EXISTS idx_task_options_base_position
+  ON task_options (task_base_id, position);
+
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS position INT;
+
+UPDATE tasks t
+SET position = x.rn
+FROM (
+  SELECT id, ROW_NUMBER() OVER (PARTITION BY work_order_id, preventive_maintenance_id ORDER BY created_at, id) AS rn
+  FROM tasks
+) x
+WHERE x.id = t.id;
+
+-- Appends new tasks to their work order (or preventive maintenance). Rows
+-- inserted earlier by the same statement are visible here, so multi-row
+-- inserts keep their order.
+CREATE OR REPLACE FUNCTION public.tasks_assign_position()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF NEW.position IS NULL THEN
+    SELECT COALESCE(MAX(t.position), 0) + 1
+    INTO NEW.position
+    FROM tasks t
+    WHERE (NEW.work_order_id IS NOT NULL AND t.work_order_id = NEW.work_order_id)
+       OR (NEW.work_order_id IS NULL AND t.work_order_id IS NULL
+           AND t.preventive_maintenance_id IS NOT DISTINCT FROM NEW.preventive_maintenance_id);
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_tasks_assign_position ON tasks;
+CREATE TRIGGER trg_tasks_assign_position
+  BEFORE INSERT ON tasks
+  FOR EACH ROW
+  EXECUTE FUNCTION public.tasks_assign_position();
+
+ALTER TABLE tasks
+  ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_work_order_position
+  ON tasks (work_order_id, position);
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS public.task_is_complete(text, text);
+
+ALTER TABLE meters
+  DROP COLUMN IF EXISTS last_task_id,
+  DROP COLUMN IF EXISTS last_read_by_id,
+  DROP COLUMN IF EXISTS last_read_at,
+  DROP COLUMN IF EXISTS last_value,
+  DROP COLUMN IF EXISTS unit;
+
+DROP INDEX IF EXISTS idx_tasks_task_option;
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS out_of_tolerance,
+  DROP COLUMN IF EXISTS task_option_id,
+  DROP COLUMN IF EXISTS numeric_value;
+
+-- Mapped task types and removed inspection choices are not restored.
+ALTER TABLE task_bases DROP CONSTRAINT IF EXISTS chk_task_bases_tolerance;
+ALTER TABLE task_bases DROP CONSTRAINT IF EXISTS chk_task_bases_task_type;
+ALTER TABLE task_bases
+  DROP COLUMN IF EXISTS max_value,
+  DROP COLUMN IF EXISTS min_value,
+  DROP COLUMN IF EXISTS unit;
+
+COMMIT;
@@ -0,0 +1,98 @@
+-- Typed task values
+-- Notes:
+--   - task_bases.task_type is now one of SUBTASK, NUMBER, TEXT, INSPECTION,
+--     MULTIPLE_CHOICE, METER. The demo seed used CHECK / SELECT / MEASURE;
+--     those are mapped below (PASS/FLAG/FAIL choices become an INSPECTION,
+--     other choices a MULTIPLE_CHOICE, readings a NUMBER or a METER when a
+--     meter is linked) before the CHECK constraint goes on.
+--   - NUMBER and METER bases carry a unit and an optional min/max tolerance.
+--     The API validates values against the type; tasks.numeric_value keeps
+--     the parsed reading and tasks.out_of_tolerance flags readings outside
+--     min/max at the time they were taken.
+--   - tasks.task_option_id binds a MULTIPLE_CHOICE answer to its option
+--     (SET NULL if the option is later removed; value keeps the label).
+--   - meters gets its last reading. A METER task writes it, with who took
+--     it and from which task.
+--   - task_is_complete() replaces the UPPER(value) IN ('COMPLETE','PASS')
+--     test: a SUBTASK is done when COMPLETE, every other type once it has
+--     a value.
+
+BEGIN;
+
+ALTER TABLE task_bases
+  ADD COLUMN IF NOT EXISTS unit      TEXT,
+  ADD COLUMN IF NOT EXISTS min_value NUMERIC,
+  ADD COLUMN IF NOT EXISTS max_value NUMERIC;
+
+UPDATE task_bases tb
+SET task_type = CASE
+  WHEN tb.task_type = 'MEASURE' AND tb.meter_id IS NOT NULL THEN 'METER'
+  WHEN tb.task_type = 'MEASURE' THEN 'NUMBER'
+  WHEN NOT EXISTS (SELECT 1 FROM task_options o WHERE o.task_base_id = tb.id) THEN 'SUBTASK'
+  WHEN NOT EXISTS (SELECT 1 FROM task_options o
+                   WHERE o.task_base_id = tb.id
+                     AND UPPER(COALESCE(o.label, '')) NOT IN ('PASS', 'FLAG', 'FAIL')) THEN 'INSPECTION'
+  ELSE 'MULTIPLE_CHOICE'
+END
+WHERE tb.task_type NOT IN ('SUBTASK', 'NUMBER', 'TEXT', 'INSPECTION', 'MULTIPLE_CHOICE', 'METER');
+
+-- Inspections have fixed results; their old choices are redundant.
+DELETE FROM task_options o
+USING task_bases tb
+WHERE tb.id = o.task_base_id
+  AND tb.task_type = 'INSPECTION';
+
+ALTER TABLE task_bases
+  DROP CONSTRAINT IF EXISTS chk_task_bases_task_type;
+ALTER TABLE task_bases
+  ADD CONSTRAINT chk_task_bases_task_type
+  CHECK (task_type IN ('SUBTASK', 'NUMBER', 'TEXT', 'INSPECTION', 'MULTIPLE_CHOICE', 'METER'));
+
+ALTER TABLE task_bases
+  DROP CONSTRAINT IF EXISTS chk_task_bases_tolerance;
+ALTER TABLE task_bases
+  ADD CONSTRAINT chk_task_bases_tolerance
+  CHECK (min_value IS NULL OR max_value IS NULL OR min_value <= max_value);
+
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS numeric_value    NUMERIC,
+  ADD COLUMN IF NOT EXISTS task_option_id   UUID REFERENCES task_options(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS out_of_tolerance BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_task_option ON tasks (task_option_id);
+
+-- Backfill readings ('72 °C' -> 72) and choices from the free-text values.
+UPDATE tasks t
+SET numeric_value = substring(t.value FROM '^\s*(-?[0-9]+(?:\.[0-9]+)?)')::numeric
+FROM task_bases tb
+WHERE tb.id = t.task_base_id
+  AND tb.task_type IN ('NUMBER', 'METER')
+  AND t.value ~ '^\s*-?[0-9]+(\.[0-9]+)?';
+
+UPDATE tasks t
+SET task_option_id = o.id
+FROM task_bases tb
+JOIN task_options o ON o.task_base_id = tb.id
+WHERE tb.id = t.task_base_id
+  AND tb.task_type = 'MULTIPLE_CHOICE'
+  AND lower(o.label) = lower(t.value);
+
+ALTER TABLE meters
+  ADD COLUMN IF NOT EXISTS unit            TEXT,
+  ADD COLUMN IF NOT EXISTS last_value      NUMERIC,
+  ADD COLUMN IF NOT EXISTS last_read_at    TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS last_read_by_id UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS last_task_id    UUID REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION public.task_is_complete(p_task_type text, p_value text)
+RETURNS boolean
+LANGUAGE sql
+IMMUTABLE
+AS $$
+  SELECT CASE
+    WHEN p_task_type = 'SUBTASK' THEN UPPER(COALESCE(p_value, '')) IN ('COMPLETE', 'PASS')
+    ELSE NULLIF(btrim(COALESCE(p_value, '')), '') IS NOT NULL
+  END;
+$$;
+
+COMMIT;
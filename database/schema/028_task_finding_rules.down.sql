@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS public.create_corrective_work_order(uuid, uuid, uuid);
+
+DROP TABLE IF EXISTS work_order_findings;
+DROP TABLE IF EXISTS task_finding_rules;
+
+COMMIT;
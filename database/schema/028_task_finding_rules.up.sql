@@ -0,0 +1,140 @@
+-- Corrective work orders from inspection findings
+-- Notes:
+--   - task_finding_rules(organisation_id, task_base_id): what an
+--     organisation does when a task of that base finds a problem. Rules are
+--     per organisation so global task bases can have one too. on_fail and
+--     on_flag apply to INSPECTION results, on_out_of_tolerance to NUMBER and
+--     METER readings outside min/max.
+--   - create_corrective_work_order() is called by the API after a task value
+--     is written. When a rule matches it creates an OPEN work order through
+--     create_work_order_from_json() (so custom IDs, category defaults and
+--     task templates apply), inheriting the source's asset and location.
+--     The rule can set the priority (default: the source's), category (whose
+--     task template then applies), team and a due date in days.
+--   - work_order_findings links the corrective work order back to the
+--     source work order and task, with the finding text. One per source
+--     task: failing it again does not spawn a second work order.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS task_finding_rules (
+  organisation_id      UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  task_base_id         UUID NOT NULL REFERENCES task_bases(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  on_fail              BOOLEAN NOT NULL DEFAULT true,
+  on_flag              BOOLEAN NOT NULL DEFAULT false,
+  on_out_of_tolerance  BOOLEAN NOT NULL DEFAULT true,
+  priority             TEXT,
+  category_id          UUID REFERENCES work_order_categories(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  team_id              UUID REFERENCES teams(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  due_in_days          INT CHECK (due_in_days IS NULL OR due_in_days BETWEEN 0 AND 365),
+  created_by_id        UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at           TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (organisation_id, task_base_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_finding_rules_task_base ON task_finding_rules (task_base_id);
+
+CREATE TABLE IF NOT EXISTS work_order_findings (
+  work_order_id         UUID PRIMARY KEY REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  organisation_id       UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  source_work_order_id  UUID REFERENCES work_order(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  source_task_id        UUID UNIQUE REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  trigger               TEXT NOT NULL CHECK (trigger IN ('FAIL', 'FLAG', 'OUT_OF_TOLERANCE')),
+  finding               TEXT NOT NULL,
+  created_at            TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_work_order_findings_source ON work_order_findings (source_work_order_id);
+
+-- Returns the new work order, or NULL when no rule matches the task's
+-- current value (or the task already has a corrective work order).
+CREATE OR REPLACE FUNCTION public.create_corrective_work_order(
+  p_org_id     UUID,
+  p_created_by UUID,
+  p_task_id    UUID
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_task    tasks%ROWTYPE;
+  v_base    task_bases%ROWTYPE;
+  v_src     work_order%ROWTYPE;
+  v_rule    task_finding_rules%ROWTYPE;
+  v_trigger TEXT;
+  v_reading TEXT;
+  v_finding TEXT;
+  v_payload JSONB;
+  v_id      UUID;
+BEGIN
+  SELECT * INTO v_task FROM tasks WHERE id = p_task_id AND organisation_id = p_org_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_src FROM work_order WHERE id = v_task.work_order_id AND deleted_at IS NULL;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_rule
+  FROM task_finding_rules
+  WHERE organisation_id = p_org_id AND task_base_id = v_task.task_base_id;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM work_order_findings WHERE source_task_id = p_task_id) THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_base FROM task_bases WHERE id = v_task.task_base_id;
+
+  v_trigger := CASE
+    WHEN v_base.task_type = 'INSPECTION' AND UPPER(v_task.value) = 'FAIL' AND v_rule.on_fail THEN 'FAIL'
+    WHEN v_base.task_type = 'INSPECTION' AND UPPER(v_task.value) = 'FLAG' AND v_rule.on_flag THEN 'FLAG'
+    WHEN v_base.task_type IN ('NUMBER', 'METER') AND v_task.out_of_tolerance AND v_rule.on_out_of_tolerance THEN 'OUT_OF_TOLERANCE'
+  END;
+  IF v_trigger IS NULL THEN
+    RETURN NULL;
+  END IF;
+
+  IF v_trigger = 'OUT_OF_TOLERANCE' THEN
+    v_reading := concat_ws(' ', v_task.value, v_base.unit)
+      || format(' (expected %s to %s)',
+                COALESCE(v_base.min_value::text, '-'),
+                COALESCE(v_base.max_value::text, '-'));
+  ELSE
+    v_reading := UPPER(v_task.value);
+  END IF;
+
+  v_finding := format('%s on %s: %s', v_base.label, v_src.custom_id, v_reading);
+  IF NULLIF(btrim(COALESCE(v_task.notes, '')), '') IS NOT NULL THEN
+    v_finding := v_finding || E'\n\n' || btrim(v_task.notes);
+  END IF;
+
+  v_payload := jsonb_build_object(
+    'title',       'Corrective: ' || v_base.label,
+    'description', v_finding,
+    'priority',    COALESCE(v_rule.priority, v_src.priority),
+    'asset',       COALESCE(v_src.asset_id, v_base.asset_id),
+    'location',    v_src.location_id,
+    'category',    v_rule.category_id
+  );
+  IF v_rule.team_id IS NOT NULL THEN
+    v_payload := v_payload || jsonb_build_object('team', v_rule.team_id);
+  END IF;
+  IF v_rule.due_in_days IS NOT NULL THEN
+    v_payload := v_payload || jsonb_build_object('dueDate', now() + make_interval(days => v_rule.due_in_days));
+  END IF;
+
+  v_id := create_work_order_from_json(p_org_id, p_created_by, v_payload);
+
+  INSERT INTO work_order_findings (work_order_id, organisation_id, source_work_order_id, source_task_id, trigger, finding)
+  VALUES (v_id, p_org_id, v_src.id, p_task_id, v_trigger, v_finding);
+
+  RETURN v_id;
+END;
+$$;
+
+COMMIT;
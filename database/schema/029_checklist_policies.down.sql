@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_work_order_require_checklist ON work_order;
+DROP FUNCTION IF EXISTS public.work_order_require_checklist();
+DROP FUNCTION IF EXISTS public.work_order_checklist_blockers(uuid);
+
+DROP TABLE IF EXISTS checklist_policies;
+
+ALTER TABLE task_bases DROP COLUMN IF EXISTS optional;
+
+COMMIT;
@@ -0,0 +1,113 @@
+-- Checklist-gated completion
+-- Notes:
+--   - checklist_policies decide whether a work order's tasks must be done
+--     before it can move to COMPLETE. A policy is keyed by category; NULL is
+--     the organisation default, and a category's own policy wins over it.
+--     Without a policy completion is not gated (the previous behaviour).
+--   - require_tasks_complete: every task whose base is not optional must be
+--     complete (task_is_complete()).
+--   - require_failure_notes: every failed item (an INSPECTION marked FAIL, or
+--     a NUMBER/METER reading out of tolerance) must have notes, optional
+--     tasks included.
+--   - work_order_checklist_blockers() lists the tasks in the way with the
+--     reason (INCOMPLETE or NOTES_REQUIRED). The check is a trigger so every
+--     path is covered; violations raise SQLSTATE 'CM425' (HTTP 409) and the
+--     API answers with the blockers.
+
+BEGIN;
+
+ALTER TABLE task_bases
+  ADD COLUMN IF NOT EXISTS optional BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE IF NOT EXISTS checklist_policies (
+  id                      UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id         UUID NOT NULL REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  category_id             UUID REFERENCES work_order_categories(id) ON UPDATE CASCADE ON DELETE CASCADE,  -- NULL: org default
+  require_tasks_complete  BOOLEAN NOT NULL DEFAULT true,
+  require_failure_notes   BOOLEAN NOT NULL DEFAULT true,
+  created_by_id           UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  created_at              TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at              TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT chk_checklist_policies_rules
+    CHECK (require_tasks_complete OR require_failure_notes)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_checklist_policies_scope
+  ON checklist_policies (
+    organisation_id,
+    COALESCE(category_id, '00000000-0000-0000-0000-000000000000'::uuid)
+  );
+
+-- ---------------------------------------------------------------------------
+-- Blocking tasks
+-- ---------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION public.work_order_checklist_blockers(p_work_order_id UUID)
+RETURNS TABLE (task_id UUID, label TEXT, task_type TEXT, reason TEXT)
+LANGUAGE sql
+STABLE
+AS $$
+  WITH pol AS (
+    SELECT cp.require_tasks_complete, cp.require_failure_notes
+    FROM work_order wo
+    JOIN checklist_policies cp
+      ON cp.organisation_id = wo.organisation_id
+     AND (cp.category_id = wo.category_id OR cp.category_id IS NULL)
+    WHERE wo.id = p_work_order_id
+    ORDER BY (cp.category_id IS NOT NULL) DESC
+    LIMIT 1
+  ),
+  checked AS (
+    SELECT
+      t.id,
+      tb.label,
+      tb.task_type,
+      t.position,
+      t.created_at,
+      pol.require_tasks_complete
+        AND NOT tb.optional
+        AND NOT task_is_complete(tb.task_type, t.value) AS incomplete,
+      pol.require_failure_notes
+        AND ((tb.task_type = 'INSPECTION' AND UPPER(COALESCE(t.value, '')) = 'FAIL')
+             OR t.out_of_tolerance)
+        AND NULLIF(btrim(COALESCE(t.notes, '')), '') IS NULL AS notes_missing
+    FROM pol, tasks t
+    JOIN task_bases tb ON tb.id = t.task_base_id
+    WHERE t.work_order_id = p_work_order_id
+  )
+  SELECT
+    id,
+    label,
+    task_type,
+    CASE WHEN incomplete THEN 'INCOMPLETE' ELSE 'NOTES_REQUIRED' END
+  FROM checked
+  WHERE incomplete OR notes_missing
+  ORDER BY position, created_at, id;
+$$;
+
+CREATE OR REPLACE FUNCTION public.work_order_require_checklist()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_count INT;
+BEGIN
+  IF NEW.status = 'COMPLETE'
+     AND OLD.status IS DISTINCT FROM 'COMPLETE' THEN
+    SELECT COUNT(*) INTO v_count FROM work_order_checklist_blockers(NEW.id);
+    IF v_count > 0 THEN
+      RAISE EXCEPTION 'work order % has % checklist item(s) blocking completion', NEW.id, v_count
+        USING ERRCODE = 'CM425';
+    END IF;
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_work_order_require_checklist ON work_order;
+CREATE TRIGGER trg_work_order_require_checklist
+  BEFORE UPDATE OF status ON work_order
+  FOR EACH ROW
+  EXECUTE FUNCTION public.work_order_require_checklist();
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_tasks_log_history ON tasks;
+DROP FUNCTION IF EXISTS public.tasks_log_history();
+DROP TRIGGER IF EXISTS trg_tasks_stamp_completion ON tasks;
+DROP FUNCTION IF EXISTS public.tasks_stamp_completion();
+
+DROP TABLE IF EXISTS task_history;
+
+DROP INDEX IF EXISTS idx_tasks_assignee;
+ALTER TABLE tasks
+  DROP COLUMN IF EXISTS updated_by_id,
+  DROP COLUMN IF EXISTS completed_at,
+  DROP COLUMN IF EXISTS completed_by_id,
+  DROP COLUMN IF EXISTS due_at,
+  DROP COLUMN IF EXISTS assignee_id;
+
+COMMIT;
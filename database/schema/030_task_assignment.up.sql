@@ -0,0 +1,123 @@
+-- Task assignment, sign-off and history
+-- Notes:
+--   - tasks.assignee_id / due_at assign a single step to a technician. New
+--     tasks default to their base's user_id (the template-level user), which
+--     is also what existing tasks get.
+--   - tasks.updated_by_id is the user behind the latest write; every task
+--     query that changes a task sets it so the triggers below know the actor.
+--   - completed_by_id / completed_at are stamped by a trigger when a task
+--     becomes complete (task_is_complete()) and cleared when it is reopened,
+--     whatever path wrote the value. Existing complete tasks keep their
+--     updated_at as completed_at; the actor is unknown.
+--   - task_history is filled by a trigger on INSERT and UPDATE with one row
+--     per changed field (value, notes, assignee_id, due_at), like
+--     work_order_field_changes.
+
+BEGIN;
+
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS assignee_id     UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS due_at          TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS completed_by_id UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS completed_at    TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS updated_by_id   UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_assignee ON tasks (assignee_id) WHERE assignee_id IS NOT NULL;
+
+UPDATE tasks t
+SET
+  assignee_id = tb.user_id,
+  completed_at = CASE WHEN task_is_complete(tb.task_type, t.value) THEN t.updated_at END
+FROM task_bases tb
+WHERE tb.id = t.task_base_id;
+
+CREATE TABLE IF NOT EXISTS task_history (
+  id               UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  organisation_id  UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  task_id          UUID NOT NULL REFERENCES tasks(id) ON UPDATE CASCADE ON DELETE CASCADE,
+  field            TEXT NOT NULL,
+  old_value        JSONB,
+  new_value        JSONB,
+  changed_by_id    UUID REFERENCES users(id) ON UPDATE CASCADE ON DELETE SET NULL,
+  changed_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history (task_id, changed_at);
+
+-- ---------------------------------------------------------------------------
+-- Assignee default and completion stamp
+-- ---------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION public.tasks_stamp_completion()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_type TEXT;
+  v_user UUID;
+  v_was  BOOLEAN := false;
+BEGIN
+  SELECT task_type, user_id INTO v_type, v_user
+  FROM task_bases
+  WHERE id = NEW.task_base_id;
+
+  IF TG_OP = 'INSERT' THEN
+    NEW.assignee_id := COALESCE(NEW.assignee_id, v_user);
+    NEW.updated_by_id := COALESCE(NEW.updated_by_id, NEW.created_by_id);
+  ELSE
+    v_was := task_is_complete(v_type, OLD.value);
+  END IF;
+
+  IF task_is_complete(v_type, NEW.value) THEN
+    IF NOT v_was THEN
+      NEW.completed_by_id := NEW.updated_by_id;
+      NEW.completed_at := now();
+    END IF;
+  ELSE
+    NEW.completed_by_id := NULL;
+    NEW.completed_at := NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_tasks_stamp_completion ON tasks;
+CREATE TRIGGER trg_tasks_stamp_completion
+  BEFORE INSERT OR UPDATE ON tasks
+  FOR EACH ROW
+  EXECUTE FUNCTION public.tasks_stamp_completion();
+
+-- ---------------------------------------------------------------------------
+-- History
+-- ---------------------------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION public.tasks_log_history()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_old JSONB := '{}'::jsonb;
+  v_new JSONB := to_jsonb(NEW);
+  v_key TEXT;
+BEGIN
+  IF TG_OP = 'UPDATE' THEN
+    v_old := to_jsonb(OLD);
+  END IF;
+  FOREACH v_key IN ARRAY ARRAY['value', 'notes', 'assignee_id', 'due_at']
+  LOOP
+    IF COALESCE(v_old -> v_key, 'null'::jsonb) IS DISTINCT FROM COALESCE(v_new -> v_key, 'null'::jsonb) THEN
+      INSERT INTO task_history (organisation_id, task_id, field, old_value, new_value, changed_by_id)
+      VALUES (NEW.organisation_id, NEW.id, v_key, v_old -> v_key, v_new -> v_key, NEW.updated_by_id);
+    END IF;
+  END LOOP;
+  RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS trg_tasks_log_history ON tasks;
+CREATE TRIGGER trg_tasks_log_history
+  AFTER INSERT OR UPDATE ON tasks
+  FOR EACH ROW
+  EXECUTE FUNCTION public.tasks_log_history();
+
+COMMIT;
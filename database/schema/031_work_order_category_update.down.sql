@@ -0,0 +1,172 @@
+BEGIN;
+
+-- Restore the 007 version (no category)
+CREATE OR REPLACE FUNCTION public.update_work_order_from_json(
+  p_org_id       UUID,
+  p_work_order_id UUID,
+  p_payload      JSONB,
+  p_updated_by   UUID DEFAULT NULL
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  -- presence flags
+  has_title               BOOLEAN := (p_payload ? 'title') OR (p_payload ? 'Title');
+  has_description         BOOLEAN := (p_payload ? 'description') OR (p_payload ? 'Description');
+  has_priority            BOOLEAN := (p_payload ? 'priority') OR (p_payload ? 'Priority');
+  has_due_date            BOOLEAN := (p_payload ? 'dueDate') OR (p_payload ? 'due_date');
+  has_est_start           BOOLEAN := (p_payload ? 'estimatedStartDate') OR (p_payload ? 'estimated_start_date');
+  has_est_duration        BOOLEAN := (p_payload ? 'estimatedDuration') OR (p_payload ? 'estimated_duration');
+  has_required_signature  BOOLEAN := (p_payload ? 'requiredSignature') OR (p_payload ? 'required_signature');
+  has_primary_user        BOOLEAN := (p_payload ? 'primaryUser') OR (p_payload ? 'primary_user') OR (p_payload ? 'primary_worker') OR (p_payload ? 'primaryWorker');
+  has_location            BOOLEAN := (p_payload ? 'location') OR (p_payload ? 'location_id');
+  has_team                BOOLEAN := (p_payload ? 'team') OR (p_payload ? 'team_id');
+  has_asset               BOOLEAN := (p_payload ? 'asset') OR (p_payload ? 'asset_id');
+  has_archived            BOOLEAN := (p_payload ? 'archived');
+  has_assigned_to         BOOLEAN := (p_payload ? 'assigned_to') OR (p_payload ? 'assignedTo');
+  has_customers           BOOLEAN := (p_payload ? 'customers') OR (p_payload ? 'customer_ids');
+
+  -- values
+  v_title                 TEXT := COALESCE(p_payload->>'title', p_payload->>'Title');
+  v_description           TEXT := COALESCE(p_payload->>'description', p_payload->>'Description');
+  v_priority              TEXT := COALESCE(p_payload->>'priority', p_payload->>'Priority');
+
+  v_due_text              TEXT := COALESCE(p_payload->>'dueDate', p_payload->>'due_date');
+  v_est_start_text        TEXT := COALESCE(p_payload->>'estimatedStartDate', p_payload->>'estimated_start_date');
+  v_due_date              TIMESTAMPTZ;
+  v_est_start             TIMESTAMPTZ;
+
+  v_est_duration          DOUBLE PRECISION;
+  v_required_signature    BOOLEAN;
+  v_archived              BOOLEAN;
+
+  v_primary_user          UUID;
+  v_location              UUID;
+  v_team                  UUID;
+  v_asset                 UUID;
+
+  v_assigned              JSONB := COALESCE(p_payload->'assigned_to', p_payload->'assignedTo');
+  v_customers             JSONB := COALESCE(p_payload->'customers',   p_payload->'customer_ids');
+
+  v_exists                BOOLEAN;
+BEGIN
+  -- Ensure the work order exists and belongs to the org
+  SELECT EXISTS (
+    SELECT 1 FROM work_order
+    WHERE id = p_work_order_id AND organisation_id = p_org_id
+  ) INTO v_exists;
+
+  IF NOT FOUND OR v_exists IS DISTINCT FROM TRUE THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+     USING ERRCODE = 'no_data_found';
+  END IF;
+
+  -- Parse dates if the key is present
+  IF has_due_date THEN
+    IF v_due_text IS NULL THEN
+      v_due_date := NULL;
+    ELSE
+      v_due_date := CASE
+        WHEN v_due_text ~ '^\d{4}-\d{2}-\d{2}$' THEN (v_due_text::date)::timestamptz
+        ELSE v_due_text::timestamptz
+      END;
+    END IF;
+  END IF;
+
+  IF has_est_start THEN
+    IF v_est_start_text IS NULL THEN
+      v_est_start := NULL;
+    ELSE
+      v_est_start := CASE
+        WHEN v_est_start_text ~ '^\d{4}-\d{2}-\d{2}$' THEN (v_est_start_text::date)::timestamptz
+        ELSE v_est_start_text::timestamptz
+      END;
+    END IF;
+  END IF;
+
+  -- Numerics / booleans (apply defaults if provided null)
+  IF has_est_duration THEN
+    v_est_duration := COALESCE((p_payload->>'estimatedDuration')::double precision,
+                               (p_payload->>'estimated_duration')::double precision,
+                               0);  -- column is NOT NULL
+  END IF;
+
+  IF has_required_signature THEN
+    v_required_signature := COALESCE((p_payload->>'requiredSignature')::boolean,
+                                     (p_payload->>'required_signature')::boolean,
+                                     FALSE); -- column is NOT NULL
+  END IF;
+
+  IF has_archived THEN
+    v_archived := (p_payload->>'archived')::boolean;
+  END IF;
+
+  -- Foreign keys (null clears if explicitly provided null)
+  IF has_primary_user THEN
+    v_primary_user := NULLIF(COALESCE(p_payload->>'primaryUser', p_payload->>'primary_user',
+                                      p_payload->>'primary_worker', p_payload->>'primaryWorker'), '')::uuid;
+  END IF;
+
+  IF has_location THEN
+    v_location := NULLIF(COALESCE(p_payload->>'location', p_payload->>'location_id'), '')::uuid;
+  END IF;
+
+  IF has_team THEN
+    v_team := NULLIF(COALESCE(p_payload->>'team', p_payload->>'team_id'), '')::uuid;
+  END IF;
+
+  IF has_asset THEN
+    v_asset := NULLIF(COALESCE(p_payload->>'asset', p_payload->>'asset_id'), '')::uuid;
+  END IF;
+
+  -- Apply the update (patch semantics)
+  UPDATE work_order SET
+    title                 = CASE WHEN has_title              THEN v_title                    ELSE title                END,
+    description           = CASE WHEN has_description        THEN v_description              ELSE description          END,
+    priority              = CASE WHEN has_priority           THEN COALESCE(upper(v_priority), priority) ELSE priority END,  -- keep non-null
+    due_date              = CASE WHEN has_due_date           THEN v_due_date                 ELSE due_date             END,
+    estimated_start_date  = CASE WHEN has_est_start          THEN v_est_start                ELSE estimated_start_date END,
+    estimated_duration    = CASE WHEN has_est_duration       THEN COALESCE(v_est_duration, estimated_duration) ELSE estimated_duration END,
+    required_signature    = CASE WHEN has_required_signature THEN COALESCE(v_required_signature, required_signature) ELSE required_signature END,
+    primary_user_id       = CASE WHEN has_primary_user       THEN v_primary_user            ELSE primary_user_id      END,
+    location_id           = CASE WHEN has_location           THEN v_location                ELSE location_id          END,
+    team_id               = CASE WHEN has_team               THEN v_team                    ELSE team_id              END,
+    asset_id              = CASE WHEN has_asset              THEN v_asset                   ELSE asset_id             END,
+    archived              = CASE WHEN has_archived           THEN COALESCE(v_archived, archived) ELSE archived END,
+    updated_at            = now()
+  WHERE id = p_work_order_id
+    AND organisation_id = p_org_id;
+
+  -- Replace assigned_to if present
+  IF has_assigned_to THEN
+    DELETE FROM work_order_assigned_to WHERE work_order_id = p_work_order_id;
+    IF v_assigned IS NOT NULL AND jsonb_typeof(v_assigned) = 'array' THEN
+      INSERT INTO work_order_assigned_to (work_order_id, user_id)
+      SELECT p_work_order_id, (val)::uuid
+      FROM (
+        SELECT DISTINCT jsonb_array_elements_text(v_assigned) AS val
+      ) s
+      WHERE NULLIF(val, '') IS NOT NULL
+      ON CONFLICT DO NOTHING;
+    END IF;
+  END IF;
+
+  -- Replace customers if present
+  IF has_customers THEN
+    DELETE FROM work_order_customers WHERE work_order_id = p_work_order_id;
+    IF v_customers IS NOT NULL AND jsonb_typeof(v_customers) = 'array' THEN
+      INSERT INTO work_order_customers (work_order_id, customer_id)
+      SELECT p_work_order_id, (val)::uuid
+      FROM (
+        SELECT DISTINCT jsonb_array_elements_text(v_customers) AS val
+      ) s
+      WHERE NULLIF(val, '') IS NOT NULL
+      ON CONFLICT DO NOTHING;
+    END IF;
+  END IF;
+
+  RETURN p_work_order_id;
+END;
+$$;
+
+COMMIT;
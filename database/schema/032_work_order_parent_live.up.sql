@@ -0,0 +1,114 @@
+-- Trashed parents
+-- Notes:
+--   - A trashed work order is left out of the tree, so nothing may be moved
+--     under one. set_work_order_parent() and trg_work_order_parent_check_cycle
+--     only accept a parent that is not trashed and raise the same
+--     foreign_key_violation as for an unknown parent otherwise.
+--   - set_work_order_parent() holds a share lock on the parent so it cannot
+--     be trashed while the move commits.
+
+BEGIN;
+
+-- Raises no_data_found for unknown, foreign or trashed work orders,
+-- foreign_key_violation for an unknown, foreign or trashed parent and
+-- CM412 for a stale expected version.
+CREATE OR REPLACE FUNCTION public.set_work_order_parent(
+  p_org_id           UUID,
+  p_work_order_id    UUID,
+  p_parent_id        UUID,
+  p_updated_by       UUID,
+  p_expected_version BIGINT DEFAULT NULL
+) RETURNS BIGINT
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_version BIGINT;
+  v_parent  UUID;
+BEGIN
+  SELECT version, parent_work_order_id INTO v_version, v_parent
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+    AND deleted_at IS NULL
+  FOR UPDATE;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  IF p_expected_version IS NOT NULL AND v_version <> p_expected_version THEN
+    RAISE EXCEPTION 'work order % is at version %, expected %', p_work_order_id, v_version, p_expected_version
+      USING ERRCODE = 'CM412';
+  END IF;
+
+  IF p_parent_id IS NOT DISTINCT FROM v_parent THEN
+    RETURN v_version;
+  END IF;
+
+  -- The parent must stay live until the move commits
+  IF p_parent_id IS NOT NULL THEN
+    PERFORM 1 FROM work_order
+    WHERE id = p_parent_id AND organisation_id = p_org_id
+      AND deleted_at IS NULL
+    FOR SHARE;
+
+    IF NOT FOUND THEN
+      RAISE EXCEPTION 'parent work order % not found for organisation %', p_parent_id, p_org_id
+        USING ERRCODE = 'foreign_key_violation';
+    END IF;
+  END IF;
+
+  PERFORM set_config('cmms.actor_id', COALESCE(p_updated_by::text, ''), true);
+
+  UPDATE work_order
+  SET parent_work_order_id = p_parent_id,
+      updated_at = now()
+  WHERE id = p_work_order_id
+  RETURNING version INTO v_version;
+
+  RETURN v_version;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.work_order_parent_check_cycle()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  IF NEW.parent_work_order_id IS NULL
+     OR NEW.parent_work_order_id IS NOT DISTINCT FROM OLD.parent_work_order_id THEN
+    RETURN NEW;
+  END IF;
+
+  PERFORM pg_advisory_xact_lock(hashtextextended('work_order_links:' || NEW.organisation_id::text, 0));
+
+  IF NOT EXISTS (
+    SELECT 1 FROM work_order p
+    WHERE p.id = NEW.parent_work_order_id
+      AND p.organisation_id = NEW.organisation_id
+      AND p.deleted_at IS NULL
+  ) THEN
+    RAISE EXCEPTION 'parent work order % not found for organisation %', NEW.parent_work_order_id, NEW.organisation_id
+      USING ERRCODE = 'foreign_key_violation';
+  END IF;
+
+  -- Is the work order the new parent itself or one of its ancestors?
+  IF EXISTS (
+    WITH RECURSIVE up AS (
+      SELECT NEW.parent_work_order_id AS id
+      UNION
+      SELECT w.parent_work_order_id
+      FROM work_order w
+      JOIN up ON w.id = up.id
+      WHERE w.parent_work_order_id IS NOT NULL
+    )
+    SELECT 1 FROM up WHERE up.id = NEW.id
+  ) THEN
+    RAISE EXCEPTION 'work order % cannot be placed under its own descendant %', NEW.id, NEW.parent_work_order_id
+      USING ERRCODE = 'CM409';
+  END IF;
+  RETURN NEW;
+END;
+$$;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_teams_org_name;
+
+ALTER TABLE teams
+  DROP COLUMN IF EXISTS organisation_id;
+
+COMMIT;
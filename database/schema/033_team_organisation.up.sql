@@ -0,0 +1,35 @@
+-- Organisation-owned teams
+-- Notes:
+--   - teams were global rows reached only through work orders, so a CSV
+--     import could not reference a team no work order used yet. Like
+--     locations and assets in 020, they now carry an owning organisation_id,
+--     backfilled where a team is used (by work orders, category defaults or
+--     finding rules) by exactly one organisation.
+--   - Team lookups match teams owned by the organisation or used by its work
+--     orders.
+
+BEGIN;
+
+ALTER TABLE teams
+  ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+UPDATE teams t
+SET organisation_id = u.org_id
+FROM (
+  SELECT team_id, MIN(organisation_id::text)::uuid AS org_id
+  FROM (
+    SELECT team_id, organisation_id FROM work_order
+    UNION ALL
+    SELECT default_team_id, organisation_id FROM work_order_categories
+    UNION ALL
+    SELECT team_id, organisation_id FROM task_finding_rules
+  ) used
+  WHERE team_id IS NOT NULL AND organisation_id IS NOT NULL
+  GROUP BY team_id
+  HAVING COUNT(DISTINCT organisation_id) = 1
+) u
+WHERE u.team_id = t.id AND t.organisation_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_teams_org_name ON teams (organisation_id, lower(name));
+
+COMMIT;
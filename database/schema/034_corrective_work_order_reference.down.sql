@@ -0,0 +1,94 @@
+BEGIN;
+
+-- Restore the 028 version
+-- Returns the new work order, or NULL when no rule matches the task's
+-- current value (or the task already has a corrective work order).
+CREATE OR REPLACE FUNCTION public.create_corrective_work_order(
+  p_org_id     UUID,
+  p_created_by UUID,
+  p_task_id    UUID
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_task    tasks%ROWTYPE;
+  v_base    task_bases%ROWTYPE;
+  v_src     work_order%ROWTYPE;
+  v_rule    task_finding_rules%ROWTYPE;
+  v_trigger TEXT;
+  v_reading TEXT;
+  v_finding TEXT;
+  v_payload JSONB;
+  v_id      UUID;
+BEGIN
+  SELECT * INTO v_task FROM tasks WHERE id = p_task_id AND organisation_id = p_org_id FOR UPDATE;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_src FROM work_order WHERE id = v_task.work_order_id AND deleted_at IS NULL;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_rule
+  FROM task_finding_rules
+  WHERE organisation_id = p_org_id AND task_base_id = v_task.task_base_id;
+  IF NOT FOUND THEN
+    RETURN NULL;
+  END IF;
+
+  IF EXISTS (SELECT 1 FROM work_order_findings WHERE source_task_id = p_task_id) THEN
+    RETURN NULL;
+  END IF;
+
+  SELECT * INTO v_base FROM task_bases WHERE id = v_task.task_base_id;
+
+  v_trigger := CASE
+    WHEN v_base.task_type = 'INSPECTION' AND UPPER(v_task.value) = 'FAIL' AND v_rule.on_fail THEN 'FAIL'
+    WHEN v_base.task_type = 'INSPECTION' AND UPPER(v_task.value) = 'FLAG' AND v_rule.on_flag THEN 'FLAG'
+    WHEN v_base.task_type IN ('NUMBER', 'METER') AND v_task.out_of_tolerance AND v_rule.on_out_of_tolerance THEN 'OUT_OF_TOLERANCE'
+  END;
+  IF v_trigger IS NULL THEN
+    RETURN NULL;
+  END IF;
+
+  IF v_trigger = 'OUT_OF_TOLERANCE' THEN
+    v_reading := concat_ws(' ', v_task.value, v_base.unit)
+      || format(' (expected %s to %s)',
+                COALESCE(v_base.min_value::text, '-'),
+                COALESCE(v_base.max_value::text, '-'));
+  ELSE
+    v_reading := UPPER(v_task.value);
+  END IF;
+
+  v_finding := format('%s on %s: %s', v_base.label, v_src.custom_id, v_reading);
+  IF NULLIF(btrim(COALESCE(v_task.notes, '')), '') IS NOT NULL THEN
+    v_finding := v_finding || E'\n\n' || btrim(v_task.notes);
+  END IF;
+
+  v_payload := jsonb_build_object(
+    'title',       'Corrective: ' || v_base.label,
+    'description', v_finding,
+    'priority',    COALESCE(v_rule.priority, v_src.priority),
+    'asset',       COALESCE(v_src.asset_id, v_base.asset_id),
+    'location',    v_src.location_id,
+    'category',    v_rule.category_id
+  );
+  IF v_rule.team_id IS NOT NULL THEN
+    v_payload := v_payload || jsonb_build_object('team', v_rule.team_id);
+  END IF;
+  IF v_rule.due_in_days IS NOT NULL THEN
+    v_payload := v_payload || jsonb_build_object('dueDate', now() + make_interval(days => v_rule.due_in_days));
+  END IF;
+
+  v_id := create_work_order_from_json(p_org_id, p_created_by, v_payload);
+
+  INSERT INTO work_order_findings (work_order_id, organisation_id, source_work_order_id, source_task_id, trigger, finding)
+  VALUES (v_id, p_org_id, v_src.id, p_task_id, v_trigger, v_finding);
+
+  RETURN v_id;
+END;
+$$;
+
+COMMIT;
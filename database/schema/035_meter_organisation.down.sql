@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_meters_org;
+
+ALTER TABLE meters
+  DROP COLUMN IF EXISTS organisation_id;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- Organisation-owned meters
+-- Notes:
+--   - meters were global rows, so a task base could point at another
+--     organisation's meter and a METER task would overwrite its reading.
+--     Like assets and locations in 020 and teams in 033, they now carry an
+--     owning organisation_id, backfilled where a meter is used (by task
+--     bases or their tasks) by exactly one organisation.
+--   - Task bases may only reference, and METER tasks only record readings
+--     on, meters of their own organisation. Meters left without an owner
+--     (shared by several organisations) are no longer written.
+
+BEGIN;
+
+ALTER TABLE meters
+  ADD COLUMN IF NOT EXISTS organisation_id UUID REFERENCES organisations(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+UPDATE meters m
+SET organisation_id = u.org_id
+FROM (
+  SELECT meter_id, MIN(organisation_id::text)::uuid AS org_id
+  FROM (
+    SELECT tb.meter_id, tb.organisation_id FROM task_bases tb
+    UNION ALL
+    SELECT tb.meter_id, t.organisation_id
+    FROM tasks t
+    JOIN task_bases tb ON tb.id = t.task_base_id
+  ) used
+  WHERE meter_id IS NOT NULL AND organisation_id IS NOT NULL
+  GROUP BY meter_id
+  HAVING COUNT(DISTINCT organisation_id) = 1
+) u
+WHERE u.meter_id = m.id AND m.organisation_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_meters_org ON meters (organisation_id);
+
+COMMIT;
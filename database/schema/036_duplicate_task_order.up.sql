@@ -0,0 +1,71 @@
+-- Duplicated checklists keep their order
+-- Notes:
+--   - duplicate_work_order() listed the source's tasks in creation order,
+--     so a checklist reordered through tasks.position (026) came back in
+--     its original order. It now follows position;
+--     create_work_order_from_json() inserts the list in order and
+--     trg_tasks_assign_position numbers it.
+
+BEGIN;
+
+-- Same as 024, with the tasks in checklist order
+CREATE OR REPLACE FUNCTION public.duplicate_work_order(
+  p_org_id        UUID,
+  p_work_order_id UUID,
+  p_created_by    UUID,
+  p_overrides     JSONB DEFAULT '{}'::jsonb
+) RETURNS UUID
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  v_src     work_order%ROWTYPE;
+  v_payload JSONB;
+  v_id      UUID;
+BEGIN
+  SELECT * INTO v_src
+  FROM work_order
+  WHERE id = p_work_order_id AND organisation_id = p_org_id
+    AND deleted_at IS NULL;
+
+  IF NOT FOUND THEN
+    RAISE EXCEPTION 'work order % not found for organisation %', p_work_order_id, p_org_id
+      USING ERRCODE = 'no_data_found';
+  END IF;
+
+  -- Every key is present so category defaults do not override the source
+  v_payload := jsonb_build_object(
+    'title',              v_src.title,
+    'description',        v_src.description,
+    'priority',           v_src.priority,
+    'estimatedDuration',  v_src.estimated_duration,
+    'estimatedStartDate', v_src.estimated_start_date,
+    'dueDate',            v_src.due_date,
+    'requiredSignature',  v_src.required_signature,
+    'primary_worker',     v_src.primary_user_id,
+    'location',           v_src.location_id,
+    'asset',              v_src.asset_id,
+    'team',               v_src.team_id,
+    'category',           v_src.category_id,
+    'assigned_to', (SELECT COALESCE(jsonb_agg(a.user_id), '[]'::jsonb)
+                    FROM work_order_assigned_to a WHERE a.work_order_id = v_src.id),
+    'customers',   (SELECT COALESCE(jsonb_agg(c.customer_id), '[]'::jsonb)
+                    FROM work_order_customers c WHERE c.work_order_id = v_src.id),
+    'tasks',       (SELECT COALESCE(jsonb_agg(t.task_base_id ORDER BY t.position, t.created_at, t.id), '[]'::jsonb)
+                    FROM tasks t WHERE t.work_order_id = v_src.id)
+  );
+  v_payload := v_payload || COALESCE(p_overrides, '{}'::jsonb);
+
+  v_id := create_work_order_from_json(p_org_id, p_created_by, v_payload);
+
+  -- Attachments are shared, not copied
+  INSERT INTO work_order_files (work_order_id, file_id)
+  SELECT v_id, f.file_id
+  FROM work_order_files f
+  WHERE f.work_order_id = v_src.id
+  ON CONFLICT DO NOTHING;
+
+  RETURN v_id;
+END;
+$$;
+
+COMMIT;
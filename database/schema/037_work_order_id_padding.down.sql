@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE work_order_id_formats
+  DROP CONSTRAINT IF EXISTS chk_work_order_id_formats_padding;
+ALTER TABLE work_order_id_formats
+  ADD CONSTRAINT chk_work_order_id_formats_padding CHECK (padding BETWEEN 1 AND 12);
+
+COMMIT;
@@ -0,0 +1,21 @@
+-- Work order ID padding capped at 9
+-- Notes:
+--   - realign_work_order_counter() only matches sequences of up to 9
+--     digits (the 32-bit counter never goes past 999999999), so a format
+--     padded to 10 or more digits could not be repaired and ID generation
+--     failed whenever its counter lagged the existing IDs. Padding is now
+--     1 to 9; wider formats are narrowed to 9, so their next IDs carry
+--     fewer leading zeros.
+
+BEGIN;
+
+UPDATE work_order_id_formats
+SET padding = 9, updated_at = now()
+WHERE padding > 9;
+
+ALTER TABLE work_order_id_formats
+  DROP CONSTRAINT IF EXISTS chk_work_order_id_formats_padding;
+ALTER TABLE work_order_id_formats
+  ADD CONSTRAINT chk_work_order_id_formats_padding CHECK (padding BETWEEN 1 AND 9);
+
+COMMIT;
@@ -18,6 +18,19 @@ type Config struct {
 	Database struct {
 		URL string `mapstructure:"url"`
 	} `mapstructure:"database"`
+	Storage struct {
+		Backend              string `mapstructure:"backend"`
+		LocalPath            string `mapstructure:"local_path"`
+		MaxUploadBytes       int64  `mapstructure:"max_upload_bytes"`
+		DefaultOrgQuotaBytes int64  `mapstructure:"default_org_quota_bytes"`
+		MaxOrgQuotaBytes     int64  `mapstructure:"max_org_quota_bytes"`
+	} `mapstructure:"storage"`
+	WorkOrders struct {
+		Trash struct {
+			RetentionDays int           `mapstructure:"retention_days"`
+			PurgeInterval time.Duration `mapstructure:"purge_interval"`
+		} `mapstructure:"trash"`
+	} `mapstructure:"work_orders"`
 	Logging struct {
 		Level  string `mapstructure:"level"`
 		Format string `mapstructure:"format"`
@@ -71,6 +84,16 @@ func Load() Config {
 	// Sensible logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "text")
+	// File storage defaults
+	viper.SetDefault("storage.backend", "local")
+	viper.SetDefault("storage.local_path", "./data/uploads")
+	viper.SetDefault("storage.max_upload_bytes", 25<<20)
+	viper.SetDefault("storage.default_org_quota_bytes", 5<<30)
+	// Highest quota an org owner may set (0 caps at the default quota)
+	viper.SetDefault("storage.max_org_quota_bytes", 0)
+	// Deleted work orders are purged after the retention (0 keeps them)
+	viper.SetDefault("work_orders.trash.retention_days", 30)
+	viper.SetDefault("work_orders.trash.purge_interval", "1h")
 	// Security defaults
 	viper.SetDefault("security.request_id.trust_header", false)
 	viper.SetDefault("security.session.sweeper_interval", "5m")
@@ -98,6 +121,11 @@ func Load() Config {
 	_ = viper.BindEnv("frontend.post_login_path", "FRONTEND_POST_LOGIN_PATH")
 	_ = viper.BindEnv("frontend.api_route", "FRONTEND_API_ROUTE")
 	_ = viper.BindEnv("database.url", "DATABASE_URL")
+	_ = viper.BindEnv("storage.backend", "STORAGE_BACKEND")
+	_ = viper.BindEnv("storage.local_path", "STORAGE_LOCAL_PATH")
+	_ = viper.BindEnv("storage.max_upload_bytes", "STORAGE_MAX_UPLOAD_BYTES")
+	_ = viper.BindEnv("storage.default_org_quota_bytes", "STORAGE_DEFAULT_ORG_QUOTA_BYTES")
+	_ = viper.BindEnv("storage.max_org_quota_bytes", "STORAGE_MAX_ORG_QUOTA_BYTES")
 	_ = viper.BindEnv("logging.level", "LOG_LEVEL")
 	_ = viper.BindEnv("logging.format", "LOG_FORMAT")
 	_ = viper.BindEnv("security.request_id.trust_header", "REQUEST_ID_TRUST_HEADER")
@@ -2,6 +2,7 @@ package db
 
 import (
     "context"
+    "errors"
 
     "github.com/jackc/pgx/v5"
     "github.com/jackc/pgx/v5/pgconn"
@@ -22,3 +23,20 @@ func (q *Queries) Query(ctx context.Context, sql string, args ...interface{}) (p
     return q.db.Query(ctx, sql, args...)
 }
 
+
+// Begin starts a transaction on the underlying DBTX (pool, conn or an outer tx).
+func (q *Queries) Begin(ctx context.Context) (pgx.Tx, error) {
+    b, ok := q.db.(interface {
+        Begin(context.Context) (pgx.Tx, error)
+    })
+    if !ok {
+        return nil, errors.New("db: underlying connection cannot begin a transaction")
+    }
+    return b.Begin(ctx)
+}
+
+// ExportWorkOrdersSQL is the ExportWorkOrders statement, for callers that
+// stream its rows through Query instead of collecting them. Scan rows by
+// name (pgx.RowToStructByName) into ExportWorkOrdersRow so they follow the
+// generated column list.
+const ExportWorkOrdersSQL = exportWorkOrders
@@ -26,10 +26,13 @@ params AS (
   FROM input
 ),
 base AS (
-  SELECT DISTINCT l.id, l.name, l.created_at
+  SELECT l.id, l.name, l.created_at
   FROM locations l
-  JOIN work_order w ON w.location_id = l.id
-  WHERE w.organisation_id = (SELECT org_id FROM params)
+  WHERE l.organisation_id = (SELECT org_id FROM params)
+     OR EXISTS (
+       SELECT 1 FROM work_order w
+       WHERE w.location_id = l.id AND w.organisation_id = (SELECT org_id FROM params)
+     )
 ),
 filtered AS (
   SELECT
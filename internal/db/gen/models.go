@@ -11,9 +11,31 @@ import (
 )
 
 type Asset struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Name           pgtype.Text        `db:"name" json:"name"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	CustomID       pgtype.Text        `db:"custom_id" json:"custom_id"`
+	LocationID     pgtype.UUID        `db:"location_id" json:"location_id"`
+}
+
+type CalendarFeedToken struct {
+	TokenHash      string             `db:"token_hash" json:"token_hash"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	LastUsedAt     pgtype.Timestamptz `db:"last_used_at" json:"last_used_at"`
+}
+
+type ChecklistPolicy struct {
+	ID                   pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID       pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	CategoryID           pgtype.UUID        `db:"category_id" json:"category_id"`
+	RequireTasksComplete bool               `db:"require_tasks_complete" json:"require_tasks_complete"`
+	RequireFailureNotes  bool               `db:"require_failure_notes" json:"require_failure_notes"`
+	CreatedByID          pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt            pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt            pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
 type Customer struct {
@@ -24,10 +46,16 @@ type Customer struct {
 }
 
 type File struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Path      pgtype.Text        `db:"path" json:"path"`
-	Filename  pgtype.Text        `db:"filename" json:"filename"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Path           pgtype.Text        `db:"path" json:"path"`
+	Filename       pgtype.Text        `db:"filename" json:"filename"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	UploadedByID   pgtype.UUID        `db:"uploaded_by_id" json:"uploaded_by_id"`
+	StorageBackend string             `db:"storage_backend" json:"storage_backend"`
+	ContentType    string             `db:"content_type" json:"content_type"`
+	SizeBytes      int64              `db:"size_bytes" json:"size_bytes"`
+	Sha256         pgtype.Text        `db:"sha256" json:"sha256"`
 }
 
 type Identity struct {
@@ -52,10 +80,31 @@ type LocalCredential struct {
 	LastPasswordChange pgtype.Timestamptz `db:"last_password_change" json:"last_password_change"`
 }
 
+type ImportJob struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	Kind           string             `db:"kind" json:"kind"`
+	DryRun         bool               `db:"dry_run" json:"dry_run"`
+	Status         string             `db:"status" json:"status"`
+	Filename       pgtype.Text        `db:"filename" json:"filename"`
+	TotalRows      int32              `db:"total_rows" json:"total_rows"`
+	ProcessedRows  int32              `db:"processed_rows" json:"processed_rows"`
+	CreatedRows    int32              `db:"created_rows" json:"created_rows"`
+	ErrorCount     int32              `db:"error_count" json:"error_count"`
+	Errors         []byte             `db:"errors" json:"errors"`
+	Message        pgtype.Text        `db:"message" json:"message"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	StartedAt      pgtype.Timestamptz `db:"started_at" json:"started_at"`
+	FinishedAt     pgtype.Timestamptz `db:"finished_at" json:"finished_at"`
+}
+
 type Location struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Name           pgtype.Text        `db:"name" json:"name"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	CustomID       pgtype.Text        `db:"custom_id" json:"custom_id"`
 }
 
 type LoginAttempt struct {
@@ -67,9 +116,15 @@ type LoginAttempt struct {
 }
 
 type Meter struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Name           pgtype.Text        `db:"name" json:"name"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	Unit           pgtype.Text        `db:"unit" json:"unit"`
+	LastValue      pgtype.Numeric     `db:"last_value" json:"last_value"`
+	LastReadAt     pgtype.Timestamptz `db:"last_read_at" json:"last_read_at"`
+	LastReadByID   pgtype.UUID        `db:"last_read_by_id" json:"last_read_by_id"`
+	LastTaskID     pgtype.UUID        `db:"last_task_id" json:"last_task_id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
 }
 
 type OrgMembership struct {
@@ -78,6 +133,12 @@ type OrgMembership struct {
 	Role   interface{} `db:"role" json:"role"`
 }
 
+type OrgStorageQuota struct {
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	QuotaBytes     int64              `db:"quota_bytes" json:"quota_bytes"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type Organisation struct {
 	ID         pgtype.UUID        `db:"id" json:"id"`
 	Slug       string             `db:"slug" json:"slug"`
@@ -86,6 +147,22 @@ type Organisation struct {
 	CreatedAt  pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type Part struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	PartNumber     string             `db:"part_number" json:"part_number"`
+	Revision       string             `db:"revision" json:"revision"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Category       string             `db:"category" json:"category"`
+	Uom            string             `db:"uom" json:"uom"`
+	UnitCost       pgtype.Numeric     `db:"unit_cost" json:"unit_cost"`
+	Tracking       string             `db:"tracking" json:"tracking"`
+	Archived       bool               `db:"archived" json:"archived"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type PasswordReset struct {
 	Token     string             `db:"token" json:"token"`
 	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
@@ -121,6 +198,19 @@ type SeedOrg struct {
 	MsTenantID pgtype.Text `db:"ms_tenant_id" json:"ms_tenant_id"`
 }
 
+type SlaPolicy struct {
+	ID                    pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID        pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	Priority              pgtype.Text        `db:"priority" json:"priority"`
+	CategoryID            pgtype.UUID        `db:"category_id" json:"category_id"`
+	ReactWithinMinutes    pgtype.Int4        `db:"react_within_minutes" json:"react_within_minutes"`
+	CompleteWithinMinutes pgtype.Int4        `db:"complete_within_minutes" json:"complete_within_minutes"`
+	AtRiskPercent         int32              `db:"at_risk_percent" json:"at_risk_percent"`
+	CreatedByID           pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
 type Task struct {
 	ID                      pgtype.UUID        `db:"id" json:"id"`
 	OrganisationID          pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
@@ -133,6 +223,15 @@ type Task struct {
 	PreviousValue           pgtype.Text        `db:"previous_value" json:"previous_value"`
 	WorkOrderID             pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
 	PreventiveMaintenanceID pgtype.UUID        `db:"preventive_maintenance_id" json:"preventive_maintenance_id"`
+	Position                int32              `db:"position" json:"position"`
+	NumericValue            pgtype.Numeric     `db:"numeric_value" json:"numeric_value"`
+	TaskOptionID            pgtype.UUID        `db:"task_option_id" json:"task_option_id"`
+	OutOfTolerance          bool               `db:"out_of_tolerance" json:"out_of_tolerance"`
+	AssigneeID              pgtype.UUID        `db:"assignee_id" json:"assignee_id"`
+	DueAt                   pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	CompletedByID           pgtype.UUID        `db:"completed_by_id" json:"completed_by_id"`
+	CompletedAt             pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	UpdatedByID             pgtype.UUID        `db:"updated_by_id" json:"updated_by_id"`
 }
 
 type TaskBasis struct {
@@ -146,6 +245,11 @@ type TaskBasis struct {
 	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
 	AssetID        pgtype.UUID        `db:"asset_id" json:"asset_id"`
 	MeterID        pgtype.UUID        `db:"meter_id" json:"meter_id"`
+	Archived       bool               `db:"archived" json:"archived"`
+	Unit           pgtype.Text        `db:"unit" json:"unit"`
+	MinValue       pgtype.Numeric     `db:"min_value" json:"min_value"`
+	MaxValue       pgtype.Numeric     `db:"max_value" json:"max_value"`
+	Optional       bool               `db:"optional" json:"optional"`
 }
 
 type TaskFile struct {
@@ -153,6 +257,32 @@ type TaskFile struct {
 	FileID pgtype.UUID `db:"file_id" json:"file_id"`
 }
 
+type TaskFindingRule struct {
+	OrganisationID   pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	TaskBaseID       pgtype.UUID        `db:"task_base_id" json:"task_base_id"`
+	OnFail           bool               `db:"on_fail" json:"on_fail"`
+	OnFlag           bool               `db:"on_flag" json:"on_flag"`
+	OnOutOfTolerance bool               `db:"on_out_of_tolerance" json:"on_out_of_tolerance"`
+	Priority         pgtype.Text        `db:"priority" json:"priority"`
+	CategoryID       pgtype.UUID        `db:"category_id" json:"category_id"`
+	TeamID           pgtype.UUID        `db:"team_id" json:"team_id"`
+	DueInDays        pgtype.Int4        `db:"due_in_days" json:"due_in_days"`
+	CreatedByID      pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt        pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt        pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type TaskHistory struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	TaskID         pgtype.UUID        `db:"task_id" json:"task_id"`
+	Field          string             `db:"field" json:"field"`
+	OldValue       []byte             `db:"old_value" json:"old_value"`
+	NewValue       []byte             `db:"new_value" json:"new_value"`
+	ChangedByID    pgtype.UUID        `db:"changed_by_id" json:"changed_by_id"`
+	ChangedAt      pgtype.Timestamptz `db:"changed_at" json:"changed_at"`
+}
+
 type TaskOption struct {
 	ID             pgtype.UUID        `db:"id" json:"id"`
 	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
@@ -161,12 +291,14 @@ type TaskOption struct {
 	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
 	Label          pgtype.Text        `db:"label" json:"label"`
 	TaskBaseID     pgtype.UUID        `db:"task_base_id" json:"task_base_id"`
+	Position       int32              `db:"position" json:"position"`
 }
 
 type Team struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Name           pgtype.Text        `db:"name" json:"name"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
 }
 
 type User struct {
@@ -179,6 +311,17 @@ type User struct {
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type UserLeave struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	StartsAt       pgtype.Timestamptz `db:"starts_at" json:"starts_at"`
+	EndsAt         pgtype.Timestamptz `db:"ends_at" json:"ends_at"`
+	Reason         pgtype.Text        `db:"reason" json:"reason"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
 type UserTotp struct {
 	UserID    pgtype.UUID        `db:"user_id" json:"user_id"`
 	Secret    string             `db:"secret" json:"secret"`
@@ -187,6 +330,15 @@ type UserTotp struct {
 	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
 }
 
+type UserWorkingHour struct {
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	UserID         pgtype.UUID `db:"user_id" json:"user_id"`
+	Weekday        int16       `db:"weekday" json:"weekday"`
+	StartTime      pgtype.Time `db:"start_time" json:"start_time"`
+	EndTime        pgtype.Time `db:"end_time" json:"end_time"`
+	TimeZone       string      `db:"time_zone" json:"time_zone"`
+}
+
 type WorkOrder struct {
 	ID                      pgtype.UUID        `db:"id" json:"id"`
 	OrganisationID          pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
@@ -216,6 +368,10 @@ type WorkOrder struct {
 	Feedback                pgtype.Text        `db:"feedback" json:"feedback"`
 	ParentPreventiveMaintID pgtype.UUID        `db:"parent_preventive_maint_id" json:"parent_preventive_maint_id"`
 	FirstTimeToReact        pgtype.Timestamptz `db:"first_time_to_react" json:"first_time_to_react"`
+	Version                 int64              `db:"version" json:"version"`
+	DeletedAt               pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	DeletedByID             pgtype.UUID        `db:"deleted_by_id" json:"deleted_by_id"`
+	ParentWorkOrderID       pgtype.UUID        `db:"parent_work_order_id" json:"parent_work_order_id"`
 }
 
 type WorkOrderAssignedTo struct {
@@ -224,15 +380,47 @@ type WorkOrderAssignedTo struct {
 }
 
 type WorkOrderCategory struct {
-	ID        pgtype.UUID        `db:"id" json:"id"`
-	Name      pgtype.Text        `db:"name" json:"name"`
-	CreatedAt pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	ID                       pgtype.UUID        `db:"id" json:"id"`
+	Name                     string             `db:"name" json:"name"`
+	CreatedAt                pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	OrganisationID           pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	Description              pgtype.Text        `db:"description" json:"description"`
+	DefaultPriority          pgtype.Text        `db:"default_priority" json:"default_priority"`
+	DefaultEstimatedDuration pgtype.Float8      `db:"default_estimated_duration" json:"default_estimated_duration"`
+	DefaultTeamID            pgtype.UUID        `db:"default_team_id" json:"default_team_id"`
+	CreatedByID              pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	UpdatedAt                pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type WorkOrderCategoryTask struct {
+	CategoryID pgtype.UUID `db:"category_id" json:"category_id"`
+	TaskBaseID pgtype.UUID `db:"task_base_id" json:"task_base_id"`
+	Position   int32       `db:"position" json:"position"`
+}
+
+type WorkOrderComment struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	ParentID       pgtype.UUID        `db:"parent_id" json:"parent_id"`
+	AuthorID       pgtype.UUID        `db:"author_id" json:"author_id"`
+	Body           string             `db:"body" json:"body"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+	EditedAt       pgtype.Timestamptz `db:"edited_at" json:"edited_at"`
+	DeletedAt      pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+}
+
+type WorkOrderCommentMention struct {
+	CommentID pgtype.UUID `db:"comment_id" json:"comment_id"`
+	UserID    pgtype.UUID `db:"user_id" json:"user_id"`
 }
 
 type WorkOrderCounter struct {
 	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
 	Year           int32       `db:"year" json:"year"`
 	NextSeq        int32       `db:"next_seq" json:"next_seq"`
+	Month          int16       `db:"month" json:"month"`
 }
 
 type WorkOrderCustomer struct {
@@ -240,7 +428,117 @@ type WorkOrderCustomer struct {
 	CustomerID  pgtype.UUID `db:"customer_id" json:"customer_id"`
 }
 
+type WorkOrderDependency struct {
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	BlockedByID    pgtype.UUID        `db:"blocked_by_id" json:"blocked_by_id"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type WorkOrderFieldChange struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	Field          string             `db:"field" json:"field"`
+	OldValue       []byte             `db:"old_value" json:"old_value"`
+	NewValue       []byte             `db:"new_value" json:"new_value"`
+	ChangedByID    pgtype.UUID        `db:"changed_by_id" json:"changed_by_id"`
+	ChangedAt      pgtype.Timestamptz `db:"changed_at" json:"changed_at"`
+}
+
 type WorkOrderFile struct {
 	WorkOrderID pgtype.UUID `db:"work_order_id" json:"work_order_id"`
 	FileID      pgtype.UUID `db:"file_id" json:"file_id"`
 }
+
+type WorkOrderFinding struct {
+	WorkOrderID       pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	OrganisationID    pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	SourceWorkOrderID pgtype.UUID        `db:"source_work_order_id" json:"source_work_order_id"`
+	SourceTaskID      pgtype.UUID        `db:"source_task_id" json:"source_task_id"`
+	Trigger           string             `db:"trigger" json:"trigger"`
+	Finding           string             `db:"finding" json:"finding"`
+	CreatedAt         pgtype.Timestamptz `db:"created_at" json:"created_at"`
+}
+
+type WorkOrderIDFormat struct {
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	Prefix         string             `db:"prefix" json:"prefix"`
+	DatePart       string             `db:"date_part" json:"date_part"`
+	Separator      string             `db:"separator" json:"separator"`
+	Padding        int32              `db:"padding" json:"padding"`
+	ResetPeriod    string             `db:"reset_period" json:"reset_period"`
+	UpdatedByID    pgtype.UUID        `db:"updated_by_id" json:"updated_by_id"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type WorkOrderPart struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	PartID         pgtype.UUID        `db:"part_id" json:"part_id"`
+	Quantity       pgtype.Numeric     `db:"quantity" json:"quantity"`
+	SerialOrBatch  pgtype.Text        `db:"serial_or_batch" json:"serial_or_batch"`
+	UnitCost       pgtype.Numeric     `db:"unit_cost" json:"unit_cost"`
+	Notes          pgtype.Text        `db:"notes" json:"notes"`
+	UsedAt         pgtype.Timestamptz `db:"used_at" json:"used_at"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type WorkOrderSla struct {
+	WorkOrderID      pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	OrganisationID   pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	PolicyID         pgtype.UUID        `db:"policy_id" json:"policy_id"`
+	ReactDueAt       pgtype.Timestamptz `db:"react_due_at" json:"react_due_at"`
+	CompleteDueAt    pgtype.Timestamptz `db:"complete_due_at" json:"complete_due_at"`
+	ReactBreached    pgtype.Bool        `db:"react_breached" json:"react_breached"`
+	CompleteBreached pgtype.Bool        `db:"complete_breached" json:"complete_breached"`
+	Breached         pgtype.Bool        `db:"breached" json:"breached"`
+	AtRisk           pgtype.Bool        `db:"at_risk" json:"at_risk"`
+}
+
+type WorkOrderStatusHistory struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	FromStatus     pgtype.Text        `db:"from_status" json:"from_status"`
+	ToStatus       string             `db:"to_status" json:"to_status"`
+	ChangedByID    pgtype.UUID        `db:"changed_by_id" json:"changed_by_id"`
+	Reason         pgtype.Text        `db:"reason" json:"reason"`
+	ChangedAt      pgtype.Timestamptz `db:"changed_at" json:"changed_at"`
+}
+
+type WorkOrderTemplate struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	Name           string             `db:"name" json:"name"`
+	Description    pgtype.Text        `db:"description" json:"description"`
+	Payload        []byte             `db:"payload" json:"payload"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+type WorkOrderTemplateTask struct {
+	TemplateID pgtype.UUID `db:"template_id" json:"template_id"`
+	TaskBaseID pgtype.UUID `db:"task_base_id" json:"task_base_id"`
+	Position   int32       `db:"position" json:"position"`
+}
+
+type WorkOrderTimeEntry struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	UserID         pgtype.UUID        `db:"user_id" json:"user_id"`
+	StartedAt      pgtype.Timestamptz `db:"started_at" json:"started_at"`
+	EndedAt        pgtype.Timestamptz `db:"ended_at" json:"ended_at"`
+	HourlyRate     pgtype.Numeric     `db:"hourly_rate" json:"hourly_rate"`
+	Notes          pgtype.Text        `db:"notes" json:"notes"`
+	Source         string             `db:"source" json:"source"`
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
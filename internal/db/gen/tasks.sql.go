@@ -11,10 +11,101 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const deleteTaskByID = `-- name: DeleteTaskByID :exec
+const assignTask = `-- name: AssignTask :one
+UPDATE tasks t
+SET
+  assignee_id = $1::uuid,
+  due_at = $2::timestamptz,
+  updated_by_id = $3,
+  updated_at = now()
+FROM work_order w
+WHERE t.id = $4
+  AND t.organisation_id = $5
+  AND w.id = t.work_order_id
+  AND w.deleted_at IS NULL
+RETURNING t.id
+`
+
+type AssignTaskParams struct {
+	AssigneeID     pgtype.UUID        `db:"assignee_id" json:"assignee_id"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	UpdatedByID    pgtype.UUID        `db:"updated_by_id" json:"updated_by_id"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+}
+
+// Replaces the assignee and due time of a task on a live work order.
+func (q *Queries) AssignTask(ctx context.Context, arg AssignTaskParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, assignTask,
+		arg.AssigneeID,
+		arg.DueAt,
+		arg.UpdatedByID,
+		arg.ID,
+		arg.OrganisationID,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createTask = `-- name: CreateTask :one
+INSERT INTO tasks (
+  organisation_id, created_by_id, task_base_id, work_order_id, notes,
+  value, numeric_value, task_option_id, out_of_tolerance, assignee_id, due_at
+)
+SELECT w.organisation_id, $1::uuid, $2::uuid, w.id, $3::text,
+       $4::text, $5::float8, $6::uuid, $7::boolean,
+       $8::uuid, $9::timestamptz
+FROM work_order w
+WHERE w.id = $10
+  AND w.organisation_id = $11
+  AND w.deleted_at IS NULL
+RETURNING id
+`
+
+type CreateTaskParams struct {
+	CreatedByID    pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	TaskBaseID     pgtype.UUID        `db:"task_base_id" json:"task_base_id"`
+	Notes          pgtype.Text        `db:"notes" json:"notes"`
+	Value          pgtype.Text        `db:"value" json:"value"`
+	NumericValue   pgtype.Float8      `db:"numeric_value" json:"numeric_value"`
+	TaskOptionID   pgtype.UUID        `db:"task_option_id" json:"task_option_id"`
+	OutOfTolerance bool               `db:"out_of_tolerance" json:"out_of_tolerance"`
+	AssigneeID     pgtype.UUID        `db:"assignee_id" json:"assignee_id"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	WorkOrderID    pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
+}
+
+// Appends a task (see trg_tasks_assign_position) to a live work order of the
+// organisation; no row when the work order is unknown or trashed. A NULL
+// assignee defaults to the base's user (trg_tasks_stamp_completion).
+func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createTask,
+		arg.CreatedByID,
+		arg.TaskBaseID,
+		arg.Notes,
+		arg.Value,
+		arg.NumericValue,
+		arg.TaskOptionID,
+		arg.OutOfTolerance,
+		arg.AssigneeID,
+		arg.DueAt,
+		arg.WorkOrderID,
+		arg.OrganisationID,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteTaskByID = `-- name: DeleteTaskByID :execrows
 DELETE FROM tasks
-WHERE organisation_id = $1
-  AND id = $2
+USING work_order w
+WHERE tasks.organisation_id = $1
+  AND tasks.id = $2
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL
 `
 
 type DeleteTaskByIDParams struct {
@@ -22,9 +113,119 @@ type DeleteTaskByIDParams struct {
 	ID             pgtype.UUID `db:"id" json:"id"`
 }
 
-func (q *Queries) DeleteTaskByID(ctx context.Context, arg DeleteTaskByIDParams) error {
-	_, err := q.db.Exec(ctx, deleteTaskByID, arg.OrganisationID, arg.ID)
-	return err
+// Tasks of a trashed work order are kept until it is restored or purged.
+func (q *Queries) DeleteTaskByID(ctx context.Context, arg DeleteTaskByIDParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteTaskByID, arg.OrganisationID, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const getTask = `-- name: GetTask :one
+SELECT
+  t.id,
+  t.work_order_id,
+  t.task_base_id,
+  tb.label,
+  tb.task_type,
+  tb.unit,
+  tb.min_value::float8 AS min_value,
+  tb.max_value::float8 AS max_value,
+  tb.meter_id,
+  t.notes,
+  t.value,
+  t.numeric_value::float8 AS numeric_value,
+  t.task_option_id,
+  t.out_of_tolerance,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS completed,
+  f.work_order_id AS corrective_work_order_id,
+  t.assignee_id,
+  au.name AS assignee_name,
+  t.due_at,
+  t.completed_by_id,
+  cu.name AS completed_by_name,
+  t.completed_at,
+  t.position,
+  t.created_by_id,
+  t.created_at,
+  t.updated_at
+FROM tasks t
+JOIN task_bases tb ON tb.id = t.task_base_id
+JOIN work_order w ON w.id = t.work_order_id AND w.deleted_at IS NULL
+LEFT JOIN work_order_findings f ON f.source_task_id = t.id
+LEFT JOIN users au ON au.id = t.assignee_id
+LEFT JOIN users cu ON cu.id = t.completed_by_id
+WHERE t.id = $1
+  AND t.organisation_id = $2
+`
+
+type GetTaskParams struct {
+	ID             pgtype.UUID `db:"id" json:"id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+type GetTaskRow struct {
+	ID                    pgtype.UUID        `db:"id" json:"id"`
+	WorkOrderID           pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
+	TaskBaseID            pgtype.UUID        `db:"task_base_id" json:"task_base_id"`
+	Label                 string             `db:"label" json:"label"`
+	TaskType              string             `db:"task_type" json:"task_type"`
+	Unit                  pgtype.Text        `db:"unit" json:"unit"`
+	MinValue              pgtype.Float8      `db:"min_value" json:"min_value"`
+	MaxValue              pgtype.Float8      `db:"max_value" json:"max_value"`
+	MeterID               pgtype.UUID        `db:"meter_id" json:"meter_id"`
+	Notes                 pgtype.Text        `db:"notes" json:"notes"`
+	Value                 pgtype.Text        `db:"value" json:"value"`
+	NumericValue          pgtype.Float8      `db:"numeric_value" json:"numeric_value"`
+	TaskOptionID          pgtype.UUID        `db:"task_option_id" json:"task_option_id"`
+	OutOfTolerance        bool               `db:"out_of_tolerance" json:"out_of_tolerance"`
+	Completed             bool               `db:"completed" json:"completed"`
+	CorrectiveWorkOrderID pgtype.UUID        `db:"corrective_work_order_id" json:"corrective_work_order_id"`
+	AssigneeID            pgtype.UUID        `db:"assignee_id" json:"assignee_id"`
+	AssigneeName          pgtype.Text        `db:"assignee_name" json:"assignee_name"`
+	DueAt                 pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	CompletedByID         pgtype.UUID        `db:"completed_by_id" json:"completed_by_id"`
+	CompletedByName       pgtype.Text        `db:"completed_by_name" json:"completed_by_name"`
+	CompletedAt           pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Position              int32              `db:"position" json:"position"`
+	CreatedByID           pgtype.UUID        `db:"created_by_id" json:"created_by_id"`
+	CreatedAt             pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	UpdatedAt             pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
+}
+
+func (q *Queries) GetTask(ctx context.Context, arg GetTaskParams) (GetTaskRow, error) {
+	row := q.db.QueryRow(ctx, getTask, arg.ID, arg.OrganisationID)
+	var i GetTaskRow
+	err := row.Scan(
+		&i.ID,
+		&i.WorkOrderID,
+		&i.TaskBaseID,
+		&i.Label,
+		&i.TaskType,
+		&i.Unit,
+		&i.MinValue,
+		&i.MaxValue,
+		&i.MeterID,
+		&i.Notes,
+		&i.Value,
+		&i.NumericValue,
+		&i.TaskOptionID,
+		&i.OutOfTolerance,
+		&i.Completed,
+		&i.CorrectiveWorkOrderID,
+		&i.AssigneeID,
+		&i.AssigneeName,
+		&i.DueAt,
+		&i.CompletedByID,
+		&i.CompletedByName,
+		&i.CompletedAt,
+		&i.Position,
+		&i.CreatedByID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
 const getTasksByWorkOrderID = `-- name: GetTasksByWorkOrderID :many
@@ -36,13 +237,28 @@ SELECT
   t.created_by_id             AS task_created_by_id,
   t.notes                     AS task_notes,
   t.value                     AS task_value,
+  t.numeric_value::float8     AS task_numeric_value,
+  t.task_option_id            AS task_option_id,
+  t.out_of_tolerance          AS task_out_of_tolerance,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS task_completed,
+  wf.work_order_id            AS task_corrective_work_order_id,
+  t.assignee_id               AS task_assignee_id,
+  au.name                     AS task_assignee_name,
+  t.due_at                    AS task_due_at,
+  t.completed_by_id           AS task_completed_by_id,
+  cu.name                     AS task_completed_by_name,
+  t.completed_at              AS task_completed_at,
   t.work_order_id             AS task_work_order_id,
   t.preventive_maintenance_id AS task_preventive_maintenance_id,
+  t.position                  AS task_position,
 
   -- TaskBase
   tb.id                       AS task_base_id,
   tb.label                    AS task_base_label,
   tb.task_type                AS task_base_type,
+  tb.unit                     AS task_base_unit,
+  tb.min_value::float8        AS task_base_min_value,
+  tb.max_value::float8        AS task_base_max_value,
 
   -- Linked User
   u.id                        AS task_user_id,
@@ -56,6 +272,8 @@ SELECT
   -- Meter
   m.id                        AS task_meter_id,
   m.name                      AS task_meter_name,
+  m.last_value::float8        AS task_meter_last_value,
+  m.last_read_at              AS task_meter_last_read_at,
 
   -- PreventiveMaintenance
   pm.id                       AS pm_id,
@@ -71,7 +289,8 @@ SELECT
     json_agg(
       DISTINCT jsonb_build_object(
         'id', topt.id,
-        'label', topt.label
+        'label', topt.label,
+        'position', topt.position
       )
     ) FILTER (WHERE topt.id IS NOT NULL),
     '[]'
@@ -87,24 +306,46 @@ SELECT
       )
     ) FILTER (WHERE f.id IS NOT NULL),
     '[]'
-  )                           AS task_files
+  )                           AS task_files,
+
+  -- History (oldest first)
+  COALESCE(
+    (SELECT json_agg(
+              jsonb_build_object(
+                'field', h.field,
+                'old_value', h.old_value,
+                'new_value', h.new_value,
+                'changed_by_id', h.changed_by_id,
+                'changed_by_name', hu.name,
+                'changed_at', h.changed_at
+              ) ORDER BY h.changed_at, h.id
+            )
+     FROM task_history h
+     LEFT JOIN users hu ON hu.id = h.changed_by_id
+     WHERE h.task_id = t.id),
+    '[]'
+  )                           AS task_history
 
 FROM tasks t
 JOIN task_bases tb ON tb.id = t.task_base_id
 LEFT JOIN users u ON u.id = tb.user_id
+LEFT JOIN users au ON au.id = t.assignee_id
+LEFT JOIN users cu ON cu.id = t.completed_by_id
 LEFT JOIN assets a ON a.id = tb.asset_id
 LEFT JOIN meters m ON m.id = tb.meter_id
 LEFT JOIN preventive_maintenances pm ON pm.id = t.preventive_maintenance_id
-JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1
+JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1 AND wo.deleted_at IS NULL
 LEFT JOIN task_options topt ON topt.task_base_id = tb.id
 LEFT JOIN task_files tf ON tf.task_id = t.id
 LEFT JOIN files f ON f.id = tf.file_id
+LEFT JOIN work_order_findings wf ON wf.source_task_id = t.id
 
 WHERE t.work_order_id = $2
   AND t.organisation_id = $1
 
 GROUP BY
-  t.id, tb.id, u.id, a.id, m.id, pm.id, wo.id
+  t.id, tb.id, u.id, au.id, cu.id, a.id, m.id, pm.id, wo.id, wf.work_order_id
+ORDER BY t.position, t.created_at, t.id
 `
 
 type GetTasksByWorkOrderIDParams struct {
@@ -120,11 +361,26 @@ type GetTasksByWorkOrderIDRow struct {
 	TaskCreatedByID             pgtype.UUID        `db:"task_created_by_id" json:"task_created_by_id"`
 	TaskNotes                   pgtype.Text        `db:"task_notes" json:"task_notes"`
 	TaskValue                   pgtype.Text        `db:"task_value" json:"task_value"`
+	TaskNumericValue            pgtype.Float8      `db:"task_numeric_value" json:"task_numeric_value"`
+	TaskOptionID                pgtype.UUID        `db:"task_option_id" json:"task_option_id"`
+	TaskOutOfTolerance          bool               `db:"task_out_of_tolerance" json:"task_out_of_tolerance"`
+	TaskCompleted               bool               `db:"task_completed" json:"task_completed"`
+	TaskCorrectiveWorkOrderID   pgtype.UUID        `db:"task_corrective_work_order_id" json:"task_corrective_work_order_id"`
+	TaskAssigneeID              pgtype.UUID        `db:"task_assignee_id" json:"task_assignee_id"`
+	TaskAssigneeName            pgtype.Text        `db:"task_assignee_name" json:"task_assignee_name"`
+	TaskDueAt                   pgtype.Timestamptz `db:"task_due_at" json:"task_due_at"`
+	TaskCompletedByID           pgtype.UUID        `db:"task_completed_by_id" json:"task_completed_by_id"`
+	TaskCompletedByName         pgtype.Text        `db:"task_completed_by_name" json:"task_completed_by_name"`
+	TaskCompletedAt             pgtype.Timestamptz `db:"task_completed_at" json:"task_completed_at"`
 	TaskWorkOrderID             pgtype.UUID        `db:"task_work_order_id" json:"task_work_order_id"`
 	TaskPreventiveMaintenanceID pgtype.UUID        `db:"task_preventive_maintenance_id" json:"task_preventive_maintenance_id"`
+	TaskPosition                int32              `db:"task_position" json:"task_position"`
 	TaskBaseID                  pgtype.UUID        `db:"task_base_id" json:"task_base_id"`
 	TaskBaseLabel               string             `db:"task_base_label" json:"task_base_label"`
 	TaskBaseType                string             `db:"task_base_type" json:"task_base_type"`
+	TaskBaseUnit                pgtype.Text        `db:"task_base_unit" json:"task_base_unit"`
+	TaskBaseMinValue            pgtype.Float8      `db:"task_base_min_value" json:"task_base_min_value"`
+	TaskBaseMaxValue            pgtype.Float8      `db:"task_base_max_value" json:"task_base_max_value"`
 	TaskUserID                  pgtype.UUID        `db:"task_user_id" json:"task_user_id"`
 	TaskUserName                pgtype.Text        `db:"task_user_name" json:"task_user_name"`
 	TaskUserEmail               pgtype.Text        `db:"task_user_email" json:"task_user_email"`
@@ -132,6 +388,8 @@ type GetTasksByWorkOrderIDRow struct {
 	TaskAssetName               pgtype.Text        `db:"task_asset_name" json:"task_asset_name"`
 	TaskMeterID                 pgtype.UUID        `db:"task_meter_id" json:"task_meter_id"`
 	TaskMeterName               pgtype.Text        `db:"task_meter_name" json:"task_meter_name"`
+	TaskMeterLastValue          pgtype.Float8      `db:"task_meter_last_value" json:"task_meter_last_value"`
+	TaskMeterLastReadAt         pgtype.Timestamptz `db:"task_meter_last_read_at" json:"task_meter_last_read_at"`
 	PmID                        pgtype.UUID        `db:"pm_id" json:"pm_id"`
 	PmName                      pgtype.Text        `db:"pm_name" json:"pm_name"`
 	WorkOrderID                 pgtype.UUID        `db:"work_order_id" json:"work_order_id"`
@@ -139,6 +397,7 @@ type GetTasksByWorkOrderIDRow struct {
 	WorkOrderStatus             string             `db:"work_order_status" json:"work_order_status"`
 	TaskOptions                 interface{}        `db:"task_options" json:"task_options"`
 	TaskFiles                   interface{}        `db:"task_files" json:"task_files"`
+	TaskHistory                 interface{}        `db:"task_history" json:"task_history"`
 }
 
 func (q *Queries) GetTasksByWorkOrderID(ctx context.Context, arg GetTasksByWorkOrderIDParams) ([]GetTasksByWorkOrderIDRow, error) {
@@ -158,11 +417,26 @@ func (q *Queries) GetTasksByWorkOrderID(ctx context.Context, arg GetTasksByWorkO
 			&i.TaskCreatedByID,
 			&i.TaskNotes,
 			&i.TaskValue,
+			&i.TaskNumericValue,
+			&i.TaskOptionID,
+			&i.TaskOutOfTolerance,
+			&i.TaskCompleted,
+			&i.TaskCorrectiveWorkOrderID,
+			&i.TaskAssigneeID,
+			&i.TaskAssigneeName,
+			&i.TaskDueAt,
+			&i.TaskCompletedByID,
+			&i.TaskCompletedByName,
+			&i.TaskCompletedAt,
 			&i.TaskWorkOrderID,
 			&i.TaskPreventiveMaintenanceID,
+			&i.TaskPosition,
 			&i.TaskBaseID,
 			&i.TaskBaseLabel,
 			&i.TaskBaseType,
+			&i.TaskBaseUnit,
+			&i.TaskBaseMinValue,
+			&i.TaskBaseMaxValue,
 			&i.TaskUserID,
 			&i.TaskUserName,
 			&i.TaskUserEmail,
@@ -170,6 +444,8 @@ func (q *Queries) GetTasksByWorkOrderID(ctx context.Context, arg GetTasksByWorkO
 			&i.TaskAssetName,
 			&i.TaskMeterID,
 			&i.TaskMeterName,
+			&i.TaskMeterLastValue,
+			&i.TaskMeterLastReadAt,
 			&i.PmID,
 			&i.PmName,
 			&i.WorkOrderID,
@@ -177,6 +453,7 @@ func (q *Queries) GetTasksByWorkOrderID(ctx context.Context, arg GetTasksByWorkO
 			&i.WorkOrderStatus,
 			&i.TaskOptions,
 			&i.TaskFiles,
+			&i.TaskHistory,
 		); err != nil {
 			return nil, err
 		}
@@ -192,22 +469,31 @@ const listSimpleTasksByWorkOrder = `-- name: ListSimpleTasksByWorkOrder :many
 SELECT
   t.id AS id,                                                -- UUID
   tb.label AS title,                                         -- task "title"
-  (UPPER(COALESCE(t.value, '')) IN ('COMPLETE','PASS')) AS completed,
+  public.task_is_complete(tb.task_type, t.value)::boolean AS completed,
+  t.out_of_tolerance AS out_of_tolerance,
+  t.assignee_id AS assignee_id,
   u.name AS assignee_name,
+  t.due_at AS due_at,
+  t.completed_at AS completed_at,
+  t.position AS position,
   jsonb_build_object(                                        -- taskBase payload
     'id', tb.id,
     'label', tb.label,
     'taskType', tb.task_type,
     'assetId', tb.asset_id,
-    'meterId', tb.meter_id
+    'meterId', tb.meter_id,
+    'unit', tb.unit,
+    'minValue', tb.min_value,
+    'maxValue', tb.max_value,
+    'optional', tb.optional
   ) AS task_base
 FROM tasks t
 JOIN task_bases tb ON tb.id = t.task_base_id
-LEFT JOIN users u ON u.id = tb.user_id
-JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1
+LEFT JOIN users u ON u.id = t.assignee_id
+JOIN work_order wo ON wo.id = t.work_order_id AND wo.organisation_id = $1 AND wo.deleted_at IS NULL
 WHERE t.organisation_id = $1
   AND t.work_order_id   = $2
-ORDER BY t.created_at ASC
+ORDER BY t.position, t.created_at ASC
 `
 
 type ListSimpleTasksByWorkOrderParams struct {
@@ -216,11 +502,16 @@ type ListSimpleTasksByWorkOrderParams struct {
 }
 
 type ListSimpleTasksByWorkOrderRow struct {
-	ID           pgtype.UUID `db:"id" json:"id"`
-	Title        string      `db:"title" json:"title"`
-	Completed    bool        `db:"completed" json:"completed"`
-	AssigneeName pgtype.Text `db:"assignee_name" json:"assignee_name"`
-	TaskBase     []byte      `db:"task_base" json:"task_base"`
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	Title          string             `db:"title" json:"title"`
+	Completed      bool               `db:"completed" json:"completed"`
+	OutOfTolerance bool               `db:"out_of_tolerance" json:"out_of_tolerance"`
+	AssigneeID     pgtype.UUID        `db:"assignee_id" json:"assignee_id"`
+	AssigneeName   pgtype.Text        `db:"assignee_name" json:"assignee_name"`
+	DueAt          pgtype.Timestamptz `db:"due_at" json:"due_at"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
+	Position       int32              `db:"position" json:"position"`
+	TaskBase       []byte             `db:"task_base" json:"task_base"`
 }
 
 func (q *Queries) ListSimpleTasksByWorkOrder(ctx context.Context, arg ListSimpleTasksByWorkOrderParams) ([]ListSimpleTasksByWorkOrderRow, error) {
@@ -236,7 +527,12 @@ func (q *Queries) ListSimpleTasksByWorkOrder(ctx context.Context, arg ListSimple
 			&i.ID,
 			&i.Title,
 			&i.Completed,
+			&i.OutOfTolerance,
+			&i.AssigneeID,
 			&i.AssigneeName,
+			&i.DueAt,
+			&i.CompletedAt,
+			&i.Position,
 			&i.TaskBase,
 		); err != nil {
 			return nil, err
@@ -249,21 +545,64 @@ func (q *Queries) ListSimpleTasksByWorkOrder(ctx context.Context, arg ListSimple
 	return items, nil
 }
 
+const listWorkOrderTaskIDs = `-- name: ListWorkOrderTaskIDs :many
+SELECT t.id
+FROM tasks t
+WHERE t.work_order_id = $1
+  AND t.organisation_id = $2
+ORDER BY t.position, t.created_at, t.id
+FOR UPDATE
+`
+
+type ListWorkOrderTaskIDsParams struct {
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+// The checklist order of a work order, locking its tasks for a reorder.
+func (q *Queries) ListWorkOrderTaskIDs(ctx context.Context, arg ListWorkOrderTaskIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, listWorkOrderTaskIDs, arg.WorkOrderID, arg.OrganisationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const markTaskComplete = `-- name: MarkTaskComplete :one
 UPDATE tasks
 SET
   value = 'COMPLETE',
+  updated_by_id = $1,
   updated_at = now()
-WHERE organisation_id = $1
-  AND id = $2
+FROM work_order w
+WHERE tasks.organisation_id = $2
+  AND tasks.id = $3
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM task_bases tb WHERE tb.id = tasks.task_base_id AND tb.task_type = 'SUBTASK')
 RETURNING
-  id,
-  organisation_id,
-  value,
-  updated_at
+  tasks.id,
+  tasks.organisation_id,
+  tasks.value,
+  tasks.completed_by_id,
+  tasks.completed_at,
+  tasks.updated_at
 `
 
 type MarkTaskCompleteParams struct {
+	UpdatedByID    pgtype.UUID `db:"updated_by_id" json:"updated_by_id"`
 	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
 	ID             pgtype.UUID `db:"id" json:"id"`
 }
@@ -272,51 +611,125 @@ type MarkTaskCompleteRow struct {
 	ID             pgtype.UUID        `db:"id" json:"id"`
 	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
 	Value          pgtype.Text        `db:"value" json:"value"`
+	CompletedByID  pgtype.UUID        `db:"completed_by_id" json:"completed_by_id"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
+// No row for a task of a trashed work order.
 func (q *Queries) MarkTaskComplete(ctx context.Context, arg MarkTaskCompleteParams) (MarkTaskCompleteRow, error) {
-	row := q.db.QueryRow(ctx, markTaskComplete, arg.OrganisationID, arg.ID)
+	row := q.db.QueryRow(ctx, markTaskComplete, arg.UpdatedByID, arg.OrganisationID, arg.ID)
 	var i MarkTaskCompleteRow
 	err := row.Scan(
 		&i.ID,
 		&i.OrganisationID,
 		&i.Value,
+		&i.CompletedByID,
+		&i.CompletedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const recordMeterReading = `-- name: RecordMeterReading :execrows
+UPDATE meters
+SET
+  last_value = $1::float8,
+  unit = COALESCE($2::text, unit),
+  last_read_at = now(),
+  last_read_by_id = $3::uuid,
+  last_task_id = $4::uuid
+WHERE id = $5
+  AND organisation_id = $6
+`
+
+type RecordMeterReadingParams struct {
+	Value          float64     `db:"value" json:"value"`
+	Unit           pgtype.Text `db:"unit" json:"unit"`
+	ReadByID       pgtype.UUID `db:"read_by_id" json:"read_by_id"`
+	TaskID         pgtype.UUID `db:"task_id" json:"task_id"`
+	ID             pgtype.UUID `db:"id" json:"id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+// Stores a METER task's reading as the meter's last one. No row for a
+// meter of another organisation.
+func (q *Queries) RecordMeterReading(ctx context.Context, arg RecordMeterReadingParams) (int64, error) {
+	result, err := q.db.Exec(ctx, recordMeterReading,
+		arg.Value,
+		arg.Unit,
+		arg.ReadByID,
+		arg.TaskID,
+		arg.ID,
+		arg.OrganisationID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reorderWorkOrderTasks = `-- name: ReorderWorkOrderTasks :execrows
+UPDATE tasks t
+SET position = x.ord::int
+FROM unnest($1::uuid[]) WITH ORDINALITY AS x(id, ord)
+WHERE t.id = x.id
+  AND t.work_order_id = $2
+  AND t.organisation_id = $3
+`
+
+type ReorderWorkOrderTasksParams struct {
+	TaskIds        []pgtype.UUID `db:"task_ids" json:"task_ids"`
+	WorkOrderID    pgtype.UUID   `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID   `db:"organisation_id" json:"organisation_id"`
+}
+
+func (q *Queries) ReorderWorkOrderTasks(ctx context.Context, arg ReorderWorkOrderTasksParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reorderWorkOrderTasks, arg.TaskIds, arg.WorkOrderID, arg.OrganisationID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const toggleTaskCompletion = `-- name: ToggleTaskCompletion :one
 UPDATE tasks
 SET
   value = CASE
     WHEN $1::boolean = true
       THEN 'COMPLETE'
-    WHEN $1::boolean = false AND previous_value IS NOT NULL
-      THEN previous_value
+    WHEN $1::boolean = false AND tasks.previous_value IS NOT NULL
+      THEN tasks.previous_value
     WHEN $1::boolean = false
       THEN 'OPEN'
-    ELSE value
+    ELSE tasks.value
   END,
   previous_value = CASE
     WHEN $1::boolean = true
-      THEN value  -- stash current before marking complete
-    ELSE previous_value
+      THEN tasks.value  -- stash current before marking complete
+    ELSE tasks.previous_value
   END,
+  updated_by_id = $2,
   updated_at = now()
-WHERE organisation_id = $2
-  AND id = $3
+FROM work_order w
+WHERE tasks.organisation_id = $3
+  AND tasks.id = $4
+  AND w.id = tasks.work_order_id
+  AND w.deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM task_bases tb WHERE tb.id = tasks.task_base_id AND tb.task_type = 'SUBTASK')
 RETURNING
-  id,
-  organisation_id,
-  value,
-  previous_value,
-  updated_at
+  tasks.id,
+  tasks.organisation_id,
+  tasks.value,
+  tasks.previous_value,
+  tasks.completed_by_id,
+  tasks.completed_at,
+  tasks.updated_at
 `
 
 type ToggleTaskCompletionParams struct {
 	Complete       bool        `db:"complete" json:"complete"`
+	UpdatedByID    pgtype.UUID `db:"updated_by_id" json:"updated_by_id"`
 	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
 	ID             pgtype.UUID `db:"id" json:"id"`
 }
@@ -326,18 +739,81 @@ type ToggleTaskCompletionRow struct {
 	OrganisationID pgtype.UUID        `db:"organisation_id" json:"organisation_id"`
 	Value          pgtype.Text        `db:"value" json:"value"`
 	PreviousValue  pgtype.Text        `db:"previous_value" json:"previous_value"`
+	CompletedByID  pgtype.UUID        `db:"completed_by_id" json:"completed_by_id"`
+	CompletedAt    pgtype.Timestamptz `db:"completed_at" json:"completed_at"`
 	UpdatedAt      pgtype.Timestamptz `db:"updated_at" json:"updated_at"`
 }
 
+// Only SUBTASK tasks toggle; typed tasks take a value through UpdateTask.
+// No row for a task of a trashed work order.
 func (q *Queries) ToggleTaskCompletion(ctx context.Context, arg ToggleTaskCompletionParams) (ToggleTaskCompletionRow, error) {
-	row := q.db.QueryRow(ctx, toggleTaskCompletion, arg.Complete, arg.OrganisationID, arg.ID)
+	row := q.db.QueryRow(ctx, toggleTaskCompletion,
+		arg.Complete,
+		arg.UpdatedByID,
+		arg.OrganisationID,
+		arg.ID,
+	)
 	var i ToggleTaskCompletionRow
 	err := row.Scan(
 		&i.ID,
 		&i.OrganisationID,
 		&i.Value,
 		&i.PreviousValue,
+		&i.CompletedByID,
+		&i.CompletedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
+
+const updateTask = `-- name: UpdateTask :one
+UPDATE tasks t
+SET
+  notes = CASE WHEN $1::boolean THEN $2::text ELSE t.notes END,
+  value = CASE WHEN $3::boolean THEN $4::text ELSE t.value END,
+  numeric_value = CASE WHEN $3::boolean THEN $5::float8 ELSE t.numeric_value END,
+  task_option_id = CASE WHEN $3::boolean THEN $6::uuid ELSE t.task_option_id END,
+  out_of_tolerance = CASE WHEN $3::boolean THEN $7::boolean ELSE t.out_of_tolerance END,
+  previous_value = CASE WHEN $3::boolean THEN NULL ELSE t.previous_value END,
+  updated_by_id = $8,
+  updated_at = now()
+FROM work_order w
+WHERE t.id = $9
+  AND t.organisation_id = $10
+  AND w.id = t.work_order_id
+  AND w.deleted_at IS NULL
+RETURNING t.id
+`
+
+type UpdateTaskParams struct {
+	SetNotes       bool          `db:"set_notes" json:"set_notes"`
+	Notes          pgtype.Text   `db:"notes" json:"notes"`
+	SetValue       bool          `db:"set_value" json:"set_value"`
+	Value          pgtype.Text   `db:"value" json:"value"`
+	NumericValue   pgtype.Float8 `db:"numeric_value" json:"numeric_value"`
+	TaskOptionID   pgtype.UUID   `db:"task_option_id" json:"task_option_id"`
+	OutOfTolerance bool          `db:"out_of_tolerance" json:"out_of_tolerance"`
+	UpdatedByID    pgtype.UUID   `db:"updated_by_id" json:"updated_by_id"`
+	ID             pgtype.UUID   `db:"id" json:"id"`
+	OrganisationID pgtype.UUID   `db:"organisation_id" json:"organisation_id"`
+}
+
+// Sets notes and/or value; a new value (already validated against the task
+// type) forgets the one stashed by ToggleTaskCompletion.
+func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, updateTask,
+		arg.SetNotes,
+		arg.Notes,
+		arg.SetValue,
+		arg.Value,
+		arg.NumericValue,
+		arg.TaskOptionID,
+		arg.OutOfTolerance,
+		arg.UpdatedByID,
+		arg.ID,
+		arg.OrganisationID,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
@@ -11,30 +11,111 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const changeWorkOrderStatus = `-- name: ChangeWorkOrderStatus :exec
-
-UPDATE work_order
-SET
-  status = $1,
-  completed_on = CASE WHEN upper($1) = 'COMPLETE'
-                      THEN COALESCE(completed_on, now())
-                      ELSE NULL
-                 END,
-  updated_at = now()
-WHERE id = $2
-  AND organisation_id = $3
+const changeWorkOrderStatus = `-- name: ChangeWorkOrderStatus :one
+WITH upd AS (
+  UPDATE work_order
+  SET
+    status = $1,
+    completed_on = CASE WHEN $1 = 'COMPLETE'
+                        THEN COALESCE(completed_on, now())
+                        ELSE NULL
+                   END,
+    completed_by_id = CASE WHEN $1 = 'COMPLETE'
+                           THEN COALESCE(completed_by_id, $2::uuid)
+                           ELSE NULL
+                      END,
+    updated_at = now()
+  WHERE id = $3
+    AND organisation_id = $4
+    AND deleted_at IS NULL
+    AND status = $5
+    AND ($6::bigint IS NULL OR version = $6::bigint)
+  RETURNING id, organisation_id
+)
+INSERT INTO work_order_status_history (
+  organisation_id, work_order_id, from_status, to_status, changed_by_id, reason
+)
+SELECT upd.organisation_id, upd.id, $5, $1, $2::uuid, $7::text
+FROM upd
+RETURNING id
 `
 
 type ChangeWorkOrderStatusParams struct {
-	Status         string      `db:"status" json:"status"`
-	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
-	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	ToStatus        string      `db:"to_status" json:"to_status"`
+	ChangedByID     pgtype.UUID `db:"changed_by_id" json:"changed_by_id"`
+	WorkOrderID     pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID  pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	FromStatus      string      `db:"from_status" json:"from_status"`
+	ExpectedVersion pgtype.Int8 `db:"expected_version" json:"expected_version"`
+	Reason          pgtype.Text `db:"reason" json:"reason"`
 }
 
-// ---------------------------------------------------------------------------
-func (q *Queries) ChangeWorkOrderStatus(ctx context.Context, arg ChangeWorkOrderStatusParams) error {
-	_, err := q.db.Exec(ctx, changeWorkOrderStatus, arg.Status, arg.WorkOrderID, arg.OrganisationID)
-	return err
+// Guarded by from_status (and expected_version when the client sent If-Match)
+// so a concurrent change makes this return no rows.
+func (q *Queries) ChangeWorkOrderStatus(ctx context.Context, arg ChangeWorkOrderStatusParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, changeWorkOrderStatus,
+		arg.ToStatus,
+		arg.ChangedByID,
+		arg.WorkOrderID,
+		arg.OrganisationID,
+		arg.FromStatus,
+		arg.ExpectedVersion,
+		arg.Reason,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const completeWorkOrder = `-- name: CompleteWorkOrder :one
+WITH upd AS (
+  UPDATE work_order
+  SET
+    status = 'COMPLETE',
+    signature_id = COALESCE($1::uuid, signature_id),
+    completed_on = now(),
+    completed_by_id = $2::uuid,
+    updated_at = now()
+  WHERE id = $3
+    AND organisation_id = $4
+    AND deleted_at IS NULL
+    AND status = $5
+    AND ($6::bigint IS NULL OR version = $6::bigint)
+  RETURNING id, organisation_id
+)
+INSERT INTO work_order_status_history (
+  organisation_id, work_order_id, from_status, to_status, changed_by_id, reason
+)
+SELECT upd.organisation_id, upd.id, $5, 'COMPLETE', $2::uuid, $7::text
+FROM upd
+RETURNING id
+`
+
+type CompleteWorkOrderParams struct {
+	SignatureID     pgtype.UUID `db:"signature_id" json:"signature_id"`
+	CompletedByID   pgtype.UUID `db:"completed_by_id" json:"completed_by_id"`
+	WorkOrderID     pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID  pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	FromStatus      string      `db:"from_status" json:"from_status"`
+	ExpectedVersion pgtype.Int8 `db:"expected_version" json:"expected_version"`
+	Reason          pgtype.Text `db:"reason" json:"reason"`
+}
+
+// ChangeWorkOrderStatus to COMPLETE that also stores the captured signature
+// and always stamps the completing user. Same guards, same no-rows contract.
+func (q *Queries) CompleteWorkOrder(ctx context.Context, arg CompleteWorkOrderParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, completeWorkOrder,
+		arg.SignatureID,
+		arg.CompletedByID,
+		arg.WorkOrderID,
+		arg.OrganisationID,
+		arg.FromStatus,
+		arg.ExpectedVersion,
+		arg.Reason,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
 const createWorkOrderFromJSON = `-- name: CreateWorkOrderFromJSON :one
@@ -58,20 +139,205 @@ func (q *Queries) CreateWorkOrderFromJSON(ctx context.Context, arg CreateWorkOrd
 	return id, err
 }
 
-const deleteWorkOrderByID = `-- name: DeleteWorkOrderByID :exec
-DELETE FROM work_order
-WHERE organisation_id = $1
-  AND id = $2
+const duplicateWorkOrder = `-- name: DuplicateWorkOrder :one
+SELECT duplicate_work_order(
+  $1::uuid,
+  $2::uuid,
+  $3::uuid,
+  $4::jsonb
+)::uuid AS id
 `
 
-type DeleteWorkOrderByIDParams struct {
+type DuplicateWorkOrderParams struct {
 	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
-	ID             pgtype.UUID `db:"id" json:"id"`
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	CreatedByID    pgtype.UUID `db:"created_by_id" json:"created_by_id"`
+	Overrides      []byte      `db:"overrides" json:"overrides"`
+}
+
+func (q *Queries) DuplicateWorkOrder(ctx context.Context, arg DuplicateWorkOrderParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, duplicateWorkOrder,
+		arg.OrganisationID,
+		arg.WorkOrderID,
+		arg.CreatedByID,
+		arg.Overrides,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
-func (q *Queries) DeleteWorkOrderByID(ctx context.Context, arg DeleteWorkOrderByIDParams) error {
-	_, err := q.db.Exec(ctx, deleteWorkOrderByID, arg.OrganisationID, arg.ID)
-	return err
+const exportWorkOrders = `-- name: ExportWorkOrders :many
+WITH
+params AS (
+  SELECT
+    $1::uuid   AS org_id,
+    $2::jsonb AS p
+),
+sort AS (
+  SELECT
+    lower(NULLIF(p->>'sortField',''))     AS field,
+    upper(COALESCE(NULLIF(p->>'direction',''),'DESC')) AS dir
+  FROM params
+)
+SELECT
+  w.id,
+  w.custom_id,
+  w.title,
+  w.status,
+  w.priority,
+  w.due_date,
+  w.created_at,
+  w.completed_on,
+  pu.name                               AS primary_worker,
+  COALESCE(asg.names, '')::text         AS assignees,
+  tm.name                               AS team,
+  l.name                                AS location,
+  a.name                                AS asset,
+  c.name                                AS category,
+  COALESCE(tk.total, 0)::bigint         AS tasks_total,
+  COALESCE(tk.completed, 0)::bigint     AS tasks_completed,
+  COALESCE(sla.breached, false)         AS sla_breached
+FROM work_order w
+JOIN params pr ON pr.org_id = w.organisation_id
+CROSS JOIN sort s
+LEFT JOIN work_order_sla sla        ON sla.work_order_id = w.id
+LEFT JOIN users pu                  ON pu.id = w.primary_user_id
+LEFT JOIN teams tm                  ON tm.id = w.team_id
+LEFT JOIN locations l               ON l.id = w.location_id
+LEFT JOIN assets a                  ON a.id = w.asset_id
+LEFT JOIN work_order_categories c   ON c.id = w.category_id
+LEFT JOIN LATERAL (
+  SELECT string_agg(COALESCE(u.name, u.email), '; ' ORDER BY COALESCE(u.name, u.email)) AS names
+  FROM work_order_assigned_to x
+  JOIN users u ON u.id = x.user_id
+  WHERE x.work_order_id = w.id
+) asg ON TRUE
+LEFT JOIN LATERAL (
+  SELECT
+    COUNT(*) AS total,
+    COUNT(*) FILTER (WHERE public.task_is_complete(tb.task_type, t.value)) AS completed
+  FROM tasks t
+  JOIN task_bases tb ON tb.id = t.task_base_id
+  WHERE t.work_order_id = w.id
+) tk ON TRUE
+WHERE w.deleted_at IS NULL
+  AND public.work_order_matches_filter(
+    w, COALESCE(sla.breached, false), COALESCE(sla.at_risk, false), pr.p->'filter'
+  )
+ORDER BY
+  CASE WHEN s.field='custom_id'  AND s.dir='ASC'  THEN w.custom_id  END ASC  NULLS LAST,
+  CASE WHEN s.field='due_date'   AND s.dir='ASC'  THEN w.due_date   END ASC  NULLS LAST,
+  CASE WHEN s.field='created_at' AND s.dir='ASC'  THEN w.created_at END ASC  NULLS LAST,
+  CASE WHEN s.field='updated_at' AND s.dir='ASC'  THEN w.updated_at END ASC  NULLS LAST,
+  CASE WHEN s.field='priority'   AND s.dir='ASC'  THEN w.priority   END ASC  NULLS LAST,
+  CASE WHEN s.field='status'     AND s.dir='ASC'  THEN w.status     END ASC  NULLS LAST,
+  CASE WHEN s.field='title'      AND s.dir='ASC'  THEN w.title      END ASC  NULLS LAST,
+  CASE WHEN s.field='custom_id'  AND s.dir='DESC' THEN w.custom_id  END DESC NULLS LAST,
+  CASE WHEN s.field='due_date'   AND s.dir='DESC' THEN w.due_date   END DESC NULLS LAST,
+  CASE WHEN s.field='created_at' AND s.dir='DESC' THEN w.created_at END DESC NULLS LAST,
+  CASE WHEN s.field='updated_at' AND s.dir='DESC' THEN w.updated_at END DESC NULLS LAST,
+  CASE WHEN s.field='priority'   AND s.dir='DESC' THEN w.priority   END DESC NULLS LAST,
+  CASE WHEN s.field='status'     AND s.dir='DESC' THEN w.status     END DESC NULLS LAST,
+  CASE WHEN s.field='title'      AND s.dir='DESC' THEN w.title      END DESC NULLS LAST,
+  w.created_at DESC, w.id DESC
+`
+
+type ExportWorkOrdersParams struct {
+	OrgID   pgtype.UUID `db:"org_id" json:"org_id"`
+	Payload []byte      `db:"payload" json:"payload"`
+}
+
+type ExportWorkOrdersRow struct {
+	ID             pgtype.UUID        `db:"id" json:"id"`
+	CustomID       pgtype.Text        `db:"custom_id" json:"custom_id"`
+	Title          string             `db:"title" json:"title"`
+	Status         string             `db:"status" json:"status"`
+	Priority       string             `db:"priority" json:"priority"`
+	DueDate        pgtype.Timestamptz `db:"due_date" json:"due_date"`
+	CreatedAt      pgtype.Timestamptz `db:"created_at" json:"created_at"`
+	CompletedOn    pgtype.Timestamptz `db:"completed_on" json:"completed_on"`
+	PrimaryWorker  pgtype.Text        `db:"primary_worker" json:"primary_worker"`
+	Assignees      string             `db:"assignees" json:"assignees"`
+	Team           pgtype.Text        `db:"team" json:"team"`
+	Location       pgtype.Text        `db:"location" json:"location"`
+	Asset          pgtype.Text        `db:"asset" json:"asset"`
+	Category       pgtype.Text        `db:"category" json:"category"`
+	TasksTotal     int64              `db:"tasks_total" json:"tasks_total"`
+	TasksCompleted int64              `db:"tasks_completed" json:"tasks_completed"`
+	SlaBreached    bool               `db:"sla_breached" json:"sla_breached"`
+}
+
+// Same filter and sort as ListWorkOrdersPaged, unpaged and flattened for
+// CSV/XLSX export. repo streams it through ExportWorkOrdersSQL, by column name.
+func (q *Queries) ExportWorkOrders(ctx context.Context, arg ExportWorkOrdersParams) ([]ExportWorkOrdersRow, error) {
+	rows, err := q.db.Query(ctx, exportWorkOrders, arg.OrgID, arg.Payload)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportWorkOrdersRow
+	for rows.Next() {
+		var i ExportWorkOrdersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CustomID,
+			&i.Title,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			&i.CreatedAt,
+			&i.CompletedOn,
+			&i.PrimaryWorker,
+			&i.Assignees,
+			&i.Team,
+			&i.Location,
+			&i.Asset,
+			&i.Category,
+			&i.TasksTotal,
+			&i.TasksCompleted,
+			&i.SlaBreached,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkOrderCompletionState = `-- name: GetWorkOrderCompletionState :one
+SELECT status, required_signature, signature_id, version
+FROM work_order
+WHERE id = $1
+  AND organisation_id = $2
+  AND deleted_at IS NULL
+`
+
+type GetWorkOrderCompletionStateParams struct {
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+type GetWorkOrderCompletionStateRow struct {
+	Status            string      `db:"status" json:"status"`
+	RequiredSignature bool        `db:"required_signature" json:"required_signature"`
+	SignatureID       pgtype.UUID `db:"signature_id" json:"signature_id"`
+	Version           int64       `db:"version" json:"version"`
+}
+
+func (q *Queries) GetWorkOrderCompletionState(ctx context.Context, arg GetWorkOrderCompletionStateParams) (GetWorkOrderCompletionStateRow, error) {
+	row := q.db.QueryRow(ctx, getWorkOrderCompletionState, arg.WorkOrderID, arg.OrganisationID)
+	var i GetWorkOrderCompletionStateRow
+	err := row.Scan(
+		&i.Status,
+		&i.RequiredSignature,
+		&i.SignatureID,
+		&i.Version,
+	)
+	return i, err
 }
 
 const getWorkOrderDetail = `-- name: GetWorkOrderDetail :one
@@ -97,6 +363,7 @@ SELECT
       'archived',                 wo.archived,
       'feedback',                 wo.feedback,
       'first_time_to_react',      wo.first_time_to_react,
+      'version',                  wo.version,
 
       -- NEW: primary worker (object with id + name)
       'primary_worker', (
@@ -156,21 +423,252 @@ SELECT
                                       JOIN files f ON f.id = wf.file_id
                                       WHERE wf.work_order_id = wo.id),
                                      '[]'::jsonb
+                                   ),
+
+      -- Labour: logged time counts finished entries only; running timers
+      -- are reported separately.
+      'time_tracking',            (SELECT jsonb_build_object(
+                                     'entries',       COUNT(*),
+                                     'running',       COUNT(*) FILTER (WHERE te.ended_at IS NULL),
+                                     'total_seconds', COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at))), 0)::bigint,
+                                     'total_hours',   ROUND(COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at))), 0) / 3600, 2),
+                                     'labour_cost',   ROUND(COALESCE(SUM(EXTRACT(EPOCH FROM (te.ended_at - te.started_at)) / 3600 * te.hourly_rate), 0), 2)
+                                   )
+                                   FROM work_order_time_entries te
+                                   WHERE te.work_order_id = wo.id),
+
+      'parts_usage',              (SELECT jsonb_build_object(
+                                     'lines',      COUNT(*),
+                                     'parts_cost', ROUND(COALESCE(SUM(wp.quantity * wp.unit_cost), 0), 2)
+                                   )
+                                   FROM work_order_parts wp
+                                   WHERE wp.work_order_id = wo.id),
+
+      -- Only present when an SLA policy applies (see work_order_sla)
+      'sla',                      (SELECT jsonb_build_object(
+                                     'policy_id',         s.policy_id,
+                                     'react_due_at',      s.react_due_at,
+                                     'complete_due_at',   s.complete_due_at,
+                                     'react_breached',    s.react_breached,
+                                     'complete_breached', s.complete_breached,
+                                     'breached',          s.breached,
+                                     'at_risk',           s.at_risk
+                                   )
+                                   FROM work_order_sla s
+                                   WHERE s.work_order_id = wo.id
+                                     AND s.policy_id IS NOT NULL),
+      -- Hierarchy: the live parent and ancestors (nearest first), the child
+      -- tree and the completion roll-up over every descendant
+      'parent',                   (SELECT jsonb_build_object('id', p.id, 'custom_id', p.custom_id, 'title', p.title, 'status', p.status)
+                                   FROM work_order p
+                                   WHERE p.id = wo.parent_work_order_id
+                                     AND p.deleted_at IS NULL),
+      'ancestors',                (WITH RECURSIVE up AS (
+                                     SELECT p.id, p.custom_id, p.title, p.status, p.parent_work_order_id, p.deleted_at, 1 AS depth
+                                     FROM work_order p
+                                     WHERE p.id = wo.parent_work_order_id
+                                     UNION ALL
+                                     SELECT p.id, p.custom_id, p.title, p.status, p.parent_work_order_id, p.deleted_at, up.depth + 1
+                                     FROM work_order p
+                                     JOIN up ON p.id = up.parent_work_order_id
+                                   )
+                                   SELECT jsonb_agg(jsonb_build_object('id', up.id, 'custom_id', up.custom_id, 'title', up.title, 'status', up.status)
+                                                    ORDER BY up.depth)
+                                   FROM up
+                                   WHERE up.deleted_at IS NULL),
+      'children',                 public.work_order_children_json(wo.id),
+      'rollup',                   public.work_order_rollup_json(wo.id),
+
+      -- Dependencies: a blocker is resolved once it is complete or cancelled;
+      -- blocked is true while any is not
+      'blocked_by',               COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object(
+                                               'id',        b.id,
+                                               'custom_id', b.custom_id,
+                                               'title',     b.title,
+                                               'status',    b.status,
+                                               'resolved',  b.status IN ('COMPLETE', 'CANCELLED')
+                                             ) ORDER BY d.created_at, b.id)
+                                      FROM work_order_dependencies d
+                                      JOIN work_order b ON b.id = d.blocked_by_id
+                                      WHERE d.work_order_id = wo.id
+                                        AND b.deleted_at IS NULL),
+                                     '[]'::jsonb
+                                   ),
+      'blocking',                 COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object('id', x.id, 'custom_id', x.custom_id, 'title', x.title, 'status', x.status)
+                                                       ORDER BY d.created_at, x.id)
+                                      FROM work_order_dependencies d
+                                      JOIN work_order x ON x.id = d.work_order_id
+                                      WHERE d.blocked_by_id = wo.id
+                                        AND x.deleted_at IS NULL),
+                                     '[]'::jsonb
+                                   ),
+      'blocked',                  EXISTS (
+                                     SELECT 1
+                                     FROM work_order_dependencies d
+                                     JOIN work_order b ON b.id = d.blocked_by_id
+                                     WHERE d.work_order_id = wo.id
+                                       AND b.deleted_at IS NULL
+                                       AND b.status NOT IN ('COMPLETE', 'CANCELLED')
+                                   ),
+
+      -- Inspection findings: the task this corrective work order came from,
+      -- and the corrective work orders spawned by this one's tasks
+      'finding',                  (SELECT jsonb_build_object(
+                                            'trigger',              f.trigger,
+                                            'finding',              f.finding,
+                                            'source_task_id',       f.source_task_id,
+                                            'source_work_order_id', f.source_work_order_id,
+                                            'source_custom_id',     s.custom_id,
+                                            'source_title',         s.title,
+                                            'created_at',           f.created_at
+                                          )
+                                   FROM work_order_findings f
+                                   LEFT JOIN work_order s ON s.id = f.source_work_order_id AND s.deleted_at IS NULL
+                                   WHERE f.work_order_id = wo.id),
+      'corrective_work_orders',   COALESCE(
+                                     (SELECT jsonb_agg(jsonb_build_object(
+                                               'id',             c.id,
+                                               'custom_id',      c.custom_id,
+                                               'title',          c.title,
+                                               'status',         c.status,
+                                               'trigger',        f.trigger,
+                                               'source_task_id', f.source_task_id
+                                             ) ORDER BY f.created_at, c.id)
+                                      FROM work_order_findings f
+                                      JOIN work_order c ON c.id = f.work_order_id
+                                      WHERE f.source_work_order_id = wo.id
+                                        AND c.deleted_at IS NULL),
+                                     '[]'::jsonb
                                    )
     )
   ) AS work_order
 FROM work_order wo
-WHERE wo.id = $1::uuid
+WHERE wo.id = $1
+  AND wo.organisation_id = $2
+  AND wo.deleted_at IS NULL
 LIMIT 1
 `
 
-func (q *Queries) GetWorkOrderDetail(ctx context.Context, dollar_1 pgtype.UUID) ([]byte, error) {
-	row := q.db.QueryRow(ctx, getWorkOrderDetail, dollar_1)
+type GetWorkOrderDetailParams struct {
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+func (q *Queries) GetWorkOrderDetail(ctx context.Context, arg GetWorkOrderDetailParams) ([]byte, error) {
+	row := q.db.QueryRow(ctx, getWorkOrderDetail, arg.WorkOrderID, arg.OrganisationID)
 	var work_order []byte
 	err := row.Scan(&work_order)
 	return work_order, err
 }
 
+const getWorkOrderStatus = `-- name: GetWorkOrderStatus :one
+
+SELECT status
+FROM work_order
+WHERE id = $1
+  AND organisation_id = $2
+  AND deleted_at IS NULL
+`
+
+type GetWorkOrderStatusParams struct {
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+// ---------------------------------------------------------------------------
+func (q *Queries) GetWorkOrderStatus(ctx context.Context, arg GetWorkOrderStatusParams) (string, error) {
+	row := q.db.QueryRow(ctx, getWorkOrderStatus, arg.WorkOrderID, arg.OrganisationID)
+	var status string
+	err := row.Scan(&status)
+	return status, err
+}
+
+const getWorkOrderVersion = `-- name: GetWorkOrderVersion :one
+SELECT version
+FROM work_order
+WHERE id = $1
+  AND organisation_id = $2
+  AND deleted_at IS NULL
+`
+
+type GetWorkOrderVersionParams struct {
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+}
+
+func (q *Queries) GetWorkOrderVersion(ctx context.Context, arg GetWorkOrderVersionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getWorkOrderVersion, arg.WorkOrderID, arg.OrganisationID)
+	var version int64
+	err := row.Scan(&version)
+	return version, err
+}
+
+const listWorkOrderStatusHistory = `-- name: ListWorkOrderStatusHistory :many
+SELECT
+  h.id,
+  h.from_status,
+  h.to_status,
+  h.reason,
+  h.changed_at,
+  h.changed_by_id,
+  u.name AS changed_by_name,
+  EXTRACT(EPOCH FROM (
+    COALESCE(LEAD(h.changed_at) OVER (ORDER BY h.changed_at, h.id), now()) - h.changed_at
+  ))::double precision AS seconds_in_status
+FROM work_order_status_history h
+JOIN work_order wo ON wo.id = h.work_order_id AND wo.organisation_id = $1 AND wo.deleted_at IS NULL
+LEFT JOIN users u ON u.id = h.changed_by_id
+WHERE h.work_order_id = $2
+ORDER BY h.changed_at, h.id
+`
+
+type ListWorkOrderStatusHistoryParams struct {
+	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+}
+
+type ListWorkOrderStatusHistoryRow struct {
+	ID              pgtype.UUID        `db:"id" json:"id"`
+	FromStatus      pgtype.Text        `db:"from_status" json:"from_status"`
+	ToStatus        string             `db:"to_status" json:"to_status"`
+	Reason          pgtype.Text        `db:"reason" json:"reason"`
+	ChangedAt       pgtype.Timestamptz `db:"changed_at" json:"changed_at"`
+	ChangedByID     pgtype.UUID        `db:"changed_by_id" json:"changed_by_id"`
+	ChangedByName   pgtype.Text        `db:"changed_by_name" json:"changed_by_name"`
+	SecondsInStatus float64            `db:"seconds_in_status" json:"seconds_in_status"`
+}
+
+func (q *Queries) ListWorkOrderStatusHistory(ctx context.Context, arg ListWorkOrderStatusHistoryParams) ([]ListWorkOrderStatusHistoryRow, error) {
+	rows, err := q.db.Query(ctx, listWorkOrderStatusHistory, arg.OrganisationID, arg.WorkOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListWorkOrderStatusHistoryRow
+	for rows.Next() {
+		var i ListWorkOrderStatusHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Reason,
+			&i.ChangedAt,
+			&i.ChangedByID,
+			&i.ChangedByName,
+			&i.SecondsInStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listWorkOrdersPaged = `-- name: ListWorkOrdersPaged :many
 WITH
 params AS (
@@ -185,31 +683,6 @@ page AS (
     COALESCE((p->>'pageSize')::int,50) AS page_size
   FROM params
 ),
-ff AS (
-  SELECT jsonb_array_elements(p->'filterFields') AS f
-  FROM params
-  WHERE (p ? 'filterFields') AND jsonb_typeof(p->'filterFields') = 'array'
-),
-status_vals AS (
-  SELECT COALESCE(array_agg(v), ARRAY[]::text[]) AS vals
-  FROM (
-    SELECT jsonb_array_elements_text(f->'values') AS v
-    FROM ff
-    WHERE f->>'field' = 'status' AND COALESCE(f->>'operation','') = 'in'
-  ) s
-),
-archived_eq AS (
-  SELECT (f->>'value')::boolean AS archived
-  FROM ff
-  WHERE f->>'field' = 'archived' AND COALESCE(f->>'operation','') IN ('eq','equals')
-  LIMIT 1
-),
-text_cn AS (
-  SELECT NULLIF(btrim(f->>'value'), '') AS term
-  FROM ff
-  WHERE f->>'field' = 'text' AND COALESCE(f->>'operation','') IN ('cn','contains','like')
-  LIMIT 1
-),
 sort AS (
   SELECT
     lower(NULLIF(p->>'sortField',''))     AS field,
@@ -217,34 +690,21 @@ sort AS (
   FROM params
 ),
 filtered AS (
-  SELECT w.id, w.organisation_id, w.created_at, w.updated_at, w.created_by_id, w.due_date, w.priority, w.estimated_duration, w.estimated_start_date, w.description, w.title, w.required_signature, w.image_id, w.category_id, w.location_id, w.team_id, w.primary_user_id, w.asset_id, w.custom_id, w.completed_by_id, w.completed_on, w.status, w.signature_id, w.archived, w.parent_request_id, w.feedback, w.parent_preventive_maint_id, w.first_time_to_react
+  SELECT
+    w.id, w.organisation_id, w.created_at, w.updated_at, w.created_by_id, w.due_date, w.priority, w.estimated_duration, w.estimated_start_date, w.description, w.title, w.required_signature, w.image_id, w.category_id, w.location_id, w.team_id, w.primary_user_id, w.asset_id, w.custom_id, w.completed_by_id, w.completed_on, w.status, w.signature_id, w.archived, w.parent_request_id, w.feedback, w.parent_preventive_maint_id, w.first_time_to_react, w.version, w.deleted_at, w.deleted_by_id, w.parent_work_order_id,
+    COALESCE(sla.breached, false) AS sla_breached,
+    COALESCE(sla.at_risk, false)  AS sla_at_risk
   FROM work_order w
-  LEFT JOIN status_vals sv ON TRUE
-  LEFT JOIN archived_eq  a  ON TRUE
-  LEFT JOIN text_cn      t  ON TRUE
-  WHERE
-    (w.organisation_id = (SELECT org_id FROM params))
-    AND (sv.vals = '{}'::text[] OR w.status = ANY (sv.vals))
-    AND (a.archived IS NULL OR w.archived = a.archived)
-    AND (
-      t.term IS NULL
-      OR (
-        w.title ILIKE (
-          '%' ||
-          replace(replace(replace(t.term, E'\\', E'\\\\'), '%', E'\\%'), '_', E'\\_')
-          || '%'
-        ) ESCAPE E'\\'
-        OR w.description ILIKE (
-          '%' ||
-          replace(replace(replace(t.term, E'\\', E'\\\\'), '%', E'\\%'), '_', E'\\_')
-          || '%'
-        ) ESCAPE E'\\'
-      )
+  JOIN params pr ON pr.org_id = w.organisation_id
+  LEFT JOIN work_order_sla sla ON sla.work_order_id = w.id
+  WHERE w.deleted_at IS NULL
+    AND public.work_order_matches_filter(
+      w, COALESCE(sla.breached, false), COALESCE(sla.at_risk, false), pr.p->'filter'
     )
 ),
 ordered AS (
   SELECT
-    f.id, f.organisation_id, f.created_at, f.updated_at, f.created_by_id, f.due_date, f.priority, f.estimated_duration, f.estimated_start_date, f.description, f.title, f.required_signature, f.image_id, f.category_id, f.location_id, f.team_id, f.primary_user_id, f.asset_id, f.custom_id, f.completed_by_id, f.completed_on, f.status, f.signature_id, f.archived, f.parent_request_id, f.feedback, f.parent_preventive_maint_id, f.first_time_to_react,
+    f.id, f.organisation_id, f.created_at, f.updated_at, f.created_by_id, f.due_date, f.priority, f.estimated_duration, f.estimated_start_date, f.description, f.title, f.required_signature, f.image_id, f.category_id, f.location_id, f.team_id, f.primary_user_id, f.asset_id, f.custom_id, f.completed_by_id, f.completed_on, f.status, f.signature_id, f.archived, f.parent_request_id, f.feedback, f.parent_preventive_maint_id, f.first_time_to_react, f.version, f.deleted_at, f.deleted_by_id, f.parent_work_order_id, f.sla_breached, f.sla_at_risk,
     COUNT(*) OVER()::bigint AS total_rows,
     ROW_NUMBER() OVER (
       ORDER BY
@@ -277,13 +737,63 @@ page_bounds AS (
     (page_num * page_size)             AS off,
     (page_num * page_size + page_size) AS lim
   FROM page
+),
+/* keyset cursor: the sort key, created_at and id of the last row seen */
+seek AS (
+  SELECT
+    s.field IN ('custom_id','priority','status','title')
+      AND s.dir IN ('ASC','DESC')                   AS txt_sort,
+    s.field IN ('due_date','created_at','updated_at')
+      AND s.dir IN ('ASC','DESC')                   AS ts_sort,
+    p->'after'->>'key'                              AS key,
+    (p->'after'->>'createdAt')::timestamptz         AS created_at,
+    (p->'after'->>'id')::uuid                       AS id
+  FROM params
+  CROSS JOIN sort s
 )
 SELECT
-  o.id, o.organisation_id, o.created_at, o.updated_at, o.created_by_id, o.due_date, o.priority, o.estimated_duration, o.estimated_start_date, o.description, o.title, o.required_signature, o.image_id, o.category_id, o.location_id, o.team_id, o.primary_user_id, o.asset_id, o.custom_id, o.completed_by_id, o.completed_on, o.status, o.signature_id, o.archived, o.parent_request_id, o.feedback, o.parent_preventive_maint_id, o.first_time_to_react, o.total_rows, o.rn
+  o.id, o.organisation_id, o.created_at, o.updated_at, o.created_by_id, o.due_date, o.priority, o.estimated_duration, o.estimated_start_date, o.description, o.title, o.required_signature, o.image_id, o.category_id, o.location_id, o.team_id, o.primary_user_id, o.asset_id, o.custom_id, o.completed_by_id, o.completed_on, o.status, o.signature_id, o.archived, o.parent_request_id, o.feedback, o.parent_preventive_maint_id, o.first_time_to_react, o.version, o.deleted_at, o.deleted_by_id, o.parent_work_order_id, o.sla_breached, o.sla_at_risk, o.total_rows, o.rn
 FROM ordered o
 JOIN page_bounds b ON TRUE
-WHERE o.rn > b.off AND o.rn <= b.lim
+CROSS JOIN sort s
+CROSS JOIN seek a
+CROSS JOIN LATERAL (
+  SELECT
+    CASE WHEN a.txt_sort THEN CASE s.field
+      WHEN 'custom_id' THEN o.custom_id
+      WHEN 'priority'  THEN o.priority
+      WHEN 'status'    THEN o.status
+      WHEN 'title'     THEN o.title
+    END END AS row_txt,
+    CASE WHEN a.ts_sort THEN CASE s.field
+      WHEN 'due_date'   THEN o.due_date
+      WHEN 'created_at' THEN o.created_at
+      WHEN 'updated_at' THEN o.updated_at
+    END END AS row_ts,
+    CASE WHEN a.txt_sort THEN a.key END              AS after_txt,
+    CASE WHEN a.ts_sort  THEN a.key::timestamptz END AS after_ts
+) k
+WHERE CASE
+  WHEN a.id IS NULL THEN o.rn > b.off AND o.rn <= b.lim
+  ELSE
+    /* rows whose sort key comes after the cursor's (NULLS LAST) ... */
+    (
+      (k.after_txt IS NOT NULL OR k.after_ts IS NOT NULL)
+      AND (
+        (k.row_txt IS NULL AND k.row_ts IS NULL)
+        OR (s.dir = 'ASC'  AND (k.row_txt > k.after_txt OR k.row_ts > k.after_ts))
+        OR (s.dir = 'DESC' AND (k.row_txt < k.after_txt OR k.row_ts < k.after_ts))
+      )
+    )
+    /* ... or ties it and follow on the created_at DESC, id DESC fallback */
+    OR (
+      k.row_txt IS NOT DISTINCT FROM k.after_txt
+      AND k.row_ts IS NOT DISTINCT FROM k.after_ts
+      AND (o.created_at, o.id) < (a.created_at, a.id)
+    )
+END
 ORDER BY o.rn
+LIMIT (SELECT page_size FROM page)
 `
 
 type ListWorkOrdersPagedParams struct {
@@ -320,11 +830,18 @@ type ListWorkOrdersPagedRow struct {
 	Feedback                pgtype.Text        `db:"feedback" json:"feedback"`
 	ParentPreventiveMaintID pgtype.UUID        `db:"parent_preventive_maint_id" json:"parent_preventive_maint_id"`
 	FirstTimeToReact        pgtype.Timestamptz `db:"first_time_to_react" json:"first_time_to_react"`
+	Version                 int64              `db:"version" json:"version"`
+	DeletedAt               pgtype.Timestamptz `db:"deleted_at" json:"deleted_at"`
+	DeletedByID             pgtype.UUID        `db:"deleted_by_id" json:"deleted_by_id"`
+	ParentWorkOrderID       pgtype.UUID        `db:"parent_work_order_id" json:"parent_work_order_id"`
+	SlaBreached             bool               `db:"sla_breached" json:"sla_breached"`
+	SlaAtRisk               bool               `db:"sla_at_risk" json:"sla_at_risk"`
 	TotalRows               int64              `db:"total_rows" json:"total_rows"`
 	Rn                      int64              `db:"rn" json:"rn"`
 }
 
-// generic text search (title + description)
+// payload: {"pageNum", "pageSize", "sortField", "direction", "filter"}; the
+// filter tree is evaluated by work_order_matches_filter() (see 019).
 // NEW: sort options (whitelisted later)
 func (q *Queries) ListWorkOrdersPaged(ctx context.Context, arg ListWorkOrdersPagedParams) ([]ListWorkOrdersPagedRow, error) {
 	rows, err := q.db.Query(ctx, listWorkOrdersPaged, arg.OrgID, arg.Payload)
@@ -364,6 +881,12 @@ func (q *Queries) ListWorkOrdersPaged(ctx context.Context, arg ListWorkOrdersPag
 			&i.Feedback,
 			&i.ParentPreventiveMaintID,
 			&i.FirstTimeToReact,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedByID,
+			&i.ParentWorkOrderID,
+			&i.SlaBreached,
+			&i.SlaAtRisk,
 			&i.TotalRows,
 			&i.Rn,
 		); err != nil {
@@ -378,19 +901,21 @@ func (q *Queries) ListWorkOrdersPaged(ctx context.Context, arg ListWorkOrdersPag
 }
 
 const updateWorkOrderFromJSON = `-- name: UpdateWorkOrderFromJSON :one
-SELECT public.update_work_order_from_json(
+SELECT public.update_work_order_from_json_if_match(
   $1::uuid,
   $2::uuid,
   $3::jsonb,
-  $4::uuid
+  $4::uuid,
+  $5::bigint
 )::uuid AS id
 `
 
 type UpdateWorkOrderFromJSONParams struct {
-	OrganisationID pgtype.UUID `db:"organisation_id" json:"organisation_id"`
-	WorkOrderID    pgtype.UUID `db:"work_order_id" json:"work_order_id"`
-	Payload        []byte      `db:"payload" json:"payload"`
-	UpdatedByID    pgtype.UUID `db:"updated_by_id" json:"updated_by_id"`
+	OrganisationID  pgtype.UUID `db:"organisation_id" json:"organisation_id"`
+	WorkOrderID     pgtype.UUID `db:"work_order_id" json:"work_order_id"`
+	Payload         []byte      `db:"payload" json:"payload"`
+	UpdatedByID     pgtype.UUID `db:"updated_by_id" json:"updated_by_id"`
+	ExpectedVersion pgtype.Int8 `db:"expected_version" json:"expected_version"`
 }
 
 func (q *Queries) UpdateWorkOrderFromJSON(ctx context.Context, arg UpdateWorkOrderFromJSONParams) (pgtype.UUID, error) {
@@ -399,6 +924,7 @@ func (q *Queries) UpdateWorkOrderFromJSON(ctx context.Context, arg UpdateWorkOrd
 		arg.WorkOrderID,
 		arg.Payload,
 		arg.UpdatedByID,
+		arg.ExpectedVersion,
 	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
@@ -0,0 +1,203 @@
+// internal/handlers/categories/categories.go
+package categories
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	repo repo.Repo
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+// categoryErrorStatus maps repo/model errors to an HTTP status.
+func categoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrCategoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrCategoryNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrTeamNotFound), errors.Is(err, models.ErrTaskBaseNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrCategoryNameRequired), errors.Is(err, models.ErrCategoryNameTooLong),
+		errors.Is(err, models.ErrUnknownPriority), errors.Is(err, models.ErrNegativeDuration):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (models.WorkOrderCategoryInput, error) {
+	defer r.Body.Close()
+	var in models.WorkOrderCategoryInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, errors.New("invalid JSON: " + err.Error())
+	}
+	return in, nil
+}
+
+// GET /work-order-categories
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	cats, err := h.repo.ListWorkOrderCategories(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch categories"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": cats,
+	})
+}
+
+// GET /work-order-categories/{categoryID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+	cat, err := h.repo.GetWorkOrderCategory(r.Context(), orgID, id)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		msg := err.Error()
+		if status == http.StatusInternalServerError {
+			msg = "failed to fetch category"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, cat)
+}
+
+// POST /work-order-categories
+//
+//	{
+//	  "name": "Corrective",
+//	  "description": "Break/fix work",
+//	  "default_priority": "HIGH",
+//	  "default_estimated_duration": 2,
+//	  "default_team_id": "team-uuid-here",
+//	  "task_template": ["task-base-uuid", "task-base-uuid"]
+//	}
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		httpserver.JSON(w, categoryErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	cat, err := h.repo.CreateWorkOrderCategory(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		msg := err.Error()
+		if status == http.StatusInternalServerError {
+			msg = "failed to create category"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, cat)
+}
+
+// PUT /work-order-categories/{categoryID}
+//
+// Full replace: omitted defaults are cleared and the task template is
+// replaced by the one sent (an empty or missing list removes it).
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		httpserver.JSON(w, categoryErrorStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	cat, err := h.repo.UpdateWorkOrderCategory(r.Context(), orgID, id, in)
+	if err != nil {
+		status := categoryErrorStatus(err)
+		msg := err.Error()
+		if status == http.StatusInternalServerError {
+			msg = "failed to update category"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, cat)
+}
+
+// DELETE /work-order-categories/{categoryID}
+//
+// Work orders in the category are kept and become uncategorised.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "categoryID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid category ID"})
+		return
+	}
+	if err := h.repo.DeleteWorkOrderCategory(r.Context(), orgID, id); err != nil {
+		status := categoryErrorStatus(err)
+		msg := err.Error()
+		if status == http.StatusInternalServerError {
+			msg = "failed to delete category"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "category deleted",
+		"id":      id,
+	})
+}
@@ -0,0 +1,190 @@
+// internal/handlers/checklists/checklists.go
+package checklists
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	repo repo.Repo
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+// policyErrorStatus maps repo/model errors to an HTTP status.
+func policyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrChecklistPolicyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrChecklistPolicyExists):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrCategoryNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrChecklistRuleRequired):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := policyErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (models.ChecklistPolicyInput, error) {
+	defer r.Body.Close()
+	var in models.ChecklistPolicyInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, errors.New("invalid JSON: " + err.Error())
+	}
+	return in, nil
+}
+
+// GET /checklist-policies
+//
+// Category policies first, then the org default.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	policies, err := h.repo.ListChecklistPolicies(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch checklist policies"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": policies,
+	})
+}
+
+// GET /checklist-policies/{policyID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid checklist policy ID"})
+		return
+	}
+	pol, err := h.repo.GetChecklistPolicy(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch checklist policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, pol)
+}
+
+// POST /checklist-policies
+//
+//	{
+//	  "category_id": "uuid",             // optional, omit for the org default
+//	  "require_tasks_complete": true,    // optional, default true
+//	  "require_failure_notes": true      // optional, default true
+//	}
+//
+// Once a policy applies, completing a work order that breaks it is refused
+// with 409 and the blocking tasks.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid checklist policy")
+		return
+	}
+	pol, err := h.repo.CreateChecklistPolicy(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create checklist policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, pol)
+}
+
+// PUT /checklist-policies/{policyID}
+//
+// Full replace; same body as create.
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid checklist policy ID"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid checklist policy")
+		return
+	}
+	pol, err := h.repo.UpdateChecklistPolicy(r.Context(), orgID, id, in)
+	if err != nil {
+		writeError(w, err, "failed to update checklist policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, pol)
+}
+
+// DELETE /checklist-policies/{policyID}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid checklist policy ID"})
+		return
+	}
+	if err := h.repo.DeleteChecklistPolicy(r.Context(), orgID, id); err != nil {
+		writeError(w, err, "failed to delete checklist policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "checklist policy deleted",
+		"id":      id,
+	})
+}
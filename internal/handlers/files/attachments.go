@@ -0,0 +1,229 @@
+// internal/handlers/files/attachments.go
+package files
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// attachTarget abstracts the work order / task specific parts of the
+// attachment endpoints.
+type attachTarget struct {
+	param   string
+	invalid string
+	exists  func(ctx context.Context, orgID, id uuid.UUID) error
+	attach  func(ctx context.Context, orgID, id, fileID uuid.UUID) error
+	detach  func(ctx context.Context, orgID, id, fileID uuid.UUID) error
+	list    func(ctx context.Context, orgID, id uuid.UUID) ([]models.File, error)
+}
+
+func (h *Handler) workOrderTarget() attachTarget {
+	return attachTarget{
+		param:   "workOrderID",
+		invalid: "invalid work order ID",
+		exists: func(ctx context.Context, orgID, id uuid.UUID) error {
+			_, err := h.repo.GetWorkOrderStatus(ctx, orgID, id)
+			return err
+		},
+		attach: h.repo.AttachFileToWorkOrder,
+		detach: h.repo.DetachFileFromWorkOrder,
+		list:   h.repo.ListWorkOrderFiles,
+	}
+}
+
+func (h *Handler) taskTarget() attachTarget {
+	return attachTarget{
+		param:   "taskID",
+		invalid: "invalid task ID",
+		exists: func(ctx context.Context, orgID, id uuid.UUID) error {
+			ok, err := h.repo.TaskExists(ctx, orgID, id)
+			if err == nil && !ok {
+				err = models.ErrTaskNotFound
+			}
+			return err
+		},
+		attach: h.repo.AttachFileToTask,
+		detach: h.repo.DetachFileFromTask,
+		list:   h.repo.ListTaskFiles,
+	}
+}
+
+// AttachRequest attaches files that were already uploaded via POST /files.
+type AttachRequest struct {
+	FileIDs []uuid.UUID `json:"file_ids"`
+}
+
+func (h *Handler) listAttachments(t attachTarget, w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, t.param))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": t.invalid})
+		return
+	}
+	if err := t.exists(r.Context(), orgID, id); err != nil {
+		status, msg := uploadErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	files, err := t.list(r.Context(), orgID, id)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch files"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      id,
+		"content": withURLs(files),
+	})
+}
+
+// addAttachments accepts either a multipart upload (new files) or a JSON
+// AttachRequest (existing files) and links the files to the target.
+func (h *Handler) addAttachments(t attachTarget, w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, t.param))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": t.invalid})
+		return
+	}
+	if !h.canWrite(r, orgID, user.ID) {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	if err := t.exists(r.Context(), orgID, id); err != nil {
+		status, msg := uploadErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	var files []models.File
+	uploaded := false
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "application/json" {
+		var req AttachRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.FileIDs) == 0 {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "file_ids is required"})
+			return
+		}
+		for _, fid := range req.FileIDs {
+			f, err := h.repo.GetFile(r.Context(), orgID, fid)
+			if err != nil {
+				status, msg := uploadErrorStatus(err)
+				httpserver.JSON(w, status, map[string]string{"error": msg + ": " + fid.String()})
+				return
+			}
+			files = append(files, f)
+		}
+	} else {
+		files, err = h.receive(w, r, orgID, user.ID)
+		if err != nil {
+			writeUploadError(w, err)
+			return
+		}
+		uploaded = true
+	}
+
+	for _, f := range files {
+		if err := t.attach(r.Context(), orgID, id, f.ID); err != nil {
+			if uploaded {
+				for _, u := range files {
+					_ = h.up.Remove(r.Context(), orgID, u.ID)
+				}
+			}
+			httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to attach files"})
+			return
+		}
+	}
+	httpserver.JSON(w, http.StatusCreated, map[string]any{
+		"id":      id,
+		"content": withURLs(files),
+	})
+}
+
+func (h *Handler) removeAttachment(t attachTarget, w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, t.param))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": t.invalid})
+		return
+	}
+	fileID, err := uuid.Parse(chi.URLParam(r, "fileID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+		return
+	}
+	if !h.canWrite(r, orgID, user.ID) {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	if err := t.detach(r.Context(), orgID, id, fileID); err != nil {
+		status, msg := uploadErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			msg = "failed to detach file"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "file detached",
+		"id":      fileID,
+	})
+}
+
+// GET /work-orders/{workOrderID}/files
+func (h *Handler) ListWorkOrderFiles(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(h.workOrderTarget(), w, r)
+}
+
+// POST /work-orders/{workOrderID}/files
+func (h *Handler) AddWorkOrderFiles(w http.ResponseWriter, r *http.Request) {
+	h.addAttachments(h.workOrderTarget(), w, r)
+}
+
+// DELETE /work-orders/{workOrderID}/files/{fileID}
+func (h *Handler) RemoveWorkOrderFile(w http.ResponseWriter, r *http.Request) {
+	h.removeAttachment(h.workOrderTarget(), w, r)
+}
+
+// GET /tasks/{taskID}/files
+func (h *Handler) ListTaskFiles(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(h.taskTarget(), w, r)
+}
+
+// POST /tasks/{taskID}/files
+func (h *Handler) AddTaskFiles(w http.ResponseWriter, r *http.Request) {
+	h.addAttachments(h.taskTarget(), w, r)
+}
+
+// DELETE /tasks/{taskID}/files/{fileID}
+func (h *Handler) RemoveTaskFile(w http.ResponseWriter, r *http.Request) {
+	h.removeAttachment(h.taskTarget(), w, r)
+}
@@ -0,0 +1,337 @@
+// internal/handlers/files/files.go
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+	"yourapp/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxFilesPerRequest caps how many parts one multipart upload may carry.
+const maxFilesPerRequest = 10
+
+type Handler struct {
+	repo repo.Repo
+	up   *Uploader
+}
+
+func New(repo repo.Repo, up *Uploader) *Handler {
+	return &Handler{repo: repo, up: up}
+}
+
+// uploadErrorStatus maps upload/storage errors to a response.
+func uploadErrorStatus(err error) (int, string) {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, models.ErrFileTooLarge), errors.As(err, &maxErr):
+		return http.StatusRequestEntityTooLarge, models.ErrFileTooLarge.Error()
+	case errors.Is(err, models.ErrUnsupportedFileType):
+		return http.StatusUnsupportedMediaType, err.Error()
+	case errors.Is(err, models.ErrEmptyFile):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, models.ErrStorageQuotaExceeded):
+		return http.StatusInsufficientStorage, err.Error()
+	case errors.Is(err, models.ErrFileNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrTaskNotFound):
+		return http.StatusNotFound, err.Error()
+	default:
+		return http.StatusInternalServerError, "failed to store file"
+	}
+}
+
+// canWrite rejects viewers; everyone else in the org may upload.
+func (h *Handler) canWrite(r *http.Request, orgID, userID uuid.UUID) bool {
+	role, err := h.repo.GetRole(r.Context(), orgID, userID)
+	return err == nil && role != models.RoleViewer
+}
+
+// receive stores every file part of a multipart/form-data request (field
+// "file" or "files"). On any failure the files already stored by this
+// request are removed again, so uploads are all-or-nothing.
+func (h *Handler) receive(w http.ResponseWriter, r *http.Request, orgID, userID uuid.UUID) ([]models.File, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.up.limits.MaxUploadBytes*maxFilesPerRequest+1<<20)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errBadMultipart
+	}
+
+	var saved []models.File
+	fail := func(err error) ([]models.File, error) {
+		for _, f := range saved {
+			if rerr := h.up.Remove(r.Context(), orgID, f.ID); rerr != nil {
+				slog.WarnContext(r.Context(), "failed to roll back upload", "file_id", f.ID, "err", rerr)
+			}
+		}
+		return nil, err
+	}
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+		if part.FileName() == "" || (part.FormName() != "file" && part.FormName() != "files") {
+			part.Close()
+			continue
+		}
+		if len(saved) == maxFilesPerRequest {
+			part.Close()
+			return fail(errTooManyFiles)
+		}
+		f, err := h.up.Save(r.Context(), orgID, userID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			return fail(err)
+		}
+		saved = append(saved, f)
+	}
+	if len(saved) == 0 {
+		return nil, errNoFiles
+	}
+	return saved, nil
+}
+
+var (
+	errBadMultipart = errors.New("expected multipart/form-data body")
+	errTooManyFiles = errors.New("too many files in one request (max " + strconv.Itoa(maxFilesPerRequest) + ")")
+	errNoFiles      = errors.New(`no file parts found (use field "file" or "files")`)
+)
+
+// writeUploadError renders receive/Save errors.
+func writeUploadError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errBadMultipart) || errors.Is(err, errTooManyFiles) || errors.Is(err, errNoFiles) {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	status, msg := uploadErrorStatus(err)
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func withURLs(fs []models.File) []models.File {
+	for i := range fs {
+		fs[i].DownloadURL = DownloadURL(fs[i].ID)
+	}
+	return fs
+}
+
+// POST /files
+//
+// Uploads files without attaching them (e.g. to reference later as a work
+// order image). Returns {"content": [file...]}.
+func (h *Handler) Upload(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if !h.canWrite(r, orgID, user.ID) {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	saved, err := h.receive(w, r, orgID, user.ID)
+	if err != nil {
+		writeUploadError(w, err)
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, map[string]any{"content": saved})
+}
+
+// GET /files/{fileID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	fileID, err := uuid.Parse(chi.URLParam(r, "fileID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+		return
+	}
+	f, err := h.repo.GetFile(r.Context(), orgID, fileID)
+	if err != nil {
+		status, msg := uploadErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	f.DownloadURL = DownloadURL(f.ID)
+	httpserver.JSON(w, http.StatusOK, f)
+}
+
+// GET /files/{fileID}/content
+//
+// Streams the file to an authenticated member of the owning org. Images,
+// video and PDFs are served inline unless ?download=1 is given.
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	fileID, err := uuid.Parse(chi.URLParam(r, "fileID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+		return
+	}
+	f, err := h.repo.GetFile(r.Context(), orgID, fileID)
+	if err != nil {
+		status, msg := uploadErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	if f.StorageBackend != h.up.store.Name() {
+		slog.ErrorContext(r.Context(), "file stored on unavailable backend", "file_id", f.ID, "backend", f.StorageBackend)
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "file is not available"})
+		return
+	}
+	rc, err := h.up.store.Open(r.Context(), f.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": "file content missing"})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to read file"})
+		return
+	}
+	defer rc.Close()
+
+	disposition := "attachment"
+	if models.IsInlineContentType(f.ContentType) && r.URL.Query().Get("download") == "" {
+		disposition = "inline"
+	}
+	etag := `"` + f.SHA256 + `"`
+	w.Header().Set("Content-Type", f.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(f.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": f.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if f.SHA256 != "" {
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		slog.WarnContext(r.Context(), "file download interrupted", "file_id", f.ID, "err", err)
+	}
+}
+
+// DELETE /files/{fileID}
+//
+// The uploader or an admin/owner may delete a file; it disappears from every
+// work order and task it was attached to.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	fileID, err := uuid.Parse(chi.URLParam(r, "fileID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid file ID"})
+		return
+	}
+	f, err := h.repo.GetFile(r.Context(), orgID, fileID)
+	if err != nil {
+		status, msg := uploadErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	isOwner := f.UploadedByID != nil && *f.UploadedByID == user.ID
+	if err != nil || (!isOwner && role != models.RoleAdmin && role != models.RoleOwner) {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	if err := h.up.Remove(r.Context(), orgID, fileID); err != nil {
+		status, msg := uploadErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			msg = "failed to delete file"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "file deleted",
+		"id":      fileID,
+	})
+}
+
+// GET /files/usage
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	usage, err := h.repo.GetStorageUsage(r.Context(), orgID, h.up.limits.DefaultOrgQuotaBytes)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch storage usage"})
+		return
+	}
+	usage.MaxUploadBytes = h.up.limits.MaxUploadBytes
+	httpserver.JSON(w, http.StatusOK, usage)
+}
+
+// QuotaRequest is the body for PUT /files/quota.
+type QuotaRequest struct {
+	QuotaBytes int64 `json:"quota_bytes"`
+}
+
+// PUT /files/quota (owner only, capped at storage.max_org_quota_bytes)
+func (h *Handler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var req QuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.QuotaBytes < 0 {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "quota_bytes must be >= 0"})
+		return
+	}
+	if ceiling := h.up.limits.maxOrgQuota(); req.QuotaBytes > ceiling {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("quota_bytes must be <= %d", ceiling)})
+		return
+	}
+	if err := h.repo.SetStorageQuota(r.Context(), orgID, req.QuotaBytes); err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to set quota"})
+		return
+	}
+	h.Usage(w, r)
+}
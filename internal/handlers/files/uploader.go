@@ -0,0 +1,149 @@
+// internal/handlers/files/uploader.go
+package files
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+	"yourapp/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const maxFilenameLength = 255
+
+// Limits bounds what a single upload and an organisation may consume.
+// MaxOrgQuotaBytes is the highest quota an owner may set for their org; 0
+// caps overrides at DefaultOrgQuotaBytes.
+type Limits struct {
+	MaxUploadBytes       int64
+	DefaultOrgQuotaBytes int64
+	MaxOrgQuotaBytes     int64
+}
+
+// maxOrgQuota is the ceiling for PUT /files/quota.
+func (l Limits) maxOrgQuota() int64 {
+	if l.MaxOrgQuotaBytes > 0 {
+		return l.MaxOrgQuotaBytes
+	}
+	return l.DefaultOrgQuotaBytes
+}
+
+// Uploader sniffs, size-checks and stores incoming blobs and records them in
+// the files table. Other handlers (e.g. work order completion) use it to
+// persist generated files such as signatures.
+type Uploader struct {
+	repo   repo.Repo
+	store  storage.Store
+	limits Limits
+}
+
+func NewUploader(r repo.Repo, store storage.Store, limits Limits) *Uploader {
+	return &Uploader{repo: r, store: store, limits: limits}
+}
+
+// MaxUploadBytes is the per-file size limit.
+func (u *Uploader) MaxUploadBytes() int64 { return u.limits.MaxUploadBytes }
+
+// DownloadURL is the authenticated endpoint that streams a file's content.
+func DownloadURL(id uuid.UUID) string {
+	return "/files/" + id.String() + "/content"
+}
+
+// Save streams body into storage under a fresh key and records it for org.
+// The content type is sniffed from the bytes; the client's claim is ignored.
+func (u *Uploader) Save(ctx context.Context, orgID, userID uuid.UUID, filename string, body io.Reader) (models.File, error) {
+	br := bufio.NewReaderSize(body, 512)
+	head, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return models.File{}, err
+	}
+	if len(head) == 0 {
+		return models.File{}, models.ErrEmptyFile
+	}
+	contentType := models.NormalizeContentType(http.DetectContentType(head))
+	if !models.AllowedUploadType(contentType) {
+		return models.File{}, models.ErrUnsupportedFileType
+	}
+
+	now := time.Now().UTC()
+	key := path.Join(orgID.String(), now.Format("2006"), now.Format("01"), uuid.NewString())
+
+	hash := sha256.New()
+	limited := io.LimitReader(br, u.limits.MaxUploadBytes+1)
+	n, err := u.store.Put(ctx, key, io.TeeReader(limited, hash))
+	if err != nil {
+		u.discard(ctx, key)
+		return models.File{}, err
+	}
+	if n > u.limits.MaxUploadBytes {
+		u.discard(ctx, key)
+		return models.File{}, models.ErrFileTooLarge
+	}
+
+	f, err := u.repo.CreateFile(ctx, orgID, userID, models.NewFile{
+		Filename:       cleanFilename(filename),
+		ContentType:    contentType,
+		SizeBytes:      n,
+		SHA256:         hex.EncodeToString(hash.Sum(nil)),
+		StorageKey:     key,
+		StorageBackend: u.store.Name(),
+	}, u.limits.DefaultOrgQuotaBytes)
+	if err != nil {
+		u.discard(ctx, key)
+		return models.File{}, err
+	}
+	f.DownloadURL = DownloadURL(f.ID)
+	return f, nil
+}
+
+// Remove deletes the row and then the blob. A blob that cannot be removed is
+// logged and left behind rather than failing the request.
+func (u *Uploader) Remove(ctx context.Context, orgID, fileID uuid.UUID) error {
+	f, err := u.repo.DeleteFile(ctx, orgID, fileID)
+	if err != nil {
+		return err
+	}
+	if f.StorageBackend == u.store.Name() {
+		u.discard(ctx, f.StorageKey)
+	}
+	return nil
+}
+
+func (u *Uploader) discard(ctx context.Context, key string) {
+	if err := u.store.Delete(context.WithoutCancel(ctx), key); err != nil {
+		slog.WarnContext(ctx, "failed to remove stored object", "key", key, "err", err)
+	}
+}
+
+// cleanFilename keeps only the base name a client sent, without control
+// characters, capped to a sane length.
+func cleanFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(strings.TrimSpace(name))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "." || name == "/" || name == "" {
+		name = "upload"
+	}
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
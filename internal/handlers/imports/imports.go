@@ -0,0 +1,225 @@
+// internal/handlers/imports/imports.go
+package imports
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxConcurrentImports bounds the import jobs running in this process; later
+// uploads wait QUEUED for a slot.
+const maxConcurrentImports = 2
+
+// importTimeout bounds one job, queueing included.
+const importTimeout = 30 * time.Minute
+
+type Handler struct {
+	repo  repo.Repo
+	slots chan struct{}
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo, slots: make(chan struct{}, maxConcurrentImports)}
+}
+
+func importErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrImportJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrUnknownImportKind), errors.Is(err, models.ErrImportEmpty),
+		errors.Is(err, models.ErrImportInvalidCSV), errors.Is(err, models.ErrImportHeader),
+		errors.Is(err, models.ErrImportMissingColumn):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrImportTooManyRows):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := importErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+// readUpload returns the CSV stream and its file name from either a
+// multipart form (field "file") or a raw text/csv body.
+func readUpload(w http.ResponseWriter, r *http.Request) (io.Reader, string, func(), error) {
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxImportBytes+1<<20)
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "multipart/form-data" {
+		return r.Body, "", func() { r.Body.Close() }, nil
+	}
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", nil, errors.New("invalid multipart body")
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, "", nil, errors.New(`no "file" part in the form`)
+		}
+		if err != nil {
+			return nil, "", nil, errors.New("invalid multipart body")
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), func() { part.Close() }, nil
+		}
+		part.Close()
+	}
+}
+
+// POST /imports?kind=work_orders|assets|locations&dry_run=true
+//
+// Body: the CSV, either raw (Content-Type: text/csv) or as the "file" field
+// of a multipart form. The header row names the columns (GET
+// /imports/template?kind= gives an empty file with all of them). The file
+// is checked up front (400 for a bad header); rows are then processed in
+// the background. Returns 202 with the job; poll GET /imports/{jobID}.
+//
+// All rows are written in one transaction: if any row fails nothing is
+// written and the job lists every row error. dry_run=true validates the
+// same way and always rolls back.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	kind, err := models.ParseImportKind(r.URL.Query().Get("kind"))
+	if err != nil {
+		writeError(w, err, "invalid import")
+		return
+	}
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	body, filename, done, err := readUpload(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	file, err := models.ParseImportCSV(kind, body)
+	done()
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			httpserver.JSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "file is too large"})
+			return
+		}
+		writeError(w, err, "invalid import file")
+		return
+	}
+
+	job, err := h.repo.CreateImportJob(r.Context(), orgID, user.ID, file, filename, dryRun)
+	if err != nil {
+		writeError(w, err, "failed to create import job")
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), importTimeout)
+		defer cancel()
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-ctx.Done():
+			slog.ErrorContext(ctx, "import job never started", "job_id", job.ID.String())
+			_ = h.repo.FailImportJob(ctx, orgID, job.ID, "timed out waiting for a free import worker; nothing was written")
+			return
+		}
+		if err := h.repo.RunImportJob(ctx, orgID, user.ID, job.ID, file, dryRun); err != nil {
+			slog.ErrorContext(ctx, "import job failed", "job_id", job.ID.String(), "err", err)
+		}
+	}()
+
+	w.Header().Set("Location", "/imports/"+job.ID.String())
+	httpserver.JSON(w, http.StatusAccepted, job)
+}
+
+// GET /imports
+//
+// The organisation's 50 most recent jobs, without row errors.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	jobs, err := h.repo.ListImportJobs(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch import jobs"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": jobs,
+	})
+}
+
+// GET /imports/{jobID}
+//
+// Status, progress (processed_rows of total_rows) and, once finished, the
+// row errors.
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "jobID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid import job ID"})
+		return
+	}
+	job, err := h.repo.GetImportJob(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch import job")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, job)
+}
+
+// GET /imports/template?kind=work_orders|assets|locations
+//
+// A CSV with just the header row. The first column is required.
+func (h *Handler) Template(w http.ResponseWriter, r *http.Request) {
+	kind, err := models.ParseImportKind(r.URL.Query().Get("kind"))
+	if err != nil {
+		writeError(w, err, "invalid import kind")
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+string(kind)+`.csv"`)
+	cw := csv.NewWriter(w)
+	_ = cw.Write(models.ImportColumns(kind))
+	cw.Flush()
+}
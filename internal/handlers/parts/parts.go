@@ -0,0 +1,253 @@
+// internal/handlers/parts/parts.go
+package parts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxReportMonths caps the span of one consumption report.
+const maxReportMonths = 36
+
+type Handler struct {
+	repo repo.Repo
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+// partErrorStatus maps repo/model errors to an HTTP status.
+func partErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrPartNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrPartNumberTaken), errors.Is(err, models.ErrPartInUse):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrPartNumberRequired), errors.Is(err, models.ErrPartNumberTooLong),
+		errors.Is(err, models.ErrInvalidPartCategory), errors.Is(err, models.ErrInvalidPartTracking),
+		errors.Is(err, models.ErrNegativeUnitCost):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := partErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (models.PartInput, error) {
+	defer r.Body.Close()
+	var in models.PartInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, errors.New("invalid JSON: " + err.Error())
+	}
+	return in, nil
+}
+
+// GET /parts?q=gearbox&include_archived=true
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
+	parts, err := h.repo.ListParts(r.Context(), orgID, r.URL.Query().Get("q"), includeArchived)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch parts"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": parts,
+	})
+}
+
+// GET /parts/{partID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "partID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid part ID"})
+		return
+	}
+	part, err := h.repo.GetPart(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch part")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, part)
+}
+
+// POST /parts
+//
+//	{
+//	  "part_number": "GBX-BRG-220",
+//	  "revision": "B",
+//	  "description": "Gearbox HSS bearing",
+//	  "category": "Major",
+//	  "uom": "EA",
+//	  "unit_cost": 1240.00,
+//	  "tracking": "SERIAL"
+//	}
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid part")
+		return
+	}
+	part, err := h.repo.CreatePart(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create part")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, part)
+}
+
+// PUT /parts/{partID}
+//
+// Full replace. Set "archived": true to retire a part that has been used.
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "partID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid part ID"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid part")
+		return
+	}
+	part, err := h.repo.UpdatePart(r.Context(), orgID, id, in)
+	if err != nil {
+		writeError(w, err, "failed to update part")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, part)
+}
+
+// DELETE /parts/{partID}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "partID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid part ID"})
+		return
+	}
+	if err := h.repo.DeletePart(r.Context(), orgID, id); err != nil {
+		writeError(w, err, "failed to delete part")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "part deleted",
+		"id":      id,
+	})
+}
+
+// GET /parts/consumption?from=2025-01&to=2025-03&asset_id=uuid
+//
+// Spare consumption per month, asset and part. from and to are inclusive
+// UTC months and default to the current month.
+func (h *Handler) Consumption(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	from, to := current, current
+	var err error
+	if v := q.Get("from"); v != "" {
+		if from, err = time.Parse("2006-01", v); err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "from must be YYYY-MM"})
+			return
+		}
+	}
+	if v := q.Get("to"); v != "" {
+		if to, err = time.Parse("2006-01", v); err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "to must be YYYY-MM"})
+			return
+		}
+	}
+	end := to.AddDate(0, 1, 0)
+	if !end.After(from) {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "from must not be after to"})
+		return
+	}
+	if from.AddDate(0, maxReportMonths, 0).Before(end) {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "report spans more than 36 months"})
+		return
+	}
+	var assetID *uuid.UUID
+	if v := q.Get("asset_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid asset ID"})
+			return
+		}
+		assetID = &id
+	}
+
+	rows, err := h.repo.ListPartConsumption(r.Context(), orgID, from, end, assetID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to build consumption report"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"from":    from.Format("2006-01"),
+		"to":      to.Format("2006-01"),
+		"content": rows,
+	})
+}
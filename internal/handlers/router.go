@@ -2,7 +2,15 @@
 package handlers
 
 import (
+    "yourapp/internal/handlers/categories"
+    "yourapp/internal/handlers/checklists"
+    "yourapp/internal/handlers/files"
+    "yourapp/internal/handlers/imports"
+    "yourapp/internal/handlers/parts"
+    "yourapp/internal/handlers/schedule"
+    "yourapp/internal/handlers/sla"
     "yourapp/internal/handlers/tasks"
+    "yourapp/internal/handlers/templates"
     "yourapp/internal/handlers/users"
     "yourapp/internal/handlers/work_orders"
     "yourapp/internal/handlers/locations"
@@ -10,31 +18,177 @@ import (
     "yourapp/internal/handlers/assets"
     "yourapp/internal/handlers/admin"
     "yourapp/internal/middleware"
+    "yourapp/internal/models"
     "yourapp/internal/repo"
 
     "github.com/go-chi/chi/v5"
 )
 
-func RegisterRoutes(mux *chi.Mux, r repo.Repo) {
-    h := work_orders.New(r)
+func RegisterRoutes(mux *chi.Mux, r repo.Repo, up *files.Uploader, baseURL string) {
+    h := work_orders.New(r, up)
+    f := files.New(r, up)
     t := tasks.New(r)
     u := users.New(r)
     l := locations.New(r)
     tm := teams.New(r)
     a := assets.New(r)
+    c := categories.New(r)
+    tpl := templates.New(r)
+    pt := parts.New(r)
+    sp := sla.New(r)
+    cl := checklists.New(r)
+    im := imports.New(r)
+    sc := schedule.New(r, baseURL)
 
 	mux.Route("/work-orders", func(sr chi.Router) {
 		// Apply auth to the whole group ONCE
 		sr.Use(middleware.RequireAuth(r))
 
 		sr.Post("/search", h.FilterSearch)
+		sr.Post("/bulk", h.Bulk)
 		sr.Post("/", h.Create)
 		sr.Get("/", h.List)
+		sr.Get("/export", h.Export)
+		sr.Post("/export", h.Export)
+		sr.Get("/id-format", h.GetIDFormat)
+		sr.With(middleware.RequireRole(r, models.RoleAdmin)).Put("/id-format", h.SetIDFormat)
+		sr.Get("/trash", h.Trash)
+		sr.With(middleware.RequireRole(r, models.RoleAdmin)).Post("/trash/purge", h.EmptyTrash)
+		sr.With(middleware.RequireRole(r, models.RoleAdmin)).Delete("/trash/{workOrderID}", h.Purge)
 		sr.Get("/{workOrderID}", h.GetByID)
 		sr.Put("/{workOrderID}", h.Update)
 		sr.Delete("/{workOrderID}", h.Delete)
 		sr.Patch("/{workOrderID}", h.Modify)
 		sr.Patch("/{workOrderID}/change-status", h.ChangeStatus)
+		sr.Post("/{workOrderID}/complete", h.Complete)
+		sr.Post("/{workOrderID}/duplicate", h.Duplicate)
+		sr.Post("/{workOrderID}/restore", h.Restore)
+		sr.Post("/{workOrderID}/reschedule", h.Reschedule)
+		sr.Get("/{workOrderID}/status-history", h.StatusHistory)
+		sr.Put("/{workOrderID}/parent", h.SetParent)
+		sr.Get("/{workOrderID}/dependencies", h.Dependencies)
+		sr.Post("/{workOrderID}/dependencies", h.AddDependency)
+		sr.Delete("/{workOrderID}/dependencies/{blockedByID}", h.RemoveDependency)
+		sr.Get("/{workOrderID}/comments", h.ListComments)
+		sr.Post("/{workOrderID}/comments", h.CreateComment)
+		sr.Patch("/{workOrderID}/comments/{commentID}", h.UpdateComment)
+		sr.Delete("/{workOrderID}/comments/{commentID}", h.DeleteComment)
+		sr.Get("/{workOrderID}/timeline", h.Timeline)
+		sr.Get("/{workOrderID}/time-entries", h.ListTimeEntries)
+		sr.Post("/{workOrderID}/time-entries", h.CreateTimeEntry)
+		sr.Put("/{workOrderID}/time-entries/{entryID}", h.UpdateTimeEntry)
+		sr.Delete("/{workOrderID}/time-entries/{entryID}", h.DeleteTimeEntry)
+		sr.Post("/{workOrderID}/timer/start", h.StartTimer)
+		sr.Post("/{workOrderID}/timer/stop", h.StopTimer)
+		sr.Get("/{workOrderID}/parts", h.ListParts)
+		sr.Post("/{workOrderID}/parts", h.AddPart)
+		sr.Put("/{workOrderID}/parts/{lineID}", h.UpdatePart)
+		sr.Delete("/{workOrderID}/parts/{lineID}", h.DeletePart)
+		sr.Get("/{workOrderID}/files", f.ListWorkOrderFiles)
+		sr.Post("/{workOrderID}/files", f.AddWorkOrderFiles)
+		sr.Delete("/{workOrderID}/files/{fileID}", f.RemoveWorkOrderFile)
+	})
+
+	mux.Route("/work-order-categories", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", c.List)
+		sr.Get("/{categoryID}", c.Get)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", c.Create)
+			wr.Put("/{categoryID}", c.Update)
+			wr.Delete("/{categoryID}", c.Delete)
+		})
+	})
+
+	mux.Route("/work-order-templates", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", tpl.List)
+		sr.Get("/{templateID}", tpl.Get)
+		sr.Post("/{templateID}/instantiate", tpl.Instantiate)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", tpl.Create)
+			wr.Put("/{templateID}", tpl.Update)
+			wr.Delete("/{templateID}", tpl.Delete)
+		})
+	})
+
+	mux.Route("/sla-policies", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", sp.List)
+		sr.Get("/{policyID}", sp.Get)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", sp.Create)
+			wr.Put("/{policyID}", sp.Update)
+			wr.Delete("/{policyID}", sp.Delete)
+		})
+	})
+
+	mux.Route("/checklist-policies", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", cl.List)
+		sr.Get("/{policyID}", cl.Get)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", cl.Create)
+			wr.Put("/{policyID}", cl.Update)
+			wr.Delete("/{policyID}", cl.Delete)
+		})
+	})
+
+	mux.Route("/imports", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+		sr.Use(middleware.RequireRole(r, models.RoleAdmin))
+
+		sr.Get("/", im.List)
+		sr.Post("/", im.Create)
+		sr.Get("/template", im.Template)
+		sr.Get("/{jobID}", im.Get)
+	})
+
+	mux.Route("/schedule", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", sc.Schedule)
+		sr.Get("/feed", sc.GetFeed)
+		sr.Post("/feed", sc.CreateFeed)
+		sr.Delete("/feed", sc.DeleteFeed)
+		sr.Get("/working-hours", sc.ListWorkingHours)
+		sr.With(middleware.RequireRole(r, models.RoleAdmin)).Put("/working-hours/{userID}", sc.SetWorkingHours)
+		sr.Get("/leave", sc.ListLeave)
+		sr.Post("/leave", sc.CreateLeave)
+		sr.Delete("/leave/{leaveID}", sc.DeleteLeave)
+	})
+
+	// Calendar feeds are fetched by calendar apps without a session; the
+	// token in the path authenticates them.
+	mux.Get("/calendar/{feed}", sc.Feed)
+
+	mux.Route("/parts", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", pt.List)
+		sr.Get("/consumption", pt.Consumption)
+		sr.Get("/{partID}", pt.Get)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", pt.Create)
+			wr.Put("/{partID}", pt.Update)
+			wr.Delete("/{partID}", pt.Delete)
+		})
 	})
 
 	mux.Route("/tasks", func(sr chi.Router) {
@@ -43,10 +197,45 @@ func RegisterRoutes(mux *chi.Mux, r repo.Repo) {
 
 		sr.Get("/work-order/{workOrderID}", t.GetByWOID)
 		sr.Get("/work-order/{workOrderID}/full", t.GetByWOIDFull)
+		sr.Put("/work-order/{workOrderID}/order", t.Reorder)
 		sr.Patch("/{taskID}", t.ToggleComplete)
 		sr.Delete("/{taskID}", t.Delete)
 		sr.Post("/", t.Create)
 		sr.Put("/{taskID}", t.Update)
+		sr.Put("/{taskID}/assignment", t.Assign)
+		sr.Get("/{taskID}/files", f.ListTaskFiles)
+		sr.Post("/{taskID}/files", f.AddTaskFiles)
+		sr.Delete("/{taskID}/files/{fileID}", f.RemoveTaskFile)
+	})
+
+	mux.Route("/task-bases", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Get("/", t.ListBases)
+		sr.Get("/finding-rules", t.ListFindingRules)
+		sr.Get("/{taskBaseID}", t.GetBase)
+		sr.Get("/{taskBaseID}/finding-rule", t.GetFindingRule)
+		sr.Group(func(wr chi.Router) {
+			wr.Use(middleware.RequireRole(r, models.RoleAdmin))
+			wr.Post("/", t.CreateBase)
+			wr.Put("/{taskBaseID}", t.UpdateBase)
+			wr.Delete("/{taskBaseID}", t.DeleteBase)
+			wr.Put("/{taskBaseID}/finding-rule", t.SetFindingRule)
+			wr.Delete("/{taskBaseID}/finding-rule", t.DeleteFindingRule)
+		})
+	})
+
+	mux.Route("/files", func(sr chi.Router) {
+		// Apply auth to the whole group ONCE
+		sr.Use(middleware.RequireAuth(r))
+
+		sr.Post("/", f.Upload)
+		sr.Get("/usage", f.Usage)
+		sr.With(middleware.RequireRole(r, models.RoleOwner)).Put("/quota", f.SetQuota)
+		sr.Get("/{fileID}", f.Get)
+		sr.Get("/{fileID}/content", f.Download)
+		sr.Delete("/{fileID}", f.Delete)
 	})
 
     mux.Route("/users", func(sr chi.Router) {
@@ -0,0 +1,251 @@
+// internal/handlers/schedule/capacity.go
+package schedule
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func capacityErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrLeaveNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrLeaveForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrUserNotMember):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrInvalidWorkingHours), errors.Is(err, models.ErrInvalidTimeZone),
+		errors.Is(err, models.ErrInvalidLeave):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeCapacityError(w http.ResponseWriter, err error, fallback string) {
+	status := capacityErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	defer r.Body.Close()
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return errors.New("invalid JSON: " + err.Error())
+	}
+	return nil
+}
+
+// isAdmin reports whether the user can manage other people's capacity.
+func (h *Handler) isAdmin(r *http.Request, orgID, userID uuid.UUID) bool {
+	role, err := h.repo.GetRole(r.Context(), orgID, userID)
+	return err == nil && (role == models.RoleAdmin || role == models.RoleOwner)
+}
+
+// GET /schedule/working-hours?user=
+//
+// Weekly working hours, for everyone with some or for one user.
+func (h *Handler) ListWorkingHours(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var userID *uuid.UUID
+	if v := r.URL.Query().Get("user"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+			return
+		}
+		userID = &id
+	}
+	hours, err := h.repo.ListWorkingHours(r.Context(), orgID, userID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch working hours"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": hours,
+	})
+}
+
+// PUT /schedule/working-hours/{userID}
+//
+//	{ "time_zone": "Europe/London",
+//	  "days": [{ "weekday": 1, "start": "08:00", "end": "16:30" }, ...] }
+//
+// Replaces the user's week. Weekday 0 is Sunday; days not listed are days
+// off. An empty days list removes the limits altogether. Admins only.
+func (h *Handler) SetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+		return
+	}
+	var in models.WorkingHoursInput
+	if err := decodeJSON(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeCapacityError(w, err, "invalid working hours")
+		return
+	}
+	hours, err := h.repo.SetWorkingHours(r.Context(), orgID, userID, in)
+	if err != nil {
+		writeCapacityError(w, err, "failed to save working hours")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, hours)
+}
+
+// GET /schedule/leave?user=&from=&to=
+//
+// Leave overlapping [from, to) (RFC 3339 or YYYY-MM-DD), for everyone or
+// one user ("me" for the caller). Without bounds everything is listed.
+func (h *Handler) ListLeave(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	q := r.URL.Query()
+	var userID *uuid.UUID
+	if v := q.Get("user"); v != "" {
+		id := user.ID
+		if v != "me" {
+			var err error
+			if id, err = uuid.Parse(v); err != nil {
+				httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+				return
+			}
+		}
+		userID = &id
+	}
+	var from, to *time.Time
+	if v := q.Get("from"); v != "" {
+		t, err := parseBound(v, false)
+		if err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "from must be an RFC 3339 time or YYYY-MM-DD"})
+			return
+		}
+		from = &t
+	}
+	if v := q.Get("to"); v != "" {
+		t, err := parseBound(v, true)
+		if err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "to must be an RFC 3339 time or YYYY-MM-DD"})
+			return
+		}
+		to = &t
+	}
+	leave, err := h.repo.ListUserLeave(r.Context(), orgID, userID, from, to)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch leave"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": leave,
+	})
+}
+
+// POST /schedule/leave
+//
+//	{ "user_id": "...", "starts_at": "...", "ends_at": "...", "reason": "..." }
+//
+// Records leave. user_id defaults to the caller; only admins can record
+// leave for someone else.
+func (h *Handler) CreateLeave(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var in models.UserLeaveInput
+	if err := decodeJSON(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if in.UserID == uuid.Nil {
+		in.UserID = user.ID
+	}
+	if err := in.Normalize(); err != nil {
+		writeCapacityError(w, err, "invalid leave")
+		return
+	}
+	if in.UserID != user.ID && !h.isAdmin(r, orgID, user.ID) {
+		writeCapacityError(w, models.ErrLeaveForbidden, "forbidden")
+		return
+	}
+	leave, err := h.repo.CreateUserLeave(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeCapacityError(w, err, "failed to save leave")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, leave)
+}
+
+// DELETE /schedule/leave/{leaveID}
+//
+// Users can remove their own leave; admins anyone's.
+func (h *Handler) DeleteLeave(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	leaveID, err := uuid.Parse(chi.URLParam(r, "leaveID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid leave ID"})
+		return
+	}
+	leave, err := h.repo.GetUserLeave(r.Context(), orgID, leaveID)
+	if err != nil {
+		writeCapacityError(w, err, "failed to delete leave")
+		return
+	}
+	if leave.UserID != user.ID && !h.isAdmin(r, orgID, user.ID) {
+		writeCapacityError(w, models.ErrLeaveForbidden, "forbidden")
+		return
+	}
+	if err := h.repo.DeleteUserLeave(r.Context(), orgID, leaveID); err != nil {
+		writeCapacityError(w, err, "failed to delete leave")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,99 @@
+// internal/handlers/schedule/ics.go
+package schedule
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"yourapp/internal/models"
+)
+
+// icsWriter writes an RFC 5545 calendar: CRLF line endings, content lines
+// folded at 75 octets and TEXT values escaped.
+type icsWriter struct {
+	w *bufio.Writer
+}
+
+func newICSWriter(w io.Writer) *icsWriter {
+	return &icsWriter{w: bufio.NewWriter(w)}
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func icsText(s string) string { return icsEscaper.Replace(s) }
+
+func icsTime(t time.Time) string { return t.UTC().Format("20060102T150405Z") }
+
+func icsDate(t time.Time) string { return t.UTC().Format("20060102") }
+
+// line writes name:value, folding after 75 octets without splitting a UTF-8
+// sequence.
+func (c *icsWriter) line(name, value string) {
+	s := name + ":" + value
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		c.w.WriteString(s[:cut])
+		c.w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts
+	}
+	c.w.WriteString(s)
+	c.w.WriteString("\r\n")
+}
+
+func (c *icsWriter) begin(name string) {
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//yourapp//Work order schedule//EN")
+	c.line("CALSCALE", "GREGORIAN")
+	c.line("METHOD", "PUBLISH")
+	c.line("X-WR-CALNAME", icsText(name))
+	c.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	c.line("X-PUBLISHED-TTL", "PT1H")
+}
+
+// event writes one VEVENT. Timed events are in UTC; due-date-only work
+// orders become all-day events.
+func (c *icsWriter) event(ev models.ScheduleEvent, stamp time.Time) {
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", "wo-"+ev.WorkOrderID.String())
+	c.line("DTSTAMP", icsTime(stamp))
+	switch {
+	case ev.Start != nil && ev.End != nil:
+		c.line("DTSTART", icsTime(*ev.Start))
+		c.line("DTEND", icsTime(*ev.End))
+	case ev.DueDate != nil:
+		c.line("DTSTART;VALUE=DATE", icsDate(*ev.DueDate))
+		c.line("DTEND;VALUE=DATE", icsDate(ev.DueDate.AddDate(0, 0, 1)))
+	}
+	summary := ev.Title
+	if ev.CustomID != "" {
+		summary = "[" + ev.CustomID + "] " + summary
+	}
+	c.line("SUMMARY", icsText(summary))
+	if ev.Location != nil && ev.Location.Name != "" {
+		c.line("LOCATION", icsText(ev.Location.Name))
+	}
+	desc := []string{"Status: " + ev.Status, "Priority: " + ev.Priority}
+	if ev.Team != nil && ev.Team.Name != "" {
+		desc = append(desc, "Team: "+ev.Team.Name)
+	}
+	if ev.Conflict {
+		desc = append(desc, "Double-booked with another work order")
+	}
+	c.line("DESCRIPTION", icsText(strings.Join(desc, "\n")))
+	c.line("TRANSP", "OPAQUE")
+	c.line("END", "VEVENT")
+}
+
+func (c *icsWriter) end() error {
+	c.line("END", "VCALENDAR")
+	return c.w.Flush()
+}
@@ -0,0 +1,284 @@
+// internal/handlers/schedule/schedule.go
+package schedule
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// defaultScheduleDays is the window used when "to" is not given.
+const defaultScheduleDays = 7
+
+// The iCalendar feed covers this much history and this much of the future.
+const (
+	feedPastDays   = 30
+	feedFutureDays = 60
+)
+
+type Handler struct {
+	repo    repo.Repo
+	baseURL string
+}
+
+func New(repo repo.Repo, baseURL string) *Handler {
+	return &Handler{repo: repo, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func scheduleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrCalendarFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidScheduleRange):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := scheduleErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+// parseBound accepts an RFC 3339 timestamp or a YYYY-MM-DD date (midnight
+// UTC). A date used as the end of the range includes that whole day.
+func parseBound(v string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// GET /schedule?from=&to=&user=&team=
+//
+// Work orders on the calendar between from and to (RFC 3339 or YYYY-MM-DD,
+// to inclusive when a date). Defaults to the next 7 days from today; at
+// most 93 days. user (a user ID or "me") keeps the work orders where that
+// user is the primary worker or an assignee; team keeps one team's.
+//
+// Scheduled work orders run from estimated_start_date for
+// estimated_duration hours; ones with only a due date are all-day events.
+// Events whose people are double-booked have conflict=true and list the
+// overlapping work orders; "conflicts" counts them.
+func (h *Handler) Schedule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	q := r.URL.Query()
+	now := time.Now().UTC()
+	f := models.ScheduleFilter{From: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)}
+	var err error
+	if v := q.Get("from"); v != "" {
+		if f.From, err = parseBound(v, false); err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "from must be an RFC 3339 time or YYYY-MM-DD"})
+			return
+		}
+	}
+	f.To = f.From.AddDate(0, 0, defaultScheduleDays)
+	if v := q.Get("to"); v != "" {
+		if f.To, err = parseBound(v, true); err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "to must be an RFC 3339 time or YYYY-MM-DD"})
+			return
+		}
+	}
+	if v := q.Get("user"); v != "" {
+		id := user.ID
+		if !strings.EqualFold(v, "me") {
+			if id, err = uuid.Parse(v); err != nil {
+				httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid user ID"})
+				return
+			}
+		}
+		f.UserID = &id
+	}
+	if v := q.Get("team"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid team ID"})
+			return
+		}
+		f.TeamID = &id
+	}
+
+	events, err := h.repo.ListScheduleEvents(r.Context(), orgID, f)
+	if err != nil {
+		writeError(w, err, "failed to fetch schedule")
+		return
+	}
+	conflicts := 0
+	for _, ev := range events {
+		if ev.Conflict {
+			conflicts++
+		}
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"from":      f.From,
+		"to":        f.To,
+		"content":   events,
+		"conflicts": conflicts,
+	})
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (h *Handler) feedURL(token string) string {
+	return h.baseURL + "/calendar/" + token + ".ics"
+}
+
+// GET /schedule/feed
+//
+// Whether the current user has a calendar feed and when it was last
+// fetched. The URL itself is only shown when the feed is issued.
+func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	feed, err := h.repo.GetCalendarFeed(r.Context(), orgID, user.ID)
+	if err != nil {
+		writeError(w, err, "failed to fetch calendar feed")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, feed)
+}
+
+// POST /schedule/feed
+//
+// Issues a private iCalendar feed of the current user's work orders and
+// returns its URL (plus a webcal:// form for phones). Calling it again
+// rotates the token and the old URL stops working.
+func (h *Handler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create calendar feed"})
+		return
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := h.repo.SetCalendarFeed(r.Context(), orgID, user.ID, hashFeedToken(token)); err != nil {
+		writeError(w, err, "failed to create calendar feed")
+		return
+	}
+	u := h.feedURL(token)
+	webcal := u
+	if i := strings.Index(u, "://"); i >= 0 {
+		webcal = "webcal" + u[i:]
+	}
+	httpserver.JSON(w, http.StatusCreated, map[string]any{
+		"url":        u,
+		"webcal_url": webcal,
+		"created_at": time.Now().UTC(),
+	})
+}
+
+// DELETE /schedule/feed
+func (h *Handler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if err := h.repo.DeleteCalendarFeed(r.Context(), orgID, user.ID); err != nil {
+		writeError(w, err, "failed to delete calendar feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /calendar/{feed}.ics
+//
+// Public: the token in the path is the credential. Serves the feed owner's
+// work orders from 30 days ago to 60 days ahead as text/calendar.
+func (h *Handler) Feed(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimSuffix(chi.URLParam(r, "feed"), ".ics")
+	if token == "" {
+		http.NotFound(w, r)
+		return
+	}
+	orgID, userID, err := h.repo.ResolveCalendarFeed(r.Context(), hashFeedToken(token))
+	if err != nil {
+		if errors.Is(err, models.ErrCalendarFeedNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, "failed to load calendar", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now().UTC()
+	events, err := h.repo.ListScheduleEvents(r.Context(), orgID, models.ScheduleFilter{
+		From:   now.AddDate(0, 0, -feedPastDays),
+		To:     now.AddDate(0, 0, feedFutureDays),
+		UserID: &userID,
+	})
+	if err != nil {
+		http.Error(w, "failed to load calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	c := newICSWriter(w)
+	c.begin("Work orders")
+	for _, ev := range events {
+		c.event(ev, now)
+	}
+	if err := c.end(); err != nil {
+		slog.WarnContext(r.Context(), "calendar feed write failed", "err", err)
+	}
+}
@@ -0,0 +1,190 @@
+// internal/handlers/sla/sla.go
+package sla
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	repo repo.Repo
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+// policyErrorStatus maps repo/model errors to an HTTP status.
+func policyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrSLAPolicyNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrSLAPolicyExists):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrCategoryNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrUnknownPriority), errors.Is(err, models.ErrSLATargetRequired),
+		errors.Is(err, models.ErrInvalidSLAWindow), errors.Is(err, models.ErrInvalidSLAAtRisk):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := policyErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (models.SLAPolicyInput, error) {
+	defer r.Body.Close()
+	var in models.SLAPolicyInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, errors.New("invalid JSON: " + err.Error())
+	}
+	return in, nil
+}
+
+// GET /sla-policies
+//
+// Most specific first, which is the order they are matched in.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	policies, err := h.repo.ListSLAPolicies(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch SLA policies"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": policies,
+	})
+}
+
+// GET /sla-policies/{policyID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid SLA policy ID"})
+		return
+	}
+	pol, err := h.repo.GetSLAPolicy(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch SLA policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, pol)
+}
+
+// POST /sla-policies
+//
+//	{
+//	  "priority": "CRITICAL",          // optional, omit for any priority
+//	  "category_id": "uuid",           // optional, omit for any category
+//	  "react_within_minutes": 60,
+//	  "complete_within_minutes": 1440,
+//	  "at_risk_percent": 80            // optional
+//	}
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid SLA policy")
+		return
+	}
+	pol, err := h.repo.CreateSLAPolicy(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create SLA policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, pol)
+}
+
+// PUT /sla-policies/{policyID}
+//
+// Full replace; same body as create.
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid SLA policy ID"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid SLA policy")
+		return
+	}
+	pol, err := h.repo.UpdateSLAPolicy(r.Context(), orgID, id, in)
+	if err != nil {
+		writeError(w, err, "failed to update SLA policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, pol)
+}
+
+// DELETE /sla-policies/{policyID}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "policyID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid SLA policy ID"})
+		return
+	}
+	if err := h.repo.DeleteSLAPolicy(r.Context(), orgID, id); err != nil {
+		writeError(w, err, "failed to delete SLA policy")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "SLA policy deleted",
+		"id":      id,
+	})
+}
@@ -0,0 +1,144 @@
+// internal/handlers/tasks/findings.go
+package tasks
+
+import (
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func findingRuleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrTaskFindingRuleNotFound), errors.Is(err, models.ErrTaskBaseNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrFindingRuleNotSupported), errors.Is(err, models.ErrCategoryNotFound),
+		errors.Is(err, models.ErrTeamNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrFindingTriggerRequired), errors.Is(err, models.ErrInvalidFindingDueIn),
+		errors.Is(err, models.ErrUnknownPriority):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeFindingRuleError(w http.ResponseWriter, err error, fallback string) {
+	status := findingRuleErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+// GET /task-bases/finding-rules
+//
+// Every task base of the organisation that spawns corrective work orders.
+func (h *Handler) ListFindingRules(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	rules, err := h.repo.ListTaskFindingRules(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch finding rules"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": rules,
+	})
+}
+
+// GET /task-bases/{taskBaseID}/finding-rule
+func (h *Handler) GetFindingRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	rule, err := h.repo.GetTaskFindingRule(r.Context(), orgID, id)
+	if err != nil {
+		writeFindingRuleError(w, err, "failed to fetch finding rule")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, rule)
+}
+
+// PUT /task-bases/{taskBaseID}/finding-rule
+//
+//	{
+//	  "on_fail": true, "on_flag": false, "on_out_of_tolerance": true,
+//	  "priority": "HIGH", "category_id": "…", "team_id": "…", "due_in_days": 7
+//	}
+//
+// When a task of this base fails its inspection (or is flagged, or reads
+// out of tolerance), a corrective work order is created with the finding as
+// its description, the source's asset and location, and a link back to the
+// source task and work order. Omitted triggers default to on_fail and
+// on_out_of_tolerance; a null priority keeps the source's. Admins only.
+func (h *Handler) SetFindingRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	var in models.TaskFindingRuleInput
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeFindingRuleError(w, err, "invalid finding rule")
+		return
+	}
+	rule, err := h.repo.SetTaskFindingRule(r.Context(), orgID, user.ID, id, in)
+	if err != nil {
+		writeFindingRuleError(w, err, "failed to save finding rule")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, rule)
+}
+
+// DELETE /task-bases/{taskBaseID}/finding-rule
+func (h *Handler) DeleteFindingRule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	if err := h.repo.DeleteTaskFindingRule(r.Context(), orgID, id); err != nil {
+		writeFindingRuleError(w, err, "failed to delete finding rule")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message":      "finding rule deleted",
+		"task_base_id": id,
+	})
+}
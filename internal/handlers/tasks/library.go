@@ -0,0 +1,199 @@
+// internal/handlers/tasks/library.go
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// taskErrorStatus maps repo/model errors to an HTTP status.
+func taskErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrTaskNotFound), errors.Is(err, models.ErrTaskBaseNotFound),
+		errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrTaskBaseInUse):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrTaskBaseArchived), errors.Is(err, models.ErrUserNotMember),
+		errors.Is(err, models.ErrAssetNotFound), errors.Is(err, models.ErrTaskOptionNotFound),
+		errors.Is(err, models.ErrInvalidReference), errors.Is(err, models.ErrTaskOrderMismatch),
+		errors.Is(err, models.ErrMeterNotFound), errors.Is(err, models.ErrInvalidTaskNumber),
+		errors.Is(err, models.ErrInvalidInspectionResult), errors.Is(err, models.ErrInvalidSubtaskValue),
+		errors.Is(err, models.ErrInvalidTaskChoice), errors.Is(err, models.ErrTaskNotToggleable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrTaskLabelRequired), errors.Is(err, models.ErrTaskLabelTooLong),
+		errors.Is(err, models.ErrInvalidTaskType), errors.Is(err, models.ErrTaskOptionsRequired),
+		errors.Is(err, models.ErrTaskOptionsNotAllowed), errors.Is(err, models.ErrTooManyTaskOptions),
+		errors.Is(err, models.ErrDuplicateTaskOption), errors.Is(err, models.ErrTaskBaseRequired),
+		errors.Is(err, models.ErrTaskWorkOrderRequired), errors.Is(err, models.ErrTaskNotesTooLong),
+		errors.Is(err, models.ErrTaskUnitTooLong), errors.Is(err, models.ErrTaskToleranceNotAllowed),
+		errors.Is(err, models.ErrInvalidTolerance), errors.Is(err, models.ErrTaskMeterRequired),
+		errors.Is(err, models.ErrTaskMeterNotAllowed):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := taskErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) error {
+	defer r.Body.Close()
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errors.New("invalid JSON: " + err.Error())
+	}
+	return nil
+}
+
+// GET /task-bases?q=belt&task_type=INSPECTION&include_archived=true
+//
+// The organisation's task library, with the global bases.
+func (h *Handler) ListBases(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	q := r.URL.Query()
+	includeArchived, _ := strconv.ParseBool(q.Get("include_archived"))
+	bases, err := h.repo.ListTaskBases(r.Context(), orgID, q.Get("q"), q.Get("task_type"), includeArchived)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch task bases"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": bases,
+	})
+}
+
+// GET /task-bases/{taskBaseID}
+func (h *Handler) GetBase(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	base, err := h.repo.GetTaskBase(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch task base")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, base)
+}
+
+// POST /task-bases
+//
+//	{
+//	  "label": "Guard condition",
+//	  "task_type": "MULTIPLE_CHOICE",
+//	  "options": [{ "label": "Good" }, { "label": "Worn" }, { "label": "Missing" }]
+//	}
+func (h *Handler) CreateBase(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	var in models.TaskBaseInput
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid task base")
+		return
+	}
+	base, err := h.repo.CreateTaskBase(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create task base")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, base)
+}
+
+// PUT /task-bases/{taskBaseID}
+//
+// Full replace. Options keep their IDs when sent back with them; options
+// left out are removed. Set "archived": true to retire a base tasks use.
+func (h *Handler) UpdateBase(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	var in models.TaskBaseInput
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid task base")
+		return
+	}
+	base, err := h.repo.UpdateTaskBase(r.Context(), orgID, user.ID, id, in)
+	if err != nil {
+		writeError(w, err, "failed to update task base")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, base)
+}
+
+// DELETE /task-bases/{taskBaseID}
+func (h *Handler) DeleteBase(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "taskBaseID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task base ID"})
+		return
+	}
+	if err := h.repo.DeleteTaskBase(r.Context(), orgID, id); err != nil {
+		writeError(w, err, "failed to delete task base")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "task base deleted",
+		"id":      id,
+	})
+}
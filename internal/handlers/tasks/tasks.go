@@ -8,6 +8,7 @@ import (
 	"strings"
 	"yourapp/internal/auth"
 	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
 	"yourapp/internal/repo"
 
 	"github.com/go-chi/chi/v5"
@@ -88,17 +89,178 @@ func (h *Handler) GetByWOIDFull(w http.ResponseWriter, r *http.Request) {
 	httpserver.JSON(w, http.StatusOK, tasks)
 }
 
-// Additional handlers for creating, updating, deleting tasks can be added here.
+// POST /tasks
+//
+//	{
+//	  "work_order_id": "…",
+//	  "task_base_id": "…",                          // a library base, or
+//	  "task_base": { "label": "Check belt tension" }, // a new one
+//	  "notes": "…",
+//	  "assignee_id": "…",                           // optional, the base's user by default
+//	  "due_at": "2025-06-01T12:00:00Z"              // optional
+//	}
+//
+// Appends the task to the work order's checklist.
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	httpserver.JSON(w, http.StatusCreated, map[string]string{
-		"message": "create task",
-	})
+	org_id, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), org_id, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	var in models.TaskInput
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid task")
+		return
+	}
+	task, err := h.repo.CreateTask(r.Context(), org_id, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create task")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, task)
 }
 
+// PUT /tasks/{taskID}
+//
+//	{ "notes": "…", "value": "…" }
+//
+// Fields left out are kept; an empty string clears them. The value must
+// suit the task type: COMPLETE/OPEN for a SUBTASK, PASS/FAIL/FLAG for an
+// INSPECTION, a number for NUMBER and METER (flagged out_of_tolerance
+// outside min/max; METER readings update the meter) and one of the
+// options for MULTIPLE_CHOICE. If the task base has a finding rule, a
+// failed result spawns a corrective work order (corrective_work_order_id).
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	httpserver.JSON(w, http.StatusOK, map[string]string{
-		"message": "update task",
-	})
+	org_id, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	t_id, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), org_id, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	var in models.TaskUpdate
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid task")
+		return
+	}
+	task, err := h.repo.UpdateTask(r.Context(), org_id, user.ID, t_id, in)
+	if err != nil {
+		writeError(w, err, "failed to update task")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, task)
+}
+
+// PUT /tasks/{taskID}/assignment
+//
+//	{ "assignee_id": "…", "due_at": "2025-06-01T12:00:00Z" }
+//
+// Replaces both; null (or left out) clears them. The assignee must be a
+// member of the organisation. Changes are kept in the task history.
+func (h *Handler) Assign(w http.ResponseWriter, r *http.Request) {
+	org_id, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	t_id, err := uuid.Parse(chi.URLParam(r, "taskID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid task ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), org_id, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	var in models.TaskAssignment
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	in.Normalize()
+	task, err := h.repo.AssignTask(r.Context(), org_id, user.ID, t_id, in)
+	if err != nil {
+		writeError(w, err, "failed to assign task")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, task)
+}
+
+// PUT /tasks/work-order/{workOrderID}/order
+//
+//	{ "task_ids": ["…", "…"] }
+//
+// Lists every task of the work order in its new order and returns the
+// reordered checklist.
+func (h *Handler) Reorder(w http.ResponseWriter, r *http.Request) {
+	org_id, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	wo_id, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), org_id, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	var in models.TaskOrderInput
+	if err := decode(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := h.repo.ReorderTasks(r.Context(), org_id, wo_id, in.TaskIDs); err != nil {
+		writeError(w, err, "failed to reorder tasks")
+		return
+	}
+	tasks, err := h.repo.ListSimpleTasksByWorkOrderID(r.Context(), org_id, wo_id)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch tasks"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, tasks)
 }
 
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
@@ -122,9 +284,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	// 2. Call repo to delete task
 	err = h.repo.DeleteTaskByID(r.Context(), org_id, t_id)
 	if err != nil {
-		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to delete task",
-		})
+		writeError(w, err, "failed to delete task")
 		return
 	}
 	httpserver.JSON(w, http.StatusOK, map[string]string{
@@ -133,7 +293,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) MarkComplete(w http.ResponseWriter, r *http.Request) {
-	// Get org_id from context (set by middleware)
+	// Get org_id and the acting user from context (set by middleware)
 	org_id, ok := auth.OrgFromContext(r.Context())
 	if !ok {
 		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{
@@ -141,6 +301,13 @@ func (h *Handler) MarkComplete(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{
+			"error": "unauthorized",
+		})
+		return
+	}
 	// 1. Parse task from URL
 	idStr := chi.URLParam(r, "taskID")
 	t_id, err := uuid.Parse(idStr)
@@ -151,18 +318,16 @@ func (h *Handler) MarkComplete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// 2. Call repo to mark task complete
-	updatedTask, err := h.repo.MarkTaskComplete(r.Context(), org_id, t_id)
+	updatedTask, err := h.repo.MarkTaskComplete(r.Context(), org_id, user.ID, t_id)
 	if err != nil {
-		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to mark task complete",
-		})
+		writeError(w, err, "failed to mark task complete")
 		return
 	}
 	httpserver.JSON(w, http.StatusOK, updatedTask)
 }
 
 func (h *Handler) ToggleComplete(w http.ResponseWriter, r *http.Request) {
-	// 0. Get org_id from context (set by middleware)
+	// 0. Get org_id and the acting user from context (set by middleware)
 	org_id, ok := auth.OrgFromContext(r.Context())
 	if !ok {
 		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{
@@ -170,6 +335,13 @@ func (h *Handler) ToggleComplete(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{
+			"error": "unauthorized",
+		})
+		return
+	}
 
 	// 1. Parse task ID from URL
 	idStr := chi.URLParam(r, "taskID")
@@ -207,11 +379,9 @@ func (h *Handler) ToggleComplete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 4. Call repo
-	updatedTask, err := h.repo.ToggleTaskComplete(r.Context(), org_id, t_id, complete)
+	updatedTask, err := h.repo.ToggleTaskComplete(r.Context(), org_id, user.ID, t_id, complete)
 	if err != nil {
-		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to toggle task complete",
-		})
+		writeError(w, err, "failed to toggle task complete")
 		return
 	}
 
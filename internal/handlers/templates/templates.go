@@ -0,0 +1,248 @@
+// internal/handlers/templates/templates.go
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+	"yourapp/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type Handler struct {
+	repo repo.Repo
+}
+
+func New(repo repo.Repo) *Handler {
+	return &Handler{repo: repo}
+}
+
+// templateErrorStatus maps repo/model errors to an HTTP status.
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, models.ErrTemplateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrTemplateNameTaken):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrTaskBaseNotFound), errors.Is(err, models.ErrCategoryNotFound),
+		errors.Is(err, models.ErrInvalidReference), errors.Is(err, models.ErrInvalidWorkOrderPayload):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrTemplateNameRequired), errors.Is(err, models.ErrTemplateNameTooLong),
+		errors.Is(err, models.ErrTemplatePayloadInvalid), errors.Is(err, models.ErrTemplateTasksInPayload):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, err error, fallback string) {
+	status := templateErrorStatus(err)
+	msg := err.Error()
+	if status == http.StatusInternalServerError {
+		msg = fallback
+	}
+	httpserver.JSON(w, status, map[string]string{"error": msg})
+}
+
+func readInput(w http.ResponseWriter, r *http.Request) (models.WorkOrderTemplateInput, error) {
+	defer r.Body.Close()
+	var in models.WorkOrderTemplateInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return in, errors.New("invalid JSON: " + err.Error())
+	}
+	return in, nil
+}
+
+func templateID(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(chi.URLParam(r, "templateID"))
+}
+
+// GET /work-order-templates
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	tpls, err := h.repo.ListWorkOrderTemplates(r.Context(), orgID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch templates"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": tpls,
+	})
+}
+
+// GET /work-order-templates/{templateID}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := templateID(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid template ID"})
+		return
+	}
+	tpl, err := h.repo.GetWorkOrderTemplate(r.Context(), orgID, id)
+	if err != nil {
+		writeError(w, err, "failed to fetch template")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, tpl)
+}
+
+// POST /work-order-templates
+//
+//	{
+//	  "name": "Annual gearbox oil change",
+//	  "description": "Standard job",
+//	  "payload": {"title": "Gearbox oil change", "priority": "MEDIUM", "category": "uuid"},
+//	  "tasks": ["task-base-uuid", "task-base-uuid"]
+//	}
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid template")
+		return
+	}
+	tpl, err := h.repo.CreateWorkOrderTemplate(r.Context(), orgID, user.ID, in)
+	if err != nil {
+		writeError(w, err, "failed to create template")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, tpl)
+}
+
+// PUT /work-order-templates/{templateID}
+//
+// Full replace, task list included.
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := templateID(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid template ID"})
+		return
+	}
+	in, err := readInput(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		writeError(w, err, "invalid template")
+		return
+	}
+	tpl, err := h.repo.UpdateWorkOrderTemplate(r.Context(), orgID, id, in)
+	if err != nil {
+		writeError(w, err, "failed to update template")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, tpl)
+}
+
+// DELETE /work-order-templates/{templateID}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := templateID(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid template ID"})
+		return
+	}
+	if err := h.repo.DeleteWorkOrderTemplate(r.Context(), orgID, id); err != nil {
+		writeError(w, err, "failed to delete template")
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "template deleted",
+		"id":      id,
+	})
+}
+
+// POST /work-order-templates/{templateID}/instantiate
+//
+// Creates a work order from the template. The optional body overrides
+// payload keys for this instance, e.g. {"dueDate": "2025-10-01"}.
+func (h *Handler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := templateID(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid template ID"})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	defer r.Body.Close()
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	overrides := bytes.TrimSpace(raw)
+	if len(overrides) == 0 {
+		overrides = []byte("{}")
+	}
+	var probe map[string]any
+	if overrides[0] != '{' || json.Unmarshal(overrides, &probe) != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "overrides must be a JSON object"})
+		return
+	}
+
+	woID, err := h.repo.InstantiateWorkOrderTemplate(r.Context(), orgID, id, user.ID, overrides)
+	if err != nil {
+		writeError(w, err, "failed to create work order from template")
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, map[string]any{
+		"message":     "created work order",
+		"id":          woID,
+		"template_id": id,
+	})
+}
@@ -0,0 +1,223 @@
+// internal/handlers/work_orders/bulk.go
+package work_orders
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// BulkRequest is the body of POST /work-orders/bulk. Only the parameters of
+// the chosen action are read:
+//
+//	change_status: status, reason
+//	reassign:      primary_worker and/or assigned_to (replaces the list)
+//	archive:       archived (defaults to true; false un-archives)
+//	set_due_date:  due_date (YYYY-MM-DD or RFC3339; null clears it)
+//	add_to_team:   team_id
+//	delete:        - (moves them to the trash)
+//
+// With atomic set, any failing item rolls back the whole batch.
+type BulkRequest struct {
+	IDs           []uuid.UUID     `json:"ids"`
+	Action        string          `json:"action"`
+	Atomic        bool            `json:"atomic"`
+	Status        string          `json:"status"`
+	Reason        string          `json:"reason"`
+	PrimaryWorker *uuid.UUID      `json:"primary_worker"`
+	AssignedTo    *[]uuid.UUID    `json:"assigned_to"`
+	Archived      *bool           `json:"archived"`
+	DueDate       json.RawMessage `json:"due_date"`
+	TeamID        *uuid.UUID      `json:"team_id"`
+}
+
+// BulkItem is one line of the per-item report.
+type BulkItem struct {
+	ID        uuid.UUID `json:"id"`
+	OK        bool      `json:"ok"`
+	Unchanged bool      `json:"unchanged,omitempty"`
+	Status    int       `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// toOperation validates the request and resolves it into a repo operation.
+func (b BulkRequest) toOperation() (models.BulkOperation, error) {
+	op := models.BulkOperation{
+		Action: strings.ToLower(strings.TrimSpace(b.Action)),
+		IDs:    models.UniqueIDs(b.IDs),
+		Atomic: b.Atomic,
+	}
+	if len(op.IDs) == 0 {
+		return op, models.ErrBulkNoIDs
+	}
+	if len(op.IDs) > models.MaxBulkWorkOrders {
+		return op, models.ErrBulkTooMany
+	}
+
+	var patch map[string]any
+	switch op.Action {
+	case models.BulkChangeStatus:
+		if strings.TrimSpace(b.Status) == "" {
+			return op, errors.New("status is required")
+		}
+		to, err := models.ParseWorkOrderStatus(b.Status)
+		if err != nil {
+			return op, errors.New("unknown status: " + b.Status)
+		}
+		op.ToStatus, op.Reason = to, strings.TrimSpace(b.Reason)
+	case models.BulkReassign:
+		if b.PrimaryWorker == nil && b.AssignedTo == nil {
+			return op, errors.New("primary_worker or assigned_to is required")
+		}
+		patch = map[string]any{}
+		if b.PrimaryWorker != nil {
+			patch["primary_worker"] = b.PrimaryWorker.String()
+		}
+		if b.AssignedTo != nil {
+			patch["assigned_to"] = models.UniqueIDs(*b.AssignedTo)
+		}
+	case models.BulkArchive:
+		archived := true
+		if b.Archived != nil {
+			archived = *b.Archived
+		}
+		patch = map[string]any{"archived": archived}
+	case models.BulkSetDueDate:
+		if len(b.DueDate) == 0 {
+			return op, errors.New("due_date is required (null clears it)")
+		}
+		var due *string
+		if !bytes.Equal(b.DueDate, []byte("null")) {
+			var s string
+			if err := json.Unmarshal(b.DueDate, &s); err != nil {
+				return op, errors.New("due_date must be a date string or null")
+			}
+			v, err := parseDateParam(strings.TrimSpace(s), false)
+			if err != nil {
+				return op, errors.New("invalid due_date: " + s)
+			}
+			due = &v
+		}
+		patch = map[string]any{"dueDate": due}
+	case models.BulkAddToTeam:
+		if b.TeamID == nil {
+			return op, errors.New("team_id is required")
+		}
+		patch = map[string]any{"team": b.TeamID.String()}
+	case models.BulkDelete:
+	default:
+		return op, models.ErrUnknownBulkAction
+	}
+
+	if patch != nil {
+		raw, err := json.Marshal(patch)
+		if err != nil {
+			return op, err
+		}
+		op.Patch = raw
+	}
+	return op, nil
+}
+
+// bulkErrorStatus maps an item failure to an HTTP-style status and message.
+func bulkErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrTransitionForbidden):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, models.ErrIllegalTransition), errors.Is(err, models.ErrStatusConflict), errors.Is(err, models.ErrWorkOrderBlocked),
+		errors.Is(err, models.ErrChecklistIncomplete):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, models.ErrSignatureRequired), errors.Is(err, models.ErrInvalidReference):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrBulkRolledBack):
+		return http.StatusFailedDependency, err.Error()
+	default:
+		return http.StatusInternalServerError, "failed to apply action"
+	}
+}
+
+// POST /work-orders/bulk
+//
+// Applies one action to many work orders in a single transaction and
+// reports the outcome per item. Items fail independently unless "atomic" is
+// set, in which case a single failure rolls everything back and the
+// response is 409 with the same report.
+func (h *Handler) Bulk(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	defer r.Body.Close()
+	var req BulkRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	op, err := req.toOperation()
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	// Deleting many work orders at once is a supervisor action
+	if op.Action == models.BulkDelete && role != models.RoleAdmin && role != models.RoleOwner {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "only admins can bulk delete work orders"})
+		return
+	}
+
+	results, err := h.repo.BulkWorkOrders(r.Context(), orgID, user.ID, role, op)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to apply bulk action"})
+		return
+	}
+
+	items := make([]BulkItem, 0, len(results))
+	succeeded, rolledBack := 0, false
+	for _, res := range results {
+		item := BulkItem{ID: res.ID, OK: res.Err == nil, Unchanged: res.Unchanged, Status: http.StatusOK}
+		if res.Err != nil {
+			item.Status, item.Error = bulkErrorStatus(res.Err)
+			rolledBack = rolledBack || errors.Is(res.Err, models.ErrBulkRolledBack)
+		} else {
+			succeeded++
+		}
+		items = append(items, item)
+	}
+
+	status := http.StatusOK
+	if op.Atomic && succeeded < len(items) {
+		status, rolledBack = http.StatusConflict, true
+	}
+	httpserver.JSON(w, status, map[string]any{
+		"action":      op.Action,
+		"atomic":      op.Atomic,
+		"total":       len(items),
+		"succeeded":   succeeded,
+		"failed":      len(items) - succeeded,
+		"rolled_back": rolledBack,
+		"results":     items,
+	})
+}
@@ -0,0 +1,22 @@
+// internal/handlers/work_orders/checklist.go
+package work_orders
+
+import (
+	"net/http"
+
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// writeChecklistIncomplete answers a completion refused by the checklist
+// policy with 409 and the tasks still in the way, each with its reason
+// (INCOMPLETE or NOTES_REQUIRED).
+func (h *Handler) writeChecklistIncomplete(w http.ResponseWriter, r *http.Request, orgID, woID uuid.UUID) {
+	resp := map[string]any{"error": models.ErrChecklistIncomplete.Error()}
+	if tasks, err := h.repo.ListChecklistBlockers(r.Context(), orgID, woID); err == nil {
+		resp["blocking_tasks"] = tasks
+	}
+	httpserver.JSON(w, http.StatusConflict, resp)
+}
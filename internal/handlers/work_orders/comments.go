@@ -0,0 +1,264 @@
+// internal/handlers/work_orders/comments.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// CommentRequest is the body for creating or editing a comment. ParentID is
+// only honoured on create; Mentions replaces the mention set when present.
+type CommentRequest struct {
+	Body     string      `json:"body"`
+	ParentID *uuid.UUID  `json:"parent_id,omitempty"`
+	Mentions []uuid.UUID `json:"mentions,omitempty"`
+}
+
+func (c *CommentRequest) validate() error {
+	c.Body = strings.TrimSpace(c.Body)
+	if c.Body == "" {
+		return errors.New("body is required")
+	}
+	if utf8.RuneCountInString(c.Body) > models.MaxCommentLength {
+		return errors.New("body is too long")
+	}
+	return nil
+}
+
+// commentErrorStatus maps repo/model errors to a response.
+func commentErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound, "work order not found"
+	case errors.Is(err, models.ErrCommentNotFound):
+		return http.StatusNotFound, "comment not found"
+	case errors.Is(err, models.ErrMentionNotMember):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrCommentForbidden):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, models.ErrCommentEditExpired):
+		return http.StatusConflict, err.Error()
+	default:
+		return http.StatusInternalServerError, "failed to save comment"
+	}
+}
+
+// GET /work-orders/{workOrderID}/comments
+func (h *Handler) ListComments(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if _, err := h.repo.GetWorkOrderStatus(r.Context(), orgID, woID); err != nil {
+		status, msg := commentErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	comments, err := h.repo.ListWorkOrderComments(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch comments"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      woID,
+		"content": comments,
+	})
+}
+
+// POST /work-orders/{workOrderID}/comments
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := req.validate(); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	c, err := h.repo.CreateWorkOrderComment(r.Context(), orgID, woID, user.ID, req.ParentID, req.Body, req.Mentions)
+	if err != nil {
+		status, msg := commentErrorStatus(err)
+		if status == http.StatusNotFound && req.ParentID != nil {
+			msg = "work order or parent comment not found"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	c.AuthorName = user.Name
+	httpserver.JSON(w, http.StatusCreated, c)
+}
+
+// PATCH /work-orders/{workOrderID}/comments/{commentID}
+//
+// Only the author may edit, and only within models.CommentEditWindow.
+func (h *Handler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid comment ID"})
+		return
+	}
+	var req CommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := req.validate(); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	existing, err := h.repo.GetWorkOrderComment(r.Context(), orgID, woID, commentID)
+	if err == nil {
+		err = models.CanEditComment(existing, user.ID, time.Now())
+	}
+	if err != nil {
+		status, msg := commentErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	c, err := h.repo.UpdateWorkOrderComment(r.Context(), orgID, woID, commentID, req.Body, req.Mentions)
+	if err != nil {
+		status, msg := commentErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	c.AuthorName = user.Name
+	httpserver.JSON(w, http.StatusOK, c)
+}
+
+// DELETE /work-orders/{workOrderID}/comments/{commentID}
+//
+// Authors may delete within the edit window; admins and owners at any time.
+// The comment is kept as a tombstone so replies stay threaded.
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	commentID, err := uuid.Parse(chi.URLParam(r, "commentID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid comment ID"})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	existing, err := h.repo.GetWorkOrderComment(r.Context(), orgID, woID, commentID)
+	if err == nil {
+		err = models.CanDeleteComment(existing, user.ID, role, time.Now())
+	}
+	if err == nil {
+		err = h.repo.DeleteWorkOrderComment(r.Context(), orgID, woID, commentID)
+	}
+	if err != nil {
+		status, msg := commentErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			msg = "failed to delete comment"
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "comment deleted",
+		"id":      commentID,
+	})
+}
+
+// GET /work-orders/{workOrderID}/timeline
+//
+// Comments, status moves and field edits in chronological order.
+func (h *Handler) Timeline(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if _, err := h.repo.GetWorkOrderStatus(r.Context(), orgID, woID); err != nil {
+		status, msg := commentErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	entries, err := h.repo.ListWorkOrderTimeline(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch timeline"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      woID,
+		"content": entries,
+	})
+}
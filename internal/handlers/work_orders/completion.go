@@ -0,0 +1,266 @@
+// internal/handlers/work_orders/completion.go
+package work_orders
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"yourapp/internal/auth"
+	"yourapp/internal/handlers/files"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxCompleteJSONBytes bounds the JSON completion body (stroke data included).
+const maxCompleteJSONBytes = 2 << 20
+
+// CompleteRequest is the JSON form of POST /work-orders/{id}/complete.
+// At most one of SignatureFileID (a file uploaded via POST /files) and
+// Signature (pen strokes, rendered to PNG server side) should be given.
+type CompleteRequest struct {
+	Reason          string          `json:"reason"`
+	SignatureFileID *uuid.UUID      `json:"signature_file_id"`
+	Signature       *SignatureInput `json:"signature"`
+}
+
+// signatureSource is whatever the client sent as a signature, not yet stored.
+type signatureSource struct {
+	existing *uuid.UUID
+	filename string
+	body     io.Reader
+}
+
+func (s signatureSource) empty() bool { return s.existing == nil && s.body == nil }
+
+// readCompletion parses either a multipart body (field "signature" holding an
+// image, optional field "reason") or a JSON CompleteRequest. The multipart
+// signature part is buffered because the body must be fully read before we
+// store anything.
+func readCompletion(w http.ResponseWriter, r *http.Request, maxUpload int64) (signatureSource, string, error) {
+	var src signatureSource
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, maxUpload+1<<20)
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return src, "", errors.New("invalid multipart body")
+		}
+		reason := ""
+		for {
+			part, err := mr.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return src, "", err
+			}
+			switch part.FormName() {
+			case "signature":
+				var buf bytes.Buffer
+				if _, err := io.Copy(&buf, part); err != nil {
+					part.Close()
+					return src, "", err
+				}
+				src.filename, src.body = part.FileName(), &buf
+				if src.filename == "" {
+					src.filename = "signature"
+				}
+			case "reason":
+				b, _ := io.ReadAll(io.LimitReader(part, 4096))
+				reason = string(b)
+			}
+			part.Close()
+		}
+		return src, strings.TrimSpace(reason), nil
+	}
+
+	var req CompleteRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCompleteJSONBytes)).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		return src, "", errors.New("invalid request body")
+	}
+	if req.SignatureFileID != nil && req.Signature != nil {
+		return src, "", errors.New("send either signature_file_id or signature, not both")
+	}
+	switch {
+	case req.SignatureFileID != nil:
+		src.existing = req.SignatureFileID
+	case req.Signature != nil:
+		img, err := req.Signature.renderPNG()
+		if err != nil {
+			return src, "", err
+		}
+		src.filename, src.body = "signature.png", bytes.NewReader(img)
+	}
+	return src, strings.TrimSpace(req.Reason), nil
+}
+
+// POST /work-orders/{workOrderID}/complete
+//
+// Completes a work order, capturing a signature when one is supplied and
+// refusing with 422 when required_signature is set and none is on file.
+// Honours If-Match like the other mutating endpoints.
+func (h *Handler) Complete(w http.ResponseWriter, r *http.Request) {
+	org, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	id, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), org, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	state, err := h.repo.GetWorkOrderCompletionState(r.Context(), org, id)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": "work order not found"})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to complete work order"})
+		return
+	}
+	if ifMatch != nil && state.Version != *ifMatch {
+		w.Header().Set("ETag", etagFor(state.Version))
+		httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": models.ErrVersionMismatch.Error()})
+		return
+	}
+	if err := models.CheckStatusTransition(state.Status, models.StatusComplete, role); err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, models.ErrTransitionForbidden) {
+			code = http.StatusForbidden
+		}
+		httpserver.JSON(w, code, map[string]any{
+			"error":   err.Error(),
+			"from":    state.Status,
+			"to":      models.StatusComplete,
+			"allowed": models.AllowedTransitions(state.Status, role),
+		})
+		return
+	}
+
+	src, reason, err := readCompletion(w, r, h.up.MaxUploadBytes())
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			httpserver.JSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": models.ErrFileTooLarge.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if src.empty() && state.RequiredSignature && state.SignatureID == nil {
+		httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": models.ErrSignatureRequired.Error()})
+		return
+	}
+
+	// Store (or look up) the signature before flipping the status
+	var sig *models.File
+	stored := false
+	switch {
+	case src.existing != nil:
+		f, err := h.repo.GetFile(r.Context(), org, *src.existing)
+		if err != nil {
+			status, msg := http.StatusInternalServerError, "failed to complete work order"
+			if errors.Is(err, models.ErrFileNotFound) {
+				status, msg = http.StatusUnprocessableEntity, "signature file not found"
+			}
+			httpserver.JSON(w, status, map[string]string{"error": msg})
+			return
+		}
+		sig = &f
+	case src.body != nil:
+		f, err := h.up.Save(r.Context(), org, user.ID, src.filename, src.body)
+		if err != nil {
+			writeSaveError(w, err)
+			return
+		}
+		sig, stored = &f, true
+	}
+	if sig != nil && !strings.HasPrefix(sig.ContentType, "image/") {
+		if stored {
+			_ = h.up.Remove(r.Context(), org, sig.ID)
+		}
+		httpserver.JSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": "signature must be an image"})
+		return
+	}
+
+	var sigID *uuid.UUID
+	if sig != nil {
+		sigID = &sig.ID
+	}
+	err = h.repo.CompleteWorkOrder(r.Context(), org, id, user.ID, state.Status, sigID, reason, ifMatch)
+	if err != nil {
+		if stored {
+			_ = h.up.Remove(r.Context(), org, sig.ID)
+		}
+		switch {
+		case errors.Is(err, models.ErrStatusConflict):
+			httpserver.JSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrVersionMismatch):
+			h.setCurrentETag(r.Context(), w, org, id)
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrSignatureRequired):
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrWorkOrderBlocked):
+			h.writeBlocked(w, r, org, id)
+		case errors.Is(err, models.ErrChecklistIncomplete):
+			h.writeChecklistIncomplete(w, r, org, id)
+		default:
+			httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to complete work order"})
+		}
+		return
+	}
+
+	h.setCurrentETag(r.Context(), w, org, id)
+	resp := map[string]any{
+		"message":         "work order completed",
+		"id":              id,
+		"from":            state.Status,
+		"status":          models.StatusComplete,
+		"completed_by_id": user.ID,
+	}
+	if sig != nil {
+		sig.DownloadURL = files.DownloadURL(sig.ID)
+		resp["signature"] = sig
+	}
+	httpserver.JSON(w, http.StatusOK, resp)
+}
+
+// writeSaveError renders an Uploader.Save failure.
+func writeSaveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrFileTooLarge):
+		httpserver.JSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrUnsupportedFileType):
+		httpserver.JSON(w, http.StatusUnsupportedMediaType, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrEmptyFile):
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrStorageQuotaExceeded):
+		httpserver.JSON(w, http.StatusInsufficientStorage, map[string]string{"error": err.Error()})
+	default:
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to store signature"})
+	}
+}
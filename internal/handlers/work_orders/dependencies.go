@@ -0,0 +1,207 @@
+// internal/handlers/work_orders/dependencies.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// writeBlocked answers a status change refused because of unfinished
+// blockers with 409 and the work orders still in the way.
+func (h *Handler) writeBlocked(w http.ResponseWriter, r *http.Request, orgID, woID uuid.UUID) {
+	resp := map[string]any{"error": models.ErrWorkOrderBlocked.Error()}
+	if deps, err := h.repo.ListWorkOrderDependencies(r.Context(), orgID, woID); err == nil {
+		resp["blocked_by"] = deps.Unresolved()
+	}
+	httpserver.JSON(w, http.StatusConflict, resp)
+}
+
+// PUT /work-orders/{workOrderID}/parent
+//
+//	{ "parent_id": "…" }   // null makes the work order top-level
+//
+// Honours If-Match like PATCH and returns the new ETag.
+func (h *Handler) SetParent(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	defer r.Body.Close()
+	var in models.ParentInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	version, err := h.repo.SetWorkOrderParent(r.Context(), orgID, woID, user.ID, in.ParentID, ifMatch)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrWorkOrderNotFound):
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrParentNotFound):
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrHierarchyCycle):
+			httpserver.JSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrVersionMismatch):
+			h.setCurrentETag(r.Context(), w, orgID, woID)
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+		default:
+			httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to set parent work order"})
+		}
+		return
+	}
+	w.Header().Set("ETag", etagFor(version))
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":        woID,
+		"parent_id": in.ParentID,
+		"version":   version,
+	})
+}
+
+// GET /work-orders/{workOrderID}/dependencies
+func (h *Handler) Dependencies(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	deps, err := h.repo.ListWorkOrderDependencies(r.Context(), orgID, woID)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch dependencies"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, deps)
+}
+
+// POST /work-orders/{workOrderID}/dependencies
+//
+//	{ "blocked_by_id": "…" }
+//
+// The work order cannot start until the blocker is complete or cancelled.
+func (h *Handler) AddDependency(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	defer r.Body.Close()
+	var in models.DependencyInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if in.BlockedByID == uuid.Nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "blocked_by_id is required"})
+		return
+	}
+	if err := h.repo.AddWorkOrderDependency(r.Context(), orgID, woID, in.BlockedByID, user.ID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrWorkOrderNotFound):
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrBlockerNotFound), errors.Is(err, models.ErrSelfDependency):
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		case errors.Is(err, models.ErrDependencyCycle), errors.Is(err, models.ErrDependencyExists):
+			httpserver.JSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+		default:
+			httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to add dependency"})
+		}
+		return
+	}
+	deps, err := h.repo.ListWorkOrderDependencies(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch dependencies"})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, deps)
+}
+
+// DELETE /work-orders/{workOrderID}/dependencies/{blockedByID}
+func (h *Handler) RemoveDependency(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	blockerID, err := uuid.Parse(chi.URLParam(r, "blockedByID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid blocking work order ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	if err := h.repo.RemoveWorkOrderDependency(r.Context(), orgID, woID, blockerID); err != nil {
+		if errors.Is(err, models.ErrDependencyNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to remove dependency"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
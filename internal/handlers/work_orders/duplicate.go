@@ -0,0 +1,94 @@
+// internal/handlers/work_orders/duplicate.go
+package work_orders
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// readOverrides reads an optional JSON object of create-payload keys. An
+// empty body means no overrides.
+func readOverrides(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+	if err != nil {
+		return nil, errors.New("invalid request body")
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return []byte("{}"), nil
+	}
+	var body map[string]any
+	if raw[0] != '{' || json.Unmarshal(raw, &body) != nil {
+		return nil, errors.New("overrides must be a JSON object")
+	}
+	return raw, nil
+}
+
+// createErrorStatus maps the errors of the work order create paths
+// (create, duplicate) to an HTTP status and message.
+func createErrorStatus(err error, fallback string) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrCategoryNotFound), errors.Is(err, models.ErrTaskBaseNotFound),
+		errors.Is(err, models.ErrInvalidReference), errors.Is(err, models.ErrInvalidWorkOrderPayload):
+		return http.StatusUnprocessableEntity, err.Error()
+	default:
+		return http.StatusInternalServerError, fallback
+	}
+}
+
+// POST /work-orders/{workOrderID}/duplicate
+//
+// Clones the work order with its assignees, customers, task list (without
+// values) and attachments. The optional body overrides create-payload keys,
+// e.g. {"title": "...", "dueDate": "2025-10-01"}.
+func (h *Handler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	srcID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	overrides, err := readOverrides(w, r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	id, err := h.repo.DuplicateWorkOrder(r.Context(), orgID, srcID, user.ID, overrides)
+	if err != nil {
+		status, msg := createErrorStatus(err, "failed to duplicate work order")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, map[string]any{
+		"message":   "duplicated work order",
+		"id":        id,
+		"source_id": srcID,
+	})
+}
@@ -0,0 +1,60 @@
+// internal/handlers/work_orders/etag.go
+package work_orders
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+var errBadIfMatch = errors.New("invalid If-Match header")
+
+// etagFor renders a work order version as a strong ETag.
+func etagFor(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the version the client expects, or nil when the
+// header is absent or "*" (no precondition).
+func parseIfMatch(r *http.Request) (*int64, error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" || raw == "*" {
+		return nil, nil
+	}
+	// Work orders carry a single version; accept the first listed tag.
+	tag := strings.TrimSpace(strings.Split(raw, ",")[0])
+	tag = strings.TrimPrefix(tag, "W/")
+	tag = strings.Trim(tag, `"`)
+	v, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || v < 1 {
+		return nil, errBadIfMatch
+	}
+	return &v, nil
+}
+
+// matchesIfNoneMatch reports whether etag is listed in If-None-Match.
+func matchesIfNoneMatch(r *http.Request, etag string) bool {
+	raw := r.Header.Get("If-None-Match")
+	if raw == "" {
+		return false
+	}
+	for _, c := range strings.Split(raw, ",") {
+		c = strings.TrimPrefix(strings.TrimSpace(c), "W/")
+		if c == etag || c == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// setCurrentETag looks up the latest version and exposes it as the ETag so
+// clients can chain edits without a re-fetch. Failures are non-fatal.
+func (h *Handler) setCurrentETag(ctx context.Context, w http.ResponseWriter, orgID, woID uuid.UUID) {
+	if v, err := h.repo.GetWorkOrderVersion(ctx, orgID, woID); err == nil {
+		w.Header().Set("ETag", etagFor(v))
+	}
+}
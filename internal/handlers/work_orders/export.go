@@ -0,0 +1,216 @@
+// internal/handlers/work_orders/export.go
+package work_orders
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client.
+const exportFlushEvery = 500
+
+var exportHeader = []string{
+	"ID", "Custom ID", "Title", "Status", "Priority", "Due date", "Created at", "Completed on",
+	"Primary worker", "Assignees", "Team", "Location", "Asset", "Category",
+	"Tasks completed", "Tasks total", "SLA breached",
+}
+
+// exportRowWriter is implemented by the CSV and XLSX encoders.
+type exportRowWriter interface {
+	WriteRow(cells []any) error
+	Flush() error
+	Close() error
+}
+
+// GET /work-orders/export?format=csv|xlsx
+//
+// Filters and sort are the same as GET /work-orders. For OR groups pass a
+// POST /work-orders/search body, URL-encoded, as search=<json> (or POST it to
+// /work-orders/export). Paging is ignored: every matching work order is
+// streamed, in the requested order.
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	switch format {
+	case "":
+		format = "csv"
+	case "csv", "xlsx":
+	default:
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "format must be csv or xlsx"})
+		return
+	}
+
+	req, err := exportSearch(w, r, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	arg, err := req.payload(user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Large exports outlive any server-wide write deadline.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	filename := "work-orders-" + time.Now().UTC().Format("20060102-150405") + "." + format
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	var out exportRowWriter
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		out, err = newXLSXWriter(w, "Work orders")
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		out = newCSVExportWriter(w)
+	}
+	if err == nil {
+		header := make([]any, len(exportHeader))
+		for i, v := range exportHeader {
+			header[i] = v
+		}
+		err = out.WriteRow(header)
+	}
+
+	n := 0
+	if err == nil {
+		err = h.repo.ExportWorkOrders(r.Context(), orgID, arg, func(wo models.WorkOrderExportRow) error {
+			if err := out.WriteRow(exportCells(wo)); err != nil {
+				return err
+			}
+			if n++; n%exportFlushEvery == 0 {
+				if err := out.Flush(); err != nil {
+					return err
+				}
+				_ = rc.Flush()
+			}
+			return nil
+		})
+	}
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		// The status line is gone by now; drop the connection so the client
+		// sees a failed download rather than a silently truncated file.
+		slog.ErrorContext(r.Context(), "work order export failed", "err", err, "format", format, "rows", n)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportSearch reads the filters for Export from a JSON body (POST), the
+// search parameter, or the GET /work-orders query string, in that order.
+func exportSearch(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (SearchRequest, error) {
+	var req SearchRequest
+	switch {
+	case r.Method == http.MethodPost:
+		defer r.Body.Close()
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			return req, errors.New("invalid request body")
+		}
+	case r.URL.Query().Get("search") != "":
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("search")), &req); err != nil {
+			return req, errors.New("search must be a JSON search request")
+		}
+	default:
+		var err error
+		if req, err = searchFromQuery(r, userID); err != nil {
+			return req, err
+		}
+	}
+	req.PageNum, req.PageSize = 0, 0
+	return req, nil
+}
+
+func exportCells(wo models.WorkOrderExportRow) []any {
+	return []any{
+		wo.ID.String(),
+		wo.CustomID,
+		wo.Title,
+		wo.Status,
+		wo.Priority,
+		exportTime(wo.DueDate),
+		exportTime(&wo.CreatedAt),
+		exportTime(wo.CompletedOn),
+		wo.PrimaryWorker,
+		wo.Assignees,
+		wo.Team,
+		wo.Location,
+		wo.Asset,
+		wo.Category,
+		wo.TasksCompleted,
+		wo.TasksTotal,
+		strconv.FormatBool(wo.SLABreached),
+	}
+}
+
+func exportTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvExportWriter adapts encoding/csv to exportRowWriter.
+type csvExportWriter struct {
+	cw  *csv.Writer
+	rec []string
+}
+
+func newCSVExportWriter(w http.ResponseWriter) *csvExportWriter {
+	return &csvExportWriter{cw: csv.NewWriter(w)}
+}
+
+func (c *csvExportWriter) WriteRow(cells []any) error {
+	c.rec = c.rec[:0]
+	for _, v := range cells {
+		switch x := v.(type) {
+		case string:
+			c.rec = append(c.rec, csvSafe(x))
+		default:
+			c.rec = append(c.rec, fmt.Sprint(x))
+		}
+	}
+	return c.cw.Write(c.rec)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
+
+func (c *csvExportWriter) Close() error { return c.Flush() }
+
+// csvSafe keeps spreadsheet apps from evaluating user text as a formula.
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
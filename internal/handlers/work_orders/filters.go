@@ -0,0 +1,374 @@
+// internal/handlers/work_orders/filters.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Bounds on a search request's filter tree.
+const (
+	maxFilterDepth  = 4
+	maxFilterLeaves = 50
+)
+
+type filterKind int
+
+const (
+	filterStatus filterKind = iota
+	filterPriority
+	filterAssignee
+	filterRef
+	filterDate
+	filterBool
+	filterText
+)
+
+// filterFields lists every field ListWorkOrdersPaged can filter on.
+var filterFields = map[string]filterKind{
+	"status":        filterStatus,
+	"priority":      filterPriority,
+	"assignee":      filterAssignee,
+	"primaryWorker": filterRef,
+	"team":          filterRef,
+	"location":      filterRef,
+	"asset":         filterRef,
+	"category":      filterRef,
+	"dueDate":       filterDate,
+	"createdAt":     filterDate,
+	"completedOn":   filterDate,
+	"overdue":       filterBool,
+	"archived":      filterBool,
+	"slaBreached":   filterBool,
+	"slaAtRisk":     filterBool,
+	"text":          filterText,
+}
+
+// filterNode is the normalized filter tree evaluated by
+// work_order_matches_filter(): a group (Op "and"/"or" with Filters) or a
+// predicate on Field with text Values.
+type filterNode struct {
+	Op      string       `json:"op"`
+	Field   string       `json:"field,omitempty"`
+	Values  []string     `json:"values,omitempty"`
+	Filters []filterNode `json:"filters,omitempty"`
+}
+
+// pagedQuery is the payload ListWorkOrdersPaged reads.
+type pagedQuery struct {
+	PageNum   int           `json:"pageNum"`
+	PageSize  int           `json:"pageSize"`
+	SortField string        `json:"sortField,omitempty"`
+	Direction SortDirection `json:"direction,omitempty"`
+	Filter    *filterNode   `json:"filter,omitempty"`
+	After     *seekKey      `json:"after,omitempty"`
+}
+
+// seekKey is the last row of the previous page. When set, the query seeks
+// past it on the active sort instead of skipping pageNum pages, so rows
+// inserted or removed meanwhile don't shift the page.
+type seekKey struct {
+	Key       *string   `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// payload validates the request and encodes it for ListWorkOrdersPaged.
+// Top-level filter fields are ANDed. "me" in assignee/primaryWorker values
+// stands for userID. pageSize is clamped to maxListLimit, as on GET.
+func (req SearchRequest) payload(userID uuid.UUID) ([]byte, error) {
+	if req.PageNum < 0 || req.PageSize < 0 {
+		return nil, errors.New("pageNum and pageSize must not be negative")
+	}
+	q := pagedQuery{
+		PageNum:   req.PageNum,
+		PageSize:  req.PageSize,
+		SortField: req.SortField,
+		Direction: req.Direction,
+		After:     req.after,
+	}
+	switch {
+	case q.PageSize == 0:
+		q.PageSize = defaultListLimit
+	case q.PageSize > maxListLimit:
+		q.PageSize = maxListLimit
+	}
+	if len(req.FilterFields) > 0 {
+		c := filterCompiler{userID: userID}
+		root, err := c.group("and", req.FilterFields, 1)
+		if err != nil {
+			return nil, err
+		}
+		q.Filter = &root
+	}
+	return json.Marshal(q)
+}
+
+type filterCompiler struct {
+	userID uuid.UUID
+	leaves int
+}
+
+func (c *filterCompiler) group(op string, fields []FilterField, depth int) (filterNode, error) {
+	if depth > maxFilterDepth {
+		return filterNode{}, fmt.Errorf("filter groups nest deeper than %d levels", maxFilterDepth)
+	}
+	if len(fields) == 0 {
+		return filterNode{}, errors.New("filter group is empty")
+	}
+	node := filterNode{Op: op}
+	for _, f := range fields {
+		n, err := c.node(f, depth)
+		if err != nil {
+			return filterNode{}, err
+		}
+		node.Filters = append(node.Filters, n)
+	}
+	return node, nil
+}
+
+func (c *filterCompiler) node(f FilterField, depth int) (filterNode, error) {
+	op := strings.ToLower(strings.TrimSpace(f.Operation))
+	if f.Filters != nil {
+		if f.Field != "" {
+			return filterNode{}, errors.New("a filter group cannot also name a field")
+		}
+		switch op {
+		case "", "and":
+			op = "and"
+		case "or":
+		default:
+			return filterNode{}, fmt.Errorf("unknown group operation: %s", f.Operation)
+		}
+		return c.group(op, f.Filters, depth+1)
+	}
+
+	if c.leaves++; c.leaves > maxFilterLeaves {
+		return filterNode{}, fmt.Errorf("more than %d filters", maxFilterLeaves)
+	}
+	kind, ok := filterFields[f.Field]
+	if !ok {
+		return filterNode{}, fmt.Errorf("unknown filter field: %s", f.Field)
+	}
+	vals, err := filterValues(f)
+	if err != nil {
+		return filterNode{}, fmt.Errorf("%s: %w", f.Field, err)
+	}
+	node := filterNode{Field: f.Field}
+	switch kind {
+	case filterStatus, filterPriority, filterAssignee:
+		node.Op, node.Values, err = c.listPredicate(kind, op, vals)
+	case filterRef:
+		node.Op, node.Values, err = c.refPredicate(f.Field, op, vals)
+	case filterDate:
+		node.Op, node.Values, err = datePredicate(op, vals)
+	case filterBool:
+		if op != "eq" && op != "equals" {
+			return filterNode{}, fmt.Errorf("%s: unsupported operation %q (use eq)", f.Field, f.Operation)
+		}
+		node.Op = "eq"
+		node.Values, err = boolValue(vals)
+	case filterText:
+		if op != "cn" && op != "contains" && op != "like" {
+			return filterNode{}, fmt.Errorf("%s: unsupported operation %q (use cn)", f.Field, f.Operation)
+		}
+		if len(vals) != 1 || strings.TrimSpace(vals[0]) == "" {
+			return filterNode{}, errors.New("text: give one non-empty value")
+		}
+		node.Op, node.Values = "cn", []string{strings.TrimSpace(vals[0])}
+	}
+	if err != nil {
+		return filterNode{}, fmt.Errorf("%s: %w", f.Field, err)
+	}
+	return node, nil
+}
+
+// listPredicate handles status, priority and assignee: eq or in.
+func (c *filterCompiler) listPredicate(kind filterKind, op string, vals []string) (string, []string, error) {
+	if op != "in" && op != "eq" {
+		return "", nil, fmt.Errorf("unsupported operation %q (use in or eq)", op)
+	}
+	if len(vals) == 0 {
+		return "", nil, errors.New("no values")
+	}
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		switch kind {
+		case filterStatus:
+			st, err := models.ParseWorkOrderStatus(v)
+			if err != nil {
+				return "", nil, fmt.Errorf("unknown status %s", v)
+			}
+			out[i] = string(st)
+		case filterPriority:
+			p, err := models.ParseWorkOrderPriority(v)
+			if err != nil {
+				return "", nil, fmt.Errorf("unknown priority %s", v)
+			}
+			out[i] = p
+		default:
+			id, err := c.userRef(v)
+			if err != nil {
+				return "", nil, err
+			}
+			out[i] = id
+		}
+	}
+	return "in", out, nil
+}
+
+// refPredicate handles the foreign keys: eq/in a set of IDs, or isnull.
+func (c *filterCompiler) refPredicate(field, op string, vals []string) (string, []string, error) {
+	switch op {
+	case "isnull":
+		b, err := boolValue(vals)
+		return "isnull", b, err
+	case "in", "eq":
+	default:
+		return "", nil, fmt.Errorf("unsupported operation %q (use in, eq or isnull)", op)
+	}
+	if len(vals) == 0 {
+		return "", nil, errors.New("no values")
+	}
+	out := make([]string, len(vals))
+	for i, v := range vals {
+		if field == "primaryWorker" {
+			id, err := c.userRef(v)
+			if err != nil {
+				return "", nil, err
+			}
+			out[i] = id
+			continue
+		}
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid ID %s", v)
+		}
+		out[i] = id.String()
+	}
+	return "in", out, nil
+}
+
+// userRef accepts a user ID or "me".
+func (c *filterCompiler) userRef(v string) (string, error) {
+	if strings.EqualFold(v, "me") {
+		if c.userID == uuid.Nil {
+			return "", errors.New(`"me" needs a signed-in user`)
+		}
+		return c.userID.String(), nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return "", fmt.Errorf("invalid user ID %s", v)
+	}
+	return id.String(), nil
+}
+
+// datePredicate handles gt/gte/lt/lte/between/isnull. Bare dates used as
+// upper bounds (and as the gt bound) cover the whole day.
+func datePredicate(op string, vals []string) (string, []string, error) {
+	switch op {
+	case "ge":
+		op = "gte"
+	case "le":
+		op = "lte"
+	}
+	switch op {
+	case "isnull":
+		b, err := boolValue(vals)
+		return op, b, err
+	case "gt", "gte", "lt", "lte":
+		if len(vals) != 1 {
+			return "", nil, errors.New("give one date")
+		}
+		t, err := parseDateParam(vals[0], op == "gt" || op == "lte")
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid date %s", vals[0])
+		}
+		return op, []string{t}, nil
+	case "between":
+		if len(vals) != 2 {
+			return "", nil, errors.New("between takes two dates")
+		}
+		from, err := parseDateParam(vals[0], false)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid date %s", vals[0])
+		}
+		to, err := parseDateParam(vals[1], true)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid date %s", vals[1])
+		}
+		if ft, tt := mustParseTime(from), mustParseTime(to); tt.Before(ft) {
+			return "", nil, errors.New("between dates are reversed")
+		}
+		return op, []string{from, to}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported operation %q (use gt, gte, lt, lte, between or isnull)", op)
+}
+
+// mustParseTime reads back a timestamp formatted by parseDateParam.
+func mustParseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+func boolValue(vals []string) ([]string, error) {
+	if len(vals) != 1 {
+		return nil, errors.New("give one true/false value")
+	}
+	b, err := strconv.ParseBool(vals[0])
+	if err != nil {
+		return nil, errors.New("value must be true or false")
+	}
+	return []string{strconv.FormatBool(b)}, nil
+}
+
+// filterValues collects a predicate's operands from values or value (a
+// scalar or an array).
+func filterValues(f FilterField) ([]string, error) {
+	if len(f.Values) > 0 {
+		if f.Value != nil {
+			return nil, errors.New("give value or values, not both")
+		}
+		return f.Values, nil
+	}
+	switch v := f.Value.(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, x := range v {
+			s, err := scalarString(x)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		s, err := scalarString(v)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+}
+
+func scalarString(v any) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return strings.TrimSpace(x), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), nil
+	}
+	return "", errors.New("unsupported value type")
+}
@@ -0,0 +1,389 @@
+// internal/handlers/work_orders/list.go
+package work_orders
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 100
+)
+
+// listCursor is the opaque paging token handed back as nextCursor. It holds
+// the sort, the page size and the seek key of the last row returned, so the
+// next page starts right after that row however the table changed since.
+type listCursor struct {
+	Sort string        `json:"f,omitempty"`
+	Dir  SortDirection `json:"d,omitempty"`
+	Key  *string       `json:"k,omitempty"`
+	At   time.Time     `json:"c"`
+	ID   uuid.UUID     `json:"i"`
+	Size int           `json:"s"`
+}
+
+func encodeCursor(c listCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (listCursor, error) {
+	var c listCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, errors.New("invalid cursor")
+	}
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == uuid.Nil || c.At.IsZero() || c.Size < 1 || c.Size > maxListLimit {
+		return listCursor{}, errors.New("invalid cursor")
+	}
+	switch c.Dir {
+	case "", DirectionASC, DirectionDESC:
+	default:
+		return listCursor{}, errors.New("invalid cursor")
+	}
+	if c.Key != nil && timeSortFields[strings.ToLower(c.Sort)] {
+		if _, err := time.Parse(time.RFC3339Nano, *c.Key); err != nil {
+			return listCursor{}, errors.New("invalid cursor")
+		}
+	}
+	return c, nil
+}
+
+// timeSortFields are the sort fields ListWorkOrdersPaged compares as
+// timestamps; the other whitelisted ones compare as text.
+var timeSortFields = map[string]bool{"due_date": true, "created_at": true, "updated_at": true}
+
+// sortKey returns wo's value for the sort field as ListWorkOrdersPaged
+// compares it, or nil when it is NULL or the field isn't sortable.
+func sortKey(wo models.WorkOrder, field string) *string {
+	var v string
+	switch strings.ToLower(field) {
+	case "custom_id":
+		v = wo.CustomID
+	case "priority":
+		v = wo.Priority
+	case "status":
+		v = wo.Status
+	case "title":
+		v = wo.Title
+	case "due_date":
+		if wo.DueDate.IsZero() {
+			return nil
+		}
+		v = wo.DueDate.Format(time.RFC3339Nano)
+	case "created_at":
+		v = wo.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		v = wo.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return nil
+	}
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// GET /work-orders
+//
+// Query string (all optional, lists are comma separated or repeated):
+//
+//	status=OPEN,IN_PROGRESS  priority=HIGH,CRITICAL  assignee=<uuid>|me
+//	primaryWorker=<uuid>|me  team=<uuid>  location=<uuid>  asset=<uuid>
+//	category=<uuid>  dueFrom=2025-09-01  dueTo=2025-09-30
+//	createdFrom=...  createdTo=...  completedFrom=...  completedTo=...
+//	overdue=true  q=gearbox  archived=false  slaBreached=true  slaAtRisk=true
+//	sort=due_date  direction=ASC  limit=50  cursor=<nextCursor>
+//
+// A cursor carries the sort, direction and limit of the page it came from;
+// those parameters are ignored when it is given.
+//
+// POST /work-orders/search takes the full grammar, including OR groups.
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	req, err := searchFromQuery(r, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	arg, err := req.payload(user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	wos, count, err := h.repo.ListWorkOrdersPaged(r.Context(), orgID, arg)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to list work orders"})
+		return
+	}
+
+	resp := map[string]any{
+		"totalElements": count,
+		"content":       wos,
+	}
+	if n := len(wos); n > 0 && n == req.PageSize {
+		last := wos[n-1]
+		resp["nextCursor"] = encodeCursor(listCursor{
+			Sort: req.SortField,
+			Dir:  req.Direction,
+			Key:  sortKey(last, req.SortField),
+			At:   last.CreatedAt,
+			ID:   last.ID,
+			Size: req.PageSize,
+		})
+	}
+	httpserver.JSONCached(w, r, http.StatusOK, resp)
+}
+
+// searchFromQuery translates GET /work-orders query parameters into the
+// SearchRequest document understood by ListWorkOrdersPaged.
+func searchFromQuery(r *http.Request, userID uuid.UUID) (SearchRequest, error) {
+	q := r.URL.Query()
+	req := SearchRequest{PageSize: defaultListLimit}
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListLimit {
+			return req, fmt.Errorf("limit must be between 1 and %d", maxListLimit)
+		}
+		req.PageSize = n
+	}
+	var cursor *listCursor
+	if v := q.Get("cursor"); v != "" {
+		c, err := decodeCursor(v)
+		if err != nil {
+			return req, err
+		}
+		cursor = &c
+	}
+
+	if vals := splitList(q["status"]); len(vals) > 0 {
+		for i, v := range vals {
+			st, err := models.ParseWorkOrderStatus(v)
+			if err != nil {
+				return req, fmt.Errorf("unknown status: %s", v)
+			}
+			vals[i] = string(st)
+		}
+		req.FilterFields = append(req.FilterFields, FilterField{Field: "status", Operation: "in", Values: vals})
+	}
+	if vals := splitList(q["priority"]); len(vals) > 0 {
+		for i, v := range vals {
+			p, err := models.ParseWorkOrderPriority(v)
+			if err != nil {
+				return req, fmt.Errorf("unknown priority: %s", v)
+			}
+			vals[i] = p
+		}
+		req.FilterFields = append(req.FilterFields, FilterField{Field: "priority", Operation: "in", Values: vals})
+	}
+	if vals := splitList(q["assignee"]); len(vals) > 0 {
+		for i, v := range vals {
+			if strings.EqualFold(v, "me") {
+				vals[i] = userID.String()
+				continue
+			}
+			if _, err := uuid.Parse(v); err != nil {
+				return req, fmt.Errorf("invalid assignee: %s", v)
+			}
+		}
+		req.FilterFields = append(req.FilterFields, FilterField{Field: "assignee", Operation: "in", Values: vals})
+	}
+	for _, field := range []string{"primaryWorker", "team", "location", "asset", "category"} {
+		if vals := splitList(q[field]); len(vals) > 0 {
+			req.FilterFields = append(req.FilterFields, FilterField{Field: field, Operation: "in", Values: vals})
+		}
+	}
+	for _, r := range []struct{ field, from, to string }{
+		{"dueDate", "dueFrom", "dueTo"},
+		{"createdAt", "createdFrom", "createdTo"},
+		{"completedOn", "completedFrom", "completedTo"},
+	} {
+		if v := q.Get(r.from); v != "" {
+			t, err := parseDateParam(v, false)
+			if err != nil {
+				return req, fmt.Errorf("invalid %s", r.from)
+			}
+			req.FilterFields = append(req.FilterFields, FilterField{Field: r.field, Operation: "gte", Value: t})
+		}
+		if v := q.Get(r.to); v != "" {
+			t, err := parseDateParam(v, true)
+			if err != nil {
+				return req, fmt.Errorf("invalid %s", r.to)
+			}
+			req.FilterFields = append(req.FilterFields, FilterField{Field: r.field, Operation: "lte", Value: t})
+		}
+	}
+	if v := strings.TrimSpace(q.Get("q")); v != "" {
+		req.FilterFields = append(req.FilterFields, FilterField{Field: "text", Operation: "cn", Value: v})
+	}
+	if v := q.Get("archived"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return req, errors.New("archived must be true or false")
+		}
+		req.FilterFields = append(req.FilterFields, FilterField{Field: "archived", Operation: "eq", Value: b})
+	}
+	for _, field := range []string{"overdue", "slaBreached", "slaAtRisk"} {
+		if v := q.Get(field); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return req, fmt.Errorf("%s must be true or false", field)
+			}
+			req.FilterFields = append(req.FilterFields, FilterField{Field: field, Operation: "eq", Value: b})
+		}
+	}
+
+	req.SortField = q.Get("sort")
+	switch d := SortDirection(strings.ToUpper(q.Get("direction"))); d {
+	case "":
+	case DirectionASC, DirectionDESC:
+		req.Direction = d
+	default:
+		return req, errors.New("direction must be ASC or DESC")
+	}
+
+	if cursor != nil {
+		req.PageSize = cursor.Size
+		req.SortField, req.Direction = cursor.Sort, cursor.Dir
+		req.after = &seekKey{Key: cursor.Key, CreatedAt: cursor.At, ID: cursor.ID}
+	}
+	return req, nil
+}
+
+// splitList flattens repeated and comma separated query values.
+func splitList(raw []string) []string {
+	var out []string
+	for _, r := range raw {
+		for _, v := range strings.Split(r, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parseDateParam accepts YYYY-MM-DD or RFC3339. For a bare date used as an
+// upper bound the whole day is included.
+func parseDateParam(v string, endOfDay bool) (string, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC().Format(time.RFC3339Nano), nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return "", err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Microsecond)
+	}
+	return t.UTC().Format(time.RFC3339Nano), nil
+}
+
+// ReplaceRequest is the full document accepted by PUT /work-orders/{id}.
+// Keys mirror the create payload; read-only fields (id, status, custom_id,
+// timestamps) are ignored if a client round-trips a GET response.
+type ReplaceRequest struct {
+	Title              string   `json:"title"`
+	Description        *string  `json:"description"`
+	Priority           string   `json:"priority"`
+	DueDate            *string  `json:"dueDate"`
+	EstimatedStartDate *string  `json:"estimatedStartDate"`
+	EstimatedDuration  *float64 `json:"estimatedDuration"`
+	RequiredSignature  bool     `json:"requiredSignature"`
+	PrimaryWorker      *string  `json:"primary_worker"`
+	Location           *string  `json:"location"`
+	Team               *string  `json:"team"`
+	Asset              *string  `json:"asset"`
+	Category           *string  `json:"category"`
+	AssignedTo         []string `json:"assigned_to"`
+	Customers          []string `json:"customers"`
+	Archived           bool     `json:"archived"`
+}
+
+// toPayload validates the document and expands it into an
+// update_work_order_from_json payload that names every writable key.
+func (d ReplaceRequest) toPayload() (map[string]any, error) {
+	if strings.TrimSpace(d.Title) == "" {
+		return nil, errors.New("title is required")
+	}
+	priority, err := models.ParseWorkOrderPriority(d.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("unknown priority: %s", d.Priority)
+	}
+	duration := 0.0
+	if d.EstimatedDuration != nil {
+		if *d.EstimatedDuration < 0 {
+			return nil, errors.New("estimatedDuration must be >= 0")
+		}
+		duration = *d.EstimatedDuration
+	}
+
+	dates := map[string]*string{"dueDate": d.DueDate, "estimatedStartDate": d.EstimatedStartDate}
+	ids := map[string]*string{"primary_worker": d.PrimaryWorker, "location": d.Location, "team": d.Team, "asset": d.Asset, "category": d.Category}
+
+	payload := map[string]any{
+		"title":             strings.TrimSpace(d.Title),
+		"description":       d.Description,
+		"priority":          priority,
+		"estimatedDuration": duration,
+		"requiredSignature": d.RequiredSignature,
+		"archived":          d.Archived,
+	}
+	for key, v := range dates {
+		if v == nil || *v == "" {
+			payload[key] = nil
+			continue
+		}
+		if _, err := parseDateParam(*v, false); err != nil {
+			return nil, fmt.Errorf("invalid %s", key)
+		}
+		payload[key] = *v
+	}
+	for key, v := range ids {
+		if v == nil || *v == "" {
+			payload[key] = nil
+			continue
+		}
+		if _, err := uuid.Parse(*v); err != nil {
+			return nil, fmt.Errorf("invalid %s", key)
+		}
+		payload[key] = *v
+	}
+	for key, list := range map[string][]string{"assigned_to": d.AssignedTo, "customers": d.Customers} {
+		for _, v := range list {
+			if _, err := uuid.Parse(v); err != nil {
+				return nil, fmt.Errorf("invalid %s entry: %s", key, v)
+			}
+		}
+		if list == nil {
+			list = []string{}
+		}
+		payload[key] = list
+	}
+	return payload, nil
+}
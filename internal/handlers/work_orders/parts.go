@@ -0,0 +1,202 @@
+// internal/handlers/work_orders/parts.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// partUsageErrorStatus maps repo/model errors to a response.
+func partUsageErrorStatus(err error, fallback string) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound), errors.Is(err, models.ErrPartNotFound),
+		errors.Is(err, models.ErrPartUsageNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrPartArchived):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, models.ErrSerialRequired), errors.Is(err, models.ErrSerialQuantity),
+		errors.Is(err, models.ErrBatchRequired):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrInvalidPartQuantity), errors.Is(err, models.ErrNegativeUnitCost),
+		errors.Is(err, models.ErrPartUsageNotesTooLong):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, fallback
+	}
+}
+
+// GET /work-orders/{workOrderID}/parts
+//
+// Part lines in usage order plus the same totals as the detail's parts_usage.
+func (h *Handler) ListParts(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if _, err := h.repo.GetWorkOrderStatus(r.Context(), orgID, woID); err != nil {
+		status, msg := partUsageErrorStatus(err, "failed to fetch work order")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	lines, err := h.repo.ListWorkOrderParts(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch parts"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      woID,
+		"content": lines,
+		"totals":  models.SumPartUsage(lines),
+	})
+}
+
+// POST /work-orders/{workOrderID}/parts
+//
+//	{
+//	  "part_id": "uuid",
+//	  "quantity": 1,
+//	  "serial_or_batch": "SN-88213",   // required for SERIAL / BATCH parts
+//	  "unit_cost": 1240.00,            // optional, defaults to the catalogue price
+//	  "notes": "HSS bearing replaced",
+//	  "used_at": "2025-09-01T10:30:00Z" // optional, defaults to now
+//	}
+func (h *Handler) AddPart(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	var in models.PartUsageInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := in.Normalize(time.Now()); err != nil {
+		status, msg := partUsageErrorStatus(err, "invalid part line")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	line, err := h.repo.AddWorkOrderPart(r.Context(), orgID, woID, user.ID, in)
+	if err != nil {
+		status, msg := partUsageErrorStatus(err, "failed to record part")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, line)
+}
+
+// PUT /work-orders/{workOrderID}/parts/{lineID}
+//
+// Full replace; same body as create.
+func (h *Handler) UpdatePart(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid part line ID"})
+		return
+	}
+	var in models.PartUsageInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := in.Normalize(time.Now()); err != nil {
+		status, msg := partUsageErrorStatus(err, "invalid part line")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	line, err := h.repo.UpdateWorkOrderPart(r.Context(), orgID, woID, lineID, in)
+	if err != nil {
+		status, msg := partUsageErrorStatus(err, "failed to update part line")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, line)
+}
+
+// DELETE /work-orders/{workOrderID}/parts/{lineID}
+func (h *Handler) DeletePart(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	lineID, err := uuid.Parse(chi.URLParam(r, "lineID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid part line ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	if err := h.repo.DeleteWorkOrderPart(r.Context(), orgID, woID, lineID); err != nil {
+		status, msg := partUsageErrorStatus(err, "failed to delete part line")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "part line deleted",
+		"id":      lineID,
+	})
+}
@@ -0,0 +1,108 @@
+// internal/handlers/work_orders/reschedule.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func rescheduleErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrVersionMismatch):
+		return http.StatusPreconditionFailed, err.Error()
+	case errors.Is(err, models.ErrRescheduleClosed), errors.Is(err, models.ErrScheduleConflict):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, models.ErrUserNotMember):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrInvalidReschedule):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, "failed to reschedule work order"
+	}
+}
+
+// POST /work-orders/{workOrderID}/reschedule
+//
+//	{ "start": "2026-03-02T09:00:00Z", "duration_hours": 2,
+//	  "primary_user_id": "...", "assignee_ids": ["..."],
+//	  "dry_run": false, "force": false }
+//
+// Moves a work order to a new start (and optionally duration and people;
+// omitted fields keep their values) after checking each person's capacity.
+// Leave and double-bookings are conflicts: the move is refused with 409
+// listing them unless force is true. Working outside someone's hours, on
+// their day off or past their daily hours are warnings that never block.
+// dry_run reports the check without moving anything. Honours If-Match.
+func (h *Handler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	defer r.Body.Close()
+	var in models.RescheduleInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		status, msg := rescheduleErrorStatus(err)
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	res, err := h.repo.RescheduleWorkOrder(r.Context(), orgID, woID, user.ID, in, ifMatch)
+	if err != nil {
+		status, msg := rescheduleErrorStatus(err)
+		switch {
+		case errors.Is(err, models.ErrScheduleConflict):
+			httpserver.JSON(w, status, map[string]any{
+				"error":     msg,
+				"conflicts": res.Conflicts,
+				"warnings":  res.Warnings,
+				"proposed":  res,
+			})
+			return
+		case errors.Is(err, models.ErrVersionMismatch):
+			h.setCurrentETag(r.Context(), w, orgID, woID)
+		}
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	if res.Applied {
+		w.Header().Set("ETag", etagFor(res.Version))
+	}
+	httpserver.JSON(w, http.StatusOK, res)
+}
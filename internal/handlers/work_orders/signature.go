@@ -0,0 +1,280 @@
+// internal/handlers/work_orders/signature.go
+package work_orders
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	defaultSignatureWidth  = 600
+	defaultSignatureHeight = 200
+	maxSignatureSide       = 2000
+	maxSignaturePoints     = 20000
+	signaturePenRadius     = 1.5
+	// maxSignatureInk caps the summed segment length in pixels, which is
+	// what rendering costs; a real signature is a few thousand.
+	maxSignatureInk = 100000
+)
+
+// SignatureInput is pen input captured on a canvas. Points are in canvas
+// pixels; Strokes holds raw [x, y] points and Paths holds SVG path data
+// (M/L/H/V/Z, absolute or relative). Either or both may be sent.
+type SignatureInput struct {
+	Width   int            `json:"width"`
+	Height  int            `json:"height"`
+	Strokes [][][2]float64 `json:"strokes"`
+	Paths   []string       `json:"paths"`
+}
+
+var errEmptySignature = errors.New("signature has no strokes")
+
+// renderPNG rasterises the strokes as black ink on a transparent canvas.
+func (s SignatureInput) renderPNG() ([]byte, error) {
+	width, height := s.Width, s.Height
+	if width == 0 && height == 0 {
+		width, height = defaultSignatureWidth, defaultSignatureHeight
+	}
+	if width < 1 || height < 1 || width > maxSignatureSide || height > maxSignatureSide {
+		return nil, fmt.Errorf("signature width and height must be between 1 and %d", maxSignatureSide)
+	}
+
+	strokes := append([][][2]float64{}, s.Strokes...)
+	for _, d := range s.Paths {
+		ps, err := parseSVGPath(d)
+		if err != nil {
+			return nil, err
+		}
+		strokes = append(strokes, ps...)
+	}
+	points := 0
+	for _, st := range strokes {
+		points += len(st)
+	}
+	if points == 0 {
+		return nil, errEmptySignature
+	}
+	if points > maxSignaturePoints {
+		return nil, fmt.Errorf("signature has too many points (max %d)", maxSignaturePoints)
+	}
+
+	// Clamp to the canvas so a stray coordinate cannot make a segment huge
+	ink := 0.0
+	for _, st := range strokes {
+		for i := range st {
+			st[i][0] = math.Max(0, math.Min(float64(width), st[i][0]))
+			st[i][1] = math.Max(0, math.Min(float64(height), st[i][1]))
+			if i > 0 {
+				ink += math.Hypot(st[i][0]-st[i-1][0], st[i][1]-st[i-1][1])
+			}
+		}
+	}
+	if ink > maxSignatureInk {
+		return nil, fmt.Errorf("signature strokes are too long (max %d pixels in total)", maxSignatureInk)
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for _, st := range strokes {
+		if len(st) == 1 {
+			stamp(img, st[0][0], st[0][1])
+			continue
+		}
+		for i := 1; i < len(st); i++ {
+			drawSegment(img, st[i-1], st[i])
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawSegment(img *image.NRGBA, a, b [2]float64) {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	steps := int(math.Ceil(math.Hypot(dx, dy) * 2))
+	if steps < 1 {
+		steps = 1
+	}
+	for i := 0; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		stamp(img, a[0]+dx*t, a[1]+dy*t)
+	}
+}
+
+// stamp paints a filled pen disc centred on (x, y), clipped to the canvas.
+func stamp(img *image.NRGBA, x, y float64) {
+	ink := color.NRGBA{A: 0xff}
+	r := signaturePenRadius
+	bounds := img.Bounds()
+	for py := int(math.Floor(y - r)); py <= int(math.Ceil(y+r)); py++ {
+		for px := int(math.Floor(x - r)); px <= int(math.Ceil(x+r)); px++ {
+			if !(image.Point{px, py}.In(bounds)) {
+				continue
+			}
+			if math.Hypot(float64(px)+0.5-x, float64(py)+0.5-y) <= r {
+				img.SetNRGBA(px, py, ink)
+			}
+		}
+	}
+}
+
+// parseSVGPath turns the straight-line subset of SVG path data into strokes.
+// Curves are rejected rather than approximated.
+func parseSVGPath(d string) ([][][2]float64, error) {
+	toks, err := tokenizePath(d)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		out        [][][2]float64
+		cur        [][2]float64
+		x, y       float64
+		startX     float64
+		startY     float64
+		cmd        byte
+		haveCursor bool
+	)
+	flush := func() {
+		if len(cur) > 0 {
+			out = append(out, cur)
+		}
+		cur = nil
+	}
+	num := func(i *int) (float64, error) {
+		if *i >= len(toks) || toks[*i].cmd != 0 {
+			return 0, errors.New("invalid signature path: expected number")
+		}
+		v := toks[*i].num
+		*i++
+		return v, nil
+	}
+	for i := 0; i < len(toks); {
+		if toks[i].cmd != 0 {
+			cmd = toks[i].cmd
+			i++
+		} else if cmd == 0 {
+			return nil, errors.New("invalid signature path: missing command")
+		}
+		rel := unicode.IsLower(rune(cmd))
+		switch unicode.ToUpper(rune(cmd)) {
+		case 'M':
+			nx, err := num(&i)
+			if err != nil {
+				return nil, err
+			}
+			ny, err := num(&i)
+			if err != nil {
+				return nil, err
+			}
+			if rel && haveCursor {
+				nx, ny = x+nx, y+ny
+			}
+			flush()
+			x, y, startX, startY, haveCursor = nx, ny, nx, ny, true
+			cur = append(cur, [2]float64{x, y})
+			// Extra coordinate pairs after M are implicit L
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L', 'H', 'V':
+			if !haveCursor {
+				return nil, errors.New("invalid signature path: must start with M")
+			}
+			nx, ny := x, y
+			switch unicode.ToUpper(rune(cmd)) {
+			case 'L':
+				a, err := num(&i)
+				if err != nil {
+					return nil, err
+				}
+				b, err := num(&i)
+				if err != nil {
+					return nil, err
+				}
+				nx, ny = a, b
+				if rel {
+					nx, ny = x+a, y+b
+				}
+			case 'H':
+				a, err := num(&i)
+				if err != nil {
+					return nil, err
+				}
+				nx = a
+				if rel {
+					nx = x + a
+				}
+			case 'V':
+				b, err := num(&i)
+				if err != nil {
+					return nil, err
+				}
+				ny = b
+				if rel {
+					ny = y + b
+				}
+			}
+			x, y = nx, ny
+			cur = append(cur, [2]float64{x, y})
+		case 'Z':
+			if haveCursor {
+				x, y = startX, startY
+				cur = append(cur, [2]float64{x, y})
+			}
+			flush()
+			cmd = 0
+		default:
+			return nil, fmt.Errorf("invalid signature path: unsupported command %q", cmd)
+		}
+	}
+	flush()
+	return out, nil
+}
+
+type pathToken struct {
+	cmd byte
+	num float64
+}
+
+func tokenizePath(d string) ([]pathToken, error) {
+	var toks []pathToken
+	for i := 0; i < len(d); {
+		c := d[i]
+		switch {
+		case c == ' ' || c == ',' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("MmLlHhVvZz", c) >= 0:
+			toks = append(toks, pathToken{cmd: c})
+			i++
+		case strings.IndexByte("CcSsQqTtAa", c) >= 0:
+			return nil, fmt.Errorf("invalid signature path: unsupported command %q", c)
+		default:
+			j := i
+			if d[j] == '-' || d[j] == '+' {
+				j++
+			}
+			for j < len(d) && (d[j] >= '0' && d[j] <= '9' || d[j] == '.' || d[j] == 'e' || d[j] == 'E' ||
+				((d[j] == '-' || d[j] == '+') && (d[j-1] == 'e' || d[j-1] == 'E'))) {
+				j++
+			}
+			v, err := strconv.ParseFloat(d[i:j], 64)
+			if err != nil || j == i {
+				return nil, errors.New("invalid signature path: bad number")
+			}
+			toks = append(toks, pathToken{num: v})
+			i = j
+		}
+	}
+	return toks, nil
+}
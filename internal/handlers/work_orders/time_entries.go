@@ -0,0 +1,351 @@
+// internal/handlers/work_orders/time_entries.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// timeEntryErrorStatus maps repo/model errors to a response.
+func timeEntryErrorStatus(err error, fallback string) (int, string) {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound), errors.Is(err, models.ErrTimeEntryNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, models.ErrTimeEntryForbidden):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, models.ErrTimerRunning), errors.Is(err, models.ErrNoRunningTimer),
+		errors.Is(err, models.ErrTimeEntryOverlap), errors.Is(err, models.ErrTimeEntryRunning):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, models.ErrTimeEntryUserNotFound):
+		return http.StatusUnprocessableEntity, err.Error()
+	case errors.Is(err, models.ErrTimeEntryRange), errors.Is(err, models.ErrTimeEntryTooLong),
+		errors.Is(err, models.ErrTimeEntryInFuture), errors.Is(err, models.ErrNegativeHourlyRate),
+		errors.Is(err, models.ErrTimeEntryNotesTooLong):
+		return http.StatusBadRequest, err.Error()
+	default:
+		return http.StatusInternalServerError, fallback
+	}
+}
+
+// decodeOptional decodes a JSON body into v with the usual size limit and
+// unknown-field check; an empty body leaves v as is.
+func decodeOptional(w http.ResponseWriter, r *http.Request, v any) error {
+	defer r.Body.Close()
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// GET /work-orders/{workOrderID}/time-entries
+//
+// Entries in start order plus the same totals as the detail's time_tracking.
+func (h *Handler) ListTimeEntries(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if _, err := h.repo.GetWorkOrderStatus(r.Context(), orgID, woID); err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to fetch work order")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	entries, err := h.repo.ListWorkOrderTimeEntries(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch time entries"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      woID,
+		"content": entries,
+		"totals":  models.SumTimeEntries(entries),
+	})
+}
+
+// POST /work-orders/{workOrderID}/timer/start
+//
+//	{"hourly_rate": 45.0, "notes": "replacing bearings"}
+//
+// Both fields are optional. A user runs at most one timer at a time.
+func (h *Handler) StartTimer(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	var in models.TimerInput
+	if err := decodeOptional(w, r, &in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		status, msg := timeEntryErrorStatus(err, "invalid timer")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err == nil {
+		err = models.CanLogTimeFor(user.ID, user.ID, role)
+	}
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	e, err := h.repo.StartWorkOrderTimer(r.Context(), orgID, woID, user.ID, in)
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to start timer")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, e)
+}
+
+// POST /work-orders/{workOrderID}/timer/stop
+//
+// Stops the caller's running timer. An optional {"notes": "..."} replaces
+// the notes given at start.
+func (h *Handler) StopTimer(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	var req struct {
+		Notes *string `json:"notes"`
+	}
+	if err := decodeOptional(w, r, &req); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if req.Notes != nil {
+		in := models.TimerInput{Notes: *req.Notes}
+		if err := in.Normalize(); err != nil {
+			status, msg := timeEntryErrorStatus(err, "invalid notes")
+			httpserver.JSON(w, status, map[string]string{"error": msg})
+			return
+		}
+		req.Notes = &in.Notes
+	}
+
+	e, err := h.repo.StopWorkOrderTimer(r.Context(), orgID, woID, user.ID, req.Notes)
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to stop timer")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, e)
+}
+
+// POST /work-orders/{workOrderID}/time-entries
+//
+//	{
+//	  "user_id": "uuid",              // optional, defaults to the caller (admins only for others)
+//	  "started_at": "2025-09-01T08:00:00Z",
+//	  "ended_at": "2025-09-01T10:30:00Z", // or "duration_minutes": 150
+//	  "hourly_rate": 45.0,
+//	  "notes": "replaced bearings"
+//	}
+func (h *Handler) CreateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	var in models.TimeEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := in.Normalize(time.Now()); err != nil {
+		status, msg := timeEntryErrorStatus(err, "invalid time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	target := user.ID
+	if in.UserID != nil {
+		target = *in.UserID
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err == nil {
+		err = models.CanLogTimeFor(target, user.ID, role)
+	}
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	e, err := h.repo.CreateWorkOrderTimeEntry(r.Context(), orgID, woID, target, user.ID, in)
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to save time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusCreated, e)
+}
+
+// PUT /work-orders/{workOrderID}/time-entries/{entryID}
+//
+// Full replace of a finished entry; same body as create. Members edit their
+// own entries, admins and owners anyone's.
+func (h *Handler) UpdateTimeEntry(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid time entry ID"})
+		return
+	}
+	var in models.TimeEntryInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if err := in.Normalize(time.Now()); err != nil {
+		status, msg := timeEntryErrorStatus(err, "invalid time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	existing, err := h.repo.GetWorkOrderTimeEntry(r.Context(), orgID, woID, entryID)
+	target := existing.UserID
+	if in.UserID != nil {
+		target = *in.UserID
+	}
+	if err == nil {
+		err = models.CanChangeTimeEntry(existing, user.ID, role)
+	}
+	if err == nil && target != existing.UserID {
+		err = models.CanLogTimeFor(target, user.ID, role)
+	}
+	if err == nil && existing.Running {
+		err = models.ErrTimeEntryRunning
+	}
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to update time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+
+	e, err := h.repo.UpdateWorkOrderTimeEntry(r.Context(), orgID, woID, entryID, target, in)
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to update time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, e)
+}
+
+// DELETE /work-orders/{workOrderID}/time-entries/{entryID}
+//
+// Also discards a running timer.
+func (h *Handler) DeleteTimeEntry(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	entryID, err := uuid.Parse(chi.URLParam(r, "entryID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid time entry ID"})
+		return
+	}
+	role, err := h.repo.GetRole(r.Context(), orgID, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+
+	existing, err := h.repo.GetWorkOrderTimeEntry(r.Context(), orgID, woID, entryID)
+	if err == nil {
+		err = models.CanChangeTimeEntry(existing, user.ID, role)
+	}
+	if err == nil {
+		err = h.repo.DeleteWorkOrderTimeEntry(r.Context(), orgID, woID, entryID)
+	}
+	if err != nil {
+		status, msg := timeEntryErrorStatus(err, "failed to delete time entry")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "time entry deleted",
+		"id":      entryID,
+	})
+}
@@ -0,0 +1,138 @@
+// internal/handlers/work_orders/trash.go
+package work_orders
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"yourapp/internal/auth"
+	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GET /work-orders/trash?q=
+//
+// Deleted work orders, most recently deleted first. They stay here until
+// restored or purged, automatically after the configured retention.
+func (h *Handler) Trash(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	items, err := h.repo.ListTrashedWorkOrders(r.Context(), orgID, r.URL.Query().Get("q"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch trash"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"content": items,
+	})
+}
+
+// POST /work-orders/{workOrderID}/restore
+//
+// Takes a work order out of the trash with its tasks, files and history.
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	version, err := h.repo.RestoreWorkOrder(r.Context(), orgID, woID, user.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotInTrash) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to restore work order"})
+		return
+	}
+	w.Header().Set("ETag", etagFor(version))
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"message": "work order restored",
+		"id":      woID,
+	})
+}
+
+// DELETE /work-orders/trash/{workOrderID}
+//
+// Permanently deletes one work order from the trash, with its tasks, file
+// links, comments, time and parts. Admins and owners only.
+func (h *Handler) Purge(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+	if err := h.repo.PurgeTrashedWorkOrder(r.Context(), orgID, woID); err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotInTrash) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to purge work order"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /work-orders/trash/purge
+//
+//	{ "older_than_days": 7 }
+//
+// Permanently deletes the work orders that have been in the trash for at
+// least older_than_days; without a body the whole trash is emptied.
+// Admins and owners only.
+func (h *Handler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	defer r.Body.Close()
+	var in models.TrashPurgeInput
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if err := in.Normalize(); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	before := time.Now().AddDate(0, 0, -*in.OlderThanDays)
+	n, err := h.repo.PurgeTrashedWorkOrders(r.Context(), &orgID, before)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to purge work orders"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"purged": n,
+	})
+}
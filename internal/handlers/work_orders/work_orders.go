@@ -3,10 +3,13 @@ package work_orders
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"yourapp/internal/auth"
+	"yourapp/internal/handlers/files"
 	httpserver "yourapp/internal/http"
+	"yourapp/internal/models"
 	"yourapp/internal/repo"
 
 	"github.com/go-chi/chi/v5"
@@ -15,10 +18,11 @@ import (
 
 type Handler struct {
 	repo repo.Repo
+	up   *files.Uploader
 }
 
-func New(repo repo.Repo) *Handler {
-	return &Handler{repo: repo}
+func New(repo repo.Repo, up *files.Uploader) *Handler {
+	return &Handler{repo: repo, up: up}
 }
 
 type SortDirection string
@@ -28,26 +32,52 @@ const (
 	DirectionDESC SortDirection = "DESC"
 )
 
+// FilterField is a predicate ({field, operation, value|values}) or, when
+// Filters is set, a group whose operation is "and" (default) or "or".
+// See filters.go for the fields and operations understood.
+type FilterField struct {
+	Field     string        `json:"field"`
+	Operation string        `json:"operation"`
+	Value     interface{}   `json:"value"`
+	Values    []string      `json:"values"`
+	EnumName  string        `json:"enumName"`
+	Filters   []FilterField `json:"filters,omitempty"`
+}
+
 type SearchRequest struct {
-	PageNum      int `json:"pageNum"`
-	PageSize     int `json:"pageSize"`
-	FilterFields []struct {
-		Field     string      `json:"field"`
-		Operation string      `json:"operation"`
-		Value     interface{} `json:"value"`
-		Values    []string    `json:"values"`
-		EnumName  string      `json:"enumName"`
-	} `json:"filterFields,omitempty"`
+	PageNum      int           `json:"pageNum"`
+	PageSize     int           `json:"pageSize"`
+	FilterFields []FilterField `json:"filterFields,omitempty"`
 
 	// NEW
 	SortField string        `json:"sortField,omitempty"`
 	Direction SortDirection `json:"direction,omitempty"` // "ASC" | "DESC"
+
+	after *seekKey // GET /work-orders cursor; not part of the search body
 }
 
 type StatusRequest struct {
-	Status string `json:"status"` // e.g., "open", "in_progress", "completed"
+	Status string `json:"status"`           // e.g., "OPEN", "IN_PROGRESS", "ON_HOLD", "COMPLETE", "CANCELLED"
+	Reason string `json:"reason,omitempty"` // optional, stored in the status history
 }
 
+// POST /work-orders/search
+//
+//	{
+//	  "pageNum": 0, "pageSize": 50,
+//	  "filterFields": [
+//	    {"field": "overdue", "operation": "eq", "value": true},
+//	    {"field": "priority", "operation": "in", "values": ["HIGH", "CRITICAL"]},
+//	    {"field": "location", "operation": "eq", "value": "uuid"},
+//	    {"operation": "or", "filters": [
+//	      {"field": "team", "operation": "eq", "value": "uuid"},
+//	      {"field": "createdAt", "operation": "between", "values": ["2025-09-01", "2025-09-30"]}
+//	    ]}
+//	  ],
+//	  "sortField": "due_date", "direction": "ASC"
+//	}
+//
+// Top-level filters are ANDed. Unknown fields or operations are a 400.
 func (h *Handler) FilterSearch(w http.ResponseWriter, r *http.Request) {
 	// get org from context
 	org, ok := auth.OrgFromContext(r.Context())
@@ -67,11 +97,15 @@ func (h *Handler) FilterSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// encode to raw []byte for query
-	arg, err := json.Marshal(req)
+	// validate the filters and encode to raw []byte for query
+	var userID uuid.UUID
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		userID = user.ID
+	}
+	arg, err := req.payload(userID)
 	if err != nil {
-		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to encode search request",
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
 		})
 		return
 	}
@@ -107,6 +141,9 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 			"primary_worker": "user-uuid-here",
 			"location": "location-uuid-here",
 			"asset": "asset-uuid-here",
+			"team": "team-uuid-here",
+			"category": "category-uuid-here", // fills priority, estimatedDuration, team and tasks when omitted
+			"tasks": ["task-base-uuid"],      // replaces the category task template
 			"assigned_to": ["uuid", "uuid"],
 			"customers": ["uuid", "uuid"],
 		}
@@ -153,9 +190,8 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	// Call the sqlc query
 	id, err := h.repo.CreateWorkOrderFromJSON(r.Context(), orgID, user.ID, payload)
 	if err != nil {
-		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
-			"error": "failed to create work order",
-		})
+		status, msg := createErrorStatus(err, "failed to create work order")
+		httpserver.JSON(w, status, map[string]string{"error": msg})
 		return
 	}
 	httpserver.JSON(w, http.StatusOK, map[string]any{
@@ -205,15 +241,35 @@ func (h *Handler) Modify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Call the sqlc-generated wrapper: SELECT public.update_work_order_from_json(...)::uuid
-	updatedID, err := h.repo.UpdateWorkOrderFromJSON(r.Context(), orgID, woID, user.ID, payload)
+	ifMatch, err := parseIfMatch(r)
 	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Call the sqlc-generated wrapper: SELECT public.update_work_order_from_json_if_match(...)::uuid
+	updatedID, err := h.repo.UpdateWorkOrderFromJSON(r.Context(), orgID, woID, user.ID, payload, ifMatch)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": "work order not found"})
+			return
+		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			h.setCurrentETag(r.Context(), w, orgID, woID)
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, models.ErrCategoryNotFound) {
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+			return
+		}
 		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to modify work order: " + err.Error(),
 		})
 		return
 	}
 
+	h.setCurrentETag(r.Context(), w, orgID, woID)
 	httpserver.JSON(w, http.StatusOK, map[string]any{
 		"message": "updated work order",
 		"id":      updatedID.String(),
@@ -221,6 +277,12 @@ func (h *Handler) Modify(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
 	// 1. Parse workOrderID from URL
 	idStr := chi.URLParam(r, "workOrderID")
 	id, err := uuid.Parse(idStr)
@@ -233,9 +295,14 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 
 	// 2. Call repo
 	ctx := r.Context()
-	wo, err := h.repo.GetWorkOrderDetail(ctx, id)
+	wo, err := h.repo.GetWorkOrderDetail(ctx, orgID, id)
+	if errors.Is(err, models.ErrWorkOrderNotFound) {
+		httpserver.JSON(w, http.StatusNotFound, map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
-		// You might want to distinguish sql.ErrNoRows here
 		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
 			"error": err.Error(),
 		})
@@ -248,31 +315,109 @@ func (h *Handler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// 3. Send the JSON document returned from DB directly
+	// 3. Version -> ETag (clients send it back as If-Match when editing)
+	var meta struct {
+		Version int64 `json:"version"`
+	}
+	if err := json.Unmarshal(wo, &meta); err == nil && meta.Version > 0 {
+		etag := etagFor(meta.Version)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "private, no-cache")
+		if matchesIfNoneMatch(r, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	// 4. Send the JSON document returned from DB directly
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write(wo)
 }
 
-// GET /work-orders
-func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
-	httpserver.JSON(w, http.StatusOK, map[string]any{
-		"message": "list work orders",
-		"data":    []string{}, // placeholder for an array of work orders
-	})
-}
-
 // PUT /work-orders/{workOrderID}
+// Full replace: every writable field is taken from the body and anything
+// omitted is reset to its default (nulls, empty assignee/customer lists).
+// Status is not writable here; use change-status.
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "workOrderID")
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+
+	defer r.Body.Close()
+	var doc ReplaceRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)) // 1MB
+	if err := dec.Decode(&doc); err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if dec.More() {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON (extra content)"})
+		return
+	}
 
+	body, err := doc.toPayload()
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "failed to encode payload"})
+		return
+	}
+
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	updatedID, err := h.repo.UpdateWorkOrderFromJSON(r.Context(), orgID, woID, user.ID, payload, ifMatch)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": "work order not found"})
+			return
+		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			h.setCurrentETag(r.Context(), w, orgID, woID)
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, models.ErrCategoryNotFound) {
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to replace work order",
+		})
+		return
+	}
+
+	h.setCurrentETag(r.Context(), w, orgID, woID)
 	httpserver.JSON(w, http.StatusOK, map[string]any{
-		"message": "update work order",
-		"id":      id,
+		"message": "replaced work order",
+		"id":      updatedID.String(),
 	})
 }
 
 // DELETE /work-orders/{workOrderID}
+//
+// Moves the work order to the trash (see trash.go); nothing is removed until
+// it is purged.
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	// Org-scoped delete
 	orgID, ok := auth.OrgFromContext(r.Context())
@@ -280,6 +425,11 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 		return
 	}
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
 
 	// Parse path param
 	idStr := chi.URLParam(r, "workOrderID")
@@ -288,15 +438,23 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
 		return
 	}
+	if role, err := h.repo.GetRole(r.Context(), orgID, user.ID); err != nil || role == models.RoleViewer {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
 
 	// Delegate to repo (org scoped)
-	if err := h.repo.DeleteWorkOrderByID(r.Context(), orgID, woID); err != nil {
+	if err := h.repo.DeleteWorkOrderByID(r.Context(), orgID, woID, user.ID); err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
 		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete work order"})
 		return
 	}
 
 	httpserver.JSON(w, http.StatusOK, map[string]any{
-		"message": "work order deleted",
+		"message": "work order moved to the trash",
 		"id":      woID,
 	})
 }
@@ -319,7 +477,12 @@ func (h *Handler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	// parse body into SearchRequest
+	user, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	// parse body into StatusRequest
 	var req StatusRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		httpserver.JSON(w, http.StatusBadRequest, map[string]string{
@@ -327,27 +490,134 @@ func (h *Handler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-
-	// encode to raw []byte for query
-	arg := req.Status
-	if arg == "" {
+	if strings.TrimSpace(req.Status) == "" {
 		httpserver.JSON(w, http.StatusBadRequest, map[string]string{
 			"error": "status field is required",
 		})
 		return
 	}
+	to, err := models.ParseWorkOrderStatus(req.Status)
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{
+			"error": "unknown status: " + req.Status,
+		})
+		return
+	}
 
-	//Call the sqlc query
-	err = h.repo.ChangeWorkOrderStatus(r.Context(), org, id, arg)
+	ifMatch, err := parseIfMatch(r)
 	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	role, err := h.repo.GetRole(r.Context(), org, user.ID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusForbidden, map[string]string{"error": "forbidden"})
+		return
+	}
+	from, err := h.repo.GetWorkOrderStatus(r.Context(), org, id)
+	if err != nil {
+		if errors.Is(err, models.ErrWorkOrderNotFound) {
+			httpserver.JSON(w, http.StatusNotFound, map[string]string{"error": "work order not found"})
+			return
+		}
 		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
 			"error": "failed to change work order status",
 		})
 		return
 	}
+
+	// A stale If-Match wins over lifecycle errors: the client is looking at old data
+	if ifMatch != nil {
+		current, err := h.repo.GetWorkOrderVersion(r.Context(), org, id)
+		if err != nil {
+			httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
+				"error": "failed to change work order status",
+			})
+			return
+		}
+		if current != *ifMatch {
+			w.Header().Set("ETag", etagFor(current))
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": models.ErrVersionMismatch.Error()})
+			return
+		}
+	}
+
+	// Validate against the lifecycle before touching the row
+	if err := models.CheckStatusTransition(from, to, role); err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, models.ErrTransitionForbidden) {
+			code = http.StatusForbidden
+		}
+		httpserver.JSON(w, code, map[string]any{
+			"error":   err.Error(),
+			"from":    from,
+			"to":      to,
+			"allowed": models.AllowedTransitions(from, role),
+		})
+		return
+	}
+
+	err = h.repo.ChangeWorkOrderStatus(r.Context(), org, id, user.ID, from, to, strings.TrimSpace(req.Reason), ifMatch)
+	if err != nil {
+		if errors.Is(err, models.ErrStatusConflict) {
+			httpserver.JSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, models.ErrVersionMismatch) {
+			h.setCurrentETag(r.Context(), w, org, id)
+			httpserver.JSON(w, http.StatusPreconditionFailed, map[string]string{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, models.ErrSignatureRequired) {
+			httpserver.JSON(w, http.StatusUnprocessableEntity, map[string]string{
+				"error":    err.Error(),
+				"complete": "/work-orders/" + id.String() + "/complete",
+			})
+			return
+		}
+		if errors.Is(err, models.ErrWorkOrderBlocked) {
+			h.writeBlocked(w, r, org, id)
+			return
+		}
+		if errors.Is(err, models.ErrChecklistIncomplete) {
+			h.writeChecklistIncomplete(w, r, org, id)
+			return
+		}
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{
+			"error": "failed to change work order status",
+		})
+		return
+	}
+	h.setCurrentETag(r.Context(), w, org, id)
 	httpserver.JSON(w, http.StatusOK, map[string]any{
 		"message": "changed work order status",
 		"id":      id,
-		"status":  arg,
+		"from":    from,
+		"status":  to,
+	})
+}
+
+// GET /work-orders/{workOrderID}/status-history
+func (h *Handler) StatusHistory(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := auth.OrgFromContext(r.Context())
+	if !ok {
+		httpserver.JSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	woID, err := uuid.Parse(chi.URLParam(r, "workOrderID"))
+	if err != nil {
+		httpserver.JSON(w, http.StatusBadRequest, map[string]string{"error": "invalid work order ID"})
+		return
+	}
+
+	history, err := h.repo.ListWorkOrderStatusHistory(r.Context(), orgID, woID)
+	if err != nil {
+		httpserver.JSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to fetch status history"})
+		return
+	}
+	httpserver.JSON(w, http.StatusOK, map[string]any{
+		"id":      woID,
+		"content": history,
 	})
 }
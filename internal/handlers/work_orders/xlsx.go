@@ -0,0 +1,131 @@
+// internal/handlers/work_orders/xlsx.go
+package work_orders
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// xlsxWriter streams a single-sheet workbook. Rows go straight into the
+// deflated sheet part, so memory use does not grow with the row count.
+// Strings are written inline (no shared string table) and numbers as
+// numeric cells; there are no styles.
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	err   error
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetHead = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetTail = `</sheetData></worksheet>`
+)
+
+func newXLSXWriter(w io.Writer, sheetName string) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	var name strings.Builder
+	_ = xml.EscapeText(&name, []byte(sheetName))
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="` + name.String() + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	x.writeString(xlsxSheetHead)
+	return x, x.err
+}
+
+// WriteRow appends a row. Cells may be string, int64, int or float64;
+// anything else is written as an empty cell.
+func (x *xlsxWriter) WriteRow(cells []any) error {
+	x.row++
+	x.writeString(`<row r="` + strconv.Itoa(x.row) + `">`)
+	for i, c := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		switch v := c.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			x.writeString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+			if x.err == nil {
+				x.err = xml.EscapeText(x.sheet, []byte(v))
+			}
+			x.writeString(`</t></is></c>`)
+		case int64:
+			x.writeString(`<c r="` + ref + `"><v>` + strconv.FormatInt(v, 10) + `</v></c>`)
+		case int:
+			x.writeString(`<c r="` + ref + `"><v>` + strconv.Itoa(v) + `</v></c>`)
+		case float64:
+			x.writeString(`<c r="` + ref + `"><v>` + strconv.FormatFloat(v, 'f', -1, 64) + `</v></c>`)
+		}
+	}
+	x.writeString(`</row>`)
+	return x.err
+}
+
+// Flush pushes buffered sheet data through the zip stream.
+func (x *xlsxWriter) Flush() error {
+	if x.err == nil {
+		x.err = x.sheet.Flush()
+	}
+	if x.err == nil {
+		x.err = x.zw.Flush()
+	}
+	return x.err
+}
+
+// Close finishes the sheet and writes the zip directory.
+func (x *xlsxWriter) Close() error {
+	x.writeString(xlsxSheetTail)
+	if x.err == nil {
+		x.err = x.sheet.Flush()
+	}
+	if x.err != nil {
+		return x.err
+	}
+	return x.zw.Close()
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	if x.err == nil {
+		_, x.err = x.sheet.WriteString(s)
+	}
+}
+
+// xlsxColumn turns a zero-based column index into A, B, ... Z, AA, ...
+func xlsxColumn(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
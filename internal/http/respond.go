@@ -2,8 +2,11 @@
 package httpserver
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 )
 
 func JSON(w http.ResponseWriter, status int, data interface{}) {
@@ -13,3 +16,30 @@ func JSON(w http.ResponseWriter, status int, data interface{}) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
+
+// JSONCached writes data like JSON but tags the response with a weak ETag
+// derived from the body, answering 304 when the client's If-None-Match matches.
+// Responses stay private and must be revalidated on every use.
+func JSONCached(w http.ResponseWriter, r *http.Request, status int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			if c := strings.TrimSpace(candidate); c == etag || c == "*" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
@@ -10,19 +10,11 @@ import (
 )
 
 func RequireRole(r repo.Repo, allowed ...models.OrgRole) func(http.Handler) http.Handler {
-	// Map role to integer level — adjust roles and levels as needed
-	var roleLevels = map[models.OrgRole]int{
-		models.RoleViewer: 1,
-		models.RoleMember: 2,
-		models.RoleAdmin:  3,
-		models.RoleOwner:  4,
-	}
-
-	// Find the minimum allowed role level
+	// Find the minimum allowed role level (models.OrgRole.Rank)
 	minAllowedLevel := 9999
 	for _, role := range allowed {
-		lvl, ok := roleLevels[role]
-		if !ok {
+		lvl := role.Rank()
+		if lvl == 0 {
 			// If unknown role, skip or handle error
 			continue
 		}
@@ -45,8 +37,8 @@ func RequireRole(r repo.Repo, allowed ...models.OrgRole) func(http.Handler) http
 				return
 			}
 
-			userLevel, ok := roleLevels[role]
-			if !ok {
+			userLevel := role.Rank()
+			if userLevel == 0 {
 				http.Error(w, "forbidden", http.StatusForbidden)
 				return
 			}
@@ -0,0 +1,203 @@
+// internal/models/capacity.go
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxRescheduleHours caps the duration given when rescheduling (31 days).
+const MaxRescheduleHours = 31 * 24
+
+// MaxLeaveDuration caps a single leave entry.
+const MaxLeaveDuration = 366 * 24 * time.Hour
+
+var (
+	ErrInvalidReschedule   = errors.New("give start, and a duration_hours above 0 and at most 744")
+	ErrRescheduleClosed    = errors.New("completed, cancelled or archived work orders cannot be rescheduled")
+	ErrScheduleConflict    = errors.New("the new slot double-books someone or overlaps their leave; resend with force=true to override")
+	ErrUserNotMember       = errors.New("user is not a member of this organisation")
+	ErrInvalidWorkingHours = errors.New("working days need a weekday from 0 (Sunday) to 6 and HH:MM start and end times, end after start, each weekday once")
+	ErrInvalidTimeZone     = errors.New("unknown time zone")
+	ErrLeaveNotFound       = errors.New("leave not found")
+	ErrInvalidLeave        = errors.New("leave needs a user_id, and ends_at after starts_at and at most a year later")
+	ErrLeaveForbidden      = errors.New("only admins can manage other users' leave")
+)
+
+// Capacity issue kinds. Leave and double-bookings are conflicts, which block
+// a reschedule unless forced; the others are warnings.
+const (
+	CapacityOnLeave      = "ON_LEAVE"
+	CapacityDoubleBooked = "DOUBLE_BOOKED"
+	CapacityNotWorking   = "NOT_WORKING"
+	CapacityOutsideHours = "OUTSIDE_WORKING_HOURS"
+	CapacityOverbooked   = "OVER_CAPACITY"
+)
+
+// WorkingDay is one weekly shift. Weekday 0 is Sunday; Start and End are
+// HH:MM local times in the user's time zone.
+type WorkingDay struct {
+	Weekday int    `json:"weekday"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+// WorkingHours is a user's weekly shift pattern. Users without working
+// hours are not checked against them.
+type WorkingHours struct {
+	UserID   uuid.UUID    `json:"user_id"`
+	UserName string       `json:"user_name"`
+	TimeZone string       `json:"time_zone"`
+	Days     []WorkingDay `json:"days"`
+}
+
+// WorkingHoursInput replaces a user's week; an empty Days clears it.
+type WorkingHoursInput struct {
+	TimeZone string       `json:"time_zone"`
+	Days     []WorkingDay `json:"days"`
+}
+
+// ParseClock parses an HH:MM (or HH:MM:SS) time of day.
+func ParseClock(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// Normalize checks the days, sorts them by weekday and defaults the time
+// zone to UTC. The zone itself is checked against the database.
+func (in *WorkingHoursInput) Normalize() error {
+	in.TimeZone = strings.TrimSpace(in.TimeZone)
+	if in.TimeZone == "" {
+		in.TimeZone = "UTC"
+	}
+	seen := map[int]bool{}
+	for i, d := range in.Days {
+		if d.Weekday < 0 || d.Weekday > 6 || seen[d.Weekday] {
+			return ErrInvalidWorkingHours
+		}
+		seen[d.Weekday] = true
+		start, err := ParseClock(d.Start)
+		if err != nil {
+			return ErrInvalidWorkingHours
+		}
+		end, err := ParseClock(d.End)
+		if err != nil || end <= start {
+			return ErrInvalidWorkingHours
+		}
+		in.Days[i].Start = FormatClock(start)
+		in.Days[i].End = FormatClock(end)
+	}
+	sort.Slice(in.Days, func(i, j int) bool { return in.Days[i].Weekday < in.Days[j].Weekday })
+	return nil
+}
+
+// FormatClock renders a time of day as HH:MM.
+func FormatClock(d time.Duration) string {
+	return time.Time{}.Add(d).Format("15:04")
+}
+
+// UserLeave blocks a user for [StartsAt, EndsAt).
+type UserLeave struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	UserName    string     `json:"user_name"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedByID *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+type UserLeaveInput struct {
+	UserID   uuid.UUID `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   *string   `json:"reason"`
+}
+
+// Normalize checks the range and trims the reason (blank means none).
+func (in *UserLeaveInput) Normalize() error {
+	if in.UserID == uuid.Nil || in.StartsAt.IsZero() || !in.EndsAt.After(in.StartsAt) ||
+		in.EndsAt.Sub(in.StartsAt) > MaxLeaveDuration {
+		return ErrInvalidLeave
+	}
+	if in.Reason != nil {
+		r := strings.TrimSpace(*in.Reason)
+		if r == "" {
+			in.Reason = nil
+		} else {
+			in.Reason = &r
+		}
+	}
+	return nil
+}
+
+// RescheduleInput moves a work order to a new slot. Nil fields keep their
+// current value: Duration the stored estimated_duration, PrimaryUserID
+// and AssigneeIDs the current people. DryRun only reports the capacity
+// check; Force applies the change despite conflicts.
+type RescheduleInput struct {
+	Start         time.Time    `json:"start"`
+	DurationHours *float64     `json:"duration_hours"`
+	PrimaryUserID *uuid.UUID   `json:"primary_user_id"`
+	AssigneeIDs   *[]uuid.UUID `json:"assignee_ids"`
+	DryRun        bool         `json:"dry_run"`
+	Force         bool         `json:"force"`
+}
+
+func (in *RescheduleInput) Normalize() error {
+	if in.Start.IsZero() {
+		return ErrInvalidReschedule
+	}
+	if d := in.DurationHours; d != nil && (*d <= 0 || *d > MaxRescheduleHours) {
+		return ErrInvalidReschedule
+	}
+	if in.PrimaryUserID != nil && *in.PrimaryUserID == uuid.Nil {
+		in.PrimaryUserID = nil
+	}
+	return nil
+}
+
+// CapacityIssue is one problem found for a person in the new slot.
+type CapacityIssue struct {
+	Kind        string     `json:"kind"`
+	UserID      uuid.UUID  `json:"user_id"`
+	UserName    string     `json:"user_name"`
+	Message     string     `json:"message"`
+	WorkOrderID *uuid.UUID `json:"work_order_id,omitempty"`
+	LeaveID     *uuid.UUID `json:"leave_id,omitempty"`
+	StartsAt    *time.Time `json:"starts_at,omitempty"`
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+}
+
+// Blocking reports whether the issue is a conflict rather than a warning.
+func (c CapacityIssue) Blocking() bool {
+	return c.Kind == CapacityOnLeave || c.Kind == CapacityDoubleBooked
+}
+
+// RescheduleResult describes the slot and the capacity check. Applied is
+// false for dry runs and refused moves.
+type RescheduleResult struct {
+	WorkOrderID   uuid.UUID       `json:"work_order_id"`
+	Start         time.Time       `json:"start"`
+	End           time.Time       `json:"end"`
+	DurationHours float64         `json:"duration_hours"`
+	PrimaryUserID *uuid.UUID      `json:"primary_user_id"`
+	AssigneeIDs   []uuid.UUID     `json:"assignee_ids"`
+	Applied       bool            `json:"applied"`
+	Version       int64           `json:"version,omitempty"`
+	Conflicts     []CapacityIssue `json:"conflicts"`
+	Warnings      []CapacityIssue `json:"warnings"`
+}
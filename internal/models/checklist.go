@@ -0,0 +1,71 @@
+// internal/models/checklist.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why a task blocks completion (ChecklistBlocker.Reason).
+const (
+	ChecklistIncomplete    = "INCOMPLETE"
+	ChecklistNotesRequired = "NOTES_REQUIRED"
+)
+
+var (
+	ErrChecklistPolicyNotFound = errors.New("checklist policy not found")
+	ErrChecklistPolicyExists   = errors.New("a checklist policy for this category already exists")
+	ErrChecklistRuleRequired   = errors.New("enable require_tasks_complete, require_failure_notes or both")
+	ErrChecklistIncomplete     = errors.New("the work order has checklist items that block completion")
+)
+
+// ChecklistPolicy gates completion of an organisation's work orders on their
+// tasks. A nil CategoryID is the org default; a category's own policy wins
+// over it. RequireTasksComplete holds a work order open while any task that
+// is not optional is incomplete; RequireFailureNotes while any failed item
+// (an INSPECTION marked FAIL, or a reading out of tolerance) has no notes.
+type ChecklistPolicy struct {
+	ID                   uuid.UUID  `json:"id"`
+	CategoryID           *uuid.UUID `json:"category_id"`
+	CategoryName         string     `json:"category_name,omitempty"`
+	RequireTasksComplete bool       `json:"require_tasks_complete"`
+	RequireFailureNotes  bool       `json:"require_failure_notes"`
+	CreatedByID          *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// ChecklistPolicyInput is the writable part of a policy. Omitted rules
+// default to on.
+type ChecklistPolicyInput struct {
+	CategoryID           *uuid.UUID `json:"category_id"`
+	RequireTasksComplete *bool      `json:"require_tasks_complete"`
+	RequireFailureNotes  *bool      `json:"require_failure_notes"`
+}
+
+// Normalize fills the default rules and checks at least one is on.
+func (in *ChecklistPolicyInput) Normalize() error {
+	if in.CategoryID != nil && *in.CategoryID == uuid.Nil {
+		in.CategoryID = nil
+	}
+	for _, b := range []**bool{&in.RequireTasksComplete, &in.RequireFailureNotes} {
+		if *b == nil {
+			on := true
+			*b = &on
+		}
+	}
+	if !*in.RequireTasksComplete && !*in.RequireFailureNotes {
+		return ErrChecklistRuleRequired
+	}
+	return nil
+}
+
+// ChecklistBlocker is a task keeping a work order from COMPLETE.
+type ChecklistBlocker struct {
+	TaskID   uuid.UUID `json:"task_id"`
+	Label    string    `json:"label"`
+	TaskType string    `json:"task_type"`
+	Reason   string    `json:"reason"`
+}
@@ -0,0 +1,90 @@
+// internal/models/file.go
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFileNotFound         = errors.New("file not found")
+	ErrEmptyFile            = errors.New("file is empty")
+	ErrFileTooLarge         = errors.New("file exceeds the upload size limit")
+	ErrUnsupportedFileType  = errors.New("file type is not allowed")
+	ErrStorageQuotaExceeded = errors.New("organisation storage quota exceeded")
+)
+
+// allowedUploadTypes lists the sniffed content types we accept. SVG and HTML
+// are deliberately absent: they are served back from our origin.
+var allowedUploadTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"image/bmp":       true,
+	"application/pdf": true,
+	"text/plain":      true,
+	"video/mp4":       true,
+	"video/webm":      true,
+	"audio/mpeg":      true,
+	// Office documents (docx/xlsx) sniff as zip
+	"application/zip": true,
+}
+
+// NormalizeContentType strips parameters (e.g. "; charset=utf-8") from a
+// sniffed content type.
+func NormalizeContentType(ct string) string {
+	if i := strings.IndexByte(ct, ';'); i >= 0 {
+		ct = ct[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(ct))
+}
+
+// AllowedUploadType reports whether a sniffed content type may be stored.
+func AllowedUploadType(ct string) bool {
+	return allowedUploadTypes[NormalizeContentType(ct)]
+}
+
+// IsInlineContentType reports whether a download may render in the browser
+// rather than being forced to an attachment.
+func IsInlineContentType(ct string) bool {
+	ct = NormalizeContentType(ct)
+	return strings.HasPrefix(ct, "image/") || strings.HasPrefix(ct, "video/") || ct == "application/pdf"
+}
+
+// File is an uploaded blob owned by an organisation. StorageKey and
+// StorageBackend locate the bytes and never leave the server.
+type File struct {
+	ID             uuid.UUID  `json:"id"`
+	Filename       string     `json:"filename"`
+	ContentType    string     `json:"content_type"`
+	SizeBytes      int64      `json:"size_bytes"`
+	SHA256         string     `json:"sha256,omitempty"`
+	UploadedByID   *uuid.UUID `json:"uploaded_by_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DownloadURL    string     `json:"download_url"`
+	StorageKey     string     `json:"-"`
+	StorageBackend string     `json:"-"`
+}
+
+// NewFile is what the upload path hands the repo once the bytes are stored.
+type NewFile struct {
+	Filename       string
+	ContentType    string
+	SizeBytes      int64
+	SHA256         string
+	StorageKey     string
+	StorageBackend string
+}
+
+// StorageUsage summarises an org's consumption against its quota.
+type StorageUsage struct {
+	UsedBytes      int64 `json:"used_bytes"`
+	FileCount      int64 `json:"file_count"`
+	QuotaBytes     int64 `json:"quota_bytes"`
+	Overridden     bool  `json:"quota_overridden"`
+	MaxUploadBytes int64 `json:"max_upload_bytes"`
+}
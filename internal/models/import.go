@@ -0,0 +1,205 @@
+// internal/models/import.go
+package models
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportRows caps the data rows accepted in one upload.
+const MaxImportRows = 5000
+
+// MaxImportBytes caps the size of one upload.
+const MaxImportBytes = 10 << 20
+
+// MaxImportErrors caps the row errors stored on a job; ErrorCount still
+// counts all of them.
+const MaxImportErrors = 500
+
+// ImportKind is what an import creates.
+type ImportKind string
+
+const (
+	ImportWorkOrders ImportKind = "work_orders"
+	ImportAssets     ImportKind = "assets"
+	ImportLocations  ImportKind = "locations"
+)
+
+// Import job states. A job that found row errors, or a dry run, writes
+// nothing.
+const (
+	ImportQueued    = "QUEUED"
+	ImportRunning   = "RUNNING"
+	ImportSucceeded = "SUCCEEDED"
+	ImportFailed    = "FAILED"
+)
+
+var (
+	ErrImportJobNotFound   = errors.New("import job not found")
+	ErrUnknownImportKind   = errors.New("kind must be one of work_orders, assets, locations")
+	ErrImportEmpty         = errors.New("the file has no data rows")
+	ErrImportTooManyRows   = fmt.Errorf("the file has more than %d data rows", MaxImportRows)
+	ErrImportInvalidCSV    = errors.New("the file is not valid CSV")
+	ErrImportHeader        = errors.New("invalid header row")
+	ErrImportMissingColumn = errors.New("missing required column")
+)
+
+// importColumns lists the accepted columns per kind; the first is required.
+// References (location, asset, category, team) match by custom ID or name,
+// people by email. assigned_to takes several emails separated by ";".
+var importColumns = map[ImportKind][]string{
+	ImportLocations: {"name", "custom_id"},
+	ImportAssets:    {"name", "custom_id", "location"},
+	ImportWorkOrders: {
+		"title", "description", "priority", "due_date", "estimated_start_date",
+		"estimated_duration", "required_signature", "custom_id", "category",
+		"location", "asset", "team", "primary_worker", "assigned_to",
+	},
+}
+
+func ParseImportKind(s string) (ImportKind, error) {
+	k := ImportKind(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := importColumns[k]; !ok {
+		return "", ErrUnknownImportKind
+	}
+	return k, nil
+}
+
+// ImportColumns returns the accepted columns for kind, required one first.
+func ImportColumns(kind ImportKind) []string {
+	return append([]string(nil), importColumns[kind]...)
+}
+
+// ImportRowError is one problem with one data row. Row is the row's line
+// number in the uploaded file, the header being line 1.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJob is the pollable state of one upload.
+type ImportJob struct {
+	ID            uuid.UUID        `json:"id"`
+	Kind          ImportKind       `json:"kind"`
+	DryRun        bool             `json:"dry_run"`
+	Status        string           `json:"status"`
+	Filename      string           `json:"filename,omitempty"`
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	CreatedRows   int              `json:"created_rows"`
+	ErrorCount    int              `json:"error_count"`
+	Errors        []ImportRowError `json:"errors,omitempty"`
+	Message       string           `json:"message,omitempty"`
+	CreatedByID   *uuid.UUID       `json:"created_by_id,omitempty"`
+	CreatedAt     time.Time        `json:"created_at"`
+	StartedAt     *time.Time       `json:"started_at,omitempty"`
+	FinishedAt    *time.Time       `json:"finished_at,omitempty"`
+}
+
+// ImportFile is a parsed upload: Rows are aligned with Columns, and Lines
+// holds each row's line number in the file.
+type ImportFile struct {
+	Kind    ImportKind
+	Columns []string
+	Rows    [][]string
+	Lines   []int
+}
+
+// Value returns column col of data row i (0-based), trimmed, or "" if the
+// file has no such column.
+func (f ImportFile) Value(i int, col string) string {
+	for j, c := range f.Columns {
+		if c == col {
+			if j < len(f.Rows[i]) {
+				return strings.TrimSpace(f.Rows[i][j])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// ParseImportCSV reads an upload for kind. Header names are matched
+// case-insensitively with spaces and dashes read as underscores; unknown or
+// repeated columns are rejected so typos do not silently drop data. Blank
+// lines are skipped.
+func ParseImportCSV(kind ImportKind, r io.Reader) (ImportFile, error) {
+	f := ImportFile{Kind: kind}
+	allowed := importColumns[kind]
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return f, ErrImportEmpty
+	}
+	if err != nil {
+		return f, fmt.Errorf("%w: %v", ErrImportInvalidCSV, err)
+	}
+
+	seen := map[string]bool{}
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, "\ufeff")
+		}
+		col := normaliseImportColumn(h)
+		known := false
+		for _, a := range allowed {
+			known = known || a == col
+		}
+		switch {
+		case !known:
+			return f, fmt.Errorf("%w: unknown column %q (expected %s)", ErrImportHeader, h, strings.Join(allowed, ", "))
+		case seen[col]:
+			return f, fmt.Errorf("%w: column %q appears twice", ErrImportHeader, h)
+		}
+		seen[col] = true
+		f.Columns = append(f.Columns, col)
+	}
+	if !seen[allowed[0]] {
+		return f, fmt.Errorf("%w: %s", ErrImportMissingColumn, allowed[0])
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return f, fmt.Errorf("%w: %v", ErrImportInvalidCSV, err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) > len(f.Columns) {
+			return f, fmt.Errorf("%w: line %d has more fields than the header", ErrImportInvalidCSV, line)
+		}
+		blank := true
+		for _, v := range rec {
+			blank = blank && strings.TrimSpace(v) == ""
+		}
+		if blank {
+			continue
+		}
+		if len(f.Rows) == MaxImportRows {
+			return f, ErrImportTooManyRows
+		}
+		f.Rows = append(f.Rows, rec)
+		f.Lines = append(f.Lines, line)
+	}
+	if len(f.Rows) == 0 {
+		return f, ErrImportEmpty
+	}
+	return f, nil
+}
+
+func normaliseImportColumn(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(h)
+}
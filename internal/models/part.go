@@ -0,0 +1,247 @@
+// internal/models/part.go
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxPartNumberLength caps part numbers and revisions accepted from clients.
+const MaxPartNumberLength = 100
+
+// MaxPartUsageNotesLength caps the notes on a part line.
+const MaxPartUsageNotesLength = 2000
+
+// Part categories, as in SparePartMaster (docs/idea.md).
+const (
+	PartCategoryMajor      = "Major"
+	PartCategoryMinor      = "Minor"
+	PartCategoryConsumable = "Consumable"
+	PartCategoryTooling    = "Tooling"
+	PartCategorySafety     = "Safety"
+)
+
+// Part tracking modes: what a consumption line must record.
+const (
+	PartTrackingNone   = "NONE"
+	PartTrackingSerial = "SERIAL"
+	PartTrackingBatch  = "BATCH"
+)
+
+var (
+	ErrPartNotFound          = errors.New("part not found")
+	ErrPartNumberRequired    = errors.New("part number is required")
+	ErrPartNumberTooLong     = errors.New("part number or revision is too long")
+	ErrPartNumberTaken       = errors.New("a part with this number and revision already exists")
+	ErrInvalidPartCategory   = errors.New("category must be one of Major, Minor, Consumable, Tooling, Safety")
+	ErrInvalidPartTracking   = errors.New("tracking must be one of NONE, SERIAL, BATCH")
+	ErrNegativeUnitCost      = errors.New("unit cost must not be negative")
+	ErrPartInUse             = errors.New("part has been used on work orders; archive it instead")
+	ErrPartArchived          = errors.New("part is archived")
+	ErrPartUsageNotFound     = errors.New("part usage not found")
+	ErrInvalidPartQuantity   = errors.New("quantity must be greater than zero")
+	ErrSerialRequired        = errors.New("this part is serial-tracked: give serial_or_batch")
+	ErrSerialQuantity        = errors.New("serial-tracked parts are recorded one unit per line")
+	ErrBatchRequired         = errors.New("this part is batch-tracked: give serial_or_batch")
+	ErrPartUsageNotesTooLong = errors.New("notes are too long")
+)
+
+// Part is a catalogue entry (SparePartMaster in docs/idea.md).
+type Part struct {
+	ID          uuid.UUID  `json:"id"`
+	PartNumber  string     `json:"part_number"`
+	Revision    string     `json:"revision"`
+	Description string     `json:"description,omitempty"`
+	Category    string     `json:"category"`
+	UOM         string     `json:"uom"`
+	UnitCost    float64    `json:"unit_cost"`
+	Tracking    string     `json:"tracking"`
+	Archived    bool       `json:"archived"`
+	CreatedByID *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PartInput is the writable part of a catalogue entry.
+type PartInput struct {
+	PartNumber  string  `json:"part_number"`
+	Revision    string  `json:"revision"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	UOM         string  `json:"uom"`
+	UnitCost    float64 `json:"unit_cost"`
+	Tracking    string  `json:"tracking"`
+	Archived    bool    `json:"archived"`
+}
+
+// Normalize trims the input and fills the defaults (Minor, EA, NONE).
+func (in *PartInput) Normalize() error {
+	in.PartNumber = strings.TrimSpace(in.PartNumber)
+	in.Revision = strings.TrimSpace(in.Revision)
+	in.Description = strings.TrimSpace(in.Description)
+	in.UOM = strings.ToUpper(strings.TrimSpace(in.UOM))
+	in.Tracking = strings.ToUpper(strings.TrimSpace(in.Tracking))
+	if in.PartNumber == "" {
+		return ErrPartNumberRequired
+	}
+	if utf8.RuneCountInString(in.PartNumber) > MaxPartNumberLength || utf8.RuneCountInString(in.Revision) > MaxPartNumberLength {
+		return ErrPartNumberTooLong
+	}
+	category, err := parsePartCategory(in.Category)
+	if err != nil {
+		return err
+	}
+	in.Category = category
+	if in.UOM == "" {
+		in.UOM = "EA"
+	}
+	switch in.Tracking {
+	case "":
+		in.Tracking = PartTrackingNone
+	case PartTrackingNone, PartTrackingSerial, PartTrackingBatch:
+	default:
+		return ErrInvalidPartTracking
+	}
+	if in.UnitCost < 0 {
+		return ErrNegativeUnitCost
+	}
+	return nil
+}
+
+func parsePartCategory(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return PartCategoryMinor, nil
+	}
+	for _, c := range []string{PartCategoryMajor, PartCategoryMinor, PartCategoryConsumable, PartCategoryTooling, PartCategorySafety} {
+		if strings.EqualFold(s, c) {
+			return c, nil
+		}
+	}
+	return "", ErrInvalidPartCategory
+}
+
+// PartUsage is one line of parts consumed on a work order. UnitCost is the
+// price at the time of use; Cost is Quantity x UnitCost.
+type PartUsage struct {
+	ID              uuid.UUID  `json:"id"`
+	WorkOrderID     uuid.UUID  `json:"work_order_id"`
+	PartID          uuid.UUID  `json:"part_id"`
+	PartNumber      string     `json:"part_number"`
+	Revision        string     `json:"revision"`
+	PartDescription string     `json:"part_description,omitempty"`
+	UOM             string     `json:"uom"`
+	Quantity        float64    `json:"quantity"`
+	SerialOrBatch   string     `json:"serial_or_batch,omitempty"`
+	UnitCost        float64    `json:"unit_cost"`
+	Cost            float64    `json:"cost"`
+	Notes           string     `json:"notes,omitempty"`
+	UsedAt          time.Time  `json:"used_at"`
+	CreatedByID     *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// PartCost prices a quantity at a unit cost, rounded to cents.
+func PartCost(quantity, unitCost float64) float64 {
+	return RoundCents(quantity * unitCost)
+}
+
+// PartUsageTotals sums a work order's part lines.
+type PartUsageTotals struct {
+	Lines     int     `json:"lines"`
+	PartsCost float64 `json:"parts_cost"`
+}
+
+// SumPartUsage computes the totals shown next to a work order's part lines.
+// It matches the parts_usage block of the work order detail.
+func SumPartUsage(lines []PartUsage) PartUsageTotals {
+	var t PartUsageTotals
+	var cost float64
+	for _, l := range lines {
+		t.Lines++
+		cost += l.Quantity * l.UnitCost
+	}
+	t.PartsCost = RoundCents(cost)
+	return t
+}
+
+// PartUsageInput is the body for recording a part line. UnitCost defaults
+// to the catalogue price, UsedAt to now.
+type PartUsageInput struct {
+	PartID        uuid.UUID  `json:"part_id"`
+	Quantity      float64    `json:"quantity"`
+	SerialOrBatch string     `json:"serial_or_batch"`
+	UnitCost      *float64   `json:"unit_cost"`
+	Notes         string     `json:"notes"`
+	UsedAt        *time.Time `json:"used_at"`
+}
+
+// Normalize trims the input and checks what can be checked without the
+// catalogue entry.
+func (in *PartUsageInput) Normalize(now time.Time) error {
+	in.SerialOrBatch = strings.TrimSpace(in.SerialOrBatch)
+	in.Notes = strings.TrimSpace(in.Notes)
+	if in.PartID == uuid.Nil {
+		return ErrPartNotFound
+	}
+	if in.Quantity <= 0 || math.IsNaN(in.Quantity) || math.IsInf(in.Quantity, 0) {
+		return ErrInvalidPartQuantity
+	}
+	if in.UnitCost != nil && *in.UnitCost < 0 {
+		return ErrNegativeUnitCost
+	}
+	if utf8.RuneCountInString(in.Notes) > MaxPartUsageNotesLength {
+		return ErrPartUsageNotesTooLong
+	}
+	if in.UsedAt == nil {
+		in.UsedAt = &now
+	}
+	return nil
+}
+
+// CheckTracking validates the line against the part's tracking mode and
+// resolves the unit cost. Archived parts cannot be recorded.
+func (in *PartUsageInput) CheckTracking(p Part) error {
+	if p.Archived {
+		return ErrPartArchived
+	}
+	switch p.Tracking {
+	case PartTrackingSerial:
+		if in.SerialOrBatch == "" {
+			return ErrSerialRequired
+		}
+		if in.Quantity != 1 {
+			return ErrSerialQuantity
+		}
+	case PartTrackingBatch:
+		if in.SerialOrBatch == "" {
+			return ErrBatchRequired
+		}
+	}
+	if in.UnitCost == nil {
+		cost := p.UnitCost
+		in.UnitCost = &cost
+	}
+	return nil
+}
+
+// PartConsumption is one row of the monthly spare consumption report:
+// quantity of a part used on an asset's work orders in a month.
+type PartConsumption struct {
+	Month      string     `json:"month"`
+	AssetID    *uuid.UUID `json:"asset_id"`
+	AssetName  string     `json:"asset_name,omitempty"`
+	PartID     uuid.UUID  `json:"part_id"`
+	PartNumber string     `json:"part_number"`
+	Revision   string     `json:"revision"`
+	Category   string     `json:"category"`
+	UOM        string     `json:"uom"`
+	Quantity   float64    `json:"quantity"`
+	Cost       float64    `json:"cost"`
+	WorkOrders int64      `json:"work_orders"`
+}
@@ -0,0 +1,76 @@
+// internal/models/schedule.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxScheduleRange caps the window of one schedule request.
+const MaxScheduleRange = 93 * 24 * time.Hour
+
+var (
+	ErrInvalidScheduleRange = errors.New("to must be after from and at most 93 days later")
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+)
+
+// ScheduleRef names a team, location or person on a schedule event.
+type ScheduleRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// ScheduleConflict is another work order that overlaps an event in time and
+// shares at least one of its people (UserIDs).
+type ScheduleConflict struct {
+	WorkOrderID uuid.UUID   `json:"work_order_id"`
+	UserIDs     []uuid.UUID `json:"user_ids"`
+}
+
+// ScheduleEvent is a work order placed on the calendar. Scheduled work
+// orders run from Start to End (estimated_start_date plus
+// estimated_duration hours, at least one hour). Work orders with only a due
+// date are AllDay events on DueDate and have no Start/End.
+type ScheduleEvent struct {
+	WorkOrderID uuid.UUID          `json:"work_order_id"`
+	CustomID    string             `json:"custom_id,omitempty"`
+	Title       string             `json:"title"`
+	Status      string             `json:"status"`
+	Priority    string             `json:"priority"`
+	Start       *time.Time         `json:"start,omitempty"`
+	End         *time.Time         `json:"end,omitempty"`
+	AllDay      bool               `json:"all_day"`
+	DueDate     *time.Time         `json:"due_date,omitempty"`
+	Team        *ScheduleRef       `json:"team,omitempty"`
+	Location    *ScheduleRef       `json:"location,omitempty"`
+	Assignees   []ScheduleRef      `json:"assignees"`
+	Conflict    bool               `json:"conflict"`
+	Conflicts   []ScheduleConflict `json:"conflicts"`
+}
+
+// ScheduleFilter selects the events of one schedule request. A nil UserID
+// or TeamID means any.
+type ScheduleFilter struct {
+	From   time.Time
+	To     time.Time
+	UserID *uuid.UUID
+	TeamID *uuid.UUID
+}
+
+// Validate checks the window against MaxScheduleRange.
+func (f ScheduleFilter) Validate() error {
+	if !f.To.After(f.From) || f.To.Sub(f.From) > MaxScheduleRange {
+		return ErrInvalidScheduleRange
+	}
+	return nil
+}
+
+// CalendarFeed describes a user's iCalendar subscription. Token is only set
+// when the feed has just been issued; afterwards only its hash is stored.
+type CalendarFeed struct {
+	Token      string     `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,86 @@
+// internal/models/sla.go
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultSLAAtRiskPercent is the share of an SLA window after which an
+// unmet target is flagged as at risk.
+const DefaultSLAAtRiskPercent = 80
+
+// MaxSLAWindowMinutes caps react/complete windows (one year).
+const MaxSLAWindowMinutes = 365 * 24 * 60
+
+var (
+	ErrSLAPolicyNotFound = errors.New("SLA policy not found")
+	ErrSLAPolicyExists   = errors.New("an SLA policy for this priority and category already exists")
+	ErrSLATargetRequired = errors.New("give react_within_minutes, complete_within_minutes or both")
+	ErrInvalidSLAWindow  = errors.New("SLA windows must be between 1 minute and 1 year")
+	ErrInvalidSLAAtRisk  = errors.New("at_risk_percent must be between 1 and 99")
+)
+
+// SLAPolicy sets response and completion targets for an organisation's work
+// orders. A nil Priority or CategoryID matches any value; when several
+// policies match a work order the most specific one applies (category and
+// priority, then category, then priority, then the org default).
+type SLAPolicy struct {
+	ID                    uuid.UUID  `json:"id"`
+	Priority              *string    `json:"priority"`
+	CategoryID            *uuid.UUID `json:"category_id"`
+	CategoryName          string     `json:"category_name,omitempty"`
+	ReactWithinMinutes    *int       `json:"react_within_minutes"`
+	CompleteWithinMinutes *int       `json:"complete_within_minutes"`
+	AtRiskPercent         int        `json:"at_risk_percent"`
+	CreatedByID           *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+	UpdatedAt             time.Time  `json:"updated_at"`
+}
+
+// SLAPolicyInput is the writable part of a policy.
+type SLAPolicyInput struct {
+	Priority              *string    `json:"priority"`
+	CategoryID            *uuid.UUID `json:"category_id"`
+	ReactWithinMinutes    *int       `json:"react_within_minutes"`
+	CompleteWithinMinutes *int       `json:"complete_within_minutes"`
+	AtRiskPercent         *int       `json:"at_risk_percent"`
+}
+
+// Normalize upper-cases the priority (empty means any) and checks the
+// windows. AtRiskPercent defaults to DefaultSLAAtRiskPercent.
+func (in *SLAPolicyInput) Normalize() error {
+	if in.Priority != nil {
+		if strings.TrimSpace(*in.Priority) == "" {
+			in.Priority = nil
+		} else {
+			p, err := ParseWorkOrderPriority(*in.Priority)
+			if err != nil {
+				return err
+			}
+			in.Priority = &p
+		}
+	}
+	if in.CategoryID != nil && *in.CategoryID == uuid.Nil {
+		in.CategoryID = nil
+	}
+	if in.ReactWithinMinutes == nil && in.CompleteWithinMinutes == nil {
+		return ErrSLATargetRequired
+	}
+	for _, m := range []*int{in.ReactWithinMinutes, in.CompleteWithinMinutes} {
+		if m != nil && (*m < 1 || *m > MaxSLAWindowMinutes) {
+			return ErrInvalidSLAWindow
+		}
+	}
+	if in.AtRiskPercent == nil {
+		pct := DefaultSLAAtRiskPercent
+		in.AtRiskPercent = &pct
+	}
+	if *in.AtRiskPercent < 1 || *in.AtRiskPercent > 99 {
+		return ErrInvalidSLAAtRisk
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+// internal/models/task.go
+package models
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Task types of a task base.
+const (
+	TaskTypeSubtask        = "SUBTASK"
+	TaskTypeNumber         = "NUMBER"
+	TaskTypeText           = "TEXT"
+	TaskTypeInspection     = "INSPECTION"
+	TaskTypeMultipleChoice = "MULTIPLE_CHOICE"
+	TaskTypeMeter          = "METER"
+)
+
+// Results of an INSPECTION task.
+const (
+	InspectionPass = "PASS"
+	InspectionFail = "FAIL"
+	InspectionFlag = "FLAG"
+)
+
+// Values of a SUBTASK task.
+const (
+	SubtaskComplete = "COMPLETE"
+	SubtaskOpen     = "OPEN"
+)
+
+// MaxTaskLabelLength caps task base labels and option labels.
+const MaxTaskLabelLength = 200
+
+// MaxTaskNotesLength caps the notes and the value of a task.
+const MaxTaskNotesLength = 2000
+
+// MaxTaskOptions caps the choices of one task base.
+const MaxTaskOptions = 50
+
+// MaxTaskUnitLength caps the unit of a NUMBER or METER task base.
+const MaxTaskUnitLength = 20
+
+var (
+	ErrTaskLabelRequired     = errors.New("task label is required")
+	ErrTaskLabelTooLong      = errors.New("task or option label is too long")
+	ErrInvalidTaskType       = errors.New("task_type must be one of SUBTASK, NUMBER, TEXT, INSPECTION, MULTIPLE_CHOICE, METER")
+	ErrTaskOptionsRequired   = errors.New("multiple choice tasks need at least one option")
+	ErrTaskOptionsNotAllowed = errors.New("only multiple choice tasks have options")
+	ErrTooManyTaskOptions    = errors.New("a task has at most 50 options")
+	ErrDuplicateTaskOption   = errors.New("option labels must be unique")
+	ErrTaskOptionNotFound    = errors.New("option does not belong to this task base")
+	ErrTaskBaseInUse         = errors.New("task base is used by tasks; archive it instead")
+	ErrTaskBaseArchived      = errors.New("task base is archived")
+	ErrTaskBaseRequired      = errors.New("give task_base_id or task_base, not both")
+	ErrTaskWorkOrderRequired = errors.New("work_order_id is required")
+	ErrTaskNotesTooLong      = errors.New("notes or value is too long")
+	ErrTaskOrderMismatch     = errors.New("task_ids must list every task of the work order exactly once")
+	ErrAssetNotFound         = errors.New("asset not found")
+
+	ErrTaskUnitTooLong         = errors.New("unit is too long")
+	ErrTaskToleranceNotAllowed = errors.New("unit, min_value and max_value only apply to NUMBER and METER tasks")
+	ErrInvalidTolerance        = errors.New("min_value must not be greater than max_value")
+	ErrTaskMeterRequired       = errors.New("METER tasks need a meter_id")
+	ErrTaskMeterNotAllowed     = errors.New("only METER tasks link a meter")
+	ErrMeterNotFound           = errors.New("meter not found")
+	ErrInvalidTaskNumber       = errors.New("value must be a number")
+	ErrInvalidInspectionResult = errors.New("value must be PASS, FAIL or FLAG")
+	ErrInvalidSubtaskValue     = errors.New("value must be COMPLETE or OPEN")
+	ErrInvalidTaskChoice       = errors.New("value must be one of the task's options")
+	ErrTaskNotToggleable       = errors.New("only SUBTASK tasks toggle; set the value instead")
+)
+
+// TaskOption is one choice of a multiple choice task base.
+type TaskOption struct {
+	ID       uuid.UUID `json:"id"`
+	Label    string    `json:"label"`
+	Position int       `json:"position"`
+}
+
+// TaskBase is a reusable checklist item in the organisation's library.
+// Global bases (shared by every organisation) can be used but not edited.
+// UsageCount is the number of tasks created from the base.
+type TaskBase struct {
+	ID          uuid.UUID    `json:"id"`
+	Label       string       `json:"label"`
+	TaskType    string       `json:"task_type"`
+	UserID      *uuid.UUID   `json:"user_id,omitempty"`
+	UserName    string       `json:"user_name,omitempty"`
+	AssetID     *uuid.UUID   `json:"asset_id,omitempty"`
+	AssetName   string       `json:"asset_name,omitempty"`
+	Unit        string       `json:"unit,omitempty"`
+	MinValue    *float64     `json:"min_value,omitempty"`
+	MaxValue    *float64     `json:"max_value,omitempty"`
+	MeterID     *uuid.UUID   `json:"meter_id,omitempty"`
+	MeterName   string       `json:"meter_name,omitempty"`
+	Options     []TaskOption `json:"options"`
+	Optional    bool         `json:"optional"`
+	Archived    bool         `json:"archived"`
+	Global      bool         `json:"global"`
+	UsageCount  int64        `json:"usage_count"`
+	CreatedByID *uuid.UUID   `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// TaskOptionInput is one choice of a TaskBaseInput. Options with an ID keep
+// it (and are relabelled); options without one are added.
+type TaskOptionInput struct {
+	ID    *uuid.UUID `json:"id"`
+	Label string     `json:"label"`
+}
+
+// TaskBaseInput is the writable part of a task base. Options are listed in
+// order; on update, options left out are removed. Unit and the min/max
+// tolerance apply to NUMBER and METER bases; METER bases need a meter.
+// Optional tasks never hold up completion under a checklist policy.
+type TaskBaseInput struct {
+	Label    string            `json:"label"`
+	TaskType string            `json:"task_type"`
+	UserID   *uuid.UUID        `json:"user_id"`
+	AssetID  *uuid.UUID        `json:"asset_id"`
+	Unit     string            `json:"unit"`
+	MinValue *float64          `json:"min_value"`
+	MaxValue *float64          `json:"max_value"`
+	MeterID  *uuid.UUID        `json:"meter_id"`
+	Options  []TaskOptionInput `json:"options"`
+	Optional bool              `json:"optional"`
+	Archived bool              `json:"archived"`
+}
+
+// Normalize trims the input, defaults the type to SUBTASK and checks the
+// options, the unit, the tolerance and the meter against it.
+func (in *TaskBaseInput) Normalize() error {
+	in.Label = strings.TrimSpace(in.Label)
+	in.TaskType = strings.ToUpper(strings.TrimSpace(in.TaskType))
+	if in.Label == "" {
+		return ErrTaskLabelRequired
+	}
+	if utf8.RuneCountInString(in.Label) > MaxTaskLabelLength {
+		return ErrTaskLabelTooLong
+	}
+	switch in.TaskType {
+	case "":
+		in.TaskType = TaskTypeSubtask
+	case TaskTypeSubtask, TaskTypeNumber, TaskTypeText, TaskTypeInspection, TaskTypeMultipleChoice, TaskTypeMeter:
+	default:
+		return ErrInvalidTaskType
+	}
+	if in.UserID != nil && *in.UserID == uuid.Nil {
+		in.UserID = nil
+	}
+	if in.AssetID != nil && *in.AssetID == uuid.Nil {
+		in.AssetID = nil
+	}
+	if in.MeterID != nil && *in.MeterID == uuid.Nil {
+		in.MeterID = nil
+	}
+	in.Unit = strings.TrimSpace(in.Unit)
+	if utf8.RuneCountInString(in.Unit) > MaxTaskUnitLength {
+		return ErrTaskUnitTooLong
+	}
+	measured := in.TaskType == TaskTypeNumber || in.TaskType == TaskTypeMeter
+	if !measured && (in.Unit != "" || in.MinValue != nil || in.MaxValue != nil) {
+		return ErrTaskToleranceNotAllowed
+	}
+	if in.MinValue != nil && in.MaxValue != nil && *in.MinValue > *in.MaxValue {
+		return ErrInvalidTolerance
+	}
+	if in.TaskType == TaskTypeMeter && in.MeterID == nil {
+		return ErrTaskMeterRequired
+	}
+	if in.TaskType != TaskTypeMeter && in.MeterID != nil {
+		return ErrTaskMeterNotAllowed
+	}
+	if in.TaskType != TaskTypeMultipleChoice {
+		if len(in.Options) > 0 {
+			return ErrTaskOptionsNotAllowed
+		}
+		return nil
+	}
+	if len(in.Options) == 0 {
+		return ErrTaskOptionsRequired
+	}
+	if len(in.Options) > MaxTaskOptions {
+		return ErrTooManyTaskOptions
+	}
+	labels := make(map[string]bool, len(in.Options))
+	ids := make(map[uuid.UUID]bool, len(in.Options))
+	for i := range in.Options {
+		o := &in.Options[i]
+		o.Label = strings.TrimSpace(o.Label)
+		if o.Label == "" {
+			return ErrTaskLabelRequired
+		}
+		if utf8.RuneCountInString(o.Label) > MaxTaskLabelLength {
+			return ErrTaskLabelTooLong
+		}
+		key := strings.ToLower(o.Label)
+		if labels[key] || (o.ID != nil && ids[*o.ID]) {
+			return ErrDuplicateTaskOption
+		}
+		labels[key] = true
+		if o.ID != nil {
+			ids[*o.ID] = true
+		}
+	}
+	return nil
+}
+
+// TaskValue is a value checked against its task base. Number is the reading
+// of NUMBER and METER tasks and OptionID the choice of MULTIPLE_CHOICE ones.
+type TaskValue struct {
+	Value          string
+	Number         *float64
+	OptionID       *uuid.UUID
+	OutOfTolerance bool
+}
+
+// ParseValue checks raw against the type of the base and returns it in its
+// canonical form: results upper-cased, numbers without their unit, choices
+// with the option's own label. An empty value clears the task.
+func (b TaskBase) ParseValue(raw string) (TaskValue, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return TaskValue{}, nil
+	}
+	switch b.TaskType {
+	case TaskTypeSubtask:
+		v := strings.ToUpper(raw)
+		if v != SubtaskComplete && v != SubtaskOpen {
+			return TaskValue{}, ErrInvalidSubtaskValue
+		}
+		return TaskValue{Value: v}, nil
+	case TaskTypeInspection:
+		v := strings.ToUpper(raw)
+		if v != InspectionPass && v != InspectionFail && v != InspectionFlag {
+			return TaskValue{}, ErrInvalidInspectionResult
+		}
+		return TaskValue{Value: v}, nil
+	case TaskTypeNumber, TaskTypeMeter:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil || !isFinite(n) {
+			return TaskValue{}, ErrInvalidTaskNumber
+		}
+		return TaskValue{
+			Value:          strconv.FormatFloat(n, 'f', -1, 64),
+			Number:         &n,
+			OutOfTolerance: (b.MinValue != nil && n < *b.MinValue) || (b.MaxValue != nil && n > *b.MaxValue),
+		}, nil
+	case TaskTypeMultipleChoice:
+		for _, o := range b.Options {
+			if strings.EqualFold(o.Label, raw) || o.ID.String() == strings.ToLower(raw) {
+				id := o.ID
+				return TaskValue{Value: o.Label, OptionID: &id}, nil
+			}
+		}
+		return TaskValue{}, ErrInvalidTaskChoice
+	}
+	return TaskValue{Value: raw}, nil
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// Task is one checklist item of a work order. OutOfTolerance flags a
+// NUMBER or METER reading outside the base's min/max when it was taken.
+type Task struct {
+	ID             uuid.UUID  `json:"id"`
+	WorkOrderID    uuid.UUID  `json:"work_order_id"`
+	TaskBaseID     uuid.UUID  `json:"task_base_id"`
+	Label          string     `json:"label"`
+	TaskType       string     `json:"task_type"`
+	Unit           string     `json:"unit,omitempty"`
+	MinValue       *float64   `json:"min_value,omitempty"`
+	MaxValue       *float64   `json:"max_value,omitempty"`
+	MeterID        *uuid.UUID `json:"meter_id,omitempty"`
+	Notes          string     `json:"notes,omitempty"`
+	Value          string     `json:"value,omitempty"`
+	NumericValue   *float64   `json:"numeric_value,omitempty"`
+	OptionID       *uuid.UUID `json:"option_id,omitempty"`
+	OutOfTolerance bool       `json:"out_of_tolerance"`
+	Completed      bool       `json:"completed"`
+	// CorrectiveWorkOrderID is the work order a finding rule spawned from
+	// this task, if any.
+	CorrectiveWorkOrderID *uuid.UUID `json:"corrective_work_order_id,omitempty"`
+	// AssigneeID is the technician the step is assigned to (by default the
+	// base's user); CompletedByID and CompletedAt record who signed it off.
+	AssigneeID      *uuid.UUID `json:"assignee_id"`
+	AssigneeName    string     `json:"assignee_name,omitempty"`
+	DueAt           *time.Time `json:"due_at"`
+	CompletedByID   *uuid.UUID `json:"completed_by_id"`
+	CompletedByName string     `json:"completed_by_name,omitempty"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	Position        int        `json:"position"`
+	CreatedByID     *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TaskInput adds a task to a work order, from a library base (TaskBaseID)
+// or from a new one (TaskBase) that is saved to the library. The task is
+// appended to the checklist. Without an assignee the base's user is used.
+type TaskInput struct {
+	WorkOrderID uuid.UUID      `json:"work_order_id"`
+	TaskBaseID  *uuid.UUID     `json:"task_base_id"`
+	TaskBase    *TaskBaseInput `json:"task_base"`
+	Notes       string         `json:"notes"`
+	Value       *string        `json:"value"`
+	AssigneeID  *uuid.UUID     `json:"assignee_id"`
+	DueAt       *time.Time     `json:"due_at"`
+}
+
+// Normalize checks that exactly one base is given and trims the rest.
+func (in *TaskInput) Normalize() error {
+	if in.WorkOrderID == uuid.Nil {
+		return ErrTaskWorkOrderRequired
+	}
+	if (in.TaskBaseID == nil) == (in.TaskBase == nil) {
+		return ErrTaskBaseRequired
+	}
+	if in.TaskBase != nil {
+		if err := in.TaskBase.Normalize(); err != nil {
+			return err
+		}
+	}
+	if in.AssigneeID != nil && *in.AssigneeID == uuid.Nil {
+		in.AssigneeID = nil
+	}
+	in.Notes = strings.TrimSpace(in.Notes)
+	return checkTaskText(in.Notes, in.Value)
+}
+
+// TaskUpdate changes the notes and/or the value of a task; nil fields are
+// left alone and an empty string clears them. The value is checked against
+// the task type by the repo (see TaskBase.ParseValue).
+type TaskUpdate struct {
+	Notes *string `json:"notes"`
+	Value *string `json:"value"`
+}
+
+func (in *TaskUpdate) Normalize() error {
+	notes := ""
+	if in.Notes != nil {
+		notes = strings.TrimSpace(*in.Notes)
+		in.Notes = &notes
+	}
+	return checkTaskText(notes, in.Value)
+}
+
+func checkTaskText(notes string, value *string) error {
+	if utf8.RuneCountInString(notes) > MaxTaskNotesLength {
+		return ErrTaskNotesTooLong
+	}
+	if value != nil && utf8.RuneCountInString(*value) > MaxTaskNotesLength {
+		return ErrTaskNotesTooLong
+	}
+	return nil
+}
+
+// TaskAssignment replaces the assignee and due time of a task; nil clears
+// them.
+type TaskAssignment struct {
+	AssigneeID *uuid.UUID `json:"assignee_id"`
+	DueAt      *time.Time `json:"due_at"`
+}
+
+func (in *TaskAssignment) Normalize() {
+	if in.AssigneeID != nil && *in.AssigneeID == uuid.Nil {
+		in.AssigneeID = nil
+	}
+}
+
+// TaskOrderInput lists every task of a work order in its new order.
+type TaskOrderInput struct {
+	TaskIDs []uuid.UUID `json:"task_ids"`
+}
@@ -0,0 +1,99 @@
+// internal/models/task_finding.go
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Findings that can spawn a corrective work order (work_order_findings.trigger).
+const (
+	FindingFail           = "FAIL"
+	FindingFlag           = "FLAG"
+	FindingOutOfTolerance = "OUT_OF_TOLERANCE"
+)
+
+// MaxFindingDueInDays caps TaskFindingRule.DueInDays.
+const MaxFindingDueInDays = 365
+
+var (
+	ErrTaskFindingRuleNotFound = errors.New("finding rule not found")
+	ErrFindingTriggerRequired  = errors.New("enable on_fail, on_flag or on_out_of_tolerance")
+	ErrInvalidFindingDueIn     = errors.New("due_in_days must be between 0 and 365")
+	ErrFindingRuleNotSupported = errors.New("finding rules apply to INSPECTION, NUMBER and METER tasks")
+)
+
+// TaskFindingRule makes a task base spawn a corrective work order when a
+// task finds a problem: an INSPECTION marked FAIL (OnFail) or FLAG
+// (OnFlag), or a NUMBER/METER reading out of tolerance (OnOutOfTolerance).
+// The work order inherits the source's asset and location; Priority (nil:
+// the source's), CategoryID, TeamID and DueInDays shape the rest.
+type TaskFindingRule struct {
+	TaskBaseID       uuid.UUID  `json:"task_base_id"`
+	TaskBaseLabel    string     `json:"task_base_label"`
+	TaskType         string     `json:"task_type"`
+	OnFail           bool       `json:"on_fail"`
+	OnFlag           bool       `json:"on_flag"`
+	OnOutOfTolerance bool       `json:"on_out_of_tolerance"`
+	Priority         *string    `json:"priority"`
+	CategoryID       *uuid.UUID `json:"category_id"`
+	CategoryName     string     `json:"category_name,omitempty"`
+	TeamID           *uuid.UUID `json:"team_id"`
+	TeamName         string     `json:"team_name,omitempty"`
+	DueInDays        *int       `json:"due_in_days"`
+	CreatedByID      *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// TaskFindingRuleInput is the writable part of a rule. Omitted triggers
+// default to on_fail and on_out_of_tolerance.
+type TaskFindingRuleInput struct {
+	OnFail           *bool      `json:"on_fail"`
+	OnFlag           *bool      `json:"on_flag"`
+	OnOutOfTolerance *bool      `json:"on_out_of_tolerance"`
+	Priority         *string    `json:"priority"`
+	CategoryID       *uuid.UUID `json:"category_id"`
+	TeamID           *uuid.UUID `json:"team_id"`
+	DueInDays        *int       `json:"due_in_days"`
+}
+
+// Normalize fills the default triggers, upper-cases the priority (empty
+// means the source's) and checks the due date.
+func (in *TaskFindingRuleInput) Normalize() error {
+	def := func(b **bool, v bool) {
+		if *b == nil {
+			*b = &v
+		}
+	}
+	def(&in.OnFail, true)
+	def(&in.OnFlag, false)
+	def(&in.OnOutOfTolerance, true)
+	if !*in.OnFail && !*in.OnFlag && !*in.OnOutOfTolerance {
+		return ErrFindingTriggerRequired
+	}
+	if in.Priority != nil {
+		if strings.TrimSpace(*in.Priority) == "" {
+			in.Priority = nil
+		} else {
+			p, err := ParseWorkOrderPriority(*in.Priority)
+			if err != nil {
+				return err
+			}
+			in.Priority = &p
+		}
+	}
+	if in.CategoryID != nil && *in.CategoryID == uuid.Nil {
+		in.CategoryID = nil
+	}
+	if in.TeamID != nil && *in.TeamID == uuid.Nil {
+		in.TeamID = nil
+	}
+	if in.DueInDays != nil && (*in.DueInDays < 0 || *in.DueInDays > MaxFindingDueInDays) {
+		return ErrInvalidFindingDueIn
+	}
+	return nil
+}
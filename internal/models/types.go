@@ -3,6 +3,7 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +21,33 @@ type WorkOrder struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 	DueDate     time.Time `json:"due_date,omitempty"`
 	CustomID    string    `json:"custom_id,omitempty"`
+	Version     int64     `json:"version"`
+	SLABreached bool      `json:"sla_breached"`
+	SLAAtRisk   bool      `json:"sla_at_risk"`
+}
+
+// Work order priorities accepted by the API (work_order.priority).
+const (
+	PriorityNone     = "NONE"
+	PriorityLow      = "LOW"
+	PriorityMedium   = "MEDIUM"
+	PriorityHigh     = "HIGH"
+	PriorityCritical = "CRITICAL"
+)
+
+var ErrUnknownPriority = errors.New("unknown work order priority")
+
+// ParseWorkOrderPriority upper-cases p and checks it is a known priority.
+// An empty string maps to PriorityNone.
+func ParseWorkOrderPriority(p string) (string, error) {
+	switch v := strings.ToUpper(strings.TrimSpace(p)); v {
+	case "":
+		return PriorityNone, nil
+	case PriorityNone, PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical:
+		return v, nil
+	default:
+		return "", ErrUnknownPriority
+	}
 }
 
 type OrgRole string
@@ -31,6 +59,23 @@ const (
 	RoleViewer OrgRole = "Viewer"
 )
 
+// Rank orders roles from Viewer (1) to Owner (4); unknown roles rank 0.
+// Every role comparison, in the middleware and the models, goes through it.
+func (r OrgRole) Rank() int {
+	switch r {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleMember:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
 type User struct {
     ID    uuid.UUID
     Email string
@@ -75,6 +120,9 @@ var (
 	ErrUserNotFound = errors.New("user not found")
 	ErrOrgNotFound  = errors.New("org not found")
 	ErrRoleNotFound = errors.New("role not found")
+	ErrTaskNotFound = errors.New("task not found")
+
+	ErrInvalidWorkOrderPayload = errors.New("work order payload has an invalid value")
 )
 
 type Org struct {
@@ -0,0 +1,63 @@
+// internal/models/work_order_bulk.go
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// MaxBulkWorkOrders caps how many work orders one bulk request may touch.
+const MaxBulkWorkOrders = 500
+
+// Bulk actions accepted by POST /work-orders/bulk.
+const (
+	BulkChangeStatus = "change_status"
+	BulkReassign     = "reassign"
+	BulkArchive      = "archive"
+	BulkSetDueDate   = "set_due_date"
+	BulkAddToTeam    = "add_to_team"
+	BulkDelete       = "delete"
+)
+
+var (
+	ErrUnknownBulkAction = errors.New("unknown bulk action")
+	ErrBulkNoIDs         = errors.New("ids must not be empty")
+	ErrBulkTooMany       = errors.New("too many work orders in one bulk request")
+	ErrInvalidReference  = errors.New("referenced record does not exist")
+	ErrBulkRolledBack    = errors.New("not applied: another item of the atomic batch failed")
+)
+
+// BulkOperation is a validated bulk request. Patch holds the
+// update_work_order_from_json payload for the field-level actions; ToStatus
+// and Reason are only used by change_status.
+type BulkOperation struct {
+	Action   string
+	IDs      []uuid.UUID
+	Atomic   bool
+	ToStatus WorkOrderStatus
+	Reason   string
+	Patch    []byte
+}
+
+// BulkItemResult reports the outcome for one work order. Err is the domain
+// error and is rendered by the handler; Unchanged marks an item that was
+// already in the requested state.
+type BulkItemResult struct {
+	ID        uuid.UUID
+	Unchanged bool
+	Err       error
+}
+
+// UniqueIDs returns ids without duplicates, keeping the first occurrence.
+func UniqueIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
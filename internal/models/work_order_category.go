@@ -0,0 +1,93 @@
+// internal/models/work_order_category.go
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxCategoryNameLength caps category names accepted from clients.
+const MaxCategoryNameLength = 100
+
+var (
+	ErrCategoryNotFound     = errors.New("category not found")
+	ErrCategoryNameRequired = errors.New("category name is required")
+	ErrCategoryNameTooLong  = errors.New("category name is too long")
+	ErrCategoryNameTaken    = errors.New("a category with this name already exists")
+	ErrNegativeDuration     = errors.New("estimated duration must not be negative")
+	ErrTeamNotFound         = errors.New("team not found")
+	ErrTaskBaseNotFound     = errors.New("task base not found")
+)
+
+// TemplateTask is one task base instantiated, in Position order, on work
+// orders created from a category or a work order template.
+type TemplateTask struct {
+	TaskBaseID uuid.UUID `json:"task_base_id"`
+	Label      string    `json:"label"`
+	TaskType   string    `json:"task_type"`
+	Position   int       `json:"position"`
+}
+
+// WorkOrderCategory groups work orders within an organisation. The Default*
+// fields and TaskTemplate are applied when a work order is created in the
+// category and the payload leaves the field out.
+type WorkOrderCategory struct {
+	ID                       uuid.UUID      `json:"id"`
+	Name                     string         `json:"name"`
+	Description              string         `json:"description,omitempty"`
+	DefaultPriority          *string        `json:"default_priority"`
+	DefaultEstimatedDuration *float64       `json:"default_estimated_duration"`
+	DefaultTeamID            *uuid.UUID     `json:"default_team_id"`
+	TaskTemplate             []TemplateTask `json:"task_template"`
+	CreatedByID              *uuid.UUID     `json:"created_by_id,omitempty"`
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
+}
+
+// WorkOrderCategoryInput is the writable part of a category. TaskTemplate
+// lists task base IDs in the order the tasks should be created.
+type WorkOrderCategoryInput struct {
+	Name                     string      `json:"name"`
+	Description              string      `json:"description"`
+	DefaultPriority          *string     `json:"default_priority"`
+	DefaultEstimatedDuration *float64    `json:"default_estimated_duration"`
+	DefaultTeamID            *uuid.UUID  `json:"default_team_id"`
+	TaskTemplate             []uuid.UUID `json:"task_template"`
+}
+
+// Normalize trims the input, upper-cases the default priority and checks
+// what can be checked without the database.
+func (in *WorkOrderCategoryInput) Normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	if in.Name == "" {
+		return ErrCategoryNameRequired
+	}
+	if utf8.RuneCountInString(in.Name) > MaxCategoryNameLength {
+		return ErrCategoryNameTooLong
+	}
+	if in.DefaultPriority != nil {
+		p, err := ParseWorkOrderPriority(*in.DefaultPriority)
+		if err != nil {
+			return err
+		}
+		in.DefaultPriority = &p
+	}
+	if in.DefaultEstimatedDuration != nil && *in.DefaultEstimatedDuration < 0 {
+		return ErrNegativeDuration
+	}
+	seen := make(map[uuid.UUID]bool, len(in.TaskTemplate))
+	tasks := in.TaskTemplate[:0]
+	for _, id := range in.TaskTemplate {
+		if !seen[id] {
+			seen[id] = true
+			tasks = append(tasks, id)
+		}
+	}
+	in.TaskTemplate = tasks
+	return nil
+}
@@ -0,0 +1,77 @@
+// internal/models/work_order_comment.go
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommentEditWindow is how long an author may edit or delete their own
+// comment. Admins and owners can delete at any time; nobody edits after it.
+const CommentEditWindow = 15 * time.Minute
+
+// MaxCommentLength caps the body size accepted from clients.
+const MaxCommentLength = 10000
+
+var (
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrMentionNotMember   = errors.New("mentioned user is not a member of this organisation")
+	ErrCommentEditExpired = errors.New("comment can no longer be changed")
+	ErrCommentForbidden   = errors.New("not allowed to change this comment")
+)
+
+// WorkOrderCommentMention is a user @-mentioned in a comment.
+type WorkOrderCommentMention struct {
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name,omitempty"`
+}
+
+// WorkOrderComment is one entry of a work order's discussion thread.
+// Deleted comments are kept as tombstones (empty body) so replies still
+// have a parent to hang off.
+type WorkOrderComment struct {
+	ID          uuid.UUID                 `json:"id"`
+	WorkOrderID uuid.UUID                 `json:"work_order_id"`
+	ParentID    *uuid.UUID                `json:"parent_id,omitempty"`
+	AuthorID    *uuid.UUID                `json:"author_id,omitempty"`
+	AuthorName  string                    `json:"author_name,omitempty"`
+	Body        string                    `json:"body"`
+	Mentions    []WorkOrderCommentMention `json:"mentions"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	EditedAt    *time.Time                `json:"edited_at,omitempty"`
+	Deleted     bool                      `json:"deleted"`
+}
+
+// CanEditComment reports whether user may edit c at now.
+func CanEditComment(c WorkOrderComment, userID uuid.UUID, now time.Time) error {
+	if c.AuthorID == nil || *c.AuthorID != userID {
+		return ErrCommentForbidden
+	}
+	if now.Sub(c.CreatedAt) > CommentEditWindow {
+		return ErrCommentEditExpired
+	}
+	return nil
+}
+
+// CanDeleteComment reports whether user (with role) may delete c at now.
+func CanDeleteComment(c WorkOrderComment, userID uuid.UUID, role OrgRole, now time.Time) error {
+	if role.Rank() >= RoleAdmin.Rank() {
+		return nil
+	}
+	return CanEditComment(c, userID, now)
+}
+
+// TimelineEntry is one item of the merged activity feed for a work order.
+// Kind is "comment", "status" or "field"; Data carries the kind-specific
+// details (body, from/to, field/old/new).
+type TimelineEntry struct {
+	Kind       string          `json:"kind"`
+	ID         uuid.UUID       `json:"id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty"`
+	ActorName  string          `json:"actor_name,omitempty"`
+	Data       json.RawMessage `json:"data"`
+}
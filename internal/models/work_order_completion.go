@@ -0,0 +1,21 @@
+// internal/models/work_order_completion.go
+package models
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrSignatureRequired is returned when a work order with required_signature
+// is completed without a signature on file.
+var ErrSignatureRequired = errors.New("work order requires a signature before it can be completed")
+
+// WorkOrderCompletionState is what the completion endpoint needs to know
+// before it accepts a signature.
+type WorkOrderCompletionState struct {
+	Status            WorkOrderStatus
+	RequiredSignature bool
+	SignatureID       *uuid.UUID
+	Version           int64
+}
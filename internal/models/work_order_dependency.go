@@ -0,0 +1,67 @@
+// internal/models/work_order_dependency.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Directions of a WorkOrderLink.
+const (
+	DependencyBlockedBy = "BLOCKED_BY"
+	DependencyBlocking  = "BLOCKING"
+)
+
+var (
+	ErrDependencyCycle    = errors.New("the dependency would make the work orders wait for each other")
+	ErrHierarchyCycle     = errors.New("a work order cannot be placed under itself or one of its children")
+	ErrSelfDependency     = errors.New("a work order cannot be blocked by itself")
+	ErrDependencyExists   = errors.New("the work order is already blocked by this work order")
+	ErrDependencyNotFound = errors.New("dependency not found")
+	ErrParentNotFound     = errors.New("parent work order not found")
+	ErrBlockerNotFound    = errors.New("blocking work order not found")
+	ErrWorkOrderBlocked   = errors.New("the work order is blocked by work orders that are not complete")
+)
+
+// WorkOrderLink is the other end of a dependency. For BLOCKED_BY links,
+// Resolved means the blocker no longer holds the work order back.
+type WorkOrderLink struct {
+	Direction string    `json:"direction"`
+	ID        uuid.UUID `json:"id"`
+	CustomID  string    `json:"custom_id,omitempty"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status"`
+	Resolved  bool      `json:"resolved"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorkOrderDependencies lists both directions for one work order. Blocked
+// is true while any BlockedBy link is unresolved.
+type WorkOrderDependencies struct {
+	BlockedBy []WorkOrderLink `json:"blocked_by"`
+	Blocking  []WorkOrderLink `json:"blocking"`
+	Blocked   bool            `json:"blocked"`
+}
+
+// Unresolved returns the blockers that still hold the work order back.
+func (d WorkOrderDependencies) Unresolved() []WorkOrderLink {
+	out := make([]WorkOrderLink, 0, len(d.BlockedBy))
+	for _, l := range d.BlockedBy {
+		if !l.Resolved {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// DependencyInput makes a work order wait for BlockedByID.
+type DependencyInput struct {
+	BlockedByID uuid.UUID `json:"blocked_by_id"`
+}
+
+// ParentInput moves a work order under ParentID; nil makes it top-level.
+type ParentInput struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+}
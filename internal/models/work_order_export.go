@@ -0,0 +1,30 @@
+// internal/models/work_order_export.go
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderExportRow is one flattened work order as written by
+// GET /work-orders/export. Names are empty when the reference is unset.
+type WorkOrderExportRow struct {
+	ID             uuid.UUID
+	CustomID       string
+	Title          string
+	Status         string
+	Priority       string
+	DueDate        *time.Time
+	CreatedAt      time.Time
+	CompletedOn    *time.Time
+	PrimaryWorker  string
+	Assignees      string
+	Team           string
+	Location       string
+	Asset          string
+	Category       string
+	TasksTotal     int64
+	TasksCompleted int64
+	SLABreached    bool
+}
@@ -0,0 +1,130 @@
+// internal/models/work_order_id_format.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Date parts a work order custom ID can carry after its prefix.
+const (
+	IDDateNone   = "NONE"
+	IDDateYear   = "YYYY"
+	IDDateYY     = "YY"
+	IDDateYearMM = "YYYYMM"
+	IDDateYYMM   = "YYMM"
+)
+
+// When the sequence of generated work order IDs starts again at 1.
+const (
+	IDResetYearly  = "YEARLY"
+	IDResetMonthly = "MONTHLY"
+	IDResetNever   = "NEVER"
+)
+
+// MaxWorkOrderIDNumber bounds next_number (the counters are 32-bit).
+const MaxWorkOrderIDNumber = 999_999_999
+
+// MaxWorkOrderIDPadding is the widest zero-padding: the digits of
+// MaxWorkOrderIDNumber, the longest sequence the counter repair matches.
+const MaxWorkOrderIDPadding = 9
+
+var (
+	ErrInvalidIDPrefix    = errors.New("prefix must be at most 16 letters, digits or - _ / . #")
+	ErrInvalidIDSeparator = errors.New("separator must be at most 3 of - _ / . #")
+	ErrInvalidIDDatePart  = errors.New("date_part must be NONE, YYYY, YY, YYYYMM or YYMM")
+	ErrInvalidIDPadding   = fmt.Errorf("padding must be between 1 and %d", MaxWorkOrderIDPadding)
+	ErrInvalidIDReset     = errors.New("reset_period must be YEARLY, MONTHLY or NEVER")
+	ErrIDResetNeedsDate   = errors.New("a YEARLY reset needs a date part with the year and a MONTHLY reset one with the month, or IDs would repeat")
+	ErrInvalidIDNumber    = errors.New("next_number must be between 1 and 999999999")
+)
+
+var (
+	idPrefixRe    = regexp.MustCompile(`^[A-Za-z0-9_/.#-]{0,16}$`)
+	idSeparatorRe = regexp.MustCompile(`^[_/.#-]{0,3}$`)
+)
+
+// WorkOrderIDFormat is how generated work order custom IDs look:
+// Prefix, the date part and Separator (only when there is a date part),
+// then the sequence zero-padded to Padding digits. Longer sequences are
+// never cut. Customised is false while the org uses the default
+// WO-YYYY-NNNN. NextNumber/NextID preview today's next generated ID.
+type WorkOrderIDFormat struct {
+	Prefix      string     `json:"prefix"`
+	DatePart    string     `json:"date_part"`
+	Separator   string     `json:"separator"`
+	Padding     int        `json:"padding"`
+	ResetPeriod string     `json:"reset_period"`
+	Customised  bool       `json:"customised"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	NextNumber  int64      `json:"next_number"`
+	NextID      string     `json:"next_id"`
+}
+
+// WorkOrderIDFormatInput replaces the format; omitted fields take the
+// defaults. NextNumber, when set, restarts the current period's sequence
+// there (numbers already taken are skipped).
+type WorkOrderIDFormatInput struct {
+	Prefix      *string `json:"prefix"`
+	DatePart    string  `json:"date_part"`
+	Separator   *string `json:"separator"`
+	Padding     *int    `json:"padding"`
+	ResetPeriod string  `json:"reset_period"`
+	NextNumber  *int64  `json:"next_number"`
+}
+
+// Normalize fills the defaults, upper-cases the enums and checks that the
+// reset period cannot repeat an ID.
+func (in *WorkOrderIDFormatInput) Normalize() error {
+	if in.Prefix == nil {
+		p := "WO-"
+		in.Prefix = &p
+	}
+	*in.Prefix = strings.TrimSpace(*in.Prefix)
+	if !idPrefixRe.MatchString(*in.Prefix) {
+		return ErrInvalidIDPrefix
+	}
+	if in.Separator == nil {
+		s := "-"
+		in.Separator = &s
+	}
+	if !idSeparatorRe.MatchString(*in.Separator) {
+		return ErrInvalidIDSeparator
+	}
+	in.DatePart = strings.ToUpper(strings.TrimSpace(in.DatePart))
+	switch in.DatePart {
+	case "":
+		in.DatePart = IDDateYear
+	case IDDateNone, IDDateYear, IDDateYY, IDDateYearMM, IDDateYYMM:
+	default:
+		return ErrInvalidIDDatePart
+	}
+	if in.Padding == nil {
+		p := 4
+		in.Padding = &p
+	}
+	if *in.Padding < 1 || *in.Padding > MaxWorkOrderIDPadding {
+		return ErrInvalidIDPadding
+	}
+	in.ResetPeriod = strings.ToUpper(strings.TrimSpace(in.ResetPeriod))
+	switch in.ResetPeriod {
+	case "":
+		in.ResetPeriod = IDResetYearly
+	case IDResetYearly, IDResetMonthly, IDResetNever:
+	default:
+		return ErrInvalidIDReset
+	}
+	switch {
+	case in.ResetPeriod == IDResetMonthly && in.DatePart != IDDateYearMM && in.DatePart != IDDateYYMM:
+		return ErrIDResetNeedsDate
+	case in.ResetPeriod == IDResetYearly && in.DatePart == IDDateNone:
+		return ErrIDResetNeedsDate
+	}
+	if in.NextNumber != nil && (*in.NextNumber < 1 || *in.NextNumber > MaxWorkOrderIDNumber) {
+		return ErrInvalidIDNumber
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// internal/models/work_order_status.go
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkOrderStatus is the lifecycle state stored in work_order.status.
+type WorkOrderStatus string
+
+const (
+	StatusOpen       WorkOrderStatus = "OPEN"
+	StatusInProgress WorkOrderStatus = "IN_PROGRESS"
+	StatusOnHold     WorkOrderStatus = "ON_HOLD"
+	StatusComplete   WorkOrderStatus = "COMPLETE"
+	StatusCancelled  WorkOrderStatus = "CANCELLED"
+)
+
+var (
+	ErrWorkOrderNotFound   = errors.New("work order not found")
+	ErrUnknownStatus       = errors.New("unknown work order status")
+	ErrIllegalTransition   = errors.New("illegal status transition")
+	ErrTransitionForbidden = errors.New("role not allowed to perform this status transition")
+	ErrStatusConflict      = errors.New("work order status was changed concurrently")
+	ErrVersionMismatch     = errors.New("work order was modified since the supplied version")
+)
+
+// statusTransitions maps from -> to -> minimum role allowed to make the move.
+// Anything not listed here is an illegal transition.
+var statusTransitions = map[WorkOrderStatus]map[WorkOrderStatus]OrgRole{
+	StatusOpen: {
+		StatusInProgress: RoleMember,
+		StatusOnHold:     RoleMember,
+		StatusCancelled:  RoleAdmin,
+	},
+	StatusInProgress: {
+		StatusOpen:      RoleMember,
+		StatusOnHold:    RoleMember,
+		StatusComplete:  RoleMember,
+		StatusCancelled: RoleAdmin,
+	},
+	StatusOnHold: {
+		StatusOpen:       RoleMember,
+		StatusInProgress: RoleMember,
+		StatusComplete:   RoleMember,
+		StatusCancelled:  RoleAdmin,
+	},
+	// Re-opening closed work is a supervisor decision
+	StatusComplete: {
+		StatusInProgress: RoleAdmin,
+	},
+	StatusCancelled: {
+		StatusOpen: RoleAdmin,
+	},
+}
+
+// ParseWorkOrderStatus normalises client input ("in progress", "completed", ...)
+// into one of the known statuses.
+func ParseWorkOrderStatus(s string) (WorkOrderStatus, error) {
+	norm := strings.ToUpper(strings.TrimSpace(s))
+	norm = strings.NewReplacer(" ", "_", "-", "_").Replace(norm)
+	switch norm {
+	case "COMPLETED", "DONE":
+		norm = string(StatusComplete)
+	case "CANCELED":
+		norm = string(StatusCancelled)
+	}
+	st := WorkOrderStatus(norm)
+	if _, ok := statusTransitions[st]; !ok {
+		return "", ErrUnknownStatus
+	}
+	return st, nil
+}
+
+// CheckStatusTransition reports whether role may move a work order from -> to.
+// Returns ErrIllegalTransition for moves outside the lifecycle and
+// ErrTransitionForbidden when the move exists but needs a higher role.
+func CheckStatusTransition(from, to WorkOrderStatus, role OrgRole) error {
+	minRole, ok := statusTransitions[from][to]
+	if !ok {
+		return ErrIllegalTransition
+	}
+	if role.Rank() < minRole.Rank() {
+		return ErrTransitionForbidden
+	}
+	return nil
+}
+
+// AllowedTransitions lists the statuses role may move a work order to from `from`.
+func AllowedTransitions(from WorkOrderStatus, role OrgRole) []WorkOrderStatus {
+	out := []WorkOrderStatus{}
+	for to, minRole := range statusTransitions[from] {
+		if role.Rank() >= minRole.Rank() {
+			out = append(out, to)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// WorkOrderStatusChange is one row of a work order's status history.
+type WorkOrderStatusChange struct {
+	ID              uuid.UUID  `json:"id"`
+	FromStatus      string     `json:"from_status,omitempty"`
+	ToStatus        string     `json:"to_status"`
+	Reason          string     `json:"reason,omitempty"`
+	ChangedAt       time.Time  `json:"changed_at"`
+	ChangedByID     *uuid.UUID `json:"changed_by_id,omitempty"`
+	ChangedByName   string     `json:"changed_by_name,omitempty"`
+	SecondsInStatus float64    `json:"seconds_in_status"`
+}
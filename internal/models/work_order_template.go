@@ -0,0 +1,79 @@
+// internal/models/work_order_template.go
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxTemplateNameLength caps template names accepted from clients.
+const MaxTemplateNameLength = 200
+
+var (
+	ErrTemplateNotFound       = errors.New("work order template not found")
+	ErrTemplateNameRequired   = errors.New("template name is required")
+	ErrTemplateNameTooLong    = errors.New("template name is too long")
+	ErrTemplateNameTaken      = errors.New("a template with this name already exists")
+	ErrTemplatePayloadInvalid = errors.New("template payload must be a JSON object")
+	ErrTemplateTasksInPayload = errors.New("list template tasks in \"tasks\", not inside the payload")
+)
+
+// WorkOrderTemplate is a saved create_work_order_from_json payload plus an
+// ordered task list, instantiated via POST /work-order-templates/{id}/instantiate.
+type WorkOrderTemplate struct {
+	ID          uuid.UUID       `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	Tasks       []TemplateTask  `json:"tasks"`
+	CreatedByID *uuid.UUID      `json:"created_by_id,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// WorkOrderTemplateInput is the writable part of a template. Tasks lists
+// task base IDs in the order the tasks should be created.
+type WorkOrderTemplateInput struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Payload     json.RawMessage `json:"payload"`
+	Tasks       []uuid.UUID     `json:"tasks"`
+}
+
+// Normalize trims the input and checks the payload is an object. A custom
+// ID is dropped: every instance needs its own.
+func (in *WorkOrderTemplateInput) Normalize() error {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Description = strings.TrimSpace(in.Description)
+	if in.Name == "" {
+		return ErrTemplateNameRequired
+	}
+	if utf8.RuneCountInString(in.Name) > MaxTemplateNameLength {
+		return ErrTemplateNameTooLong
+	}
+
+	payload := map[string]json.RawMessage{}
+	if raw := bytes.TrimSpace(in.Payload); len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		if raw[0] != '{' || json.Unmarshal(raw, &payload) != nil {
+			return ErrTemplatePayloadInvalid
+		}
+	}
+	if _, ok := payload["tasks"]; ok {
+		return ErrTemplateTasksInPayload
+	}
+	delete(payload, "custom_id")
+	delete(payload, "customId")
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ErrTemplatePayloadInvalid
+	}
+	in.Payload = b
+	in.Tasks = UniqueIDs(in.Tasks)
+	return nil
+}
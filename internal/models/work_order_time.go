@@ -0,0 +1,198 @@
+// internal/models/work_order_time.go
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// MaxTimeEntryDuration caps a single manual entry; longer spans are almost
+// always a typo in the date.
+const MaxTimeEntryDuration = 24 * time.Hour
+
+// MaxTimeEntryNotesLength caps the notes accepted from clients.
+const MaxTimeEntryNotesLength = 2000
+
+const (
+	TimeEntrySourceTimer  = "timer"
+	TimeEntrySourceManual = "manual"
+)
+
+var (
+	ErrTimeEntryNotFound     = errors.New("time entry not found")
+	ErrTimerRunning          = errors.New("a timer is already running for this user")
+	ErrNoRunningTimer        = errors.New("no running timer on this work order")
+	ErrTimeEntryOverlap      = errors.New("time entry overlaps another entry for this user")
+	ErrTimeEntryRunning      = errors.New("running timers cannot be edited; stop the timer first")
+	ErrTimeEntryRange        = errors.New("give started_at and either ended_at or duration_minutes, ending after the start")
+	ErrTimeEntryTooLong      = errors.New("time entry is longer than 24 hours")
+	ErrTimeEntryInFuture     = errors.New("time entry ends in the future")
+	ErrNegativeHourlyRate    = errors.New("hourly rate must not be negative")
+	ErrTimeEntryNotesTooLong = errors.New("notes are too long")
+	ErrTimeEntryForbidden    = errors.New("not allowed to change this time entry")
+	ErrTimeEntryUserNotFound = errors.New("user is not a member of this organisation")
+)
+
+// TimeEntry is one span of labour on a work order. EndedAt is nil while the
+// timer is running; DurationSeconds and Cost then count up to now.
+type TimeEntry struct {
+	ID              uuid.UUID  `json:"id"`
+	WorkOrderID     uuid.UUID  `json:"work_order_id"`
+	UserID          uuid.UUID  `json:"user_id"`
+	UserName        string     `json:"user_name,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	Running         bool       `json:"running"`
+	DurationSeconds int64      `json:"duration_seconds"`
+	HourlyRate      float64    `json:"hourly_rate"`
+	Cost            float64    `json:"cost"`
+	Notes           string     `json:"notes,omitempty"`
+	Source          string     `json:"source"`
+	CreatedByID     *uuid.UUID `json:"created_by_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// TimeTotals sums the finished entries of a work order. Running timers are
+// only counted.
+type TimeTotals struct {
+	Entries      int     `json:"entries"`
+	Running      int     `json:"running"`
+	TotalSeconds int64   `json:"total_seconds"`
+	TotalHours   float64 `json:"total_hours"`
+	LabourCost   float64 `json:"labour_cost"`
+}
+
+// LabourCost prices seconds at an hourly rate, rounded to cents.
+func LabourCost(seconds int64, hourlyRate float64) float64 {
+	return RoundCents(float64(seconds) / 3600 * hourlyRate)
+}
+
+// RoundCents rounds a money amount to two decimals.
+func RoundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// SumTimeEntries computes the totals shown next to a work order's entries.
+// It matches the time_tracking block of the work order detail.
+func SumTimeEntries(entries []TimeEntry) TimeTotals {
+	var t TimeTotals
+	var cost float64
+	for _, e := range entries {
+		t.Entries++
+		if e.Running {
+			t.Running++
+			continue
+		}
+		t.TotalSeconds += e.DurationSeconds
+		cost += float64(e.DurationSeconds) / 3600 * e.HourlyRate
+	}
+	t.TotalHours = RoundCents(float64(t.TotalSeconds) / 3600)
+	t.LabourCost = RoundCents(cost)
+	return t
+}
+
+// TimerInput is the body for starting a timer.
+type TimerInput struct {
+	HourlyRate *float64 `json:"hourly_rate"`
+	Notes      string   `json:"notes"`
+}
+
+// Normalize trims the notes and checks the rate.
+func (in *TimerInput) Normalize() error {
+	in.Notes = strings.TrimSpace(in.Notes)
+	if utf8.RuneCountInString(in.Notes) > MaxTimeEntryNotesLength {
+		return ErrTimeEntryNotesTooLong
+	}
+	if in.HourlyRate != nil && *in.HourlyRate < 0 {
+		return ErrNegativeHourlyRate
+	}
+	return nil
+}
+
+// Rate returns the hourly rate, 0 when not given.
+func (in TimerInput) Rate() float64 {
+	if in.HourlyRate == nil {
+		return 0
+	}
+	return *in.HourlyRate
+}
+
+// TimeEntryInput is a manual entry. The span is StartedAt plus either
+// EndedAt or DurationMinutes. UserID defaults to the caller.
+type TimeEntryInput struct {
+	UserID          *uuid.UUID `json:"user_id"`
+	StartedAt       *time.Time `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at"`
+	DurationMinutes *float64   `json:"duration_minutes"`
+	HourlyRate      *float64   `json:"hourly_rate"`
+	Notes           string     `json:"notes"`
+}
+
+// Normalize resolves DurationMinutes into EndedAt and checks the span
+// against now. After it succeeds StartedAt and EndedAt are both set.
+func (in *TimeEntryInput) Normalize(now time.Time) error {
+	in.Notes = strings.TrimSpace(in.Notes)
+	if utf8.RuneCountInString(in.Notes) > MaxTimeEntryNotesLength {
+		return ErrTimeEntryNotesTooLong
+	}
+	if in.HourlyRate != nil && *in.HourlyRate < 0 {
+		return ErrNegativeHourlyRate
+	}
+	if in.StartedAt == nil || (in.EndedAt == nil) == (in.DurationMinutes == nil) {
+		return ErrTimeEntryRange
+	}
+	if in.DurationMinutes != nil {
+		if *in.DurationMinutes <= 0 {
+			return ErrTimeEntryRange
+		}
+		end := in.StartedAt.Add(time.Duration(*in.DurationMinutes * float64(time.Minute)))
+		in.EndedAt = &end
+		in.DurationMinutes = nil
+	}
+	if !in.EndedAt.After(*in.StartedAt) {
+		return ErrTimeEntryRange
+	}
+	if in.EndedAt.Sub(*in.StartedAt) > MaxTimeEntryDuration {
+		return ErrTimeEntryTooLong
+	}
+	if in.EndedAt.After(now) {
+		return ErrTimeEntryInFuture
+	}
+	return nil
+}
+
+// Rate returns the hourly rate, 0 when not given.
+func (in TimeEntryInput) Rate() float64 {
+	if in.HourlyRate == nil {
+		return 0
+	}
+	return *in.HourlyRate
+}
+
+// CanChangeTimeEntry reports whether user (with role) may edit or delete e.
+// Members manage their own entries; admins and owners manage everyone's.
+func CanChangeTimeEntry(e TimeEntry, userID uuid.UUID, role OrgRole) error {
+	if role.Rank() >= RoleAdmin.Rank() {
+		return nil
+	}
+	if role.Rank() < RoleMember.Rank() || e.UserID != userID {
+		return ErrTimeEntryForbidden
+	}
+	return nil
+}
+
+// CanLogTimeFor reports whether user (with role) may log time for target.
+func CanLogTimeFor(target, userID uuid.UUID, role OrgRole) error {
+	if role.Rank() < RoleMember.Rank() {
+		return ErrTimeEntryForbidden
+	}
+	if target != userID && role.Rank() < RoleAdmin.Rank() {
+		return ErrTimeEntryForbidden
+	}
+	return nil
+}
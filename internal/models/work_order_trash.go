@@ -0,0 +1,47 @@
+// internal/models/work_order_trash.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultTrashRetentionDays is how long deleted work orders stay in the
+// trash before the purge job removes them for good.
+const DefaultTrashRetentionDays = 30
+
+var (
+	ErrWorkOrderNotInTrash = errors.New("work order is not in the trash")
+	ErrInvalidPurgeAge     = errors.New("older_than_days must be between 0 and 36500")
+)
+
+// TrashedWorkOrder is a soft-deleted work order awaiting restore or purge.
+type TrashedWorkOrder struct {
+	ID            uuid.UUID  `json:"id"`
+	CustomID      string     `json:"custom_id,omitempty"`
+	Title         string     `json:"title"`
+	Status        string     `json:"status"`
+	Priority      string     `json:"priority"`
+	DeletedAt     time.Time  `json:"deleted_at"`
+	DeletedByID   *uuid.UUID `json:"deleted_by_id,omitempty"`
+	DeletedByName string     `json:"deleted_by_name,omitempty"`
+}
+
+// TrashPurgeInput empties the trash of work orders deleted at least
+// OlderThanDays ago; nil or 0 purges everything in it.
+type TrashPurgeInput struct {
+	OlderThanDays *int `json:"older_than_days"`
+}
+
+func (in *TrashPurgeInput) Normalize() error {
+	if in.OlderThanDays == nil {
+		d := 0
+		in.OlderThanDays = &d
+	}
+	if *in.OlderThanDays < 0 || *in.OlderThanDays > 36500 {
+		return ErrInvalidPurgeAge
+	}
+	return nil
+}
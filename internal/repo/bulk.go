@@ -0,0 +1,156 @@
+// internal/repo/bulk.go
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Bulk work order operations ----------------
+
+// errBulkAborted rolls back an atomic batch after an item failed.
+var errBulkAborted = errors.New("bulk operation aborted")
+
+// isForeignKeyViolation reports whether err is a foreign_key_violation (23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// BulkWorkOrders applies op to every work order in op.IDs inside a single
+// transaction. Each item runs in its own savepoint, so a failing item is
+// rolled back on its own and reported in its result. With op.Atomic any
+// failure rolls the whole batch back and the items that had succeeded report
+// models.ErrBulkRolledBack. Items are processed in ID order to keep row locks
+// ordered between concurrent batches; results follow op.IDs.
+func (p *pgRepo) BulkWorkOrders(ctx context.Context, org_id, user_id uuid.UUID, role models.OrgRole, op models.BulkOperation) ([]models.BulkItemResult, error) {
+	slog.DebugContext(ctx, "BulkWorkOrders", "org_id", org_id.String(), "action", op.Action, "count", len(op.IDs), "atomic", op.Atomic)
+
+	order := append([]uuid.UUID(nil), op.IDs...)
+	sort.Slice(order, func(i, j int) bool { return bytes.Compare(order[i][:], order[j][:]) < 0 })
+
+	outcome := make(map[uuid.UUID]models.BulkItemResult, len(order))
+	failed := false
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		for _, id := range order {
+			res, err := bulkItem(ctx, q, org_id, user_id, role, op, id)
+			if err != nil {
+				return err
+			}
+			outcome[id] = res
+			if res.Err != nil {
+				failed = true
+			}
+		}
+		if failed && op.Atomic {
+			return errBulkAborted
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBulkAborted) {
+		slog.ErrorContext(ctx, "BulkWorkOrders failed", "err", err)
+		return nil, err
+	}
+
+	out := make([]models.BulkItemResult, 0, len(op.IDs))
+	for _, id := range op.IDs {
+		res := outcome[id]
+		if errors.Is(err, errBulkAborted) && res.Err == nil {
+			res = models.BulkItemResult{ID: id, Err: models.ErrBulkRolledBack}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+// bulkItem runs one item in a savepoint. Domain failures are returned in the
+// result; only errors that leave the transaction unusable are returned.
+func bulkItem(ctx context.Context, q *db.Queries, orgID, userID uuid.UUID, role models.OrgRole, op models.BulkOperation, id uuid.UUID) (models.BulkItemResult, error) {
+	res := models.BulkItemResult{ID: id}
+	sp, err := q.Begin(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer sp.Rollback(ctx)
+
+	res.Unchanged, res.Err = applyBulkAction(ctx, q.WithTx(sp), orgID, userID, role, op, id)
+	if res.Err != nil {
+		if err := sp.Rollback(ctx); err != nil {
+			return res, err
+		}
+		return res, nil
+	}
+	return res, sp.Commit(ctx)
+}
+
+func applyBulkAction(ctx context.Context, q *db.Queries, orgID, userID uuid.UUID, role models.OrgRole, op models.BulkOperation, id uuid.UUID) (bool, error) {
+	status, err := q.GetWorkOrderStatus(ctx, db.GetWorkOrderStatusParams{
+		WorkOrderID:    toPgUUID(id),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, models.ErrWorkOrderNotFound
+		}
+		return false, err
+	}
+	from := models.WorkOrderStatus(status)
+
+	switch op.Action {
+	case models.BulkChangeStatus:
+		if from == op.ToStatus {
+			return true, nil
+		}
+		if err := models.CheckStatusTransition(from, op.ToStatus, role); err != nil {
+			return false, err
+		}
+		_, err = q.ChangeWorkOrderStatus(ctx, db.ChangeWorkOrderStatusParams{
+			ToStatus:       string(op.ToStatus),
+			ChangedByID:    fromUUID(userID),
+			WorkOrderID:    toPgUUID(id),
+			OrganisationID: fromUUID(orgID),
+			FromStatus:     string(from),
+			Reason:         toNullableText(op.Reason),
+		})
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return false, models.ErrStatusConflict
+		case isSignatureRequired(err):
+			return false, models.ErrSignatureRequired
+		case isWorkOrderBlocked(err):
+			return false, models.ErrWorkOrderBlocked
+		case isChecklistIncomplete(err):
+			return false, models.ErrChecklistIncomplete
+		}
+	case models.BulkDelete:
+		_, err = q.SoftDeleteWorkOrder(ctx, db.SoftDeleteWorkOrderParams{
+			OrganisationID: fromUUID(orgID),
+			WorkOrderID:    toPgUUID(id),
+			DeletedByID:    fromUUID(userID),
+		})
+	default:
+		_, err = q.UpdateWorkOrderFromJSON(ctx, db.UpdateWorkOrderFromJSONParams{
+			OrganisationID: fromUUID(orgID),
+			WorkOrderID:    toPgUUID(id),
+			Payload:        op.Patch,
+			UpdatedByID:    fromUUID(userID),
+		})
+	}
+	switch {
+	case err != nil && isForeignKeyViolation(err):
+		return false, models.ErrInvalidReference
+	case err != nil && isNoDataFound(err):
+		return false, models.ErrWorkOrderNotFound
+	}
+	return false, err
+}
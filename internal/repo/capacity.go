@@ -0,0 +1,507 @@
+// internal/repo/capacity.go
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Working hours ----------------
+
+func clockFromTime(t pgtype.Time) string {
+	return models.FormatClock(time.Duration(t.Microseconds) * time.Microsecond)
+}
+
+func timeFromClock(s string) (pgtype.Time, error) {
+	d, err := models.ParseClock(s)
+	if err != nil {
+		return pgtype.Time{}, err
+	}
+	return pgtype.Time{Microseconds: d.Microseconds(), Valid: true}, nil
+}
+
+// checkOrgMembers makes sure every user in ids belongs to the organisation.
+func checkOrgMembers(ctx context.Context, q *db.Queries, orgID uuid.UUID, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	pg := toPgUUIDs(dedupeUUIDs(ids))
+	n, err := q.CountOrgMembers(ctx, db.CountOrgMembersParams{
+		OrgID:   fromUUID(orgID),
+		UserIds: pg,
+	})
+	if err != nil {
+		return err
+	}
+	if int(n) != len(pg) {
+		return models.ErrUserNotMember
+	}
+	return nil
+}
+
+func (p *pgRepo) ListWorkingHours(ctx context.Context, org_id uuid.UUID, userID *uuid.UUID) ([]models.WorkingHours, error) {
+	slog.DebugContext(ctx, "ListWorkingHours", "org_id", org_id.String())
+	rows, err := p.q.ListWorkingHours(ctx, db.ListWorkingHoursParams{
+		OrganisationID: fromUUID(org_id),
+		UserID:         toNullUUID(userID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkingHours failed", "err", err)
+		return nil, err
+	}
+	out := []models.WorkingHours{}
+	for _, r := range rows {
+		id := toUUID(r.UserID)
+		if len(out) == 0 || out[len(out)-1].UserID != id {
+			out = append(out, models.WorkingHours{
+				UserID:   id,
+				UserName: r.UserName,
+				TimeZone: r.TimeZone,
+				Days:     []models.WorkingDay{},
+			})
+		}
+		wh := &out[len(out)-1]
+		wh.Days = append(wh.Days, models.WorkingDay{
+			Weekday: int(r.Weekday),
+			Start:   clockFromTime(r.StartTime),
+			End:     clockFromTime(r.EndTime),
+		})
+	}
+	return out, nil
+}
+
+// SetWorkingHours replaces the user's week. in must be normalised.
+func (p *pgRepo) SetWorkingHours(ctx context.Context, org_id, userID uuid.UUID, in models.WorkingHoursInput) (models.WorkingHours, error) {
+	slog.DebugContext(ctx, "SetWorkingHours", "org_id", org_id.String(), "user_id", userID.String())
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkOrgMembers(ctx, q, org_id, []uuid.UUID{userID}); err != nil {
+			return err
+		}
+		ok, err := q.IsTimeZone(ctx, in.TimeZone)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrInvalidTimeZone
+		}
+		if err := q.DeleteWorkingHours(ctx, db.DeleteWorkingHoursParams{
+			OrganisationID: fromUUID(org_id),
+			UserID:         fromUUID(userID),
+		}); err != nil {
+			return err
+		}
+		for _, d := range in.Days {
+			start, err := timeFromClock(d.Start)
+			if err != nil {
+				return models.ErrInvalidWorkingHours
+			}
+			end, err := timeFromClock(d.End)
+			if err != nil {
+				return models.ErrInvalidWorkingHours
+			}
+			if err := q.InsertWorkingHours(ctx, db.InsertWorkingHoursParams{
+				OrganisationID: fromUUID(org_id),
+				UserID:         fromUUID(userID),
+				Weekday:        int16(d.Weekday),
+				StartTime:      start,
+				EndTime:        end,
+				TimeZone:       in.TimeZone,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotMember) || errors.Is(err, models.ErrInvalidTimeZone) ||
+			errors.Is(err, models.ErrInvalidWorkingHours) {
+			return models.WorkingHours{}, err
+		}
+		slog.ErrorContext(ctx, "SetWorkingHours failed", "err", err)
+		return models.WorkingHours{}, err
+	}
+	list, err := p.ListWorkingHours(ctx, org_id, &userID)
+	if err != nil {
+		return models.WorkingHours{}, err
+	}
+	if len(list) == 0 {
+		return models.WorkingHours{UserID: userID, TimeZone: in.TimeZone, Days: []models.WorkingDay{}}, nil
+	}
+	return list[0], nil
+}
+
+// ---------------- Leave ----------------
+
+func userLeaveFromRow(r db.ListUserLeaveRow) models.UserLeave {
+	return models.UserLeave{
+		ID:          toUUID(r.ID),
+		UserID:      toUUID(r.UserID),
+		UserName:    r.UserName,
+		StartsAt:    toTime(r.StartsAt),
+		EndsAt:      toTime(r.EndsAt),
+		Reason:      textOrEmpty(r.Reason),
+		CreatedByID: optUUID(r.CreatedByID),
+		CreatedAt:   toTime(r.CreatedAt),
+	}
+}
+
+func toNullTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return toTimestamptz(*t)
+}
+
+func (p *pgRepo) ListUserLeave(ctx context.Context, org_id uuid.UUID, userID *uuid.UUID, from, to *time.Time) ([]models.UserLeave, error) {
+	slog.DebugContext(ctx, "ListUserLeave", "org_id", org_id.String())
+	rows, err := p.q.ListUserLeave(ctx, db.ListUserLeaveParams{
+		OrganisationID: fromUUID(org_id),
+		UserID:         toNullUUID(userID),
+		RangeStart:     toNullTimestamptz(from),
+		RangeEnd:       toNullTimestamptz(to),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListUserLeave failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.UserLeave, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, userLeaveFromRow(r))
+	}
+	return out, nil
+}
+
+func getUserLeave(ctx context.Context, q *db.Queries, orgID, leaveID uuid.UUID) (models.UserLeave, error) {
+	row, err := q.GetUserLeave(ctx, db.GetUserLeaveParams{
+		ID:             fromUUID(leaveID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserLeave{}, models.ErrLeaveNotFound
+		}
+		return models.UserLeave{}, err
+	}
+	return userLeaveFromRow(db.ListUserLeaveRow(row)), nil
+}
+
+func (p *pgRepo) GetUserLeave(ctx context.Context, org_id, leaveID uuid.UUID) (models.UserLeave, error) {
+	slog.DebugContext(ctx, "GetUserLeave", "org_id", org_id.String(), "leave_id", leaveID.String())
+	l, err := getUserLeave(ctx, p.q, org_id, leaveID)
+	if err != nil && !errors.Is(err, models.ErrLeaveNotFound) {
+		slog.ErrorContext(ctx, "GetUserLeave failed", "err", err)
+	}
+	return l, err
+}
+
+// CreateUserLeave records leave for a member. in must be normalised.
+func (p *pgRepo) CreateUserLeave(ctx context.Context, org_id, user_id uuid.UUID, in models.UserLeaveInput) (models.UserLeave, error) {
+	slog.DebugContext(ctx, "CreateUserLeave", "org_id", org_id.String(), "user_id", in.UserID.String())
+	var out models.UserLeave
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkOrgMembers(ctx, q, org_id, []uuid.UUID{in.UserID}); err != nil {
+			return err
+		}
+		id, err := q.CreateUserLeave(ctx, db.CreateUserLeaveParams{
+			OrganisationID: fromUUID(org_id),
+			UserID:         fromUUID(in.UserID),
+			StartsAt:       toTimestamptz(in.StartsAt),
+			EndsAt:         toTimestamptz(in.EndsAt),
+			Reason:         toNullText(in.Reason),
+			CreatedByID:    fromUUID(user_id),
+		})
+		if err != nil {
+			return err
+		}
+		out, err = getUserLeave(ctx, q, org_id, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotMember) {
+			return models.UserLeave{}, err
+		}
+		slog.ErrorContext(ctx, "CreateUserLeave failed", "err", err)
+		return models.UserLeave{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteUserLeave(ctx context.Context, org_id, leaveID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteUserLeave", "org_id", org_id.String(), "leave_id", leaveID.String())
+	n, err := p.q.DeleteUserLeave(ctx, db.DeleteUserLeaveParams{
+		ID:             fromUUID(leaveID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteUserLeave failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrLeaveNotFound
+	}
+	return nil
+}
+
+// ---------------- Rescheduling ----------------
+
+type capacityBooking struct {
+	WorkOrderID uuid.UUID `json:"work_order_id"`
+	CustomID    *string   `json:"custom_id"`
+	Title       string    `json:"title"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+}
+
+type capacityLeave struct {
+	LeaveID  uuid.UUID `json:"leave_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   *string   `json:"reason"`
+}
+
+// overlapHours is the length of [aStart, aEnd) ∩ [bStart, bEnd) in hours.
+func overlapHours(aStart, aEnd, bStart, bEnd time.Time) float64 {
+	start, end := aStart, aEnd
+	if bStart.After(start) {
+		start = bStart
+	}
+	if bEnd.Before(end) {
+		end = bEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// capacityIssues turns one CheckScheduleCapacity row into conflicts and
+// warnings for the slot [start, end).
+func capacityIssues(r db.CheckScheduleCapacityRow, start, end time.Time) ([]models.CapacityIssue, error) {
+	var bookings []capacityBooking
+	if err := json.Unmarshal(r.Bookings, &bookings); err != nil {
+		return nil, err
+	}
+	var leave []capacityLeave
+	if err := json.Unmarshal(r.Leave, &leave); err != nil {
+		return nil, err
+	}
+	userID := toUUID(r.UserID)
+	issue := func(kind, msg string) models.CapacityIssue {
+		return models.CapacityIssue{Kind: kind, UserID: userID, UserName: r.UserName, Message: msg}
+	}
+
+	var out []models.CapacityIssue
+	for _, l := range leave {
+		is := issue(models.CapacityOnLeave, r.UserName+" is on leave")
+		if l.Reason != nil && *l.Reason != "" {
+			is.Message += " (" + *l.Reason + ")"
+		}
+		is.LeaveID = &l.LeaveID
+		is.StartsAt, is.EndsAt = &l.StartsAt, &l.EndsAt
+		out = append(out, is)
+	}
+	for _, b := range bookings {
+		label := b.Title
+		if b.CustomID != nil && *b.CustomID != "" {
+			label = *b.CustomID + " " + label
+		}
+		is := issue(models.CapacityDoubleBooked, r.UserName+" is already booked on "+label)
+		is.WorkOrderID = &b.WorkOrderID
+		is.StartsAt, is.EndsAt = &b.StartsAt, &b.EndsAt
+		out = append(out, is)
+	}
+
+	if !r.TimeZone.Valid {
+		return out, nil // no working hours set
+	}
+	if !r.ShiftStart.Valid || !r.ShiftEnd.Valid {
+		day := start.In(time.UTC).Weekday().String()
+		if loc, err := time.LoadLocation(r.TimeZone.String); err == nil {
+			day = start.In(loc).Weekday().String()
+		}
+		return append(out, issue(models.CapacityNotWorking, r.UserName+" does not work on "+day)), nil
+	}
+	shiftStart, shiftEnd := r.ShiftStart.Time, r.ShiftEnd.Time
+	if start.Before(shiftStart) || end.After(shiftEnd) {
+		is := issue(models.CapacityOutsideHours, r.UserName+" is not working for all of this slot")
+		is.StartsAt, is.EndsAt = &shiftStart, &shiftEnd
+		out = append(out, is)
+	}
+	shiftHours := shiftEnd.Sub(shiftStart).Hours()
+	booked := r.ShiftBookedHours + overlapHours(start, end, shiftStart, shiftEnd)
+	if booked > shiftHours+1e-6 {
+		is := issue(models.CapacityOverbooked,
+			fmt.Sprintf("%s would be booked for %.1f of %.1f working hours that day", r.UserName, booked, shiftHours))
+		is.StartsAt, is.EndsAt = &shiftStart, &shiftEnd
+		out = append(out, is)
+	}
+	return out, nil
+}
+
+// RescheduleWorkOrder moves a work order to in.Start, optionally changing
+// its duration and people, after checking everyone's capacity. Conflicts
+// (leave, double-bookings) return models.ErrScheduleConflict together with
+// the result unless in.Force is set; warnings never block. Reschedules
+// touching the same people are serialised so two dispatchers cannot book
+// the same slot at once. Dry runs report without writing.
+func (p *pgRepo) RescheduleWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.RescheduleInput, ifMatch *int64) (models.RescheduleResult, error) {
+	slog.DebugContext(ctx, "RescheduleWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	res := models.RescheduleResult{
+		WorkOrderID: workOrderID,
+		Conflicts:   []models.CapacityIssue{},
+		Warnings:    []models.CapacityIssue{},
+	}
+	var refused bool
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		cur, err := q.GetWorkOrderSchedule(ctx, db.GetWorkOrderScheduleParams{
+			ID:             fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		if ifMatch != nil && cur.Version != *ifMatch {
+			return models.ErrVersionMismatch
+		}
+		if cur.Archived || cur.Status == string(models.StatusComplete) || cur.Status == string(models.StatusCancelled) {
+			return models.ErrRescheduleClosed
+		}
+
+		res.DurationHours = cur.EstimatedDuration
+		if in.DurationHours != nil {
+			res.DurationHours = *in.DurationHours
+		}
+		effective := res.DurationHours
+		if effective <= 0 {
+			effective = 1 // same default as the schedule view
+		}
+		res.Start = in.Start.UTC()
+		res.End = res.Start.Add(time.Duration(effective * float64(time.Hour)))
+		res.PrimaryUserID = optUUID(cur.PrimaryUserID)
+		if in.PrimaryUserID != nil {
+			res.PrimaryUserID = in.PrimaryUserID
+		}
+		res.AssigneeIDs = make([]uuid.UUID, 0, len(cur.AssigneeIds))
+		for _, id := range cur.AssigneeIds {
+			res.AssigneeIDs = append(res.AssigneeIDs, toUUID(id))
+		}
+		if in.AssigneeIDs != nil {
+			res.AssigneeIDs = dedupeUUIDs(*in.AssigneeIDs)
+		}
+
+		people := append([]uuid.UUID{}, res.AssigneeIDs...)
+		if res.PrimaryUserID != nil {
+			people = append(people, *res.PrimaryUserID)
+		}
+		people = dedupeUUIDs(people)
+		if err := checkOrgMembers(ctx, q, org_id, people); err != nil {
+			return err
+		}
+		if len(people) > 0 {
+			if err := q.LockScheduleUsers(ctx, toPgUUIDs(people)); err != nil {
+				return err
+			}
+			rows, err := q.CheckScheduleCapacity(ctx, db.CheckScheduleCapacityParams{
+				OrganisationID: fromUUID(org_id),
+				UserIds:        toPgUUIDs(people),
+				StartsAt:       toTimestamptz(res.Start),
+				WorkOrderID:    fromUUID(workOrderID),
+				EndsAt:         toTimestamptz(res.End),
+			})
+			if err != nil {
+				return err
+			}
+			for _, r := range rows {
+				issues, err := capacityIssues(r, res.Start, res.End)
+				if err != nil {
+					return err
+				}
+				for _, is := range issues {
+					if is.Blocking() {
+						res.Conflicts = append(res.Conflicts, is)
+					} else {
+						res.Warnings = append(res.Warnings, is)
+					}
+				}
+			}
+		}
+		if len(res.Conflicts) > 0 && !in.Force {
+			refused = true
+			return nil
+		}
+		if in.DryRun {
+			return nil
+		}
+
+		patch := map[string]any{
+			"estimated_start_date": res.Start.Format(time.RFC3339Nano),
+			"estimated_duration":   res.DurationHours,
+		}
+		if in.PrimaryUserID != nil {
+			patch["primary_user"] = in.PrimaryUserID.String()
+		}
+		if in.AssigneeIDs != nil {
+			patch["assigned_to"] = res.AssigneeIDs
+		}
+		payload, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		if _, err := q.UpdateWorkOrderFromJSON(ctx, db.UpdateWorkOrderFromJSONParams{
+			OrganisationID: fromUUID(org_id),
+			WorkOrderID:    toPgUUID(workOrderID),
+			UpdatedByID:    fromUUID(user_id),
+			Payload:        payload,
+		}); err != nil {
+			return err
+		}
+		res.Version, err = q.GetWorkOrderVersion(ctx, db.GetWorkOrderVersionParams{
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			return err
+		}
+		res.Applied = true
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrWorkOrderNotFound), errors.Is(err, models.ErrVersionMismatch),
+			errors.Is(err, models.ErrRescheduleClosed), errors.Is(err, models.ErrUserNotMember):
+			return res, err
+		}
+		slog.ErrorContext(ctx, "RescheduleWorkOrder failed", "err", err)
+		return res, err
+	}
+	if refused {
+		return res, models.ErrScheduleConflict
+	}
+	return res, nil
+}
+
+func dedupeUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	out := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if id != uuid.Nil && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,279 @@
+// internal/repo/categories.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order categories ----------------
+
+func categoryFromRow(c db.WorkOrderCategory) models.WorkOrderCategory {
+	out := models.WorkOrderCategory{
+		ID:            toUUID(c.ID),
+		Name:          c.Name,
+		Description:   textOrEmpty(c.Description),
+		DefaultTeamID: optUUID(c.DefaultTeamID),
+		TaskTemplate:  []models.TemplateTask{},
+		CreatedByID:   optUUID(c.CreatedByID),
+		CreatedAt:     toTime(c.CreatedAt),
+		UpdatedAt:     toTime(c.UpdatedAt),
+	}
+	if c.DefaultPriority.Valid {
+		p := c.DefaultPriority.String
+		out.DefaultPriority = &p
+	}
+	if c.DefaultEstimatedDuration.Valid {
+		d := c.DefaultEstimatedDuration.Float64
+		out.DefaultEstimatedDuration = &d
+	}
+	return out
+}
+
+func toNullFloat8(p *float64) pgtype.Float8 {
+	if p == nil {
+		return pgtype.Float8{}
+	}
+	return pgtype.Float8{Float64: *p, Valid: true}
+}
+
+func toNullUUID(p *uuid.UUID) pgtype.UUID {
+	if p == nil {
+		return pgtype.UUID{}
+	}
+	return fromUUID(*p)
+}
+
+// isUniqueViolation reports whether err is a unique_violation (23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// withTemplates loads the task templates of cats in one query.
+func withTemplates(ctx context.Context, q *db.Queries, cats []models.WorkOrderCategory) error {
+	if len(cats) == 0 {
+		return nil
+	}
+	ids := make([]pgtype.UUID, 0, len(cats))
+	byID := make(map[uuid.UUID]int, len(cats))
+	for i, c := range cats {
+		ids = append(ids, fromUUID(c.ID))
+		byID[c.ID] = i
+	}
+	rows, err := q.ListWorkOrderCategoryTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		i, ok := byID[toUUID(r.CategoryID)]
+		if !ok {
+			continue
+		}
+		cats[i].TaskTemplate = append(cats[i].TaskTemplate, models.TemplateTask{
+			TaskBaseID: toUUID(r.TaskBaseID),
+			Label:      r.Label,
+			TaskType:   r.TaskType,
+			Position:   int(r.Position),
+		})
+	}
+	return nil
+}
+
+// checkCategoryRefs verifies the default team and every template task base
+// are usable by the org.
+func checkCategoryRefs(ctx context.Context, q *db.Queries, orgID uuid.UUID, in models.WorkOrderCategoryInput) error {
+	if in.DefaultTeamID != nil {
+		ok, err := q.TeamExists(ctx, db.TeamExistsParams{
+			ID:             fromUUID(*in.DefaultTeamID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrTeamNotFound
+		}
+	}
+	if len(in.TaskTemplate) > 0 {
+		n, err := q.CountOrgTaskBases(ctx, db.CountOrgTaskBasesParams{
+			TaskBaseIds:    toPgUUIDs(in.TaskTemplate),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return err
+		}
+		if n != int64(len(in.TaskTemplate)) {
+			return models.ErrTaskBaseNotFound
+		}
+	}
+	return nil
+}
+
+func setCategoryTemplate(ctx context.Context, q *db.Queries, categoryID uuid.UUID, taskBases []uuid.UUID) error {
+	if err := q.ClearWorkOrderCategoryTasks(ctx, fromUUID(categoryID)); err != nil {
+		return err
+	}
+	if len(taskBases) == 0 {
+		return nil
+	}
+	return q.AddWorkOrderCategoryTasks(ctx, db.AddWorkOrderCategoryTasksParams{
+		CategoryID:  fromUUID(categoryID),
+		TaskBaseIds: toPgUUIDs(taskBases),
+	})
+}
+
+func (p *pgRepo) ListWorkOrderCategories(ctx context.Context, org_id uuid.UUID) ([]models.WorkOrderCategory, error) {
+	slog.DebugContext(ctx, "ListWorkOrderCategories", "org_id", org_id.String())
+	rows, err := p.q.ListWorkOrderCategories(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderCategories failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.WorkOrderCategory, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, categoryFromRow(r))
+	}
+	if err := withTemplates(ctx, p.q, out); err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderCategoryTasks failed", "err", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID) (models.WorkOrderCategory, error) {
+	slog.DebugContext(ctx, "GetWorkOrderCategory", "org_id", org_id.String(), "category_id", categoryID.String())
+	row, err := p.q.GetWorkOrderCategory(ctx, db.GetWorkOrderCategoryParams{
+		ID:             fromUUID(categoryID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WorkOrderCategory{}, models.ErrCategoryNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderCategory failed", "err", err)
+		return models.WorkOrderCategory{}, err
+	}
+	out := []models.WorkOrderCategory{categoryFromRow(row)}
+	if err := withTemplates(ctx, p.q, out); err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderCategoryTasks failed", "err", err)
+		return models.WorkOrderCategory{}, err
+	}
+	return out[0], nil
+}
+
+// CreateWorkOrderCategory stores the category and its task template in one
+// transaction. in must already be normalized.
+func (p *pgRepo) CreateWorkOrderCategory(ctx context.Context, org_id, user_id uuid.UUID, in models.WorkOrderCategoryInput) (models.WorkOrderCategory, error) {
+	slog.DebugContext(ctx, "CreateWorkOrderCategory", "org_id", org_id.String(), "user_id", user_id.String())
+	var out models.WorkOrderCategory
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkCategoryRefs(ctx, q, org_id, in); err != nil {
+			return err
+		}
+		row, err := q.CreateWorkOrderCategory(ctx, db.CreateWorkOrderCategoryParams{
+			OrganisationID:           fromUUID(org_id),
+			CreatedByID:              fromUUID(user_id),
+			Name:                     in.Name,
+			Description:              toNullableText(in.Description),
+			DefaultPriority:          toNullText(in.DefaultPriority),
+			DefaultEstimatedDuration: toNullFloat8(in.DefaultEstimatedDuration),
+			DefaultTeamID:            toNullUUID(in.DefaultTeamID),
+		})
+		if err != nil {
+			return err
+		}
+		if err := setCategoryTemplate(ctx, q, toUUID(row.ID), in.TaskTemplate); err != nil {
+			return err
+		}
+		cats := []models.WorkOrderCategory{categoryFromRow(row)}
+		if err := withTemplates(ctx, q, cats); err != nil {
+			return err
+		}
+		out = cats[0]
+		return nil
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.WorkOrderCategory{}, models.ErrCategoryNameTaken
+		case errors.Is(err, models.ErrTeamNotFound), errors.Is(err, models.ErrTaskBaseNotFound):
+			return models.WorkOrderCategory{}, err
+		}
+		slog.ErrorContext(ctx, "CreateWorkOrderCategory failed", "err", err)
+		return models.WorkOrderCategory{}, err
+	}
+	return out, nil
+}
+
+// UpdateWorkOrderCategory replaces the category's fields and task template.
+// Work orders already created keep the values they were created with.
+func (p *pgRepo) UpdateWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID, in models.WorkOrderCategoryInput) (models.WorkOrderCategory, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderCategory", "org_id", org_id.String(), "category_id", categoryID.String())
+	var out models.WorkOrderCategory
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkCategoryRefs(ctx, q, org_id, in); err != nil {
+			return err
+		}
+		row, err := q.UpdateWorkOrderCategory(ctx, db.UpdateWorkOrderCategoryParams{
+			Name:                     in.Name,
+			Description:              toNullableText(in.Description),
+			DefaultPriority:          toNullText(in.DefaultPriority),
+			DefaultEstimatedDuration: toNullFloat8(in.DefaultEstimatedDuration),
+			DefaultTeamID:            toNullUUID(in.DefaultTeamID),
+			ID:                       fromUUID(categoryID),
+			OrganisationID:           fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrCategoryNotFound
+			}
+			return err
+		}
+		if err := setCategoryTemplate(ctx, q, categoryID, in.TaskTemplate); err != nil {
+			return err
+		}
+		cats := []models.WorkOrderCategory{categoryFromRow(row)}
+		if err := withTemplates(ctx, q, cats); err != nil {
+			return err
+		}
+		out = cats[0]
+		return nil
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.WorkOrderCategory{}, models.ErrCategoryNameTaken
+		case errors.Is(err, models.ErrCategoryNotFound), errors.Is(err, models.ErrTeamNotFound), errors.Is(err, models.ErrTaskBaseNotFound):
+			return models.WorkOrderCategory{}, err
+		}
+		slog.ErrorContext(ctx, "UpdateWorkOrderCategory failed", "err", err)
+		return models.WorkOrderCategory{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderCategory", "org_id", org_id.String(), "category_id", categoryID.String())
+	n, err := p.q.DeleteWorkOrderCategory(ctx, db.DeleteWorkOrderCategoryParams{
+		ID:             fromUUID(categoryID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteWorkOrderCategory failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrCategoryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// internal/repo/checklists.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Checklist policies ----------------
+
+// isChecklistIncomplete reports whether err is the CM425 raised by
+// trg_work_order_require_checklist.
+func isChecklistIncomplete(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "CM425"
+}
+
+func checklistPolicyFromRow(r db.ListChecklistPoliciesRow) models.ChecklistPolicy {
+	return models.ChecklistPolicy{
+		ID:                   toUUID(r.ID),
+		CategoryID:           optUUID(r.CategoryID),
+		CategoryName:         textOrEmpty(r.CategoryName),
+		RequireTasksComplete: r.RequireTasksComplete,
+		RequireFailureNotes:  r.RequireFailureNotes,
+		CreatedByID:          optUUID(r.CreatedByID),
+		CreatedAt:            toTime(r.CreatedAt),
+		UpdatedAt:            toTime(r.UpdatedAt),
+	}
+}
+
+func getChecklistPolicy(ctx context.Context, q *db.Queries, orgID, policyID uuid.UUID) (models.ChecklistPolicy, error) {
+	row, err := q.GetChecklistPolicy(ctx, db.GetChecklistPolicyParams{
+		ID:             fromUUID(policyID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ChecklistPolicy{}, models.ErrChecklistPolicyNotFound
+		}
+		return models.ChecklistPolicy{}, err
+	}
+	return checklistPolicyFromRow(db.ListChecklistPoliciesRow(row)), nil
+}
+
+// checklistPolicyError maps a failed write to a model error, or nil if
+// unexpected.
+func checklistPolicyError(err error) error {
+	switch {
+	case isUniqueViolation(err):
+		return models.ErrChecklistPolicyExists
+	case errors.Is(err, models.ErrChecklistPolicyNotFound), errors.Is(err, models.ErrCategoryNotFound):
+		return err
+	}
+	return nil
+}
+
+func (p *pgRepo) ListChecklistPolicies(ctx context.Context, org_id uuid.UUID) ([]models.ChecklistPolicy, error) {
+	slog.DebugContext(ctx, "ListChecklistPolicies", "org_id", org_id.String())
+	rows, err := p.q.ListChecklistPolicies(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListChecklistPolicies failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.ChecklistPolicy, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, checklistPolicyFromRow(r))
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID) (models.ChecklistPolicy, error) {
+	slog.DebugContext(ctx, "GetChecklistPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	pol, err := getChecklistPolicy(ctx, p.q, org_id, policyID)
+	if err != nil && !errors.Is(err, models.ErrChecklistPolicyNotFound) {
+		slog.ErrorContext(ctx, "GetChecklistPolicy failed", "err", err)
+	}
+	return pol, err
+}
+
+// CreateChecklistPolicy adds a policy. in must already be normalized.
+func (p *pgRepo) CreateChecklistPolicy(ctx context.Context, org_id, user_id uuid.UUID, in models.ChecklistPolicyInput) (models.ChecklistPolicy, error) {
+	slog.DebugContext(ctx, "CreateChecklistPolicy", "org_id", org_id.String())
+	var out models.ChecklistPolicy
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkSLACategory(ctx, q, org_id, in.CategoryID); err != nil {
+			return err
+		}
+		id, err := q.CreateChecklistPolicy(ctx, db.CreateChecklistPolicyParams{
+			OrganisationID:       fromUUID(org_id),
+			CategoryID:           toNullUUID(in.CategoryID),
+			RequireTasksComplete: *in.RequireTasksComplete,
+			RequireFailureNotes:  *in.RequireFailureNotes,
+			CreatedByID:          fromUUID(user_id),
+		})
+		if err != nil {
+			return err
+		}
+		out, err = getChecklistPolicy(ctx, q, org_id, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := checklistPolicyError(err); mapped != nil {
+			return models.ChecklistPolicy{}, mapped
+		}
+		slog.ErrorContext(ctx, "CreateChecklistPolicy failed", "err", err)
+		return models.ChecklistPolicy{}, err
+	}
+	return out, nil
+}
+
+// UpdateChecklistPolicy replaces a policy. in must already be normalized.
+func (p *pgRepo) UpdateChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID, in models.ChecklistPolicyInput) (models.ChecklistPolicy, error) {
+	slog.DebugContext(ctx, "UpdateChecklistPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	var out models.ChecklistPolicy
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkSLACategory(ctx, q, org_id, in.CategoryID); err != nil {
+			return err
+		}
+		_, err := q.UpdateChecklistPolicy(ctx, db.UpdateChecklistPolicyParams{
+			CategoryID:           toNullUUID(in.CategoryID),
+			RequireTasksComplete: *in.RequireTasksComplete,
+			RequireFailureNotes:  *in.RequireFailureNotes,
+			ID:                   fromUUID(policyID),
+			OrganisationID:       fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrChecklistPolicyNotFound
+			}
+			return err
+		}
+		out, err = getChecklistPolicy(ctx, q, org_id, policyID)
+		return err
+	})
+	if err != nil {
+		if mapped := checklistPolicyError(err); mapped != nil {
+			return models.ChecklistPolicy{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateChecklistPolicy failed", "err", err)
+		return models.ChecklistPolicy{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteChecklistPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	n, err := p.q.DeleteChecklistPolicy(ctx, db.DeleteChecklistPolicyParams{
+		ID:             fromUUID(policyID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteChecklistPolicy failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrChecklistPolicyNotFound
+	}
+	return nil
+}
+
+// ListChecklistBlockers returns the tasks keeping a work order from COMPLETE
+// under the policy that applies to it, in task order. Empty when nothing
+// blocks (or no policy applies).
+func (p *pgRepo) ListChecklistBlockers(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.ChecklistBlocker, error) {
+	slog.DebugContext(ctx, "ListChecklistBlockers", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListChecklistBlockers(ctx, db.ListChecklistBlockersParams{
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListChecklistBlockers failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.ChecklistBlocker, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.ChecklistBlocker{
+			TaskID:   toUUID(r.TaskID),
+			Label:    textOrEmpty(r.Label),
+			TaskType: textOrEmpty(r.TaskType),
+			Reason:   textOrEmpty(r.Reason),
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,246 @@
+// internal/repo/comments.go
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order comments ----------------
+
+func toPgUUIDs(ids []uuid.UUID) []pgtype.UUID {
+	out := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, toPgUUID(id))
+	}
+	return out
+}
+
+func optUUID(u pgtype.UUID) *uuid.UUID {
+	if !u.Valid {
+		return nil
+	}
+	id := toUUID(u)
+	return &id
+}
+
+func commentFromRow(c db.WorkOrderComment) models.WorkOrderComment {
+	out := models.WorkOrderComment{
+		ID:          toUUID(c.ID),
+		WorkOrderID: toUUID(c.WorkOrderID),
+		ParentID:    optUUID(c.ParentID),
+		AuthorID:    optUUID(c.AuthorID),
+		Body:        c.Body,
+		Mentions:    []models.WorkOrderCommentMention{},
+		CreatedAt:   toTime(c.CreatedAt),
+		Deleted:     c.DeletedAt.Valid,
+	}
+	if c.EditedAt.Valid {
+		t := c.EditedAt.Time
+		out.EditedAt = &t
+	}
+	return out
+}
+
+// setMentions validates that every mentioned user belongs to the org and
+// replaces the comment's mention set.
+func setMentions(ctx context.Context, q *db.Queries, orgID, commentID uuid.UUID, mentions []uuid.UUID) error {
+	ids := toPgUUIDs(mentions)
+	if len(ids) > 0 {
+		n, err := q.CountOrgMembers(ctx, db.CountOrgMembersParams{OrgID: fromUUID(orgID), UserIds: ids})
+		if err != nil {
+			return err
+		}
+		if n != int64(len(uniqueUUIDs(mentions))) {
+			return models.ErrMentionNotMember
+		}
+	}
+	return q.ReplaceWorkOrderCommentMentions(ctx, db.ReplaceWorkOrderCommentMentionsParams{
+		CommentID: fromUUID(commentID),
+		UserIds:   ids,
+		OrgID:     fromUUID(orgID),
+	})
+}
+
+func uniqueUUIDs(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	set := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		set[id] = struct{}{}
+	}
+	return set
+}
+
+// CreateWorkOrderComment adds a comment (optionally a reply) with its mentions.
+// Returns models.ErrWorkOrderNotFound when the work order or parent comment
+// does not exist in the org, models.ErrMentionNotMember for foreign mentions.
+func (p *pgRepo) CreateWorkOrderComment(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, parentID *uuid.UUID, body string, mentions []uuid.UUID) (models.WorkOrderComment, error) {
+	slog.DebugContext(ctx, "CreateWorkOrderComment", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	var out models.WorkOrderComment
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		args := db.CreateWorkOrderCommentParams{
+			AuthorID:       fromUUID(user_id),
+			Body:           body,
+			WorkOrderID:    toPgUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		}
+		if parentID != nil {
+			args.ParentID = toPgUUID(*parentID)
+		}
+		row, err := q.CreateWorkOrderComment(ctx, args)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		out = commentFromRow(row)
+		return setMentions(ctx, q, org_id, out.ID, mentions)
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrWorkOrderNotFound) && !errors.Is(err, models.ErrMentionNotMember) {
+			slog.ErrorContext(ctx, "CreateWorkOrderComment failed", "err", err)
+		}
+		return models.WorkOrderComment{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID) (models.WorkOrderComment, error) {
+	slog.DebugContext(ctx, "GetWorkOrderComment", "org_id", org_id.String(), "comment_id", commentID.String())
+	row, err := p.q.GetWorkOrderComment(ctx, db.GetWorkOrderCommentParams{
+		ID:             toPgUUID(commentID),
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WorkOrderComment{}, models.ErrCommentNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderComment failed", "err", err)
+		return models.WorkOrderComment{}, err
+	}
+	return commentFromRow(row), nil
+}
+
+// UpdateWorkOrderComment rewrites the body and, when mentions is non-nil,
+// the mention set. Permission checks are the caller's job.
+func (p *pgRepo) UpdateWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID, body string, mentions []uuid.UUID) (models.WorkOrderComment, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderComment", "org_id", org_id.String(), "comment_id", commentID.String())
+	var out models.WorkOrderComment
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		row, err := q.UpdateWorkOrderCommentBody(ctx, db.UpdateWorkOrderCommentBodyParams{
+			Body:           body,
+			ID:             toPgUUID(commentID),
+			WorkOrderID:    toPgUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrCommentNotFound
+			}
+			return err
+		}
+		out = commentFromRow(row)
+		if mentions == nil {
+			return nil
+		}
+		return setMentions(ctx, q, org_id, out.ID, mentions)
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrCommentNotFound) && !errors.Is(err, models.ErrMentionNotMember) {
+			slog.ErrorContext(ctx, "UpdateWorkOrderComment failed", "err", err)
+		}
+		return models.WorkOrderComment{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderComment", "org_id", org_id.String(), "comment_id", commentID.String())
+	n, err := p.q.SoftDeleteWorkOrderComment(ctx, db.SoftDeleteWorkOrderCommentParams{
+		ID:             toPgUUID(commentID),
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteWorkOrderComment failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (p *pgRepo) ListWorkOrderComments(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.WorkOrderComment, error) {
+	slog.DebugContext(ctx, "ListWorkOrderComments", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListWorkOrderComments(ctx, db.ListWorkOrderCommentsParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderComments failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.WorkOrderComment, 0, len(rows))
+	for _, r := range rows {
+		c := models.WorkOrderComment{
+			ID:          toUUID(r.ID),
+			WorkOrderID: workOrderID,
+			ParentID:    optUUID(r.ParentID),
+			AuthorID:    optUUID(r.AuthorID),
+			AuthorName:  fromText(r.AuthorName),
+			Body:        r.Body,
+			Mentions:    []models.WorkOrderCommentMention{},
+			CreatedAt:   toTime(r.CreatedAt),
+			Deleted:     r.Deleted,
+		}
+		if r.EditedAt.Valid {
+			t := r.EditedAt.Time
+			c.EditedAt = &t
+		}
+		if len(r.Mentions) > 0 {
+			if err := json.Unmarshal(r.Mentions, &c.Mentions); err != nil {
+				slog.ErrorContext(ctx, "ListWorkOrderComments: bad mentions", "err", err)
+				return nil, err
+			}
+		}
+		out = append(out, c)
+	}
+	slog.DebugContext(ctx, "ListWorkOrderComments ok", "count", len(out))
+	return out, nil
+}
+
+func (p *pgRepo) ListWorkOrderTimeline(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.TimelineEntry, error) {
+	slog.DebugContext(ctx, "ListWorkOrderTimeline", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListWorkOrderTimeline(ctx, db.ListWorkOrderTimelineParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderTimeline failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.TimelineEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.TimelineEntry{
+			Kind:       r.Kind,
+			ID:         toUUID(r.ID),
+			OccurredAt: toTime(r.OccurredAt),
+			ActorID:    optUUID(r.ActorID),
+			ActorName:  fromText(r.ActorName),
+			Data:       json.RawMessage(r.Data),
+		})
+	}
+	slog.DebugContext(ctx, "ListWorkOrderTimeline ok", "count", len(out))
+	return out, nil
+}
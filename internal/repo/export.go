@@ -0,0 +1,85 @@
+// internal/repo/export.go
+package repo
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func optTime(t pgtype.Timestamptz) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	v := t.Time
+	return &v
+}
+
+// ExportWorkOrders streams every work order matching the ListWorkOrdersPaged
+// payload (paging keys are ignored) to fn, in the requested sort order.
+func (p *pgRepo) ExportWorkOrders(ctx context.Context, org_id uuid.UUID, arg []byte, fn func(models.WorkOrderExportRow) error) error {
+	slog.DebugContext(ctx, "ExportWorkOrders", "org_id", org_id.String())
+	params := db.ExportWorkOrdersParams{
+		OrgID:   toPgUUID(org_id),
+		Payload: arg,
+	}
+	n := 0
+	err := exportWorkOrdersEach(ctx, p.q, params, func(r db.ExportWorkOrdersRow) error {
+		n++
+		return fn(models.WorkOrderExportRow{
+			ID:             toUUID(r.ID),
+			CustomID:       textOrEmpty(r.CustomID),
+			Title:          r.Title,
+			Status:         r.Status,
+			Priority:       r.Priority,
+			DueDate:        optTime(r.DueDate),
+			CreatedAt:      toTime(r.CreatedAt),
+			CompletedOn:    optTime(r.CompletedOn),
+			PrimaryWorker:  textOrEmpty(r.PrimaryWorker),
+			Assignees:      r.Assignees,
+			Team:           textOrEmpty(r.Team),
+			Location:       textOrEmpty(r.Location),
+			Asset:          textOrEmpty(r.Asset),
+			Category:       textOrEmpty(r.Category),
+			TasksTotal:     r.TasksTotal,
+			TasksCompleted: r.TasksCompleted,
+			SLABreached:    r.SlaBreached,
+		})
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ExportWorkOrders failed", "err", err, "rows", n)
+		return err
+	}
+	slog.DebugContext(ctx, "ExportWorkOrders ok", "rows", n)
+	return nil
+}
+
+// exportWorkOrdersEach runs ExportWorkOrders and hands each row to fn as it
+// is read instead of collecting the result, so large exports are never held
+// in memory. Rows are scanned by column name, so a change to the query's
+// columns fails here instead of shifting fields. An error from fn stops the
+// iteration and is returned.
+func exportWorkOrdersEach(ctx context.Context, q *db.Queries, arg db.ExportWorkOrdersParams, fn func(db.ExportWorkOrdersRow) error) error {
+	rows, err := q.Query(ctx, db.ExportWorkOrdersSQL, arg.OrgID, arg.Payload)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := pgx.RowToStructByName[db.ExportWorkOrdersRow](rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
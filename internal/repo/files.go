@@ -0,0 +1,225 @@
+// internal/repo/files.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Files & attachments ----------------
+
+func fileFromRow(f db.File) models.File {
+	return models.File{
+		ID:             toUUID(f.ID),
+		Filename:       fromText(f.Filename),
+		ContentType:    f.ContentType,
+		SizeBytes:      f.SizeBytes,
+		SHA256:         fromText(f.Sha256),
+		UploadedByID:   optUUID(f.UploadedByID),
+		CreatedAt:      toTime(f.CreatedAt),
+		StorageKey:     fromText(f.Path),
+		StorageBackend: f.StorageBackend,
+	}
+}
+
+func filesFromRows(rows []db.File) []models.File {
+	out := make([]models.File, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, fileFromRow(r))
+	}
+	return out
+}
+
+// CreateFile records an already-stored blob. Returns
+// models.ErrStorageQuotaExceeded if it would take the org over quota; the
+// caller is responsible for removing the blob in that case.
+func (p *pgRepo) CreateFile(ctx context.Context, org_id, user_id uuid.UUID, f models.NewFile, defaultQuota int64) (models.File, error) {
+	slog.DebugContext(ctx, "CreateFile", "org_id", org_id.String(), "size", f.SizeBytes, "content_type", f.ContentType)
+	var out models.File
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := q.LockOrgStorage(ctx, fromUUID(org_id)); err != nil {
+			return err
+		}
+		row, err := q.CreateFile(ctx, db.CreateFileParams{
+			OrganisationID: fromUUID(org_id),
+			UploadedByID:   fromUUID(user_id),
+			Path:           f.StorageKey,
+			Filename:       f.Filename,
+			StorageBackend: f.StorageBackend,
+			ContentType:    f.ContentType,
+			SizeBytes:      f.SizeBytes,
+			Sha256:         f.SHA256,
+			DefaultQuota:   defaultQuota,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrStorageQuotaExceeded
+			}
+			return err
+		}
+		out = fileFromRow(row)
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, models.ErrStorageQuotaExceeded) {
+			slog.ErrorContext(ctx, "CreateFile failed", "err", err)
+		}
+		return models.File{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetFile(ctx context.Context, org_id, fileID uuid.UUID) (models.File, error) {
+	slog.DebugContext(ctx, "GetFile", "org_id", org_id.String(), "file_id", fileID.String())
+	row, err := p.q.GetFile(ctx, db.GetFileParams{ID: toPgUUID(fileID), OrganisationID: fromUUID(org_id)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.File{}, models.ErrFileNotFound
+		}
+		slog.ErrorContext(ctx, "GetFile failed", "err", err)
+		return models.File{}, err
+	}
+	return fileFromRow(row), nil
+}
+
+// DeleteFile removes the row (and with it every attachment) and returns it so
+// the caller can drop the blob.
+func (p *pgRepo) DeleteFile(ctx context.Context, org_id, fileID uuid.UUID) (models.File, error) {
+	slog.DebugContext(ctx, "DeleteFile", "org_id", org_id.String(), "file_id", fileID.String())
+	row, err := p.q.DeleteFile(ctx, db.DeleteFileParams{ID: toPgUUID(fileID), OrganisationID: fromUUID(org_id)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.File{}, models.ErrFileNotFound
+		}
+		slog.ErrorContext(ctx, "DeleteFile failed", "err", err)
+		return models.File{}, err
+	}
+	return fileFromRow(row), nil
+}
+
+func (p *pgRepo) GetStorageUsage(ctx context.Context, org_id uuid.UUID, defaultQuota int64) (models.StorageUsage, error) {
+	slog.DebugContext(ctx, "GetStorageUsage", "org_id", org_id.String())
+	usage, err := p.q.GetOrgStorageUsage(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "GetOrgStorageUsage failed", "err", err)
+		return models.StorageUsage{}, err
+	}
+	out := models.StorageUsage{UsedBytes: usage.UsedBytes, FileCount: usage.FileCount, QuotaBytes: defaultQuota}
+	quota, err := p.q.GetOrgStorageQuota(ctx, fromUUID(org_id))
+	switch {
+	case err == nil:
+		out.QuotaBytes, out.Overridden = quota, true
+	case !errors.Is(err, pgx.ErrNoRows):
+		slog.ErrorContext(ctx, "GetOrgStorageQuota failed", "err", err)
+		return models.StorageUsage{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) SetStorageQuota(ctx context.Context, org_id uuid.UUID, quotaBytes int64) error {
+	slog.DebugContext(ctx, "SetStorageQuota", "org_id", org_id.String(), "quota_bytes", quotaBytes)
+	err := p.q.SetOrgStorageQuota(ctx, db.SetOrgStorageQuotaParams{OrganisationID: fromUUID(org_id), QuotaBytes: quotaBytes})
+	if err != nil {
+		slog.ErrorContext(ctx, "SetOrgStorageQuota failed", "err", err)
+	}
+	return err
+}
+
+// AttachFileToWorkOrder links a file; re-attaching is a no-op. Both sides
+// must belong to the org, which the caller is expected to have checked.
+func (p *pgRepo) AttachFileToWorkOrder(ctx context.Context, org_id, workOrderID, fileID uuid.UUID) error {
+	slog.DebugContext(ctx, "AttachFileToWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "file_id", fileID.String())
+	_, err := p.q.AttachFileToWorkOrder(ctx, db.AttachFileToWorkOrderParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+		FileID:         toPgUUID(fileID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "AttachFileToWorkOrder failed", "err", err)
+	}
+	return err
+}
+
+func (p *pgRepo) DetachFileFromWorkOrder(ctx context.Context, org_id, workOrderID, fileID uuid.UUID) error {
+	slog.DebugContext(ctx, "DetachFileFromWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "file_id", fileID.String())
+	n, err := p.q.DetachFileFromWorkOrder(ctx, db.DetachFileFromWorkOrderParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+		FileID:         toPgUUID(fileID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DetachFileFromWorkOrder failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrFileNotFound
+	}
+	return nil
+}
+
+func (p *pgRepo) ListWorkOrderFiles(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.File, error) {
+	slog.DebugContext(ctx, "ListWorkOrderFiles", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListWorkOrderFiles(ctx, db.ListWorkOrderFilesParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderFiles failed", "err", err)
+		return nil, err
+	}
+	return filesFromRows(rows), nil
+}
+
+func (p *pgRepo) TaskExists(ctx context.Context, org_id, taskID uuid.UUID) (bool, error) {
+	return p.q.TaskExists(ctx, db.TaskExistsParams{TaskID: toPgUUID(taskID), OrganisationID: fromUUID(org_id)})
+}
+
+func (p *pgRepo) AttachFileToTask(ctx context.Context, org_id, taskID, fileID uuid.UUID) error {
+	slog.DebugContext(ctx, "AttachFileToTask", "org_id", org_id.String(), "task_id", taskID.String(), "file_id", fileID.String())
+	_, err := p.q.AttachFileToTask(ctx, db.AttachFileToTaskParams{
+		TaskID:         toPgUUID(taskID),
+		OrganisationID: fromUUID(org_id),
+		FileID:         toPgUUID(fileID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "AttachFileToTask failed", "err", err)
+	}
+	return err
+}
+
+func (p *pgRepo) DetachFileFromTask(ctx context.Context, org_id, taskID, fileID uuid.UUID) error {
+	slog.DebugContext(ctx, "DetachFileFromTask", "org_id", org_id.String(), "task_id", taskID.String(), "file_id", fileID.String())
+	n, err := p.q.DetachFileFromTask(ctx, db.DetachFileFromTaskParams{
+		TaskID:         toPgUUID(taskID),
+		OrganisationID: fromUUID(org_id),
+		FileID:         toPgUUID(fileID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DetachFileFromTask failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrFileNotFound
+	}
+	return nil
+}
+
+func (p *pgRepo) ListTaskFiles(ctx context.Context, org_id, taskID uuid.UUID) ([]models.File, error) {
+	slog.DebugContext(ctx, "ListTaskFiles", "org_id", org_id.String(), "task_id", taskID.String())
+	rows, err := p.q.ListTaskFiles(ctx, db.ListTaskFilesParams{
+		TaskID:         toPgUUID(taskID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListTaskFiles failed", "err", err)
+		return nil, err
+	}
+	return filesFromRows(rows), nil
+}
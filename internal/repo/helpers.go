@@ -7,7 +7,6 @@ import (
     "github.com/jackc/pgx/v5/pgtype"
 
     db "yourapp/internal/db/gen"
-    "yourapp/internal/models"
 )
 
 // Common pg/uuid helpers
@@ -25,6 +24,11 @@ func toNullText(p *string) pgtype.Text {
     return pgtype.Text{String: *p, Valid: true}
 }
 
+func toNullInt8(p *int64) pgtype.Int8 {
+    if p == nil { return pgtype.Int8{} }
+    return pgtype.Int8{Int64: *p, Valid: true}
+}
+
 // toNullableText returns NULL when s is empty; otherwise a valid text.
 func toNullableText(s string) pgtype.Text {
     if s == "" {
@@ -33,20 +37,6 @@ func toNullableText(s string) pgtype.Text {
     return pgtype.Text{String: s, Valid: true}
 }
 
-// Role ranking utility (for best-match selection)
-func rankRole(r string) int {
-    switch r {
-    case string(models.RoleOwner):
-        return 4
-    case string(models.RoleAdmin):
-        return 3
-    case string(models.RoleMember):
-        return 2
-    default:
-        return 1 // Viewer or unknown
-    }
-}
-
 // tiny helpers for pgtype.Text
 func textOrEmpty(t pgtype.Text) string {
     if t.Valid {
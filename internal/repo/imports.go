@@ -0,0 +1,515 @@
+// internal/repo/imports.go
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- CSV imports ----------------
+
+// importProgressEvery is how many rows are processed between progress writes.
+const importProgressEvery = 25
+
+var (
+	// errImportRejected and errImportDryRun roll the import transaction back.
+	errImportRejected = errors.New("import has row errors")
+	errImportDryRun   = errors.New("dry run")
+)
+
+func importJobFromRow(r db.GetImportJobRow) models.ImportJob {
+	j := models.ImportJob{
+		ID:            toUUID(r.ID),
+		Kind:          models.ImportKind(r.Kind),
+		DryRun:        r.DryRun,
+		Status:        r.Status,
+		Filename:      textOrEmpty(r.Filename),
+		TotalRows:     int(r.TotalRows),
+		ProcessedRows: int(r.ProcessedRows),
+		CreatedRows:   int(r.CreatedRows),
+		ErrorCount:    int(r.ErrorCount),
+		Message:       textOrEmpty(r.Message),
+		CreatedByID:   optUUID(r.CreatedByID),
+		CreatedAt:     toTime(r.CreatedAt),
+		StartedAt:     optTime(r.StartedAt),
+		FinishedAt:    optTime(r.FinishedAt),
+	}
+	if len(r.Errors) > 0 {
+		_ = json.Unmarshal(r.Errors, &j.Errors)
+	}
+	return j
+}
+
+func (p *pgRepo) GetImportJob(ctx context.Context, org_id, jobID uuid.UUID) (models.ImportJob, error) {
+	slog.DebugContext(ctx, "GetImportJob", "org_id", org_id.String(), "job_id", jobID.String())
+	r, err := p.q.GetImportJob(ctx, db.GetImportJobParams{ID: toPgUUID(jobID), OrganisationID: fromUUID(org_id)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ImportJob{}, models.ErrImportJobNotFound
+		}
+		slog.ErrorContext(ctx, "GetImportJob failed", "err", err)
+		return models.ImportJob{}, err
+	}
+	return importJobFromRow(r), nil
+}
+
+func (p *pgRepo) ListImportJobs(ctx context.Context, org_id uuid.UUID) ([]models.ImportJob, error) {
+	slog.DebugContext(ctx, "ListImportJobs", "org_id", org_id.String())
+	rows, err := p.q.ListImportJobs(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListImportJobs failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.ImportJob, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, importJobFromRow(db.GetImportJobRow{
+			ID: r.ID, Kind: r.Kind, DryRun: r.DryRun, Status: r.Status, Filename: r.Filename,
+			TotalRows: r.TotalRows, ProcessedRows: r.ProcessedRows, CreatedRows: r.CreatedRows,
+			ErrorCount: r.ErrorCount, Message: r.Message, CreatedByID: r.CreatedByID,
+			CreatedAt: r.CreatedAt, StartedAt: r.StartedAt, FinishedAt: r.FinishedAt,
+		}))
+	}
+	return out, nil
+}
+
+// FailInterruptedImportJobs marks every QUEUED or RUNNING job FAILED. Jobs
+// run in a goroutine of the server process, so at startup any such job was
+// cut off by a restart (its rows were rolled back). Call it once before
+// serving requests.
+func (p *pgRepo) FailInterruptedImportJobs(ctx context.Context) (int64, error) {
+	slog.DebugContext(ctx, "FailInterruptedImportJobs")
+	n, err := p.q.FailInterruptedImportJobs(ctx, toNullableText("interrupted by a server restart; nothing was written"))
+	if err != nil {
+		slog.ErrorContext(ctx, "FailInterruptedImportJobs failed", "err", err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// FailImportJob finishes a job that never ran as FAILED with message. It
+// uses its own short-lived context, so it works after ctx is done.
+func (p *pgRepo) FailImportJob(ctx context.Context, org_id, jobID uuid.UUID, message string) error {
+	slog.DebugContext(ctx, "FailImportJob", "org_id", org_id.String(), "job_id", jobID.String())
+	fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	err := p.q.FinishImportJob(fctx, db.FinishImportJobParams{
+		Status:  models.ImportFailed,
+		Errors:  []byte("[]"),
+		Message: toNullableText(message),
+		ID:      toPgUUID(jobID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "FailImportJob failed", "err", err, "job_id", jobID.String())
+	}
+	return err
+}
+
+// CreateImportJob records a queued job for a parsed upload. Run it with
+// RunImportJob.
+func (p *pgRepo) CreateImportJob(ctx context.Context, org_id, user_id uuid.UUID, file models.ImportFile, filename string, dryRun bool) (models.ImportJob, error) {
+	slog.DebugContext(ctx, "CreateImportJob", "org_id", org_id.String(), "kind", file.Kind, "rows", len(file.Rows), "dry_run", dryRun)
+	id, err := p.q.CreateImportJob(ctx, db.CreateImportJobParams{
+		OrganisationID: fromUUID(org_id),
+		Kind:           string(file.Kind),
+		DryRun:         dryRun,
+		Filename:       toNullableText(filename),
+		TotalRows:      int32(len(file.Rows)),
+		CreatedByID:    fromUUID(user_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "CreateImportJob failed", "err", err)
+		return models.ImportJob{}, err
+	}
+	return p.GetImportJob(ctx, org_id, toUUID(id))
+}
+
+// RunImportJob validates and writes every row of file in one transaction,
+// each row under its own savepoint so all bad rows are reported. Any row
+// error, or dryRun, rolls the whole import back. Progress is written outside
+// the transaction for pollers; the outcome is recorded on the job.
+func (p *pgRepo) RunImportJob(ctx context.Context, org_id, user_id, jobID uuid.UUID, file models.ImportFile, dryRun bool) error {
+	slog.DebugContext(ctx, "RunImportJob", "org_id", org_id.String(), "job_id", jobID.String(), "kind", file.Kind)
+	if err := p.q.StartImportJob(ctx, toPgUUID(jobID)); err != nil {
+		slog.ErrorContext(ctx, "StartImportJob failed", "err", err)
+		return err
+	}
+
+	imp := &importer{org: org_id, user: user_id, file: file, refs: map[string]importRef{}}
+	processed := 0
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		for i := range file.Rows {
+			if err := imp.row(ctx, q, i); err != nil {
+				return err
+			}
+			processed++
+			if processed%importProgressEvery == 0 {
+				if err := p.q.UpdateImportJobProgress(ctx, db.UpdateImportJobProgressParams{
+					ProcessedRows: int32(processed),
+					ErrorCount:    int32(len(imp.errs)),
+					ID:            toPgUUID(jobID),
+				}); err != nil {
+					slog.WarnContext(ctx, "UpdateImportJobProgress failed", "err", err)
+				}
+			}
+		}
+		switch {
+		case len(imp.errs) > 0:
+			return errImportRejected
+		case dryRun:
+			return errImportDryRun
+		}
+		return nil
+	})
+
+	status, message := models.ImportSucceeded, ""
+	switch {
+	case err == nil:
+		message = fmt.Sprintf("imported %d rows", imp.created)
+	case errors.Is(err, errImportDryRun):
+		message = fmt.Sprintf("dry run: %d rows are valid; nothing was written", imp.created)
+	case errors.Is(err, errImportRejected):
+		status = models.ImportFailed
+		message = fmt.Sprintf("%d rows have errors; nothing was written", imp.rowsWithErrors())
+	default:
+		slog.ErrorContext(ctx, "RunImportJob failed", "err", err, "job_id", jobID.String())
+		status, message = models.ImportFailed, "import failed; nothing was written"
+	}
+	if status == models.ImportFailed && !dryRun {
+		imp.created = 0
+	}
+
+	stored := imp.errs
+	if len(stored) > models.MaxImportErrors {
+		stored = stored[:models.MaxImportErrors]
+	}
+	if stored == nil {
+		stored = []models.ImportRowError{}
+	}
+	errsJSON, _ := json.Marshal(stored)
+	// The request context may be gone by now; always record the outcome.
+	fctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if ferr := p.q.FinishImportJob(fctx, db.FinishImportJobParams{
+		Status:        status,
+		ProcessedRows: int32(processed),
+		CreatedRows:   int32(imp.created),
+		ErrorCount:    int32(len(imp.errs)),
+		Errors:        errsJSON,
+		Message:       toNullableText(message),
+		ID:            toPgUUID(jobID),
+	}); ferr != nil {
+		slog.ErrorContext(ctx, "FinishImportJob failed", "err", ferr, "job_id", jobID.String())
+		return ferr
+	}
+	slog.DebugContext(ctx, "RunImportJob done", "job_id", jobID.String(), "status", status, "created", imp.created, "errors", len(imp.errs))
+	return nil
+}
+
+// importRef is a resolved reference, or the reason it did not resolve.
+type importRef struct {
+	id  pgtype.UUID
+	err string
+}
+
+// importer carries the state of one RunImportJob.
+type importer struct {
+	org, user uuid.UUID
+	file      models.ImportFile
+	refs      map[string]importRef
+	errs      []models.ImportRowError
+	created   int
+}
+
+func (imp *importer) fail(i int, col, msg string) {
+	imp.errs = append(imp.errs, models.ImportRowError{Row: imp.file.Lines[i], Column: col, Message: msg})
+}
+
+func (imp *importer) rowsWithErrors() int {
+	rows := map[int]bool{}
+	for _, e := range imp.errs {
+		rows[e.Row] = true
+	}
+	return len(rows)
+}
+
+// row imports data row i under a savepoint. Problems with the row are
+// recorded and swallowed; only database failures unrelated to the row's
+// content are returned.
+func (imp *importer) row(ctx context.Context, q *db.Queries, i int) error {
+	sp, err := q.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer sp.Rollback(ctx)
+	sq := q.WithTx(sp)
+
+	before := len(imp.errs)
+	switch imp.file.Kind {
+	case models.ImportLocations:
+		err = imp.location(ctx, sq, i)
+	case models.ImportAssets:
+		err = imp.asset(ctx, sq, i)
+	case models.ImportWorkOrders:
+		err = imp.workOrder(ctx, sq, i)
+	}
+	if err != nil {
+		return err
+	}
+	if len(imp.errs) > before {
+		return nil
+	}
+	imp.created++
+	return sp.Commit(ctx)
+}
+
+func (imp *importer) location(ctx context.Context, q *db.Queries, i int) error {
+	name := imp.file.Value(i, "name")
+	if name == "" {
+		imp.fail(i, "name", "name is required")
+		return nil
+	}
+	_, err := q.CreateImportedLocation(ctx, db.CreateImportedLocationParams{
+		OrganisationID: fromUUID(imp.org),
+		Name:           toText(name),
+		CustomID:       toNullableText(imp.file.Value(i, "custom_id")),
+	})
+	return imp.insertError(ctx, i, "location", err)
+}
+
+func (imp *importer) asset(ctx context.Context, q *db.Queries, i int) error {
+	name := imp.file.Value(i, "name")
+	if name == "" {
+		imp.fail(i, "name", "name is required")
+	}
+	location, err := imp.resolve(ctx, q, i, "location")
+	if err != nil || name == "" {
+		return err
+	}
+	_, err = q.CreateImportedAsset(ctx, db.CreateImportedAssetParams{
+		OrganisationID: fromUUID(imp.org),
+		Name:           toText(name),
+		CustomID:       toNullableText(imp.file.Value(i, "custom_id")),
+		LocationID:     location,
+	})
+	return imp.insertError(ctx, i, "asset", err)
+}
+
+// workOrder builds a create_work_order_from_json payload from row i, so
+// imported work orders get the same defaults (category priority, duration,
+// team and task template; generated custom IDs) as ones created by hand.
+func (imp *importer) workOrder(ctx context.Context, q *db.Queries, i int) error {
+	v := func(col string) string { return imp.file.Value(i, col) }
+	before := len(imp.errs)
+	payload := map[string]any{}
+
+	if t := v("title"); t != "" {
+		payload["title"] = t
+	} else {
+		imp.fail(i, "title", "title is required")
+	}
+	if d := v("description"); d != "" {
+		payload["description"] = d
+	}
+	if s := v("priority"); s != "" {
+		if pr, err := models.ParseWorkOrderPriority(s); err != nil {
+			imp.fail(i, "priority", "unknown priority "+s)
+		} else {
+			payload["priority"] = pr
+		}
+	}
+	for _, f := range [][2]string{{"due_date", "dueDate"}, {"estimated_start_date", "estimatedStartDate"}} {
+		col, key := f[0], f[1]
+		s := v(col)
+		if s == "" {
+			continue
+		}
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			if _, err := time.Parse(time.RFC3339, s); err != nil {
+				imp.fail(i, col, "expected YYYY-MM-DD or an RFC 3339 timestamp")
+				continue
+			}
+		}
+		payload[key] = s
+	}
+	if s := v("estimated_duration"); s != "" {
+		if n, err := strconv.ParseFloat(s, 64); err != nil || n < 0 {
+			imp.fail(i, "estimated_duration", "estimated_duration must be a number of hours >= 0")
+		} else {
+			payload["estimatedDuration"] = n
+		}
+	}
+	if s := v("required_signature"); s != "" {
+		if b, ok := parseImportBool(s); !ok {
+			imp.fail(i, "required_signature", "required_signature must be true or false")
+		} else {
+			payload["requiredSignature"] = b
+		}
+	}
+	if s := v("custom_id"); s != "" {
+		payload["custom_id"] = s
+	}
+
+	for _, col := range []string{"category", "location", "asset", "team", "primary_worker"} {
+		id, err := imp.resolve(ctx, q, i, col)
+		if err != nil {
+			return err
+		}
+		if id.Valid {
+			payload[col] = toUUID(id).String()
+		}
+	}
+	if s := v("assigned_to"); s != "" {
+		var ids []string
+		for _, email := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+			if email = strings.TrimSpace(email); email == "" {
+				continue
+			}
+			id, err := imp.lookup(ctx, q, i, "assigned_to", email)
+			if err != nil {
+				return err
+			}
+			if id.Valid {
+				ids = append(ids, toUUID(id).String())
+			}
+		}
+		payload["assigned_to"] = ids
+	}
+	if len(imp.errs) > before {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateWorkOrderFromJSON(ctx, db.CreateWorkOrderFromJSONParams{
+		OrganisationID: fromUUID(imp.org),
+		CreatedByID:    fromUUID(imp.user),
+		Payload:        body,
+	})
+	if mapped := createPayloadError(err); mapped != nil {
+		imp.fail(i, "", mapped.Error())
+		return nil
+	}
+	return imp.insertError(ctx, i, "work order", err)
+}
+
+// insertError records a failed insert of row i as a row error. Unique
+// violations are always on custom_id.
+func (imp *importer) insertError(ctx context.Context, i int, what string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case isUniqueViolation(err):
+		imp.fail(i, "custom_id", "custom ID "+imp.file.Value(i, "custom_id")+" is already in use")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	default:
+		slog.WarnContext(ctx, "import row failed", "kind", imp.file.Kind, "line", imp.file.Lines[i], "err", err)
+		imp.fail(i, "", "could not create "+what)
+	}
+	return nil
+}
+
+// resolve looks up the reference in column col of row i. An empty cell is
+// a NULL reference.
+func (imp *importer) resolve(ctx context.Context, q *db.Queries, i int, col string) (pgtype.UUID, error) {
+	ref := imp.file.Value(i, col)
+	if ref == "" {
+		return pgtype.UUID{}, nil
+	}
+	return imp.lookup(ctx, q, i, col, ref)
+}
+
+// lookup resolves ref for column col, caching the result for the job.
+// Locations and assets match by custom ID, then name; categories and teams
+// by name; people by the email of an organisation member.
+func (imp *importer) lookup(ctx context.Context, q *db.Queries, i int, col, ref string) (pgtype.UUID, error) {
+	kind := col
+	if col == "primary_worker" || col == "assigned_to" {
+		kind = "member"
+	}
+	key := kind + "\x00" + strings.ToLower(ref)
+	r, ok := imp.refs[key]
+	if !ok {
+		var ids []pgtype.UUID
+		var err error
+		org := fromUUID(imp.org)
+		switch kind {
+		case "location", "asset":
+			var rows []db.FindLocationsByRefRow
+			if kind == "location" {
+				rows, err = q.FindLocationsByRef(ctx, db.FindLocationsByRefParams{Ref: ref, OrganisationID: org})
+			} else {
+				var arows []db.FindAssetsByRefRow
+				arows, err = q.FindAssetsByRef(ctx, db.FindAssetsByRefParams{Ref: ref, OrganisationID: org})
+				for _, a := range arows {
+					rows = append(rows, db.FindLocationsByRefRow(a))
+				}
+			}
+			if len(rows) > 0 && rows[0].ByCustomID {
+				rows = rows[:1]
+			}
+			for _, row := range rows {
+				ids = append(ids, row.ID)
+			}
+		case "category":
+			ids, err = q.FindCategoriesByName(ctx, db.FindCategoriesByNameParams{OrganisationID: org, Name: ref})
+		case "team":
+			ids, err = q.FindTeamsByName(ctx, db.FindTeamsByNameParams{OrganisationID: org, Name: ref})
+		case "member":
+			var id pgtype.UUID
+			id, err = q.FindMemberByEmail(ctx, db.FindMemberByEmailParams{OrganisationID: org, Email: ref})
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = nil
+			} else if err == nil {
+				ids = append(ids, id)
+			}
+		}
+		if err != nil {
+			return pgtype.UUID{}, err
+		}
+		switch {
+		case len(ids) == 1:
+			r.id = ids[0]
+		case len(ids) == 0 && kind == "member":
+			r.err = ref + " is not a member of this organisation"
+		case len(ids) == 0:
+			r.err = "no " + kind + " matches " + ref
+		default:
+			r.err = ref + " matches more than one " + kind
+			if kind == "location" || kind == "asset" {
+				r.err += "; use its custom ID"
+			}
+		}
+		imp.refs[key] = r
+	}
+	if r.err != "" {
+		imp.fail(i, col, r.err)
+	}
+	return r.id, nil
+}
+
+func parseImportBool(s string) (bool, bool) {
+	switch strings.ToLower(s) {
+	case "yes", "y":
+		return true, true
+	case "no", "n":
+		return false, true
+	}
+	b, err := strconv.ParseBool(s)
+	return b, err == nil
+}
@@ -133,7 +133,7 @@ func (p *pgRepo) ApplyGroupRoleMappings(ctx context.Context, orgID uuid.UUID, pr
         default:
             continue
         }
-        if best == "" || rankRole(role) > rankRole(best) {
+        if best == "" || models.OrgRole(role).Rank() > models.OrgRole(best).Rank() {
             best = role
         }
     }
@@ -0,0 +1,375 @@
+// internal/repo/parts.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Parts catalogue & consumption ----------------
+
+func partFromRow(r db.ListPartsRow) models.Part {
+	return models.Part{
+		ID:          toUUID(r.ID),
+		PartNumber:  r.PartNumber,
+		Revision:    r.Revision,
+		Description: textOrEmpty(r.Description),
+		Category:    r.Category,
+		UOM:         r.Uom,
+		UnitCost:    r.UnitCost,
+		Tracking:    r.Tracking,
+		Archived:    r.Archived,
+		CreatedByID: optUUID(r.CreatedByID),
+		CreatedAt:   toTime(r.CreatedAt),
+		UpdatedAt:   toTime(r.UpdatedAt),
+	}
+}
+
+func partUsageFromRow(r db.ListWorkOrderPartsRow) models.PartUsage {
+	return models.PartUsage{
+		ID:              toUUID(r.ID),
+		WorkOrderID:     toUUID(r.WorkOrderID),
+		PartID:          toUUID(r.PartID),
+		PartNumber:      r.PartNumber,
+		Revision:        r.Revision,
+		PartDescription: textOrEmpty(r.PartDescription),
+		UOM:             r.Uom,
+		Quantity:        r.Quantity,
+		SerialOrBatch:   textOrEmpty(r.SerialOrBatch),
+		UnitCost:        r.UnitCost,
+		Cost:            models.PartCost(r.Quantity, r.UnitCost),
+		Notes:           textOrEmpty(r.Notes),
+		UsedAt:          toTime(r.UsedAt),
+		CreatedByID:     optUUID(r.CreatedByID),
+		CreatedAt:       toTime(r.CreatedAt),
+	}
+}
+
+func getPart(ctx context.Context, q *db.Queries, orgID, partID uuid.UUID) (models.Part, error) {
+	row, err := q.GetPart(ctx, db.GetPartParams{
+		ID:             fromUUID(partID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Part{}, models.ErrPartNotFound
+		}
+		return models.Part{}, err
+	}
+	return partFromRow(db.ListPartsRow(row)), nil
+}
+
+func getPartUsage(ctx context.Context, q *db.Queries, orgID, workOrderID, lineID uuid.UUID) (models.PartUsage, error) {
+	row, err := q.GetWorkOrderPart(ctx, db.GetWorkOrderPartParams{
+		ID:             fromUUID(lineID),
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PartUsage{}, models.ErrPartUsageNotFound
+		}
+		return models.PartUsage{}, err
+	}
+	return partUsageFromRow(db.ListWorkOrderPartsRow(row)), nil
+}
+
+// partUsageError passes model errors through; anything else is unexpected.
+func partUsageError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrWorkOrderNotFound), errors.Is(err, models.ErrPartNotFound),
+		errors.Is(err, models.ErrPartUsageNotFound), errors.Is(err, models.ErrPartArchived),
+		errors.Is(err, models.ErrSerialRequired), errors.Is(err, models.ErrSerialQuantity),
+		errors.Is(err, models.ErrBatchRequired):
+		return err
+	}
+	return nil
+}
+
+func (p *pgRepo) ListParts(ctx context.Context, org_id uuid.UUID, search string, includeArchived bool) ([]models.Part, error) {
+	slog.DebugContext(ctx, "ListParts", "org_id", org_id.String())
+	rows, err := p.q.ListParts(ctx, db.ListPartsParams{
+		OrganisationID:  fromUUID(org_id),
+		IncludeArchived: includeArchived,
+		Search:          toNullableText(search),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListParts failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.Part, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, partFromRow(r))
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetPart(ctx context.Context, org_id, partID uuid.UUID) (models.Part, error) {
+	slog.DebugContext(ctx, "GetPart", "org_id", org_id.String(), "part_id", partID.String())
+	part, err := getPart(ctx, p.q, org_id, partID)
+	if err != nil && !errors.Is(err, models.ErrPartNotFound) {
+		slog.ErrorContext(ctx, "GetPart failed", "err", err)
+	}
+	return part, err
+}
+
+// CreatePart adds a catalogue entry. in must already be normalized.
+func (p *pgRepo) CreatePart(ctx context.Context, org_id, user_id uuid.UUID, in models.PartInput) (models.Part, error) {
+	slog.DebugContext(ctx, "CreatePart", "org_id", org_id.String(), "part_number", in.PartNumber)
+	var out models.Part
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		id, err := q.CreatePart(ctx, db.CreatePartParams{
+			OrganisationID: fromUUID(org_id),
+			PartNumber:     in.PartNumber,
+			Revision:       in.Revision,
+			Description:    toNullableText(in.Description),
+			Category:       in.Category,
+			Uom:            in.UOM,
+			UnitCost:       in.UnitCost,
+			Tracking:       in.Tracking,
+			Archived:       in.Archived,
+			CreatedByID:    fromUUID(user_id),
+		})
+		if err != nil {
+			return err
+		}
+		out, err = getPart(ctx, q, org_id, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return models.Part{}, models.ErrPartNumberTaken
+		}
+		slog.ErrorContext(ctx, "CreatePart failed", "err", err)
+		return models.Part{}, err
+	}
+	return out, nil
+}
+
+// UpdatePart replaces a catalogue entry. Past consumption lines keep their
+// unit cost.
+func (p *pgRepo) UpdatePart(ctx context.Context, org_id, partID uuid.UUID, in models.PartInput) (models.Part, error) {
+	slog.DebugContext(ctx, "UpdatePart", "org_id", org_id.String(), "part_id", partID.String())
+	var out models.Part
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		_, err := q.UpdatePart(ctx, db.UpdatePartParams{
+			PartNumber:     in.PartNumber,
+			Revision:       in.Revision,
+			Description:    toNullableText(in.Description),
+			Category:       in.Category,
+			Uom:            in.UOM,
+			UnitCost:       in.UnitCost,
+			Tracking:       in.Tracking,
+			Archived:       in.Archived,
+			ID:             fromUUID(partID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrPartNotFound
+			}
+			return err
+		}
+		out, err = getPart(ctx, q, org_id, partID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.Part{}, models.ErrPartNumberTaken
+		case errors.Is(err, models.ErrPartNotFound):
+			return models.Part{}, err
+		}
+		slog.ErrorContext(ctx, "UpdatePart failed", "err", err)
+		return models.Part{}, err
+	}
+	return out, nil
+}
+
+// DeletePart removes a catalogue entry that was never used. Returns
+// models.ErrPartInUse otherwise.
+func (p *pgRepo) DeletePart(ctx context.Context, org_id, partID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeletePart", "org_id", org_id.String(), "part_id", partID.String())
+	n, err := p.q.DeletePart(ctx, db.DeletePartParams{
+		ID:             fromUUID(partID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrPartInUse
+		}
+		slog.ErrorContext(ctx, "DeletePart failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrPartNotFound
+	}
+	return nil
+}
+
+func (p *pgRepo) ListWorkOrderParts(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.PartUsage, error) {
+	slog.DebugContext(ctx, "ListWorkOrderParts", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListWorkOrderParts(ctx, db.ListWorkOrderPartsParams{
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderParts failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.PartUsage, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, partUsageFromRow(r))
+	}
+	return out, nil
+}
+
+// AddWorkOrderPart records a part line after checking it against the part's
+// tracking mode. in must already be normalized.
+func (p *pgRepo) AddWorkOrderPart(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.PartUsageInput) (models.PartUsage, error) {
+	slog.DebugContext(ctx, "AddWorkOrderPart", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "part_id", in.PartID.String())
+	var out models.PartUsage
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		part, err := getPart(ctx, q, org_id, in.PartID)
+		if err != nil {
+			return err
+		}
+		if err := in.CheckTracking(part); err != nil {
+			return err
+		}
+		id, err := q.AddWorkOrderPart(ctx, db.AddWorkOrderPartParams{
+			PartID:         fromUUID(in.PartID),
+			Quantity:       in.Quantity,
+			SerialOrBatch:  toNullableText(in.SerialOrBatch),
+			UnitCost:       *in.UnitCost,
+			Notes:          toNullableText(in.Notes),
+			UsedAt:         toTimestamptz(*in.UsedAt),
+			CreatedByID:    fromUUID(user_id),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		out, err = getPartUsage(ctx, q, org_id, workOrderID, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := partUsageError(err); mapped != nil {
+			return models.PartUsage{}, mapped
+		}
+		slog.ErrorContext(ctx, "AddWorkOrderPart failed", "err", err)
+		return models.PartUsage{}, err
+	}
+	return out, nil
+}
+
+// UpdateWorkOrderPart replaces a part line. A line may keep pointing at a
+// part archived since it was recorded.
+func (p *pgRepo) UpdateWorkOrderPart(ctx context.Context, org_id, workOrderID, lineID uuid.UUID, in models.PartUsageInput) (models.PartUsage, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderPart", "org_id", org_id.String(), "line_id", lineID.String())
+	var out models.PartUsage
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		existing, err := getPartUsage(ctx, q, org_id, workOrderID, lineID)
+		if err != nil {
+			return err
+		}
+		part, err := getPart(ctx, q, org_id, in.PartID)
+		if err != nil {
+			return err
+		}
+		if part.ID == existing.PartID {
+			part.Archived = false
+		}
+		if err := in.CheckTracking(part); err != nil {
+			return err
+		}
+		if _, err := q.UpdateWorkOrderPart(ctx, db.UpdateWorkOrderPartParams{
+			PartID:         fromUUID(in.PartID),
+			Quantity:       in.Quantity,
+			SerialOrBatch:  toNullableText(in.SerialOrBatch),
+			UnitCost:       *in.UnitCost,
+			Notes:          toNullableText(in.Notes),
+			UsedAt:         toTimestamptz(*in.UsedAt),
+			ID:             fromUUID(lineID),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrPartUsageNotFound
+			}
+			return err
+		}
+		out, err = getPartUsage(ctx, q, org_id, workOrderID, lineID)
+		return err
+	})
+	if err != nil {
+		if mapped := partUsageError(err); mapped != nil {
+			return models.PartUsage{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateWorkOrderPart failed", "err", err)
+		return models.PartUsage{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteWorkOrderPart(ctx context.Context, org_id, workOrderID, lineID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderPart", "org_id", org_id.String(), "line_id", lineID.String())
+	n, err := p.q.DeleteWorkOrderPart(ctx, db.DeleteWorkOrderPartParams{
+		ID:             fromUUID(lineID),
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteWorkOrderPart failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrPartUsageNotFound
+	}
+	return nil
+}
+
+// ListPartConsumption reports parts used per month, asset and part in
+// [from, to). assetID narrows it to one asset.
+func (p *pgRepo) ListPartConsumption(ctx context.Context, org_id uuid.UUID, from, to time.Time, assetID *uuid.UUID) ([]models.PartConsumption, error) {
+	slog.DebugContext(ctx, "ListPartConsumption", "org_id", org_id.String(), "from", from, "to", to)
+	rows, err := p.q.ListPartConsumption(ctx, db.ListPartConsumptionParams{
+		OrganisationID: fromUUID(org_id),
+		PeriodStart:    toTimestamptz(from),
+		PeriodEnd:      toTimestamptz(to),
+		AssetID:        toNullUUID(assetID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListPartConsumption failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.PartConsumption, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.PartConsumption{
+			Month:      r.Month,
+			AssetID:    optUUID(r.AssetID),
+			AssetName:  fromText(r.AssetName),
+			PartID:     toUUID(r.PartID),
+			PartNumber: r.PartNumber,
+			Revision:   r.Revision,
+			Category:   r.Category,
+			UOM:        r.Uom,
+			Quantity:   r.Quantity,
+			Cost:       models.RoundCents(r.Cost),
+			WorkOrders: r.WorkOrders,
+		})
+	}
+	return out, nil
+}
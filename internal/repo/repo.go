@@ -50,18 +50,155 @@ type Repo interface {
     UpdateUserProfile(ctx context.Context, userID uuid.UUID, name *string, avatarURL *string, phone *string, country *string) error
 
 	ListWorkOrdersPaged(ctx context.Context, org_id uuid.UUID, arg []byte) ([]models.WorkOrder, int64, error)
-	GetWorkOrderDetail(ctx context.Context, id uuid.UUID) (json.RawMessage, error)
-	ChangeWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, status string) error
+	ExportWorkOrders(ctx context.Context, org_id uuid.UUID, arg []byte, fn func(models.WorkOrderExportRow) error) error
+	GetWorkOrderIDFormat(ctx context.Context, org_id uuid.UUID) (models.WorkOrderIDFormat, error)
+	SetWorkOrderIDFormat(ctx context.Context, org_id, user_id uuid.UUID, in models.WorkOrderIDFormatInput) (models.WorkOrderIDFormat, error)
+	GetWorkOrderDetail(ctx context.Context, org_id uuid.UUID, id uuid.UUID) (json.RawMessage, error)
+	GetWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (models.WorkOrderStatus, error)
+	GetWorkOrderVersion(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (int64, error)
+	ChangeWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, from, to models.WorkOrderStatus, reason string, ifMatch *int64) error
+	GetWorkOrderCompletionState(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (models.WorkOrderCompletionState, error)
+	CompleteWorkOrder(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, from models.WorkOrderStatus, signatureID *uuid.UUID, reason string, ifMatch *int64) error
+	ListWorkOrderStatusHistory(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) ([]models.WorkOrderStatusChange, error)
 	CreateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, payload []byte) (uuid.UUID, error)
-	UpdateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, payload []byte) (uuid.UUID, error)
-	DeleteWorkOrderByID(ctx context.Context, org_id, workOrderID uuid.UUID) error
+	UpdateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, payload []byte, ifMatch *int64) (uuid.UUID, error)
+	DeleteWorkOrderByID(ctx context.Context, org_id, workOrderID, user_id uuid.UUID) error
+	DuplicateWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, overrides []byte) (uuid.UUID, error)
+	BulkWorkOrders(ctx context.Context, org_id, user_id uuid.UUID, role models.OrgRole, op models.BulkOperation) ([]models.BulkItemResult, error)
+
+	// Work order trash
+	ListTrashedWorkOrders(ctx context.Context, org_id uuid.UUID, search string) ([]models.TrashedWorkOrder, error)
+	RestoreWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID) (int64, error)
+	PurgeTrashedWorkOrder(ctx context.Context, org_id, workOrderID uuid.UUID) error
+	PurgeTrashedWorkOrders(ctx context.Context, orgID *uuid.UUID, deletedBefore time.Time) (int64, error)
+
+	// Work order hierarchy & dependencies
+	SetWorkOrderParent(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, parentID *uuid.UUID, ifMatch *int64) (int64, error)
+	ListWorkOrderDependencies(ctx context.Context, org_id, workOrderID uuid.UUID) (models.WorkOrderDependencies, error)
+	AddWorkOrderDependency(ctx context.Context, org_id, workOrderID, blockedByID, user_id uuid.UUID) error
+	RemoveWorkOrderDependency(ctx context.Context, org_id, workOrderID, blockedByID uuid.UUID) error
+
+	// Work order comments & activity
+	CreateWorkOrderComment(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, parentID *uuid.UUID, body string, mentions []uuid.UUID) (models.WorkOrderComment, error)
+	GetWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID) (models.WorkOrderComment, error)
+	UpdateWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID, body string, mentions []uuid.UUID) (models.WorkOrderComment, error)
+	DeleteWorkOrderComment(ctx context.Context, org_id, workOrderID, commentID uuid.UUID) error
+	ListWorkOrderComments(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.WorkOrderComment, error)
+	ListWorkOrderTimeline(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.TimelineEntry, error)
+
+	// Work order time tracking
+	ListWorkOrderTimeEntries(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.TimeEntry, error)
+	GetWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID uuid.UUID) (models.TimeEntry, error)
+	StartWorkOrderTimer(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.TimerInput) (models.TimeEntry, error)
+	StopWorkOrderTimer(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, notes *string) (models.TimeEntry, error)
+	CreateWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, user_id, createdBy uuid.UUID, in models.TimeEntryInput) (models.TimeEntry, error)
+	UpdateWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID, user_id uuid.UUID, in models.TimeEntryInput) (models.TimeEntry, error)
+	DeleteWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID uuid.UUID) error
+
+	// Parts catalogue & consumption
+	ListParts(ctx context.Context, org_id uuid.UUID, search string, includeArchived bool) ([]models.Part, error)
+	GetPart(ctx context.Context, org_id, partID uuid.UUID) (models.Part, error)
+	CreatePart(ctx context.Context, org_id, user_id uuid.UUID, in models.PartInput) (models.Part, error)
+	UpdatePart(ctx context.Context, org_id, partID uuid.UUID, in models.PartInput) (models.Part, error)
+	DeletePart(ctx context.Context, org_id, partID uuid.UUID) error
+	ListWorkOrderParts(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.PartUsage, error)
+	AddWorkOrderPart(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.PartUsageInput) (models.PartUsage, error)
+	UpdateWorkOrderPart(ctx context.Context, org_id, workOrderID, lineID uuid.UUID, in models.PartUsageInput) (models.PartUsage, error)
+	DeleteWorkOrderPart(ctx context.Context, org_id, workOrderID, lineID uuid.UUID) error
+	ListPartConsumption(ctx context.Context, org_id uuid.UUID, from, to time.Time, assetID *uuid.UUID) ([]models.PartConsumption, error)
+
+	// CSV imports
+	CreateImportJob(ctx context.Context, org_id, user_id uuid.UUID, file models.ImportFile, filename string, dryRun bool) (models.ImportJob, error)
+	RunImportJob(ctx context.Context, org_id, user_id, jobID uuid.UUID, file models.ImportFile, dryRun bool) error
+	GetImportJob(ctx context.Context, org_id, jobID uuid.UUID) (models.ImportJob, error)
+	ListImportJobs(ctx context.Context, org_id uuid.UUID) ([]models.ImportJob, error)
+	FailImportJob(ctx context.Context, org_id, jobID uuid.UUID, message string) error
+	FailInterruptedImportJobs(ctx context.Context) (int64, error)
+
+	// Schedule and calendar feeds
+	ListScheduleEvents(ctx context.Context, org_id uuid.UUID, f models.ScheduleFilter) ([]models.ScheduleEvent, error)
+	GetCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID) (models.CalendarFeed, error)
+	SetCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID, tokenHash string) error
+	DeleteCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID) error
+	ResolveCalendarFeed(ctx context.Context, tokenHash string) (org_id, user_id uuid.UUID, err error)
+
+	// Technician capacity and rescheduling
+	ListWorkingHours(ctx context.Context, org_id uuid.UUID, userID *uuid.UUID) ([]models.WorkingHours, error)
+	SetWorkingHours(ctx context.Context, org_id, userID uuid.UUID, in models.WorkingHoursInput) (models.WorkingHours, error)
+	ListUserLeave(ctx context.Context, org_id uuid.UUID, userID *uuid.UUID, from, to *time.Time) ([]models.UserLeave, error)
+	GetUserLeave(ctx context.Context, org_id, leaveID uuid.UUID) (models.UserLeave, error)
+	CreateUserLeave(ctx context.Context, org_id, user_id uuid.UUID, in models.UserLeaveInput) (models.UserLeave, error)
+	DeleteUserLeave(ctx context.Context, org_id, leaveID uuid.UUID) error
+	RescheduleWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.RescheduleInput, ifMatch *int64) (models.RescheduleResult, error)
+
+	// SLA policies
+	ListSLAPolicies(ctx context.Context, org_id uuid.UUID) ([]models.SLAPolicy, error)
+	GetSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID) (models.SLAPolicy, error)
+	CreateSLAPolicy(ctx context.Context, org_id, user_id uuid.UUID, in models.SLAPolicyInput) (models.SLAPolicy, error)
+	UpdateSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID, in models.SLAPolicyInput) (models.SLAPolicy, error)
+	DeleteSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID) error
+
+	// Work order categories
+	ListWorkOrderCategories(ctx context.Context, org_id uuid.UUID) ([]models.WorkOrderCategory, error)
+	GetWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID) (models.WorkOrderCategory, error)
+	CreateWorkOrderCategory(ctx context.Context, org_id, user_id uuid.UUID, in models.WorkOrderCategoryInput) (models.WorkOrderCategory, error)
+	UpdateWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID, in models.WorkOrderCategoryInput) (models.WorkOrderCategory, error)
+	DeleteWorkOrderCategory(ctx context.Context, org_id, categoryID uuid.UUID) error
+
+	// Work order templates
+	ListWorkOrderTemplates(ctx context.Context, org_id uuid.UUID) ([]models.WorkOrderTemplate, error)
+	GetWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID) (models.WorkOrderTemplate, error)
+	CreateWorkOrderTemplate(ctx context.Context, org_id, user_id uuid.UUID, in models.WorkOrderTemplateInput) (models.WorkOrderTemplate, error)
+	UpdateWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID, in models.WorkOrderTemplateInput) (models.WorkOrderTemplate, error)
+	DeleteWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID) error
+	InstantiateWorkOrderTemplate(ctx context.Context, org_id, templateID, user_id uuid.UUID, overrides []byte) (uuid.UUID, error)
+
+	// Files & attachments
+	CreateFile(ctx context.Context, org_id, user_id uuid.UUID, f models.NewFile, defaultQuota int64) (models.File, error)
+	GetFile(ctx context.Context, org_id, fileID uuid.UUID) (models.File, error)
+	DeleteFile(ctx context.Context, org_id, fileID uuid.UUID) (models.File, error)
+	GetStorageUsage(ctx context.Context, org_id uuid.UUID, defaultQuota int64) (models.StorageUsage, error)
+	SetStorageQuota(ctx context.Context, org_id uuid.UUID, quotaBytes int64) error
+	AttachFileToWorkOrder(ctx context.Context, org_id, workOrderID, fileID uuid.UUID) error
+	DetachFileFromWorkOrder(ctx context.Context, org_id, workOrderID, fileID uuid.UUID) error
+	ListWorkOrderFiles(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.File, error)
+	TaskExists(ctx context.Context, org_id, taskID uuid.UUID) (bool, error)
+	AttachFileToTask(ctx context.Context, org_id, taskID, fileID uuid.UUID) error
+	DetachFileFromTask(ctx context.Context, org_id, taskID, fileID uuid.UUID) error
+	ListTaskFiles(ctx context.Context, org_id, taskID uuid.UUID) ([]models.File, error)
 
     // Tasks
 	GetTasksByWorkOrderID(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) ([]db.GetTasksByWorkOrderIDRow, error)
 	ListSimpleTasksByWorkOrderID(ctx context.Context, org_id, workOrderID uuid.UUID) ([]db.ListSimpleTasksByWorkOrderRow, error)
-	MarkTaskComplete(ctx context.Context, org_id uuid.UUID, taskID uuid.UUID) (db.MarkTaskCompleteRow, error)
+	MarkTaskComplete(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, taskID uuid.UUID) (db.MarkTaskCompleteRow, error)
 	DeleteTaskByID(ctx context.Context, org_id, taskID uuid.UUID) error
-    ToggleTaskComplete(ctx context.Context, org_id uuid.UUID, taskID uuid.UUID, complete bool) (db.ToggleTaskCompletionRow, error)
+    ToggleTaskComplete(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, taskID uuid.UUID, complete bool) (db.ToggleTaskCompletionRow, error)
+	GetTask(ctx context.Context, org_id, taskID uuid.UUID) (models.Task, error)
+	CreateTask(ctx context.Context, org_id, user_id uuid.UUID, in models.TaskInput) (models.Task, error)
+	UpdateTask(ctx context.Context, org_id, user_id, taskID uuid.UUID, in models.TaskUpdate) (models.Task, error)
+	AssignTask(ctx context.Context, org_id, user_id, taskID uuid.UUID, in models.TaskAssignment) (models.Task, error)
+	ReorderTasks(ctx context.Context, org_id, workOrderID uuid.UUID, taskIDs []uuid.UUID) error
+
+	// Task library
+	ListTaskBases(ctx context.Context, org_id uuid.UUID, search, taskType string, includeArchived bool) ([]models.TaskBase, error)
+	GetTaskBase(ctx context.Context, org_id, baseID uuid.UUID) (models.TaskBase, error)
+	CreateTaskBase(ctx context.Context, org_id, user_id uuid.UUID, in models.TaskBaseInput) (models.TaskBase, error)
+	UpdateTaskBase(ctx context.Context, org_id, user_id, baseID uuid.UUID, in models.TaskBaseInput) (models.TaskBase, error)
+	DeleteTaskBase(ctx context.Context, org_id, baseID uuid.UUID) error
+
+	// Task finding rules
+	ListTaskFindingRules(ctx context.Context, org_id uuid.UUID) ([]models.TaskFindingRule, error)
+	GetTaskFindingRule(ctx context.Context, org_id, baseID uuid.UUID) (models.TaskFindingRule, error)
+	SetTaskFindingRule(ctx context.Context, org_id, user_id, baseID uuid.UUID, in models.TaskFindingRuleInput) (models.TaskFindingRule, error)
+	DeleteTaskFindingRule(ctx context.Context, org_id, baseID uuid.UUID) error
+
+	// Checklist policies
+	ListChecklistPolicies(ctx context.Context, org_id uuid.UUID) ([]models.ChecklistPolicy, error)
+	GetChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID) (models.ChecklistPolicy, error)
+	CreateChecklistPolicy(ctx context.Context, org_id, user_id uuid.UUID, in models.ChecklistPolicyInput) (models.ChecklistPolicy, error)
+	UpdateChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID, in models.ChecklistPolicyInput) (models.ChecklistPolicy, error)
+	DeleteChecklistPolicy(ctx context.Context, org_id, policyID uuid.UUID) error
+	ListChecklistBlockers(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.ChecklistBlocker, error)
 
     // Login events
     RecordLoginSuccess(ctx context.Context, username string, ip netip.Addr) error
@@ -80,3 +217,17 @@ type Repo interface {
 type pgRepo struct{ q *db.Queries }
 
 func New(q *db.Queries) Repo { return &pgRepo{q: q} }
+
+// inTx runs fn against a transaction-bound Queries, committing if fn returns
+// nil and rolling back otherwise.
+func (p *pgRepo) inTx(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := p.q.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := fn(p.q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,145 @@
+// internal/repo/schedule.go
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Schedule ----------------
+
+func scheduleRef(id pgtype.UUID, name pgtype.Text) *models.ScheduleRef {
+	if !id.Valid {
+		return nil
+	}
+	return &models.ScheduleRef{ID: toUUID(id), Name: textOrEmpty(name)}
+}
+
+func scheduleEventFromRow(r db.ListScheduleEventsRow) (models.ScheduleEvent, error) {
+	ev := models.ScheduleEvent{
+		WorkOrderID: toUUID(r.ID),
+		CustomID:    textOrEmpty(r.CustomID),
+		Title:       r.Title,
+		Status:      r.Status,
+		Priority:    r.Priority,
+		Start:       optTime(r.StartsAt),
+		End:         optTime(r.EndsAt),
+		AllDay:      !r.StartsAt.Valid,
+		DueDate:     optTime(r.DueDate),
+		Team:        scheduleRef(r.TeamID, r.TeamName),
+		Location:    scheduleRef(r.LocationID, r.LocationName),
+		Assignees:   []models.ScheduleRef{},
+		Conflicts:   []models.ScheduleConflict{},
+	}
+	if err := json.Unmarshal(r.People, &ev.Assignees); err != nil {
+		return models.ScheduleEvent{}, err
+	}
+	if err := json.Unmarshal(r.Conflicts, &ev.Conflicts); err != nil {
+		return models.ScheduleEvent{}, err
+	}
+	ev.Conflict = len(ev.Conflicts) > 0
+	return ev, nil
+}
+
+func (p *pgRepo) ListScheduleEvents(ctx context.Context, org_id uuid.UUID, f models.ScheduleFilter) ([]models.ScheduleEvent, error) {
+	slog.DebugContext(ctx, "ListScheduleEvents", "org_id", org_id.String(), "from", f.From, "to", f.To)
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	rows, err := p.q.ListScheduleEvents(ctx, db.ListScheduleEventsParams{
+		OrganisationID: fromUUID(org_id),
+		RangeStart:     toTimestamptz(f.From),
+		RangeEnd:       toTimestamptz(f.To),
+		UserID:         toNullUUID(f.UserID),
+		TeamID:         toNullUUID(f.TeamID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListScheduleEvents failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.ScheduleEvent, 0, len(rows))
+	for _, r := range rows {
+		ev, err := scheduleEventFromRow(r)
+		if err != nil {
+			slog.ErrorContext(ctx, "ListScheduleEvents decode failed", "work_order_id", toUUID(r.ID).String(), "err", err)
+			return nil, err
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// ---------------- Calendar feeds ----------------
+
+func (p *pgRepo) GetCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID) (models.CalendarFeed, error) {
+	slog.DebugContext(ctx, "GetCalendarFeed", "org_id", org_id.String(), "user_id", user_id.String())
+	row, err := p.q.GetCalendarFeed(ctx, db.GetCalendarFeedParams{
+		OrganisationID: fromUUID(org_id),
+		UserID:         fromUUID(user_id),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.CalendarFeed{}, models.ErrCalendarFeedNotFound
+		}
+		slog.ErrorContext(ctx, "GetCalendarFeed failed", "err", err)
+		return models.CalendarFeed{}, err
+	}
+	return models.CalendarFeed{
+		CreatedAt:  toTime(row.CreatedAt),
+		LastUsedAt: optTime(row.LastUsedAt),
+	}, nil
+}
+
+// SetCalendarFeed stores the hash of a new feed token for the user,
+// replacing (and so revoking) any earlier one.
+func (p *pgRepo) SetCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID, tokenHash string) error {
+	slog.DebugContext(ctx, "SetCalendarFeed", "org_id", org_id.String(), "user_id", user_id.String())
+	err := p.q.UpsertCalendarFeed(ctx, db.UpsertCalendarFeedParams{
+		TokenHash:      tokenHash,
+		OrganisationID: fromUUID(org_id),
+		UserID:         fromUUID(user_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "SetCalendarFeed failed", "err", err)
+	}
+	return err
+}
+
+func (p *pgRepo) DeleteCalendarFeed(ctx context.Context, org_id, user_id uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteCalendarFeed", "org_id", org_id.String(), "user_id", user_id.String())
+	n, err := p.q.DeleteCalendarFeed(ctx, db.DeleteCalendarFeedParams{
+		OrganisationID: fromUUID(org_id),
+		UserID:         fromUUID(user_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteCalendarFeed failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// ResolveCalendarFeed returns the organisation and user a feed token belongs
+// to. Tokens of users who have left the organisation no longer resolve.
+func (p *pgRepo) ResolveCalendarFeed(ctx context.Context, tokenHash string) (uuid.UUID, uuid.UUID, error) {
+	row, err := p.q.UseCalendarFeed(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, uuid.Nil, models.ErrCalendarFeedNotFound
+		}
+		slog.ErrorContext(ctx, "ResolveCalendarFeed failed", "err", err)
+		return uuid.Nil, uuid.Nil, err
+	}
+	return toUUID(row.OrganisationID), toUUID(row.UserID), nil
+}
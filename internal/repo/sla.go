@@ -0,0 +1,199 @@
+// internal/repo/sla.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- SLA policies ----------------
+
+func toNullInt4(p *int) pgtype.Int4 {
+	if p == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: int32(*p), Valid: true}
+}
+
+func optInt(v pgtype.Int4) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int32)
+	return &n
+}
+
+func slaPolicyFromRow(r db.ListSLAPoliciesRow) models.SLAPolicy {
+	var priority *string
+	if r.Priority.Valid {
+		priority = &r.Priority.String
+	}
+	return models.SLAPolicy{
+		ID:                    toUUID(r.ID),
+		Priority:              priority,
+		CategoryID:            optUUID(r.CategoryID),
+		CategoryName:          textOrEmpty(r.CategoryName),
+		ReactWithinMinutes:    optInt(r.ReactWithinMinutes),
+		CompleteWithinMinutes: optInt(r.CompleteWithinMinutes),
+		AtRiskPercent:         int(r.AtRiskPercent),
+		CreatedByID:           optUUID(r.CreatedByID),
+		CreatedAt:             toTime(r.CreatedAt),
+		UpdatedAt:             toTime(r.UpdatedAt),
+	}
+}
+
+func getSLAPolicy(ctx context.Context, q *db.Queries, orgID, policyID uuid.UUID) (models.SLAPolicy, error) {
+	row, err := q.GetSLAPolicy(ctx, db.GetSLAPolicyParams{
+		ID:             fromUUID(policyID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.SLAPolicy{}, models.ErrSLAPolicyNotFound
+		}
+		return models.SLAPolicy{}, err
+	}
+	return slaPolicyFromRow(db.ListSLAPoliciesRow(row)), nil
+}
+
+// checkSLACategory makes sure categoryID (if any) belongs to the organisation.
+func checkSLACategory(ctx context.Context, q *db.Queries, orgID uuid.UUID, categoryID *uuid.UUID) error {
+	if categoryID == nil {
+		return nil
+	}
+	_, err := q.GetWorkOrderCategory(ctx, db.GetWorkOrderCategoryParams{
+		ID:             fromUUID(*categoryID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.ErrCategoryNotFound
+	}
+	return err
+}
+
+// slaPolicyError maps a failed write to a model error, or nil if unexpected.
+func slaPolicyError(err error) error {
+	switch {
+	case isUniqueViolation(err):
+		return models.ErrSLAPolicyExists
+	case errors.Is(err, models.ErrSLAPolicyNotFound), errors.Is(err, models.ErrCategoryNotFound):
+		return err
+	}
+	return nil
+}
+
+func (p *pgRepo) ListSLAPolicies(ctx context.Context, org_id uuid.UUID) ([]models.SLAPolicy, error) {
+	slog.DebugContext(ctx, "ListSLAPolicies", "org_id", org_id.String())
+	rows, err := p.q.ListSLAPolicies(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListSLAPolicies failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.SLAPolicy, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, slaPolicyFromRow(r))
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID) (models.SLAPolicy, error) {
+	slog.DebugContext(ctx, "GetSLAPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	pol, err := getSLAPolicy(ctx, p.q, org_id, policyID)
+	if err != nil && !errors.Is(err, models.ErrSLAPolicyNotFound) {
+		slog.ErrorContext(ctx, "GetSLAPolicy failed", "err", err)
+	}
+	return pol, err
+}
+
+// CreateSLAPolicy adds a policy. in must already be normalized.
+func (p *pgRepo) CreateSLAPolicy(ctx context.Context, org_id, user_id uuid.UUID, in models.SLAPolicyInput) (models.SLAPolicy, error) {
+	slog.DebugContext(ctx, "CreateSLAPolicy", "org_id", org_id.String())
+	var out models.SLAPolicy
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkSLACategory(ctx, q, org_id, in.CategoryID); err != nil {
+			return err
+		}
+		id, err := q.CreateSLAPolicy(ctx, db.CreateSLAPolicyParams{
+			OrganisationID:        fromUUID(org_id),
+			Priority:              toNullText(in.Priority),
+			CategoryID:            toNullUUID(in.CategoryID),
+			ReactWithinMinutes:    toNullInt4(in.ReactWithinMinutes),
+			CompleteWithinMinutes: toNullInt4(in.CompleteWithinMinutes),
+			AtRiskPercent:         int32(*in.AtRiskPercent),
+			CreatedByID:           fromUUID(user_id),
+		})
+		if err != nil {
+			return err
+		}
+		out, err = getSLAPolicy(ctx, q, org_id, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := slaPolicyError(err); mapped != nil {
+			return models.SLAPolicy{}, mapped
+		}
+		slog.ErrorContext(ctx, "CreateSLAPolicy failed", "err", err)
+		return models.SLAPolicy{}, err
+	}
+	return out, nil
+}
+
+// UpdateSLAPolicy replaces a policy. in must already be normalized.
+func (p *pgRepo) UpdateSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID, in models.SLAPolicyInput) (models.SLAPolicy, error) {
+	slog.DebugContext(ctx, "UpdateSLAPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	var out models.SLAPolicy
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkSLACategory(ctx, q, org_id, in.CategoryID); err != nil {
+			return err
+		}
+		_, err := q.UpdateSLAPolicy(ctx, db.UpdateSLAPolicyParams{
+			Priority:              toNullText(in.Priority),
+			CategoryID:            toNullUUID(in.CategoryID),
+			ReactWithinMinutes:    toNullInt4(in.ReactWithinMinutes),
+			CompleteWithinMinutes: toNullInt4(in.CompleteWithinMinutes),
+			AtRiskPercent:         int32(*in.AtRiskPercent),
+			ID:                    fromUUID(policyID),
+			OrganisationID:        fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrSLAPolicyNotFound
+			}
+			return err
+		}
+		out, err = getSLAPolicy(ctx, q, org_id, policyID)
+		return err
+	})
+	if err != nil {
+		if mapped := slaPolicyError(err); mapped != nil {
+			return models.SLAPolicy{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateSLAPolicy failed", "err", err)
+		return models.SLAPolicy{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteSLAPolicy(ctx context.Context, org_id, policyID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteSLAPolicy", "org_id", org_id.String(), "policy_id", policyID.String())
+	n, err := p.q.DeleteSLAPolicy(ctx, db.DeleteSLAPolicyParams{
+		ID:             fromUUID(policyID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteSLAPolicy failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrSLAPolicyNotFound
+	}
+	return nil
+}
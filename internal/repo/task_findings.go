@@ -0,0 +1,190 @@
+// internal/repo/task_findings.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Task finding rules ----------------
+
+func findingRuleFromRow(r db.ListTaskFindingRulesRow) models.TaskFindingRule {
+	out := models.TaskFindingRule{
+		TaskBaseID:       toUUID(r.TaskBaseID),
+		TaskBaseLabel:    r.TaskBaseLabel,
+		TaskType:         r.TaskType,
+		OnFail:           r.OnFail,
+		OnFlag:           r.OnFlag,
+		OnOutOfTolerance: r.OnOutOfTolerance,
+		CategoryID:       optUUID(r.CategoryID),
+		CategoryName:     textOrEmpty(r.CategoryName),
+		TeamID:           optUUID(r.TeamID),
+		TeamName:         textOrEmpty(r.TeamName),
+		DueInDays:        optInt(r.DueInDays),
+		CreatedByID:      optUUID(r.CreatedByID),
+		CreatedAt:        toTime(r.CreatedAt),
+		UpdatedAt:        toTime(r.UpdatedAt),
+	}
+	if r.Priority.Valid {
+		p := r.Priority.String
+		out.Priority = &p
+	}
+	return out
+}
+
+func getFindingRule(ctx context.Context, q *db.Queries, orgID, baseID uuid.UUID) (models.TaskFindingRule, error) {
+	row, err := q.GetTaskFindingRule(ctx, db.GetTaskFindingRuleParams{
+		OrganisationID: fromUUID(orgID),
+		TaskBaseID:     fromUUID(baseID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TaskFindingRule{}, models.ErrTaskFindingRuleNotFound
+		}
+		return models.TaskFindingRule{}, err
+	}
+	return findingRuleFromRow(db.ListTaskFindingRulesRow(row)), nil
+}
+
+// checkFindingRuleRefs checks the base can find problems, that the category
+// exists and that the org can use the team.
+func checkFindingRuleRefs(ctx context.Context, q *db.Queries, orgID, baseID uuid.UUID, in models.TaskFindingRuleInput) error {
+	base, err := getTaskBase(ctx, q, orgID, baseID)
+	if err != nil {
+		return err
+	}
+	switch base.TaskType {
+	case models.TaskTypeInspection, models.TaskTypeNumber, models.TaskTypeMeter:
+	default:
+		return models.ErrFindingRuleNotSupported
+	}
+	if in.CategoryID != nil {
+		_, err := q.GetWorkOrderCategory(ctx, db.GetWorkOrderCategoryParams{
+			ID:             fromUUID(*in.CategoryID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.ErrCategoryNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if in.TeamID != nil {
+		ok, err := q.TeamExists(ctx, db.TeamExistsParams{
+			ID:             fromUUID(*in.TeamID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrTeamNotFound
+		}
+	}
+	return nil
+}
+
+// createCorrectiveWorkOrder spawns the corrective work order for a task
+// whose value a finding rule matches; it reports whether one was created.
+func createCorrectiveWorkOrder(ctx context.Context, q *db.Queries, orgID, userID, taskID uuid.UUID) (bool, error) {
+	id, err := q.CreateCorrectiveWorkOrder(ctx, db.CreateCorrectiveWorkOrderParams{
+		OrganisationID: fromUUID(orgID),
+		CreatedByID:    fromUUID(userID),
+		TaskID:         fromUUID(taskID),
+	})
+	if err != nil {
+		return false, err
+	}
+	if id.Valid {
+		slog.InfoContext(ctx, "corrective work order created", "task_id", taskID.String(), "work_order_id", toUUID(id).String())
+	}
+	return id.Valid, nil
+}
+
+func (p *pgRepo) ListTaskFindingRules(ctx context.Context, org_id uuid.UUID) ([]models.TaskFindingRule, error) {
+	slog.DebugContext(ctx, "ListTaskFindingRules", "org_id", org_id.String())
+	rows, err := p.q.ListTaskFindingRules(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListTaskFindingRules failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.TaskFindingRule, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, findingRuleFromRow(r))
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetTaskFindingRule(ctx context.Context, org_id, baseID uuid.UUID) (models.TaskFindingRule, error) {
+	slog.DebugContext(ctx, "GetTaskFindingRule", "org_id", org_id.String(), "task_base_id", baseID.String())
+	rule, err := getFindingRule(ctx, p.q, org_id, baseID)
+	if err != nil && !errors.Is(err, models.ErrTaskFindingRuleNotFound) {
+		slog.ErrorContext(ctx, "GetTaskFindingRule failed", "err", err)
+	}
+	return rule, err
+}
+
+// SetTaskFindingRule creates or replaces the organisation's rule for a task
+// base (its own or a global one). in must already be normalized.
+func (p *pgRepo) SetTaskFindingRule(ctx context.Context, org_id, user_id, baseID uuid.UUID, in models.TaskFindingRuleInput) (models.TaskFindingRule, error) {
+	slog.DebugContext(ctx, "SetTaskFindingRule", "org_id", org_id.String(), "task_base_id", baseID.String())
+	var out models.TaskFindingRule
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkFindingRuleRefs(ctx, q, org_id, baseID, in); err != nil {
+			return err
+		}
+		if err := q.UpsertTaskFindingRule(ctx, db.UpsertTaskFindingRuleParams{
+			OrganisationID:   fromUUID(org_id),
+			TaskBaseID:       fromUUID(baseID),
+			OnFail:           *in.OnFail,
+			OnFlag:           *in.OnFlag,
+			OnOutOfTolerance: *in.OnOutOfTolerance,
+			Priority:         toNullText(in.Priority),
+			CategoryID:       toNullUUID(in.CategoryID),
+			TeamID:           toNullUUID(in.TeamID),
+			DueInDays:        toNullInt4(in.DueInDays),
+			CreatedByID:      fromUUID(user_id),
+		}); err != nil {
+			return err
+		}
+		var err error
+		out, err = getFindingRule(ctx, q, org_id, baseID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrTaskBaseNotFound), errors.Is(err, models.ErrFindingRuleNotSupported),
+			errors.Is(err, models.ErrCategoryNotFound), errors.Is(err, models.ErrTeamNotFound):
+			return models.TaskFindingRule{}, err
+		}
+		slog.ErrorContext(ctx, "SetTaskFindingRule failed", "err", err)
+		return models.TaskFindingRule{}, err
+	}
+	return out, nil
+}
+
+// DeleteTaskFindingRule stops a task base from spawning work orders.
+// Corrective work orders already created are kept.
+func (p *pgRepo) DeleteTaskFindingRule(ctx context.Context, org_id, baseID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteTaskFindingRule", "org_id", org_id.String(), "task_base_id", baseID.String())
+	n, err := p.q.DeleteTaskFindingRule(ctx, db.DeleteTaskFindingRuleParams{
+		OrganisationID: fromUUID(org_id),
+		TaskBaseID:     fromUUID(baseID),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteTaskFindingRule failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrTaskFindingRuleNotFound
+	}
+	return nil
+}
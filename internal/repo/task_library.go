@@ -0,0 +1,644 @@
+// internal/repo/task_library.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Task library & task editing ----------------
+
+func optFloat8(f pgtype.Float8) *float64 {
+	if !f.Valid {
+		return nil
+	}
+	v := f.Float64
+	return &v
+}
+
+func taskBaseFromRow(r db.ListTaskBasesRow) models.TaskBase {
+	return models.TaskBase{
+		ID:          toUUID(r.ID),
+		Label:       r.Label,
+		TaskType:    r.TaskType,
+		UserID:      optUUID(r.UserID),
+		UserName:    textOrEmpty(r.UserName),
+		AssetID:     optUUID(r.AssetID),
+		AssetName:   textOrEmpty(r.AssetName),
+		Unit:        textOrEmpty(r.Unit),
+		MinValue:    optFloat8(r.MinValue),
+		MaxValue:    optFloat8(r.MaxValue),
+		MeterID:     optUUID(r.MeterID),
+		MeterName:   textOrEmpty(r.MeterName),
+		Options:     []models.TaskOption{},
+		Optional:    r.Optional,
+		Archived:    r.Archived,
+		Global:      !r.OrganisationID.Valid,
+		UsageCount:  r.UsageCount,
+		CreatedByID: optUUID(r.CreatedByID),
+		CreatedAt:   toTime(r.CreatedAt),
+		UpdatedAt:   toTime(r.UpdatedAt),
+	}
+}
+
+// withTaskOptions loads the options of bases in one query.
+func withTaskOptions(ctx context.Context, q *db.Queries, bases []models.TaskBase) error {
+	if len(bases) == 0 {
+		return nil
+	}
+	ids := make([]pgtype.UUID, 0, len(bases))
+	byID := make(map[uuid.UUID]int, len(bases))
+	for i, b := range bases {
+		ids = append(ids, fromUUID(b.ID))
+		byID[b.ID] = i
+	}
+	rows, err := q.ListTaskOptions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		i := byID[toUUID(r.TaskBaseID)]
+		bases[i].Options = append(bases[i].Options, models.TaskOption{
+			ID:       toUUID(r.ID),
+			Label:    textOrEmpty(r.Label),
+			Position: int(r.Position),
+		})
+	}
+	return nil
+}
+
+// getTaskBase returns one of the organisation's bases or a global one.
+func getTaskBase(ctx context.Context, q *db.Queries, orgID, baseID uuid.UUID) (models.TaskBase, error) {
+	row, err := q.GetTaskBase(ctx, db.GetTaskBaseParams{
+		ID:             fromUUID(baseID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TaskBase{}, models.ErrTaskBaseNotFound
+		}
+		return models.TaskBase{}, err
+	}
+	out := []models.TaskBase{taskBaseFromRow(db.ListTaskBasesRow(row))}
+	if err := withTaskOptions(ctx, q, out); err != nil {
+		return models.TaskBase{}, err
+	}
+	return out[0], nil
+}
+
+// checkTaskBaseRefs checks the default assignee, the asset and the meter of
+// a base.
+func checkTaskBaseRefs(ctx context.Context, q *db.Queries, orgID uuid.UUID, in models.TaskBaseInput) error {
+	if in.UserID != nil {
+		if err := checkOrgMembers(ctx, q, orgID, []uuid.UUID{*in.UserID}); err != nil {
+			return err
+		}
+	}
+	if in.AssetID != nil {
+		ok, err := q.AssetExists(ctx, db.AssetExistsParams{
+			ID:             fromUUID(*in.AssetID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrAssetNotFound
+		}
+	}
+	if in.MeterID != nil {
+		ok, err := q.MeterExists(ctx, db.MeterExistsParams{
+			ID:             fromUUID(*in.MeterID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return models.ErrMeterNotFound
+		}
+	}
+	return nil
+}
+
+// syncTaskOptions makes the options of a base match opts, in order: listed
+// IDs are relabelled, new labels added and everything else removed.
+func syncTaskOptions(ctx context.Context, q *db.Queries, orgID, userID, baseID uuid.UUID, opts []models.TaskOptionInput) error {
+	keep := make([]pgtype.UUID, 0, len(opts))
+	for _, o := range opts {
+		if o.ID != nil {
+			keep = append(keep, fromUUID(*o.ID))
+		}
+	}
+	if err := q.DeleteTaskOptionsExcept(ctx, db.DeleteTaskOptionsExceptParams{
+		TaskBaseID: fromUUID(baseID),
+		KeepIds:    keep,
+	}); err != nil {
+		return err
+	}
+	for i, o := range opts {
+		position := int32(i + 1)
+		if o.ID != nil {
+			n, err := q.UpdateTaskOption(ctx, db.UpdateTaskOptionParams{
+				Label:      toNullableText(o.Label),
+				Position:   position,
+				ID:         fromUUID(*o.ID),
+				TaskBaseID: fromUUID(baseID),
+			})
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return models.ErrTaskOptionNotFound
+			}
+			continue
+		}
+		if err := q.CreateTaskOption(ctx, db.CreateTaskOptionParams{
+			OrganisationID: fromUUID(orgID),
+			CreatedByID:    fromUUID(userID),
+			TaskBaseID:     fromUUID(baseID),
+			Label:          toNullableText(o.Label),
+			Position:       position,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createTaskBase(ctx context.Context, q *db.Queries, orgID, userID uuid.UUID, in models.TaskBaseInput) (uuid.UUID, error) {
+	if err := checkTaskBaseRefs(ctx, q, orgID, in); err != nil {
+		return uuid.Nil, err
+	}
+	id, err := q.CreateTaskBase(ctx, db.CreateTaskBaseParams{
+		OrganisationID: fromUUID(orgID),
+		CreatedByID:    fromUUID(userID),
+		Label:          in.Label,
+		TaskType:       in.TaskType,
+		UserID:         toNullUUID(in.UserID),
+		AssetID:        toNullUUID(in.AssetID),
+		Unit:           toNullableText(in.Unit),
+		MinValue:       toNullFloat8(in.MinValue),
+		MaxValue:       toNullFloat8(in.MaxValue),
+		MeterID:        toNullUUID(in.MeterID),
+		Optional:       in.Optional,
+		Archived:       in.Archived,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := syncTaskOptions(ctx, q, orgID, userID, toUUID(id), in.Options); err != nil {
+		return uuid.Nil, err
+	}
+	return toUUID(id), nil
+}
+
+// taskLibraryError passes model errors through; anything else is unexpected.
+func taskLibraryError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrTaskBaseNotFound), errors.Is(err, models.ErrTaskBaseArchived),
+		errors.Is(err, models.ErrTaskOptionNotFound), errors.Is(err, models.ErrUserNotMember),
+		errors.Is(err, models.ErrAssetNotFound), errors.Is(err, models.ErrWorkOrderNotFound),
+		errors.Is(err, models.ErrTaskNotFound), errors.Is(err, models.ErrTaskOrderMismatch),
+		errors.Is(err, models.ErrMeterNotFound), errors.Is(err, models.ErrInvalidTaskNumber),
+		errors.Is(err, models.ErrInvalidInspectionResult), errors.Is(err, models.ErrInvalidSubtaskValue),
+		errors.Is(err, models.ErrInvalidTaskChoice):
+		return err
+	case isForeignKeyViolation(err):
+		return models.ErrInvalidReference
+	}
+	return nil
+}
+
+func (p *pgRepo) ListTaskBases(ctx context.Context, org_id uuid.UUID, search, taskType string, includeArchived bool) ([]models.TaskBase, error) {
+	slog.DebugContext(ctx, "ListTaskBases", "org_id", org_id.String())
+	rows, err := p.q.ListTaskBases(ctx, db.ListTaskBasesParams{
+		OrganisationID:  fromUUID(org_id),
+		IncludeArchived: includeArchived,
+		TaskType:        toNullableText(strings.ToUpper(strings.TrimSpace(taskType))),
+		Search:          toNullableText(strings.TrimSpace(search)),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListTaskBases failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.TaskBase, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, taskBaseFromRow(r))
+	}
+	if err := withTaskOptions(ctx, p.q, out); err != nil {
+		slog.ErrorContext(ctx, "ListTaskBases options failed", "err", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetTaskBase(ctx context.Context, org_id, baseID uuid.UUID) (models.TaskBase, error) {
+	slog.DebugContext(ctx, "GetTaskBase", "org_id", org_id.String(), "task_base_id", baseID.String())
+	base, err := getTaskBase(ctx, p.q, org_id, baseID)
+	if err != nil && !errors.Is(err, models.ErrTaskBaseNotFound) {
+		slog.ErrorContext(ctx, "GetTaskBase failed", "err", err)
+	}
+	return base, err
+}
+
+// CreateTaskBase adds a base to the library. in must already be normalized.
+func (p *pgRepo) CreateTaskBase(ctx context.Context, org_id, user_id uuid.UUID, in models.TaskBaseInput) (models.TaskBase, error) {
+	slog.DebugContext(ctx, "CreateTaskBase", "org_id", org_id.String(), "label", in.Label)
+	var out models.TaskBase
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		id, err := createTaskBase(ctx, q, org_id, user_id, in)
+		if err != nil {
+			return err
+		}
+		out, err = getTaskBase(ctx, q, org_id, id)
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return models.TaskBase{}, mapped
+		}
+		slog.ErrorContext(ctx, "CreateTaskBase failed", "err", err)
+		return models.TaskBase{}, err
+	}
+	return out, nil
+}
+
+// UpdateTaskBase replaces one of the organisation's bases and its options.
+// Tasks created from it show the new label straight away.
+func (p *pgRepo) UpdateTaskBase(ctx context.Context, org_id, user_id, baseID uuid.UUID, in models.TaskBaseInput) (models.TaskBase, error) {
+	slog.DebugContext(ctx, "UpdateTaskBase", "org_id", org_id.String(), "task_base_id", baseID.String())
+	var out models.TaskBase
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkTaskBaseRefs(ctx, q, org_id, in); err != nil {
+			return err
+		}
+		_, err := q.UpdateTaskBase(ctx, db.UpdateTaskBaseParams{
+			Label:          in.Label,
+			TaskType:       in.TaskType,
+			UserID:         toNullUUID(in.UserID),
+			AssetID:        toNullUUID(in.AssetID),
+			Unit:           toNullableText(in.Unit),
+			MinValue:       toNullFloat8(in.MinValue),
+			MaxValue:       toNullFloat8(in.MaxValue),
+			MeterID:        toNullUUID(in.MeterID),
+			Optional:       in.Optional,
+			Archived:       in.Archived,
+			ID:             fromUUID(baseID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrTaskBaseNotFound
+			}
+			return err
+		}
+		if err := syncTaskOptions(ctx, q, org_id, user_id, baseID, in.Options); err != nil {
+			return err
+		}
+		out, err = getTaskBase(ctx, q, org_id, baseID)
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return models.TaskBase{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateTaskBase failed", "err", err)
+		return models.TaskBase{}, err
+	}
+	return out, nil
+}
+
+// DeleteTaskBase removes a base no task uses. Returns models.ErrTaskBaseInUse
+// otherwise.
+func (p *pgRepo) DeleteTaskBase(ctx context.Context, org_id, baseID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteTaskBase", "org_id", org_id.String(), "task_base_id", baseID.String())
+	n, err := p.q.DeleteTaskBase(ctx, db.DeleteTaskBaseParams{
+		ID:             fromUUID(baseID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return models.ErrTaskBaseInUse
+		}
+		slog.ErrorContext(ctx, "DeleteTaskBase failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrTaskBaseNotFound
+	}
+	return nil
+}
+
+func getTask(ctx context.Context, q *db.Queries, orgID, taskID uuid.UUID) (models.Task, error) {
+	r, err := q.GetTask(ctx, db.GetTaskParams{
+		ID:             fromUUID(taskID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Task{}, models.ErrTaskNotFound
+		}
+		return models.Task{}, err
+	}
+	return models.Task{
+		ID:                    toUUID(r.ID),
+		WorkOrderID:           toUUID(r.WorkOrderID),
+		TaskBaseID:            toUUID(r.TaskBaseID),
+		Label:                 r.Label,
+		TaskType:              r.TaskType,
+		Unit:                  textOrEmpty(r.Unit),
+		MinValue:              optFloat8(r.MinValue),
+		MaxValue:              optFloat8(r.MaxValue),
+		MeterID:               optUUID(r.MeterID),
+		Notes:                 textOrEmpty(r.Notes),
+		Value:                 textOrEmpty(r.Value),
+		NumericValue:          optFloat8(r.NumericValue),
+		OptionID:              optUUID(r.TaskOptionID),
+		OutOfTolerance:        r.OutOfTolerance,
+		Completed:             r.Completed,
+		CorrectiveWorkOrderID: optUUID(r.CorrectiveWorkOrderID),
+		AssigneeID:            optUUID(r.AssigneeID),
+		AssigneeName:          textOrEmpty(r.AssigneeName),
+		DueAt:                 optTime(r.DueAt),
+		CompletedByID:         optUUID(r.CompletedByID),
+		CompletedByName:       textOrEmpty(r.CompletedByName),
+		CompletedAt:           optTime(r.CompletedAt),
+		Position:              int(r.Position),
+		CreatedByID:           optUUID(r.CreatedByID),
+		CreatedAt:             toTime(r.CreatedAt),
+		UpdatedAt:             toTime(r.UpdatedAt),
+	}, nil
+}
+
+// parseTaskValue checks raw against the base of a task.
+func parseTaskValue(ctx context.Context, q *db.Queries, orgID, baseID uuid.UUID, raw string) (models.TaskValue, error) {
+	base, err := getTaskBase(ctx, q, orgID, baseID)
+	if err != nil {
+		return models.TaskValue{}, err
+	}
+	return base.ParseValue(raw)
+}
+
+// afterTaskValue runs the side effects of a new task value: a METER reading
+// is copied to its meter, and a finding rule may spawn a corrective work
+// order (in which case t is reloaded to link it).
+func afterTaskValue(ctx context.Context, q *db.Queries, orgID, userID uuid.UUID, t models.Task) (models.Task, error) {
+	if t.TaskType == models.TaskTypeMeter && t.MeterID != nil && t.NumericValue != nil {
+		n, err := q.RecordMeterReading(ctx, db.RecordMeterReadingParams{
+			Value:          *t.NumericValue,
+			Unit:           toNullableText(t.Unit),
+			ReadByID:       fromUUID(userID),
+			TaskID:         fromUUID(t.ID),
+			ID:             fromUUID(*t.MeterID),
+			OrganisationID: fromUUID(orgID),
+		})
+		if err != nil {
+			return t, err
+		}
+		if n == 0 {
+			return t, models.ErrMeterNotFound
+		}
+	}
+	created, err := createCorrectiveWorkOrder(ctx, q, orgID, userID, t.ID)
+	if err != nil || !created {
+		return t, err
+	}
+	return getTask(ctx, q, orgID, t.ID)
+}
+
+func (p *pgRepo) GetTask(ctx context.Context, org_id, taskID uuid.UUID) (models.Task, error) {
+	slog.DebugContext(ctx, "GetTask", "org_id", org_id.String(), "task_id", taskID.String())
+	t, err := getTask(ctx, p.q, org_id, taskID)
+	if err != nil && !errors.Is(err, models.ErrTaskNotFound) {
+		slog.ErrorContext(ctx, "GetTask failed", "err", err)
+	}
+	return t, err
+}
+
+// CreateTask appends a task to a work order, saving in.TaskBase to the
+// library first when given. in must already be normalized.
+func (p *pgRepo) CreateTask(ctx context.Context, org_id, user_id uuid.UUID, in models.TaskInput) (models.Task, error) {
+	slog.DebugContext(ctx, "CreateTask", "org_id", org_id.String(), "work_order_id", in.WorkOrderID.String())
+	var out models.Task
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		var baseID uuid.UUID
+		if in.TaskBase != nil {
+			id, err := createTaskBase(ctx, q, org_id, user_id, *in.TaskBase)
+			if err != nil {
+				return err
+			}
+			baseID = id
+		} else {
+			base, err := getTaskBase(ctx, q, org_id, *in.TaskBaseID)
+			if err != nil {
+				return err
+			}
+			if base.Archived {
+				return models.ErrTaskBaseArchived
+			}
+			baseID = base.ID
+		}
+		if in.AssigneeID != nil {
+			if err := checkOrgMembers(ctx, q, org_id, []uuid.UUID{*in.AssigneeID}); err != nil {
+				return err
+			}
+		}
+		var value models.TaskValue
+		if in.Value != nil {
+			v, err := parseTaskValue(ctx, q, org_id, baseID, *in.Value)
+			if err != nil {
+				return err
+			}
+			value = v
+		}
+		id, err := q.CreateTask(ctx, db.CreateTaskParams{
+			CreatedByID:    fromUUID(user_id),
+			TaskBaseID:     fromUUID(baseID),
+			Notes:          toNullableText(in.Notes),
+			Value:          toNullableText(value.Value),
+			NumericValue:   toNullFloat8(value.Number),
+			TaskOptionID:   toNullUUID(value.OptionID),
+			OutOfTolerance: value.OutOfTolerance,
+			AssigneeID:     toNullUUID(in.AssigneeID),
+			DueAt:          toNullTimestamptz(in.DueAt),
+			WorkOrderID:    fromUUID(in.WorkOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		if out, err = getTask(ctx, q, org_id, toUUID(id)); err != nil {
+			return err
+		}
+		if in.Value == nil {
+			return nil
+		}
+		out, err = afterTaskValue(ctx, q, org_id, user_id, out)
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return models.Task{}, mapped
+		}
+		slog.ErrorContext(ctx, "CreateTask failed", "err", err)
+		return models.Task{}, err
+	}
+	return out, nil
+}
+
+// UpdateTask sets the notes and/or value of a task. in must already be
+// normalized; the value is checked against the task type here, and the
+// change (with its side effects: meter reading, corrective work order) is
+// done on behalf of user_id.
+func (p *pgRepo) UpdateTask(ctx context.Context, org_id, user_id, taskID uuid.UUID, in models.TaskUpdate) (models.Task, error) {
+	slog.DebugContext(ctx, "UpdateTask", "org_id", org_id.String(), "task_id", taskID.String())
+	args := db.UpdateTaskParams{
+		SetNotes:       in.Notes != nil,
+		SetValue:       in.Value != nil,
+		UpdatedByID:    fromUUID(user_id),
+		ID:             fromUUID(taskID),
+		OrganisationID: fromUUID(org_id),
+	}
+	if in.Notes != nil {
+		args.Notes = toNullableText(*in.Notes)
+	}
+	var out models.Task
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if in.Value != nil {
+			t, err := getTask(ctx, q, org_id, taskID)
+			if err != nil {
+				return err
+			}
+			v, err := parseTaskValue(ctx, q, org_id, t.TaskBaseID, *in.Value)
+			if err != nil {
+				return err
+			}
+			args.Value = toNullableText(v.Value)
+			args.NumericValue = toNullFloat8(v.Number)
+			args.TaskOptionID = toNullUUID(v.OptionID)
+			args.OutOfTolerance = v.OutOfTolerance
+		}
+		if _, err := q.UpdateTask(ctx, args); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrTaskNotFound
+			}
+			return err
+		}
+		var err error
+		if out, err = getTask(ctx, q, org_id, taskID); err != nil {
+			return err
+		}
+		if in.Value == nil {
+			return nil
+		}
+		out, err = afterTaskValue(ctx, q, org_id, user_id, out)
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return models.Task{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateTask failed", "err", err)
+		return models.Task{}, err
+	}
+	return out, nil
+}
+
+// AssignTask replaces the assignee and due time of a task on behalf of
+// user_id. The assignee must be a member of the organisation.
+func (p *pgRepo) AssignTask(ctx context.Context, org_id, user_id, taskID uuid.UUID, in models.TaskAssignment) (models.Task, error) {
+	slog.DebugContext(ctx, "AssignTask", "org_id", org_id.String(), "task_id", taskID.String())
+	var out models.Task
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if in.AssigneeID != nil {
+			if err := checkOrgMembers(ctx, q, org_id, []uuid.UUID{*in.AssigneeID}); err != nil {
+				return err
+			}
+		}
+		if _, err := q.AssignTask(ctx, db.AssignTaskParams{
+			AssigneeID:     toNullUUID(in.AssigneeID),
+			DueAt:          toNullTimestamptz(in.DueAt),
+			UpdatedByID:    fromUUID(user_id),
+			ID:             fromUUID(taskID),
+			OrganisationID: fromUUID(org_id),
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrTaskNotFound
+			}
+			return err
+		}
+		var err error
+		out, err = getTask(ctx, q, org_id, taskID)
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return models.Task{}, mapped
+		}
+		slog.ErrorContext(ctx, "AssignTask failed", "err", err)
+		return models.Task{}, err
+	}
+	return out, nil
+}
+
+// ReorderTasks puts the tasks of a work order in the order of taskIDs,
+// which must list each of them exactly once.
+func (p *pgRepo) ReorderTasks(ctx context.Context, org_id, workOrderID uuid.UUID, taskIDs []uuid.UUID) error {
+	slog.DebugContext(ctx, "ReorderTasks", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "count", len(taskIDs))
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if _, err := q.GetWorkOrderVersion(ctx, db.GetWorkOrderVersionParams{
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		}); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		current, err := q.ListWorkOrderTaskIDs(ctx, db.ListWorkOrderTaskIDsParams{
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			return err
+		}
+		if len(current) != len(taskIDs) || len(dedupeUUIDs(taskIDs)) != len(taskIDs) {
+			return models.ErrTaskOrderMismatch
+		}
+		known := make(map[uuid.UUID]bool, len(current))
+		for _, id := range current {
+			known[toUUID(id)] = true
+		}
+		for _, id := range taskIDs {
+			if !known[id] {
+				return models.ErrTaskOrderMismatch
+			}
+		}
+		_, err = q.ReorderWorkOrderTasks(ctx, db.ReorderWorkOrderTasksParams{
+			TaskIds:        toPgUUIDs(taskIDs),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		return err
+	})
+	if err != nil {
+		if mapped := taskLibraryError(err); mapped != nil {
+			return mapped
+		}
+		slog.ErrorContext(ctx, "ReorderTasks failed", "err", err)
+	}
+	return err
+}
@@ -2,32 +2,56 @@ package repo
 
 import (
     "context"
+    "errors"
     "log/slog"
 
     "github.com/google/uuid"
+    "github.com/jackc/pgx/v5"
 
     db "yourapp/internal/db/gen"
+    "yourapp/internal/models"
 )
 
 // ---------------- Tasks ----------------
 
-func (p *pgRepo) ToggleTaskComplete(ctx context.Context, org_id uuid.UUID, taskID uuid.UUID, complete bool) (db.ToggleTaskCompletionRow, error) {
+// notToggleable tells a task that is not a SUBTASK (so the toggle queries
+// skip it) from one that does not exist.
+func (p *pgRepo) notToggleable(ctx context.Context, org_id, taskID uuid.UUID) error {
+    if _, err := getTask(ctx, p.q, org_id, taskID); err != nil {
+        return err
+    }
+    return models.ErrTaskNotToggleable
+}
+
+// ToggleTaskComplete flips a SUBTASK on behalf of user_id, who is recorded
+// as the one that completed it.
+func (p *pgRepo) ToggleTaskComplete(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, taskID uuid.UUID, complete bool) (db.ToggleTaskCompletionRow, error) {
     slog.DebugContext(ctx, "ToggleTaskComplete", "org_id", org_id.String(), "task_id", taskID.String(), "complete", complete)
     args := db.ToggleTaskCompletionParams{
+        UpdatedByID:    fromUUID(user_id),
         OrganisationID: fromUUID(org_id),
         ID:             toPgUUID(taskID),
         Complete:       complete,
     }
-    return p.q.ToggleTaskCompletion(ctx, args)
+    row, err := p.q.ToggleTaskCompletion(ctx, args)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return row, p.notToggleable(ctx, org_id, taskID)
+    }
+    return row, err
 }
 
-func (p *pgRepo) MarkTaskComplete(ctx context.Context, org_id uuid.UUID, taskID uuid.UUID) (db.MarkTaskCompleteRow, error) {
+func (p *pgRepo) MarkTaskComplete(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, taskID uuid.UUID) (db.MarkTaskCompleteRow, error) {
     slog.DebugContext(ctx, "MarkTaskComplete", "org_id", org_id.String(), "task_id", taskID.String())
     args := db.MarkTaskCompleteParams{
+        UpdatedByID:    fromUUID(user_id),
         OrganisationID: fromUUID(org_id),
         ID:             toPgUUID(taskID),
     }
-    return p.q.MarkTaskComplete(ctx, args)
+    row, err := p.q.MarkTaskComplete(ctx, args)
+    if errors.Is(err, pgx.ErrNoRows) {
+        return row, p.notToggleable(ctx, org_id, taskID)
+    }
+    return row, err
 }
 
 func (p *pgRepo) DeleteTaskByID(ctx context.Context, org_id, taskID uuid.UUID) error {
@@ -36,7 +60,15 @@ func (p *pgRepo) DeleteTaskByID(ctx context.Context, org_id, taskID uuid.UUID) e
         OrganisationID: fromUUID(org_id),
         ID:             fromUUID(taskID),
     }
-    return p.q.DeleteTaskByID(ctx, args)
+    n, err := p.q.DeleteTaskByID(ctx, args)
+    if err != nil {
+        slog.ErrorContext(ctx, "DeleteTaskByID failed", "err", err)
+        return err
+    }
+    if n == 0 {
+        return models.ErrTaskNotFound
+    }
+    return nil
 }
 
 func (p *pgRepo) ListSimpleTasksByWorkOrderID(ctx context.Context, org_id, workOrderID uuid.UUID) ([]db.ListSimpleTasksByWorkOrderRow, error) {
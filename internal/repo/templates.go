@@ -0,0 +1,245 @@
+// internal/repo/templates.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order templates ----------------
+
+func templateFromRow(t db.WorkOrderTemplate) models.WorkOrderTemplate {
+	return models.WorkOrderTemplate{
+		ID:          toUUID(t.ID),
+		Name:        t.Name,
+		Description: textOrEmpty(t.Description),
+		Payload:     t.Payload,
+		Tasks:       []models.TemplateTask{},
+		CreatedByID: optUUID(t.CreatedByID),
+		CreatedAt:   toTime(t.CreatedAt),
+		UpdatedAt:   toTime(t.UpdatedAt),
+	}
+}
+
+// withTemplateTasks loads the task lists of tpls in one query.
+func withTemplateTasks(ctx context.Context, q *db.Queries, tpls []models.WorkOrderTemplate) error {
+	if len(tpls) == 0 {
+		return nil
+	}
+	ids := make([]pgtype.UUID, 0, len(tpls))
+	byID := make(map[uuid.UUID]int, len(tpls))
+	for i, t := range tpls {
+		ids = append(ids, fromUUID(t.ID))
+		byID[t.ID] = i
+	}
+	rows, err := q.ListWorkOrderTemplateTasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		i, ok := byID[toUUID(r.TemplateID)]
+		if !ok {
+			continue
+		}
+		tpls[i].Tasks = append(tpls[i].Tasks, models.TemplateTask{
+			TaskBaseID: toUUID(r.TaskBaseID),
+			Label:      r.Label,
+			TaskType:   r.TaskType,
+			Position:   int(r.Position),
+		})
+	}
+	return nil
+}
+
+// saveTemplateTasks checks the task bases are usable by the org and
+// replaces the template's task list.
+func saveTemplateTasks(ctx context.Context, q *db.Queries, orgID, templateID uuid.UUID, taskBases []uuid.UUID) error {
+	if err := q.ClearWorkOrderTemplateTasks(ctx, fromUUID(templateID)); err != nil {
+		return err
+	}
+	if len(taskBases) == 0 {
+		return nil
+	}
+	n, err := q.CountOrgTaskBases(ctx, db.CountOrgTaskBasesParams{
+		TaskBaseIds:    toPgUUIDs(taskBases),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		return err
+	}
+	if n != int64(len(taskBases)) {
+		return models.ErrTaskBaseNotFound
+	}
+	return q.AddWorkOrderTemplateTasks(ctx, db.AddWorkOrderTemplateTasksParams{
+		TemplateID:  fromUUID(templateID),
+		TaskBaseIds: toPgUUIDs(taskBases),
+	})
+}
+
+func (p *pgRepo) ListWorkOrderTemplates(ctx context.Context, org_id uuid.UUID) ([]models.WorkOrderTemplate, error) {
+	slog.DebugContext(ctx, "ListWorkOrderTemplates", "org_id", org_id.String())
+	rows, err := p.q.ListWorkOrderTemplates(ctx, fromUUID(org_id))
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderTemplates failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.WorkOrderTemplate, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, templateFromRow(r))
+	}
+	if err := withTemplateTasks(ctx, p.q, out); err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderTemplateTasks failed", "err", err)
+		return nil, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) GetWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID) (models.WorkOrderTemplate, error) {
+	slog.DebugContext(ctx, "GetWorkOrderTemplate", "org_id", org_id.String(), "template_id", templateID.String())
+	row, err := p.q.GetWorkOrderTemplate(ctx, db.GetWorkOrderTemplateParams{
+		ID:             fromUUID(templateID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WorkOrderTemplate{}, models.ErrTemplateNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderTemplate failed", "err", err)
+		return models.WorkOrderTemplate{}, err
+	}
+	out := []models.WorkOrderTemplate{templateFromRow(row)}
+	if err := withTemplateTasks(ctx, p.q, out); err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderTemplateTasks failed", "err", err)
+		return models.WorkOrderTemplate{}, err
+	}
+	return out[0], nil
+}
+
+// CreateWorkOrderTemplate stores the template and its task list in one
+// transaction. in must already be normalized.
+func (p *pgRepo) CreateWorkOrderTemplate(ctx context.Context, org_id, user_id uuid.UUID, in models.WorkOrderTemplateInput) (models.WorkOrderTemplate, error) {
+	slog.DebugContext(ctx, "CreateWorkOrderTemplate", "org_id", org_id.String(), "user_id", user_id.String())
+	var out models.WorkOrderTemplate
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		row, err := q.CreateWorkOrderTemplate(ctx, db.CreateWorkOrderTemplateParams{
+			OrganisationID: fromUUID(org_id),
+			CreatedByID:    fromUUID(user_id),
+			Name:           in.Name,
+			Description:    toNullableText(in.Description),
+			Payload:        in.Payload,
+		})
+		if err != nil {
+			return err
+		}
+		if err := saveTemplateTasks(ctx, q, org_id, toUUID(row.ID), in.Tasks); err != nil {
+			return err
+		}
+		tpls := []models.WorkOrderTemplate{templateFromRow(row)}
+		if err := withTemplateTasks(ctx, q, tpls); err != nil {
+			return err
+		}
+		out = tpls[0]
+		return nil
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.WorkOrderTemplate{}, models.ErrTemplateNameTaken
+		case errors.Is(err, models.ErrTaskBaseNotFound):
+			return models.WorkOrderTemplate{}, err
+		}
+		slog.ErrorContext(ctx, "CreateWorkOrderTemplate failed", "err", err)
+		return models.WorkOrderTemplate{}, err
+	}
+	return out, nil
+}
+
+// UpdateWorkOrderTemplate replaces the template's fields and task list.
+func (p *pgRepo) UpdateWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID, in models.WorkOrderTemplateInput) (models.WorkOrderTemplate, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderTemplate", "org_id", org_id.String(), "template_id", templateID.String())
+	var out models.WorkOrderTemplate
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		row, err := q.UpdateWorkOrderTemplate(ctx, db.UpdateWorkOrderTemplateParams{
+			Name:           in.Name,
+			Description:    toNullableText(in.Description),
+			Payload:        in.Payload,
+			ID:             fromUUID(templateID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrTemplateNotFound
+			}
+			return err
+		}
+		if err := saveTemplateTasks(ctx, q, org_id, templateID, in.Tasks); err != nil {
+			return err
+		}
+		tpls := []models.WorkOrderTemplate{templateFromRow(row)}
+		if err := withTemplateTasks(ctx, q, tpls); err != nil {
+			return err
+		}
+		out = tpls[0]
+		return nil
+	})
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return models.WorkOrderTemplate{}, models.ErrTemplateNameTaken
+		case errors.Is(err, models.ErrTemplateNotFound), errors.Is(err, models.ErrTaskBaseNotFound):
+			return models.WorkOrderTemplate{}, err
+		}
+		slog.ErrorContext(ctx, "UpdateWorkOrderTemplate failed", "err", err)
+		return models.WorkOrderTemplate{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteWorkOrderTemplate(ctx context.Context, org_id, templateID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderTemplate", "org_id", org_id.String(), "template_id", templateID.String())
+	n, err := p.q.DeleteWorkOrderTemplate(ctx, db.DeleteWorkOrderTemplateParams{
+		ID:             fromUUID(templateID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteWorkOrderTemplate failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrTemplateNotFound
+	}
+	return nil
+}
+
+// InstantiateWorkOrderTemplate creates a work order from the template with
+// overrides (a partial create payload) merged on top.
+func (p *pgRepo) InstantiateWorkOrderTemplate(ctx context.Context, org_id, templateID, user_id uuid.UUID, overrides []byte) (uuid.UUID, error) {
+	slog.DebugContext(ctx, "InstantiateWorkOrderTemplate", "org_id", org_id.String(), "template_id", templateID.String(), "user_id", user_id.String())
+	id, err := p.q.InstantiateWorkOrderTemplate(ctx, db.InstantiateWorkOrderTemplateParams{
+		OrganisationID: fromUUID(org_id),
+		TemplateID:     fromUUID(templateID),
+		CreatedByID:    fromUUID(user_id),
+		Overrides:      overrides,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "P0002" {
+			return uuid.Nil, models.ErrTemplateNotFound
+		}
+		if mapped := createPayloadError(err); mapped != nil {
+			return uuid.Nil, mapped
+		}
+		slog.ErrorContext(ctx, "InstantiateWorkOrderTemplate failed", "err", err)
+		return uuid.Nil, err
+	}
+	return toUUID(id), nil
+}
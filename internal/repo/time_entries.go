@@ -0,0 +1,288 @@
+// internal/repo/time_entries.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order time entries ----------------
+
+func timeEntryFromRow(r db.ListWorkOrderTimeEntriesRow) models.TimeEntry {
+	e := models.TimeEntry{
+		ID:              toUUID(r.ID),
+		WorkOrderID:     toUUID(r.WorkOrderID),
+		UserID:          toUUID(r.UserID),
+		UserName:        fromText(r.UserName),
+		StartedAt:       toTime(r.StartedAt),
+		Running:         !r.EndedAt.Valid,
+		DurationSeconds: r.DurationSeconds,
+		HourlyRate:      r.HourlyRate,
+		Cost:            models.LabourCost(r.DurationSeconds, r.HourlyRate),
+		Notes:           textOrEmpty(r.Notes),
+		Source:          r.Source,
+		CreatedByID:     optUUID(r.CreatedByID),
+		CreatedAt:       toTime(r.CreatedAt),
+	}
+	if r.EndedAt.Valid {
+		t := r.EndedAt.Time
+		e.EndedAt = &t
+	}
+	return e
+}
+
+func isExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23P01"
+}
+
+func toTimestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: t, Valid: true}
+}
+
+// checkTimeEntryUser makes sure time is only logged for org members.
+func checkTimeEntryUser(ctx context.Context, q *db.Queries, orgID, userID uuid.UUID) error {
+	n, err := q.CountOrgMembers(ctx, db.CountOrgMembersParams{
+		OrgID:   fromUUID(orgID),
+		UserIds: []pgtype.UUID{fromUUID(userID)},
+	})
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return models.ErrTimeEntryUserNotFound
+	}
+	return nil
+}
+
+// timeEntryError maps constraint violations on work_order_time_entries.
+func timeEntryError(err error) error {
+	switch {
+	case isExclusionViolation(err):
+		return models.ErrTimeEntryOverlap
+	case errors.Is(err, models.ErrWorkOrderNotFound), errors.Is(err, models.ErrTimeEntryNotFound),
+		errors.Is(err, models.ErrTimerRunning), errors.Is(err, models.ErrNoRunningTimer),
+		errors.Is(err, models.ErrTimeEntryUserNotFound):
+		return err
+	}
+	return nil
+}
+
+func getTimeEntry(ctx context.Context, q *db.Queries, orgID, workOrderID, entryID uuid.UUID) (models.TimeEntry, error) {
+	row, err := q.GetWorkOrderTimeEntry(ctx, db.GetWorkOrderTimeEntryParams{
+		ID:             fromUUID(entryID),
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(orgID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.TimeEntry{}, models.ErrTimeEntryNotFound
+		}
+		return models.TimeEntry{}, err
+	}
+	return timeEntryFromRow(db.ListWorkOrderTimeEntriesRow(row)), nil
+}
+
+func (p *pgRepo) ListWorkOrderTimeEntries(ctx context.Context, org_id, workOrderID uuid.UUID) ([]models.TimeEntry, error) {
+	slog.DebugContext(ctx, "ListWorkOrderTimeEntries", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	rows, err := p.q.ListWorkOrderTimeEntries(ctx, db.ListWorkOrderTimeEntriesParams{
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderTimeEntries failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.TimeEntry, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, timeEntryFromRow(r))
+	}
+	slog.DebugContext(ctx, "ListWorkOrderTimeEntries ok", "count", len(out))
+	return out, nil
+}
+
+func (p *pgRepo) GetWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID uuid.UUID) (models.TimeEntry, error) {
+	slog.DebugContext(ctx, "GetWorkOrderTimeEntry", "org_id", org_id.String(), "entry_id", entryID.String())
+	e, err := getTimeEntry(ctx, p.q, org_id, workOrderID, entryID)
+	if err != nil && !errors.Is(err, models.ErrTimeEntryNotFound) {
+		slog.ErrorContext(ctx, "GetWorkOrderTimeEntry failed", "err", err)
+	}
+	return e, err
+}
+
+// StartWorkOrderTimer starts user_id's timer on the work order. Returns
+// models.ErrTimerRunning when the user already has one running in the org,
+// models.ErrTimeEntryOverlap when the start falls inside a logged entry.
+func (p *pgRepo) StartWorkOrderTimer(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, in models.TimerInput) (models.TimeEntry, error) {
+	slog.DebugContext(ctx, "StartWorkOrderTimer", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	var out models.TimeEntry
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		_, err := q.GetRunningTimeEntry(ctx, db.GetRunningTimeEntryParams{
+			UserID:         fromUUID(user_id),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err == nil {
+			return models.ErrTimerRunning
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		id, err := q.StartWorkOrderTimer(ctx, db.StartWorkOrderTimerParams{
+			UserID:         fromUUID(user_id),
+			HourlyRate:     in.Rate(),
+			Notes:          toNullableText(in.Notes),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		out, err = getTimeEntry(ctx, q, org_id, workOrderID, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := timeEntryError(err); mapped != nil {
+			return models.TimeEntry{}, mapped
+		}
+		slog.ErrorContext(ctx, "StartWorkOrderTimer failed", "err", err)
+		return models.TimeEntry{}, err
+	}
+	return out, nil
+}
+
+// StopWorkOrderTimer stops user_id's running timer on the work order. notes,
+// when non-nil, replaces the entry's notes.
+func (p *pgRepo) StopWorkOrderTimer(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, notes *string) (models.TimeEntry, error) {
+	slog.DebugContext(ctx, "StopWorkOrderTimer", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	var out models.TimeEntry
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		id, err := q.StopWorkOrderTimer(ctx, db.StopWorkOrderTimerParams{
+			Notes:          toNullText(notes),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+			UserID:         fromUUID(user_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrNoRunningTimer
+			}
+			return err
+		}
+		out, err = getTimeEntry(ctx, q, org_id, workOrderID, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := timeEntryError(err); mapped != nil {
+			return models.TimeEntry{}, mapped
+		}
+		slog.ErrorContext(ctx, "StopWorkOrderTimer failed", "err", err)
+		return models.TimeEntry{}, err
+	}
+	return out, nil
+}
+
+// CreateWorkOrderTimeEntry logs a manual entry for user_id, recorded as
+// created by createdBy. in must already be normalized.
+func (p *pgRepo) CreateWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, user_id, createdBy uuid.UUID, in models.TimeEntryInput) (models.TimeEntry, error) {
+	slog.DebugContext(ctx, "CreateWorkOrderTimeEntry", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	var out models.TimeEntry
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkTimeEntryUser(ctx, q, org_id, user_id); err != nil {
+			return err
+		}
+		id, err := q.CreateWorkOrderTimeEntry(ctx, db.CreateWorkOrderTimeEntryParams{
+			UserID:         fromUUID(user_id),
+			StartedAt:      toTimestamptz(*in.StartedAt),
+			EndedAt:        toTimestamptz(*in.EndedAt),
+			HourlyRate:     in.Rate(),
+			Notes:          toNullableText(in.Notes),
+			CreatedByID:    fromUUID(createdBy),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrWorkOrderNotFound
+			}
+			return err
+		}
+		out, err = getTimeEntry(ctx, q, org_id, workOrderID, toUUID(id))
+		return err
+	})
+	if err != nil {
+		if mapped := timeEntryError(err); mapped != nil {
+			return models.TimeEntry{}, mapped
+		}
+		slog.ErrorContext(ctx, "CreateWorkOrderTimeEntry failed", "err", err)
+		return models.TimeEntry{}, err
+	}
+	return out, nil
+}
+
+// UpdateWorkOrderTimeEntry replaces a finished entry's span, rate, notes and
+// user. Permission checks are the caller's job.
+func (p *pgRepo) UpdateWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID, user_id uuid.UUID, in models.TimeEntryInput) (models.TimeEntry, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderTimeEntry", "org_id", org_id.String(), "entry_id", entryID.String())
+	var out models.TimeEntry
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		if err := checkTimeEntryUser(ctx, q, org_id, user_id); err != nil {
+			return err
+		}
+		_, err := q.UpdateWorkOrderTimeEntry(ctx, db.UpdateWorkOrderTimeEntryParams{
+			UserID:         fromUUID(user_id),
+			StartedAt:      toTimestamptz(*in.StartedAt),
+			EndedAt:        toTimestamptz(*in.EndedAt),
+			HourlyRate:     in.Rate(),
+			Notes:          toNullableText(in.Notes),
+			ID:             fromUUID(entryID),
+			WorkOrderID:    fromUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return models.ErrTimeEntryNotFound
+			}
+			return err
+		}
+		out, err = getTimeEntry(ctx, q, org_id, workOrderID, entryID)
+		return err
+	})
+	if err != nil {
+		if mapped := timeEntryError(err); mapped != nil {
+			return models.TimeEntry{}, mapped
+		}
+		slog.ErrorContext(ctx, "UpdateWorkOrderTimeEntry failed", "err", err)
+		return models.TimeEntry{}, err
+	}
+	return out, nil
+}
+
+func (p *pgRepo) DeleteWorkOrderTimeEntry(ctx context.Context, org_id, workOrderID, entryID uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderTimeEntry", "org_id", org_id.String(), "entry_id", entryID.String())
+	n, err := p.q.DeleteWorkOrderTimeEntry(ctx, db.DeleteWorkOrderTimeEntryParams{
+		ID:             fromUUID(entryID),
+		WorkOrderID:    fromUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "DeleteWorkOrderTimeEntry failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrTimeEntryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// internal/repo/work_order_dependencies.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order dependencies ----------------
+
+// isWorkOrderBlocked reports whether err is the CM423 raised by
+// trg_work_order_enforce_dependencies.
+func isWorkOrderBlocked(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "CM423"
+}
+
+// isLinkCycle reports whether err is the CM409 raised by the parent and
+// dependency cycle checks.
+func isLinkCycle(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "CM409"
+}
+
+// SetWorkOrderParent moves a work order under parentID (nil makes it
+// top-level) and returns its version. The parent must be a live work order
+// of the organisation; ifMatch behaves as in UpdateWorkOrderFromJSON.
+func (p *pgRepo) SetWorkOrderParent(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, parentID *uuid.UUID, ifMatch *int64) (int64, error) {
+	slog.DebugContext(ctx, "SetWorkOrderParent", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	var version int64
+	err := p.inTx(ctx, func(q *db.Queries) error {
+		args := db.SetWorkOrderParentParams{
+			OrganisationID:  fromUUID(org_id),
+			WorkOrderID:     toPgUUID(workOrderID),
+			UpdatedByID:     fromUUID(user_id),
+			ExpectedVersion: toNullInt8(ifMatch),
+		}
+		if parentID != nil {
+			if *parentID == workOrderID {
+				return models.ErrHierarchyCycle
+			}
+			if _, err := q.GetWorkOrderVersion(ctx, db.GetWorkOrderVersionParams{
+				WorkOrderID:    toPgUUID(*parentID),
+				OrganisationID: fromUUID(org_id),
+			}); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return models.ErrParentNotFound
+				}
+				return err
+			}
+			args.ParentID = toPgUUID(*parentID)
+		}
+		v, err := q.SetWorkOrderParent(ctx, args)
+		if err != nil {
+			return err
+		}
+		version = v
+		return nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, models.ErrHierarchyCycle), errors.Is(err, models.ErrParentNotFound):
+			return 0, err
+		case isLinkCycle(err):
+			return 0, models.ErrHierarchyCycle
+		case isForeignKeyViolation(err):
+			return 0, models.ErrParentNotFound
+		case isNoDataFound(err):
+			return 0, models.ErrWorkOrderNotFound
+		case errors.As(err, &pgErr) && pgErr.Code == "CM412":
+			return 0, models.ErrVersionMismatch
+		}
+		slog.ErrorContext(ctx, "SetWorkOrderParent failed", "err", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// ListWorkOrderDependencies returns what a work order waits for and what
+// waits for it.
+func (p *pgRepo) ListWorkOrderDependencies(ctx context.Context, org_id, workOrderID uuid.UUID) (models.WorkOrderDependencies, error) {
+	slog.DebugContext(ctx, "ListWorkOrderDependencies", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	out := models.WorkOrderDependencies{
+		BlockedBy: []models.WorkOrderLink{},
+		Blocking:  []models.WorkOrderLink{},
+	}
+	if _, err := p.GetWorkOrderVersion(ctx, org_id, workOrderID); err != nil {
+		return out, err
+	}
+	rows, err := p.q.ListWorkOrderDependencies(ctx, db.ListWorkOrderDependenciesParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderDependencies failed", "err", err)
+		return out, err
+	}
+	for _, r := range rows {
+		l := models.WorkOrderLink{
+			Direction: r.Direction,
+			ID:        toUUID(r.ID),
+			CustomID:  textOrEmpty(r.CustomID),
+			Title:     r.Title,
+			Status:    r.Status,
+			Resolved:  r.Resolved,
+			CreatedAt: r.CreatedAt.Time,
+		}
+		if l.Direction == models.DependencyBlocking {
+			out.Blocking = append(out.Blocking, l)
+			continue
+		}
+		out.BlockedBy = append(out.BlockedBy, l)
+		out.Blocked = out.Blocked || !l.Resolved
+	}
+	return out, nil
+}
+
+// AddWorkOrderDependency makes workOrderID wait for blockedByID.
+func (p *pgRepo) AddWorkOrderDependency(ctx context.Context, org_id, workOrderID, blockedByID, user_id uuid.UUID) error {
+	slog.DebugContext(ctx, "AddWorkOrderDependency", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "blocked_by_id", blockedByID.String())
+	if workOrderID == blockedByID {
+		return models.ErrSelfDependency
+	}
+	return p.inTx(ctx, func(q *db.Queries) error {
+		for _, c := range []struct {
+			id      uuid.UUID
+			missing error
+		}{{workOrderID, models.ErrWorkOrderNotFound}, {blockedByID, models.ErrBlockerNotFound}} {
+			if _, err := q.GetWorkOrderVersion(ctx, db.GetWorkOrderVersionParams{
+				WorkOrderID:    toPgUUID(c.id),
+				OrganisationID: fromUUID(org_id),
+			}); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return c.missing
+				}
+				return err
+			}
+		}
+		n, err := q.AddWorkOrderDependency(ctx, db.AddWorkOrderDependencyParams{
+			CreatedByID:    fromUUID(user_id),
+			WorkOrderID:    toPgUUID(workOrderID),
+			OrganisationID: fromUUID(org_id),
+			BlockedByID:    toPgUUID(blockedByID),
+		})
+		if err != nil {
+			if isLinkCycle(err) {
+				return models.ErrDependencyCycle
+			}
+			slog.ErrorContext(ctx, "AddWorkOrderDependency failed", "err", err)
+			return err
+		}
+		if n == 0 {
+			return models.ErrDependencyExists
+		}
+		return nil
+	})
+}
+
+// RemoveWorkOrderDependency drops the link between workOrderID and
+// blockedByID.
+func (p *pgRepo) RemoveWorkOrderDependency(ctx context.Context, org_id, workOrderID, blockedByID uuid.UUID) error {
+	slog.DebugContext(ctx, "RemoveWorkOrderDependency", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "blocked_by_id", blockedByID.String())
+	n, err := p.q.DeleteWorkOrderDependency(ctx, db.DeleteWorkOrderDependencyParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		BlockedByID:    toPgUUID(blockedByID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "RemoveWorkOrderDependency failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrDependencyNotFound
+	}
+	return nil
+}
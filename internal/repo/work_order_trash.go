@@ -0,0 +1,174 @@
+// internal/repo/work_order_trash.go
+package repo
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "yourapp/internal/db/gen"
+	"yourapp/internal/models"
+)
+
+// ---------------- Work order trash ----------------
+
+// purgeBatchSize bounds how many work orders (and their cascades) one
+// purge statement removes.
+const purgeBatchSize = 500
+
+// isNoDataFound reports whether err is the no_data_found (P0002) raised by
+// the work order functions for unknown, foreign or trashed IDs.
+func isNoDataFound(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "P0002"
+}
+
+// DeleteWorkOrderByID moves a work order to the trash. Its tasks, files,
+// comments and history are kept until it is purged.
+func (p *pgRepo) DeleteWorkOrderByID(ctx context.Context, org_id, workOrderID, user_id uuid.UUID) error {
+	slog.DebugContext(ctx, "DeleteWorkOrderByID", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	_, err := p.q.SoftDeleteWorkOrder(ctx, db.SoftDeleteWorkOrderParams{
+		OrganisationID: fromUUID(org_id),
+		WorkOrderID:    toPgUUID(workOrderID),
+		DeletedByID:    fromUUID(user_id),
+	})
+	if err != nil {
+		if isNoDataFound(err) {
+			return models.ErrWorkOrderNotFound
+		}
+		slog.ErrorContext(ctx, "DeleteWorkOrderByID failed", "err", err)
+	}
+	return err
+}
+
+// RestoreWorkOrder takes a work order out of the trash and returns its new
+// version.
+func (p *pgRepo) RestoreWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID) (int64, error) {
+	slog.DebugContext(ctx, "RestoreWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	version, err := p.q.RestoreWorkOrder(ctx, db.RestoreWorkOrderParams{
+		OrganisationID: fromUUID(org_id),
+		WorkOrderID:    toPgUUID(workOrderID),
+		RestoredByID:   fromUUID(user_id),
+	})
+	if err != nil {
+		if isNoDataFound(err) {
+			return 0, models.ErrWorkOrderNotInTrash
+		}
+		slog.ErrorContext(ctx, "RestoreWorkOrder failed", "err", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+func (p *pgRepo) ListTrashedWorkOrders(ctx context.Context, org_id uuid.UUID, search string) ([]models.TrashedWorkOrder, error) {
+	slog.DebugContext(ctx, "ListTrashedWorkOrders", "org_id", org_id.String())
+	rows, err := p.q.ListTrashedWorkOrders(ctx, db.ListTrashedWorkOrdersParams{
+		OrganisationID: fromUUID(org_id),
+		Search:         toNullableText(strings.TrimSpace(search)),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "ListTrashedWorkOrders failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.TrashedWorkOrder, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, models.TrashedWorkOrder{
+			ID:            toUUID(r.ID),
+			CustomID:      textOrEmpty(r.CustomID),
+			Title:         r.Title,
+			Status:        r.Status,
+			Priority:      r.Priority,
+			DeletedAt:     r.DeletedAt.Time,
+			DeletedByID:   optUUID(r.DeletedByID),
+			DeletedByName: textOrEmpty(r.DeletedByName),
+		})
+	}
+	return out, nil
+}
+
+// PurgeTrashedWorkOrder permanently deletes one work order from the trash.
+func (p *pgRepo) PurgeTrashedWorkOrder(ctx context.Context, org_id, workOrderID uuid.UUID) error {
+	slog.DebugContext(ctx, "PurgeTrashedWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	n, err := p.q.PurgeTrashedWorkOrder(ctx, db.PurgeTrashedWorkOrderParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "PurgeTrashedWorkOrder failed", "err", err)
+		return err
+	}
+	if n == 0 {
+		return models.ErrWorkOrderNotInTrash
+	}
+	return nil
+}
+
+// PurgeTrashedWorkOrders permanently deletes the work orders trashed before
+// deletedBefore, in batches, for one organisation or (orgID nil) all of
+// them, and returns how many went.
+func (p *pgRepo) PurgeTrashedWorkOrders(ctx context.Context, orgID *uuid.UUID, deletedBefore time.Time) (int64, error) {
+	slog.DebugContext(ctx, "PurgeTrashedWorkOrders", "deleted_before", deletedBefore)
+	org := pgtype.UUID{}
+	if orgID != nil {
+		org = fromUUID(*orgID)
+	}
+	var total int64
+	for {
+		n, err := p.q.PurgeTrashedWorkOrders(ctx, db.PurgeTrashedWorkOrdersParams{
+			DeletedBefore:  toTimestamptz(deletedBefore),
+			OrganisationID: org,
+			BatchSize:      purgeBatchSize,
+		})
+		total += n
+		if err != nil {
+			slog.ErrorContext(ctx, "PurgeTrashedWorkOrders failed", "err", err, "purged", total)
+			return total, err
+		}
+		if n < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// StartTrashPurger launches a background goroutine that permanently deletes
+// work orders which have been in the trash for longer than retention, once
+// at start and then every interval. A retention of 0 keeps them forever.
+// It stops when ctx is done.
+func StartTrashPurger(ctx context.Context, r Repo, retention, interval time.Duration) {
+	if retention <= 0 {
+		slog.InfoContext(ctx, "work order trash purge disabled")
+		return
+	}
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	purge := func() {
+		n, err := r.PurgeTrashedWorkOrders(ctx, nil, time.Now().Add(-retention))
+		if err != nil {
+			slog.ErrorContext(ctx, "work order trash purge failed", "err", err)
+			return
+		}
+		if n > 0 {
+			slog.InfoContext(ctx, "purged work orders from the trash", "count", n, "retention", retention)
+		}
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		purge()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purge()
+			}
+		}
+	}()
+}
@@ -7,6 +7,8 @@ import (
 	"log/slog"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	db "yourapp/internal/db/gen"
 	"yourapp/internal/models"
@@ -14,10 +16,16 @@ import (
 
 // ---------------- Work Orders ----------------
 
-func (p *pgRepo) GetWorkOrderDetail(ctx context.Context, id uuid.UUID) (json.RawMessage, error) {
-	slog.DebugContext(ctx, "GetWorkOrderDetail", "work_order_id", id.String())
-	row, err := p.q.GetWorkOrderDetail(ctx, toPgUUID(id))
+func (p *pgRepo) GetWorkOrderDetail(ctx context.Context, org_id uuid.UUID, id uuid.UUID) (json.RawMessage, error) {
+	slog.DebugContext(ctx, "GetWorkOrderDetail", "org_id", org_id.String(), "work_order_id", id.String())
+	row, err := p.q.GetWorkOrderDetail(ctx, db.GetWorkOrderDetailParams{
+		WorkOrderID:    toPgUUID(id),
+		OrganisationID: fromUUID(org_id),
+	})
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, models.ErrWorkOrderNotFound
+		}
 		slog.ErrorContext(ctx, "GetWorkOrderDetail failed", "err", err)
 		return nil, err
 	}
@@ -77,15 +85,18 @@ func (p *pgRepo) ListWorkOrdersPaged(ctx context.Context, org_id uuid.UUID, arg
 	wos := make([]models.WorkOrder, 0, len(rows))
 	for _, r := range rows {
 		wo := models.WorkOrder{
-			ID:        toUUID(r.ID),
-			OrgID:     toUUID(r.OrganisationID),
-			Title:     r.Title,
-			Status:    r.Status,
-			Priority:  r.Priority,
-			CreatedAt: toTime(r.CreatedAt),
-			UpdatedAt: toTime(r.UpdatedAt),
-			DueDate:   toTime(r.DueDate),
-			CustomID:  fromText(r.CustomID),
+			ID:          toUUID(r.ID),
+			OrgID:       toUUID(r.OrganisationID),
+			Version:     r.Version,
+			Title:       r.Title,
+			Status:      r.Status,
+			Priority:    r.Priority,
+			CreatedAt:   toTime(r.CreatedAt),
+			UpdatedAt:   toTime(r.UpdatedAt),
+			DueDate:     toTime(r.DueDate),
+			CustomID:    fromText(r.CustomID),
+			SLABreached: r.SlaBreached,
+			SLAAtRisk:   r.SlaAtRisk,
 		}
 		wo.Description = fromText(r.Description)
 		wos = append(wos, wo)
@@ -94,14 +105,176 @@ func (p *pgRepo) ListWorkOrdersPaged(ctx context.Context, org_id uuid.UUID, arg
 	return wos, count, nil
 }
 
-func (p *pgRepo) ChangeWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, status string) error {
-	slog.DebugContext(ctx, "ChangeWorkOrderStatus", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "status", status)
+func (p *pgRepo) GetWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (models.WorkOrderStatus, error) {
+	slog.DebugContext(ctx, "GetWorkOrderStatus", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	args := db.GetWorkOrderStatusParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	}
+	status, err := p.q.GetWorkOrderStatus(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", models.ErrWorkOrderNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderStatus failed", "err", err)
+		return "", err
+	}
+	return models.WorkOrderStatus(status), nil
+}
+
+func (p *pgRepo) GetWorkOrderVersion(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (int64, error) {
+	slog.DebugContext(ctx, "GetWorkOrderVersion", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	args := db.GetWorkOrderVersionParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	}
+	version, err := p.q.GetWorkOrderVersion(ctx, args)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, models.ErrWorkOrderNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderVersion failed", "err", err)
+		return 0, err
+	}
+	return version, nil
+}
+
+// ChangeWorkOrderStatus moves a work order from -> to and records the transition.
+// The caller is expected to have validated the transition; if the stored status
+// is no longer `from` this returns models.ErrStatusConflict, or
+// models.ErrVersionMismatch when ifMatch was given. Starting a work order
+// whose blockers are not done returns models.ErrWorkOrderBlocked, and
+// completing one whose checklist policy is not met
+// models.ErrChecklistIncomplete.
+func (p *pgRepo) ChangeWorkOrderStatus(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, from, to models.WorkOrderStatus, reason string, ifMatch *int64) error {
+	slog.DebugContext(ctx, "ChangeWorkOrderStatus", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "from", from, "to", to)
 	args := db.ChangeWorkOrderStatusParams{
+		ToStatus:        string(to),
+		ChangedByID:     fromUUID(user_id),
+		WorkOrderID:     toPgUUID(workOrderID),
+		OrganisationID:  fromUUID(org_id),
+		FromStatus:      string(from),
+		ExpectedVersion: toNullInt8(ifMatch),
+		Reason:          toNullableText(reason),
+	}
+	if _, err := p.q.ChangeWorkOrderStatus(ctx, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if ifMatch != nil {
+				return models.ErrVersionMismatch
+			}
+			return models.ErrStatusConflict
+		}
+		if isSignatureRequired(err) {
+			return models.ErrSignatureRequired
+		}
+		if isWorkOrderBlocked(err) {
+			return models.ErrWorkOrderBlocked
+		}
+		if isChecklistIncomplete(err) {
+			return models.ErrChecklistIncomplete
+		}
+		slog.ErrorContext(ctx, "ChangeWorkOrderStatus failed", "err", err)
+		return err
+	}
+	return nil
+}
+
+// isSignatureRequired reports whether err is the CM428 raised by
+// trg_work_order_require_signature.
+func isSignatureRequired(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "CM428"
+}
+
+func (p *pgRepo) GetWorkOrderCompletionState(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) (models.WorkOrderCompletionState, error) {
+	slog.DebugContext(ctx, "GetWorkOrderCompletionState", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	row, err := p.q.GetWorkOrderCompletionState(ctx, db.GetWorkOrderCompletionStateParams{
+		WorkOrderID:    toPgUUID(workOrderID),
+		OrganisationID: fromUUID(org_id),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.WorkOrderCompletionState{}, models.ErrWorkOrderNotFound
+		}
+		slog.ErrorContext(ctx, "GetWorkOrderCompletionState failed", "err", err)
+		return models.WorkOrderCompletionState{}, err
+	}
+	return models.WorkOrderCompletionState{
+		Status:            models.WorkOrderStatus(row.Status),
+		RequiredSignature: row.RequiredSignature,
+		SignatureID:       optUUID(row.SignatureID),
+		Version:           row.Version,
+	}, nil
+}
+
+// CompleteWorkOrder moves a work order from -> COMPLETE, optionally storing a
+// signature file, and records the transition. Error contract matches
+// ChangeWorkOrderStatus, plus models.ErrSignatureRequired.
+func (p *pgRepo) CompleteWorkOrder(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, from models.WorkOrderStatus, signatureID *uuid.UUID, reason string, ifMatch *int64) error {
+	slog.DebugContext(ctx, "CompleteWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "from", from)
+	args := db.CompleteWorkOrderParams{
+		CompletedByID:   fromUUID(user_id),
+		WorkOrderID:     toPgUUID(workOrderID),
+		OrganisationID:  fromUUID(org_id),
+		FromStatus:      string(from),
+		ExpectedVersion: toNullInt8(ifMatch),
+		Reason:          toNullableText(reason),
+	}
+	if signatureID != nil {
+		args.SignatureID = toPgUUID(*signatureID)
+	}
+	if _, err := p.q.CompleteWorkOrder(ctx, args); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if ifMatch != nil {
+				return models.ErrVersionMismatch
+			}
+			return models.ErrStatusConflict
+		}
+		if isSignatureRequired(err) {
+			return models.ErrSignatureRequired
+		}
+		if isWorkOrderBlocked(err) {
+			return models.ErrWorkOrderBlocked
+		}
+		if isChecklistIncomplete(err) {
+			return models.ErrChecklistIncomplete
+		}
+		slog.ErrorContext(ctx, "CompleteWorkOrder failed", "err", err)
+		return err
+	}
+	return nil
+}
+
+func (p *pgRepo) ListWorkOrderStatusHistory(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID) ([]models.WorkOrderStatusChange, error) {
+	slog.DebugContext(ctx, "ListWorkOrderStatusHistory", "org_id", org_id.String(), "work_order_id", workOrderID.String())
+	params := db.ListWorkOrderStatusHistoryParams{
 		OrganisationID: fromUUID(org_id),
 		WorkOrderID:    toPgUUID(workOrderID),
-		Status:         status,
 	}
-	return p.q.ChangeWorkOrderStatus(ctx, args)
+	rows, err := p.q.ListWorkOrderStatusHistory(ctx, params)
+	if err != nil {
+		slog.ErrorContext(ctx, "ListWorkOrderStatusHistory failed", "err", err)
+		return nil, err
+	}
+	out := make([]models.WorkOrderStatusChange, 0, len(rows))
+	for _, r := range rows {
+		c := models.WorkOrderStatusChange{
+			ID:              toUUID(r.ID),
+			FromStatus:      fromText(r.FromStatus),
+			ToStatus:        r.ToStatus,
+			Reason:          fromText(r.Reason),
+			ChangedAt:       toTime(r.ChangedAt),
+			ChangedByName:   fromText(r.ChangedByName),
+			SecondsInStatus: r.SecondsInStatus,
+		}
+		if r.ChangedByID.Valid {
+			id := toUUID(r.ChangedByID)
+			c.ChangedByID = &id
+		}
+		out = append(out, c)
+	}
+	slog.DebugContext(ctx, "ListWorkOrderStatusHistory ok", "count", len(out))
+	return out, nil
 }
 
 func (p *pgRepo) CreateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, user_id uuid.UUID, payload []byte) (uuid.UUID, error) {
@@ -113,33 +286,91 @@ func (p *pgRepo) CreateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID,
 	}
 	id, err := p.q.CreateWorkOrderFromJSON(ctx, args)
 	if err != nil {
+		if mapped := createPayloadError(err); mapped != nil {
+			return uuid.Nil, mapped
+		}
 		slog.ErrorContext(ctx, "CreateWorkOrderFromJSON failed", "err", err)
 		return uuid.Nil, err
 	}
 	return toUUID(id), nil
 }
 
-func (p *pgRepo) UpdateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, payload []byte) (uuid.UUID, error) {
-	slog.DebugContext(ctx, "UpdateWorkOrderFromJSON", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
-	args := db.UpdateWorkOrderFromJSONParams{
+// createPayloadError maps the payload errors raised by
+// create_work_order_from_json (CM422 for a foreign category, CM424 for an
+// unknown task base, FK and cast failures) to domain errors, or returns nil.
+func createPayloadError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.Code {
+	case "CM422":
+		return models.ErrCategoryNotFound
+	case "CM424":
+		return models.ErrTaskBaseNotFound
+	case "23503":
+		return models.ErrInvalidReference
+	case "22P02", "22007", "22008":
+		return models.ErrInvalidWorkOrderPayload
+	}
+	return nil
+}
+
+// DuplicateWorkOrder clones a work order (fields, assignees, customers, a
+// fresh task list and file links) and applies overrides, a partial create
+// payload, on top.
+func (p *pgRepo) DuplicateWorkOrder(ctx context.Context, org_id, workOrderID, user_id uuid.UUID, overrides []byte) (uuid.UUID, error) {
+	slog.DebugContext(ctx, "DuplicateWorkOrder", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	id, err := p.q.DuplicateWorkOrder(ctx, db.DuplicateWorkOrderParams{
 		OrganisationID: fromUUID(org_id),
 		WorkOrderID:    toPgUUID(workOrderID),
-		UpdatedByID:    fromUUID(user_id),
-		Payload:        payload,
-	}
-	id, err := p.q.UpdateWorkOrderFromJSON(ctx, args)
+		CreatedByID:    fromUUID(user_id),
+		Overrides:      overrides,
+	})
 	if err != nil {
-		slog.ErrorContext(ctx, "UpdateWorkOrderFromJSON failed", "err", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "P0002" {
+			return uuid.Nil, models.ErrWorkOrderNotFound
+		}
+		if mapped := createPayloadError(err); mapped != nil {
+			return uuid.Nil, mapped
+		}
+		slog.ErrorContext(ctx, "DuplicateWorkOrder failed", "err", err)
 		return uuid.Nil, err
 	}
 	return toUUID(id), nil
 }
 
-func (p *pgRepo) DeleteWorkOrderByID(ctx context.Context, org_id, workOrderID uuid.UUID) error {
-	slog.DebugContext(ctx, "DeleteWorkOrderByID", "org_id", org_id.String(), "work_order_id", workOrderID.String())
-	args := db.DeleteWorkOrderByIDParams{
-		OrganisationID: fromUUID(org_id),
-		ID:             toPgUUID(workOrderID),
+// UpdateWorkOrderFromJSON applies a patch payload. When ifMatch is set the
+// update only happens if the stored version still matches, otherwise
+// models.ErrVersionMismatch is returned.
+func (p *pgRepo) UpdateWorkOrderFromJSON(ctx context.Context, org_id uuid.UUID, workOrderID uuid.UUID, user_id uuid.UUID, payload []byte, ifMatch *int64) (uuid.UUID, error) {
+	slog.DebugContext(ctx, "UpdateWorkOrderFromJSON", "org_id", org_id.String(), "work_order_id", workOrderID.String(), "user_id", user_id.String())
+	args := db.UpdateWorkOrderFromJSONParams{
+		OrganisationID:  fromUUID(org_id),
+		WorkOrderID:     toPgUUID(workOrderID),
+		UpdatedByID:     fromUUID(user_id),
+		Payload:         payload,
+		ExpectedVersion: toNullInt8(ifMatch),
 	}
-	return p.q.DeleteWorkOrderByID(ctx, args)
+	id, err := p.q.UpdateWorkOrderFromJSON(ctx, args)
+	if err != nil {
+		// update_work_order_from_json_if_match raises no_data_found for
+		// unknown/foreign IDs, CM412 for a stale version and CM422 for a
+		// foreign category
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "P0002":
+				return uuid.Nil, models.ErrWorkOrderNotFound
+			case "CM412":
+				return uuid.Nil, models.ErrVersionMismatch
+			case "CM422":
+				return uuid.Nil, models.ErrCategoryNotFound
+			}
+		}
+		slog.ErrorContext(ctx, "UpdateWorkOrderFromJSON failed", "err", err)
+		return uuid.Nil, err
+	}
+	return toUUID(id), nil
 }
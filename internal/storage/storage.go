@@ -0,0 +1,36 @@
+// internal/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store is the blob backend behind uploaded files. Keys are opaque,
+// slash-separated paths chosen by the caller (e.g. "<org>/2025/09/<uuid>").
+type Store interface {
+	// Name is recorded on each files row so a later backend migration can
+	// tell where an object lives.
+	Name() string
+	// Put streams r into key and returns the number of bytes written.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open returns a reader for key or ErrNotFound.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key; deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// New builds the backend named by backend. Only "local" exists today; an
+// S3-compatible backend (MinIO in dev) slots in here behind the same interface.
+func New(backend, localPath string) (Store, error) {
+	switch backend {
+	case "", "local":
+		return NewLocal(localPath)
+	default:
+		return nil, fmt.Errorf("storage: unsupported backend %q", backend)
+	}
+}